@@ -0,0 +1,135 @@
+package ach
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// entryDescription is what the beneficiary sees on their statement.
+const entryDescription = "PAYMENT"
+
+// Exporter writes the pending outbound payments to NACHA files in the outbox
+// directory, which whatever sends files to the ACH operator picks up.
+type Exporter struct {
+	store  db.Store
+	config utils.Config
+	now    func() time.Time
+}
+
+func NewExporter(config utils.Config, store db.Store) *Exporter {
+	return &Exporter{
+		store:  store,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Export writes one file with up to the configured batch size of pending
+// payments and returns it with the path it was written to. It returns
+// db.ErrNoOutboundPayments when nothing is pending.
+//
+// The file is written under a temporary name and only renamed into place
+// once the payments are marked exported, so the outbox never holds a file
+// whose payments would be exported again.
+func (exporter *Exporter) Export(ctx context.Context) (db.ExportOutboundPaymentsTxResult, string, error) {
+	now := exporter.now().UTC()
+	calendar, err := db.LoadCalendar(ctx, exporter.store, now)
+	if err != nil {
+		return db.ExportOutboundPaymentsTxResult{}, "", err
+	}
+	effectiveDate := calendar.NextBusinessDay(now.AddDate(0, 0, 1))
+	var tmpPath string
+	result, err := exporter.store.ExportOutboundPaymentsTx(ctx, db.ExportOutboundPaymentsTxParams{
+		Limit:       exporter.config.ACHExportBatchSize,
+		TracePrefix: rdfi(exporter.config.ACHOriginRouting),
+		FileName: func(modifier string) string {
+			return fmt.Sprintf("ach-%s-%s.txt", now.Format("20060102"), modifier)
+		},
+		Write: func(file db.AchFile, payments []db.OutboundPayment) error {
+			if err := os.MkdirAll(exporter.config.ACHOutboxDir, 0o750); err != nil {
+				return err
+			}
+			// a retried transaction may name the file differently
+			if tmpPath != "" {
+				os.Remove(tmpPath)
+			}
+			tmpPath = filepath.Join(exporter.config.ACHOutboxDir, "."+file.FileName+".tmp")
+			return writeFile(tmpPath, exporter.file(now, effectiveDate, file, payments))
+		},
+	})
+	if err != nil {
+		if tmpPath != "" {
+			os.Remove(tmpPath)
+		}
+		return result, "", err
+	}
+	path := filepath.Join(exporter.config.ACHOutboxDir, result.File.FileName)
+	if err := os.Rename(tmpPath, path); err != nil {
+		return result, "", fmt.Errorf("ach file %d was exported but not moved into the outbox: %w", result.File.ID, err)
+	}
+	return result, path, nil
+}
+
+// file builds the ACH file of the exported payments: one batch of credits
+// to the beneficiaries, effective on effectiveDate.
+func (exporter *Exporter) file(now, effectiveDate time.Time, achFile db.AchFile, payments []db.OutboundPayment) File {
+	batch := Batch{
+		ServiceClassCode: ServiceClassCredits,
+		CompanyName:      exporter.config.ACHCompanyName,
+		CompanyID:        exporter.config.ACHCompanyID,
+		SECCode:          SECPPD,
+		EntryDescription: entryDescription,
+		EffectiveDate:    effectiveDate,
+		ODFI:             rdfi(exporter.config.ACHOriginRouting),
+		Number:           1,
+	}
+	for _, payment := range payments {
+		code := CheckingCredit
+		if payment.AccountType == db.AccountTypeSavings {
+			code = SavingsCredit
+		}
+		batch.Entries = append(batch.Entries, Entry{
+			TransactionCode: code,
+			RoutingNumber:   payment.RoutingNumber,
+			AccountNumber:   payment.AccountNumber,
+			Amount:          payment.Amount,
+			IndividualID:    fmt.Sprint(payment.ID),
+			Name:            payment.BeneficiaryName,
+			TraceNumber:     payment.TraceNumber.String,
+		})
+	}
+	return File{
+		ImmediateDestination: exporter.config.ACHDestinationRouting,
+		ImmediateOrigin:      exporter.config.ACHOriginRouting,
+		DestinationName:      exporter.config.ACHDestinationName,
+		OriginName:           exporter.config.ACHOriginName,
+		CreatedAt:            now,
+		IDModifier:           achFile.FileIDModifier,
+		Batches:              []Batch{batch},
+	}
+}
+
+// writeFile writes file to a new file at path and syncs it to disk.
+func writeFile(path string, file File) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return err
+	}
+	if err := file.Write(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
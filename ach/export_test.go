@@ -0,0 +1,173 @@
+package ach
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func testExporter(t *testing.T, store db.Store) *Exporter {
+	exporter := NewExporter(utils.Config{
+		ACHOutboxDir:          filepath.Join(t.TempDir(), "outbox"),
+		ACHOriginRouting:      "123456780",
+		ACHOriginName:         "DIGI BANK",
+		ACHDestinationRouting: "011000015",
+		ACHDestinationName:    "FEDERAL RESERVE BANK",
+		ACHCompanyID:          "1234567890",
+		ACHCompanyName:        "DIGI BANK",
+		ACHExportBatchSize:    100,
+	}, store)
+	// a Friday, so the payments are effective on Monday
+	exporter.now = func() time.Time { return time.Date(2026, 10, 23, 22, 0, 0, 0, time.UTC) }
+	return exporter
+}
+
+// exportTx stands in for ExportOutboundPaymentsTx, calling the Write
+// callback like the store does and returning commitErr as if committing failed.
+func exportTx(payments []db.OutboundPayment, commitErr error) func(context.Context, db.ExportOutboundPaymentsTxParams) (db.ExportOutboundPaymentsTxResult, error) {
+	return func(_ context.Context, arg db.ExportOutboundPaymentsTxParams) (db.ExportOutboundPaymentsTxResult, error) {
+		result := db.ExportOutboundPaymentsTxResult{
+			File:     db.AchFile{ID: 1, FileName: arg.FileName("A"), FileIDModifier: "A", EntryCount: int32(len(payments))},
+			Payments: payments,
+		}
+		for i := range result.Payments {
+			result.Payments[i].TraceNumber = sql.NullString{String: db.TraceNumber(arg.TracePrefix, int64(i)+1), Valid: true}
+		}
+		if err := arg.Write(result.File, result.Payments); err != nil {
+			return result, err
+		}
+		return result, commitErr
+	}
+}
+
+func TestExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	payments := []db.OutboundPayment{
+		{ID: 12, BeneficiaryName: "Jane Doe", RoutingNumber: "011000015", AccountNumber: "12345678", AccountType: db.AccountTypeChecking, Amount: 2500},
+		{ID: 13, BeneficiaryName: "John Roe", RoutingNumber: "021000021", AccountNumber: "87654321", AccountType: db.AccountTypeSavings, Amount: 1000},
+	}
+	store := mockdb.NewMockStore(ctrl)
+	expectHolidays(store)
+	store.EXPECT().
+		ExportOutboundPaymentsTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(exportTx(payments, nil))
+
+	exporter := testExporter(t, store)
+	result, path, err := exporter.Export(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Payments, 2)
+	require.Equal(t, filepath.Join(exporter.config.ACHOutboxDir, "ach-20261023-A.txt"), path)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	file, err := Parse(f)
+	require.NoError(t, err)
+	require.Equal(t, "011000015", file.ImmediateDestination)
+	require.Equal(t, "A", file.IDModifier)
+	require.Len(t, file.Batches, 1)
+	batch := file.Batches[0]
+	require.Equal(t, ServiceClassCredits, batch.ServiceClassCode)
+	require.Equal(t, "12345678", batch.ODFI)
+	require.Equal(t, time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC), batch.EffectiveDate)
+	require.Len(t, batch.Entries, 2)
+	require.Equal(t, CheckingCredit, batch.Entries[0].TransactionCode)
+	require.Equal(t, SavingsCredit, batch.Entries[1].TransactionCode)
+	require.Equal(t, "123456780000001", batch.Entries[0].TraceNumber)
+	require.Equal(t, "JOHN ROE", batch.Entries[1].Name)
+
+	entries, err := os.ReadDir(exporter.config.ACHOutboxDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestExportFailure(t *testing.T) {
+	payments := []db.OutboundPayment{
+		{ID: 12, BeneficiaryName: "Jane Doe", RoutingNumber: "011000015", AccountNumber: "12345678", AccountType: db.AccountTypeChecking, Amount: 2500},
+	}
+
+	t.Run("NothingPending", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mockdb.NewMockStore(ctrl)
+		expectHolidays(store)
+		store.EXPECT().
+			ExportOutboundPaymentsTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.ExportOutboundPaymentsTxResult{}, db.ErrNoOutboundPayments)
+
+		exporter := testExporter(t, store)
+		_, _, err := exporter.Export(context.Background())
+		require.ErrorIs(t, err, db.ErrNoOutboundPayments)
+	})
+
+	t.Run("CommitFailed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := mockdb.NewMockStore(ctrl)
+		expectHolidays(store)
+		store.EXPECT().
+			ExportOutboundPaymentsTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(exportTx(payments, sql.ErrConnDone))
+
+		exporter := testExporter(t, store)
+		_, path, err := exporter.Export(context.Background())
+		require.True(t, errors.Is(err, sql.ErrConnDone))
+		require.Empty(t, path)
+
+		// the payments are still pending, so no file may be left behind
+		entries, err := os.ReadDir(exporter.config.ACHOutboxDir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+}
+
+func TestExportSkipsHolidays(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	payments := []db.OutboundPayment{
+		{ID: 12, BeneficiaryName: "Jane Doe", RoutingNumber: "011000015", AccountNumber: "12345678", AccountType: db.AccountTypeChecking, Amount: 2500},
+	}
+	store := mockdb.NewMockStore(ctrl)
+	// the Monday after the export is a holiday
+	expectHolidays(store, time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC))
+	store.EXPECT().
+		ExportOutboundPaymentsTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(exportTx(payments, nil))
+
+	_, path, err := testExporter(t, store).Export(context.Background())
+	require.NoError(t, err)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	file, err := Parse(f)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 10, 27, 0, 0, 0, 0, time.UTC), file.Batches[0].EffectiveDate)
+}
+
+func expectHolidays(store *mockdb.MockStore, dates ...time.Time) {
+	holidays := make([]db.Holiday, len(dates))
+	for i, date := range dates {
+		holidays[i] = db.Holiday{Date: date, Name: "Holiday"}
+	}
+	store.EXPECT().ListHolidays(gomock.Any(), gomock.Any()).Times(1).Return(holidays, nil)
+}
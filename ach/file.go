@@ -0,0 +1,345 @@
+// Package ach writes and reads NACHA formatted ACH files, the fixed-width
+// files US banks exchange to send payments to each other.
+package ach
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrFieldOverflow is returned when writing a number that doesn't fit in
+// its field, like an amount of ten billion cents or more.
+var ErrFieldOverflow = errors.New("ach: number doesn't fit in its field")
+
+// MaxAmount is the largest amount, in cents, an entry can carry.
+const MaxAmount = 9_999_999_999
+
+// RecordLength is the length of every record in a NACHA file.
+const RecordLength = 94
+
+// blockingFactor is the number of records in a block; files are padded
+// with records of nines to a whole number of blocks.
+const blockingFactor = 10
+
+// Service class codes of a batch.
+const (
+	ServiceClassMixed   = 200
+	ServiceClassCredits = 220
+	ServiceClassDebits  = 225
+)
+
+// Transaction codes of an entry.
+const (
+	CheckingReturn = 21
+	CheckingCredit = 22
+	CheckingDebit  = 27
+	SavingsReturn  = 31
+	SavingsCredit  = 32
+	SavingsDebit   = 37
+)
+
+// SECPPD is the standard entry class of payments to consumer accounts.
+const SECPPD = "PPD"
+
+// File is an ACH file: a header, its batches and, implicitly, the control
+// records that Write computes and Parse checks.
+type File struct {
+	// ImmediateDestination and ImmediateOrigin are the routing numbers of
+	// the bank the file is sent to and the bank that sends it.
+	ImmediateDestination string
+	ImmediateOrigin      string
+	DestinationName      string
+	OriginName           string
+	CreatedAt            time.Time
+	// IDModifier tells apart the files sent on the same day, A to Z then 0 to 9.
+	IDModifier string
+	Batches    []Batch
+}
+
+// Batch is a group of entries from one originating company.
+type Batch struct {
+	ServiceClassCode int
+	CompanyName      string
+	CompanyID        string
+	SECCode          string
+	EntryDescription string
+	EffectiveDate    time.Time
+	// ODFI is the first eight digits of the originating bank's routing number.
+	ODFI    string
+	Number  int
+	Entries []Entry
+}
+
+// Entry is a single payment to, or return from, an account at another bank.
+type Entry struct {
+	TransactionCode int
+	// RoutingNumber is the nine digit routing number of the receiving bank.
+	RoutingNumber string
+	AccountNumber string
+	// Amount is in cents.
+	Amount       int64
+	IndividualID string
+	Name         string
+	TraceNumber  string
+	// Return is set on entries a receiving bank sent back.
+	Return *Return
+}
+
+// Return is the addenda of a returned entry.
+type Return struct {
+	// Code is the reason of the return, like R03 for no such account.
+	Code string
+	// OriginalTraceNumber is the trace number of the entry that was returned.
+	OriginalTraceNumber string
+	// OriginalRDFI is the first eight digits of the routing number the
+	// entry was sent to.
+	OriginalRDFI string
+	Info         string
+}
+
+// IsCredit reports whether the entry credits the receiving account.
+func (entry Entry) IsCredit() bool {
+	return entry.TransactionCode%10 <= 4
+}
+
+// Write writes the file with its control records and padding. It fails,
+// writing nothing, when a number doesn't fit in its field.
+func (file File) Write(w io.Writer) error {
+	records := []string{file.header()}
+	var fileControl control
+	for _, batch := range file.Batches {
+		header, err := batch.header()
+		if err != nil {
+			return fmt.Errorf("ach: batch %d: %w", batch.Number, err)
+		}
+		records = append(records, header)
+		var batchControl control
+		for _, entry := range batch.Entries {
+			record, err := entry.record()
+			if err != nil {
+				return fmt.Errorf("ach: entry %s: %w", entry.TraceNumber, err)
+			}
+			records = append(records, record)
+			batchControl.add(entry)
+			if entry.Return != nil {
+				records = append(records, entry.Return.record(entry.TraceNumber))
+				batchControl.entries++
+			}
+		}
+		control, err := batch.control(batchControl)
+		if err != nil {
+			return fmt.Errorf("ach: batch %d control: %w", batch.Number, err)
+		}
+		records = append(records, control)
+		fileControl.merge(batchControl)
+	}
+	control, err := file.control(fileControl, len(records)+1)
+	if err != nil {
+		return fmt.Errorf("ach: file control: %w", err)
+	}
+	records = append(records, control)
+	for len(records)%blockingFactor != 0 {
+		records = append(records, strings.Repeat("9", RecordLength))
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, record := range records {
+		if len(record) != RecordLength {
+			return fmt.Errorf("ach: record %q is %d characters long", record, len(record))
+		}
+		bw.WriteString(record)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// control holds the totals of a batch or file control record.
+type control struct {
+	batches int
+	entries int
+	hash    int64
+	debits  int64
+	credits int64
+}
+
+func (c *control) add(entry Entry) {
+	c.entries++
+	c.hash += digits(rdfi(entry.RoutingNumber))
+	if entry.IsCredit() {
+		c.credits += entry.Amount
+	} else {
+		c.debits += entry.Amount
+	}
+}
+
+func (c *control) merge(batch control) {
+	c.batches++
+	c.entries += batch.entries
+	c.hash += batch.hash
+	c.debits += batch.debits
+	c.credits += batch.credits
+}
+
+// entryHash is the hash as written: the last ten digits of the sum.
+func (c control) entryHash() int64 {
+	return c.hash % 10_000_000_000
+}
+
+func (file File) header() string {
+	return "1" + "01" +
+		alpha(" "+file.ImmediateDestination, 10) +
+		alpha(" "+file.ImmediateOrigin, 10) +
+		file.CreatedAt.Format("060102") +
+		file.CreatedAt.Format("1504") +
+		alpha(file.IDModifier, 1) +
+		"094" + "10" + "1" +
+		alpha(file.DestinationName, 23) +
+		alpha(file.OriginName, 23) +
+		alpha("", 8)
+}
+
+func (file File) control(totals control, records int) (string, error) {
+	var f fields
+	blocks := (records + blockingFactor - 1) / blockingFactor
+	return "9" +
+		f.numeric(int64(totals.batches), 6) +
+		f.numeric(int64(blocks), 6) +
+		f.numeric(int64(totals.entries), 8) +
+		f.numeric(totals.entryHash(), 10) +
+		f.numeric(totals.debits, 12) +
+		f.numeric(totals.credits, 12) +
+		alpha("", 39), f.err
+}
+
+func (batch Batch) header() (string, error) {
+	var f fields
+	return "5" +
+		f.numeric(int64(batch.ServiceClassCode), 3) +
+		alpha(batch.CompanyName, 16) +
+		alpha("", 20) +
+		alpha(batch.CompanyID, 10) +
+		alpha(batch.SECCode, 3) +
+		alpha(batch.EntryDescription, 10) +
+		alpha("", 6) +
+		batch.EffectiveDate.Format("060102") +
+		alpha("", 3) +
+		"1" +
+		alpha(batch.ODFI, 8) +
+		f.numeric(int64(batch.Number), 7), f.err
+}
+
+func (batch Batch) control(totals control) (string, error) {
+	var f fields
+	return "8" +
+		f.numeric(int64(batch.ServiceClassCode), 3) +
+		f.numeric(int64(totals.entries), 6) +
+		f.numeric(totals.entryHash(), 10) +
+		f.numeric(totals.debits, 12) +
+		f.numeric(totals.credits, 12) +
+		alpha(batch.CompanyID, 10) +
+		alpha("", 19) +
+		alpha("", 6) +
+		alpha(batch.ODFI, 8) +
+		f.numeric(int64(batch.Number), 7), f.err
+}
+
+func (entry Entry) record() (string, error) {
+	var f fields
+	addenda := "0"
+	if entry.Return != nil {
+		addenda = "1"
+	}
+	return "6" +
+		f.numeric(int64(entry.TransactionCode), 2) +
+		alpha(entry.RoutingNumber, 9) +
+		alpha(entry.AccountNumber, 17) +
+		f.numeric(entry.Amount, 10) +
+		alpha(entry.IndividualID, 15) +
+		alpha(entry.Name, 22) +
+		alpha("", 2) +
+		addenda +
+		alpha(entry.TraceNumber, 15), f.err
+}
+
+func (ret Return) record(traceNumber string) string {
+	return "7" + "99" +
+		alpha(ret.Code, 3) +
+		alpha(ret.OriginalTraceNumber, 15) +
+		alpha("", 6) +
+		alpha(ret.OriginalRDFI, 8) +
+		alpha(ret.Info, 44) +
+		alpha(traceNumber, 15)
+}
+
+// numeric formats n right-aligned and zero-padded to width digits. It
+// returns ErrFieldOverflow when n is negative or has more digits.
+func numeric(n int64, width int) (string, error) {
+	s := fmt.Sprintf("%0*d", width, n)
+	if n < 0 || len(s) > width {
+		return strings.Repeat("0", width), fmt.Errorf("%d in %d digits: %w", n, width, ErrFieldOverflow)
+	}
+	return s, nil
+}
+
+// fields formats the numeric fields of a record and keeps the first error,
+// so a record can be built in one expression and checked once.
+type fields struct {
+	err error
+}
+
+func (f *fields) numeric(n int64, width int) string {
+	s, err := numeric(n, width)
+	if err != nil && f.err == nil {
+		f.err = err
+	}
+	return s
+}
+
+// alpha formats s left-aligned and space-padded to width characters, in
+// upper case and with anything but printable ASCII replaced by spaces.
+func alpha(s string, width int) string {
+	// strip accents so names keep their letters in plain ASCII
+	s, _, _ = transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == 'đ' || r == 'Đ':
+			return 'D'
+		case r < ' ' || r > '~':
+			return ' '
+		}
+		return unicode.ToUpper(r)
+	}, s)
+	if len(s) > width {
+		return s[:width]
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+// rdfi returns the part of a routing number that identifies the bank,
+// without its check digit.
+func rdfi(routingNumber string) string {
+	if len(routingNumber) > 8 {
+		return routingNumber[:8]
+	}
+	return routingNumber
+}
+
+// digits returns the value of a string of digits, ignoring anything else.
+func digits(s string) int64 {
+	var n int64
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			n = n*10 + int64(r-'0')
+		}
+	}
+	return n
+}
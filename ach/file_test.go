@@ -0,0 +1,138 @@
+package ach
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testFile() File {
+	created := time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)
+	return File{
+		ImmediateDestination: "011000015",
+		ImmediateOrigin:      "123456780",
+		DestinationName:      "Federal Reserve Bank",
+		OriginName:           "Digi Bank",
+		CreatedAt:            created,
+		IDModifier:           "A",
+		Batches: []Batch{{
+			ServiceClassCode: ServiceClassCredits,
+			CompanyName:      "Digi Bank",
+			CompanyID:        "1234567890",
+			SECCode:          SECPPD,
+			EntryDescription: "PAYMENT",
+			EffectiveDate:    time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+			ODFI:             "12345678",
+			Number:           1,
+			Entries: []Entry{
+				{TransactionCode: CheckingCredit, RoutingNumber: "011000015", AccountNumber: "12345678", Amount: 2500, IndividualID: "12", Name: "Jane Doe", TraceNumber: "123456780000012"},
+				{TransactionCode: SavingsCredit, RoutingNumber: "021000021", AccountNumber: "987654321", Amount: 1000, IndividualID: "13", Name: "Trần Văn Đức", TraceNumber: "123456780000013"},
+			},
+		}},
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testFile().Write(&buf))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 10)
+	for _, line := range lines {
+		require.Len(t, line, RecordLength)
+	}
+	require.Equal(t, "101 011000015 123456780", lines[0][:23])
+	require.Equal(t, "5220DIGI BANK", lines[1][:13])
+	require.Equal(t, "622011000015", lines[2][:12])
+	require.Contains(t, lines[3], "TRAN VAN DUC")
+	// entry hash 01100001 + 02100002, credits 3500
+	require.Equal(t, "8220000002000320000300000000000000000000350", lines[4][:43])
+	require.Equal(t, "9000001000001000000020003200003", lines[5][:31])
+	for _, padding := range lines[6:] {
+		require.Equal(t, strings.Repeat("9", RecordLength), padding)
+	}
+}
+
+func TestWriteFileFieldOverflow(t *testing.T) {
+	file := testFile()
+	file.Batches[0].Entries[0].Amount = MaxAmount
+	require.NoError(t, file.Write(&bytes.Buffer{}))
+
+	file.Batches[0].Entries[0].Amount = MaxAmount + 1
+	var buf bytes.Buffer
+	err := file.Write(&buf)
+	require.ErrorIs(t, err, ErrFieldOverflow)
+	require.Contains(t, err.Error(), "123456780000012")
+	require.Zero(t, buf.Len())
+}
+
+func TestParseFile(t *testing.T) {
+	var buf bytes.Buffer
+	file := testFile()
+	require.NoError(t, file.Write(&buf))
+
+	parsed, err := Parse(&buf)
+	require.NoError(t, err)
+	require.Equal(t, file.ImmediateDestination, parsed.ImmediateDestination)
+	require.Equal(t, file.CreatedAt, parsed.CreatedAt)
+	require.Equal(t, "A", parsed.IDModifier)
+	require.Len(t, parsed.Batches, 1)
+	batch := parsed.Batches[0]
+	require.Equal(t, ServiceClassCredits, batch.ServiceClassCode)
+	require.Equal(t, file.Batches[0].EffectiveDate, batch.EffectiveDate)
+	require.Len(t, batch.Entries, 2)
+	require.Equal(t, file.Batches[0].Entries[0].Amount, batch.Entries[0].Amount)
+	require.Equal(t, "987654321", batch.Entries[1].AccountNumber)
+	require.Equal(t, "123456780000013", batch.Entries[1].TraceNumber)
+	require.Nil(t, batch.Entries[1].Return)
+}
+
+func TestParseReturnsFile(t *testing.T) {
+	f, err := os.Open("testdata/returns.txt")
+	require.NoError(t, err)
+	defer f.Close()
+
+	file, err := Parse(f)
+	require.NoError(t, err)
+	require.Len(t, file.Batches, 1)
+	entries := file.Batches[0].Entries
+	require.Len(t, entries, 3)
+	require.Equal(t, CheckingReturn, entries[0].TransactionCode)
+	require.Equal(t, &Return{
+		Code:                "R03",
+		OriginalTraceNumber: "123456780000012",
+		OriginalRDFI:        "01100001",
+		Info:                "NO ACCOUNT",
+	}, entries[0].Return)
+	require.Equal(t, "R02", entries[1].Return.Code)
+}
+
+func TestParseInvalidFile(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testFile().Write(&buf))
+	valid := buf.String()
+
+	testCases := []struct {
+		name string
+		file string
+	}{
+		{"Empty", ""},
+		{"ShortRecord", strings.Replace(valid, "PAYMENT   ", "PAYMENT", 1)},
+		// changes the amount of the first entry from 2500 to 2600
+		{"WrongTotals", strings.Replace(valid, "0000002500", "0000002600", 1)},
+		{"NoFileControl", valid[:strings.Index(valid, "\n9")+1]},
+		{"NoBatchControl", strings.Replace(valid, "\n8", "\n6", 1)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.file))
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr), "%v", err)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package ach
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError is an error in a record of an ACH file.
+type ParseError struct {
+	Line int
+	Err  string
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("ach: line %d: %s", err.Line, err.Err)
+}
+
+// Parse reads an ACH file and checks its control records against the
+// entries it holds.
+func Parse(r io.Reader) (File, error) {
+	p := parser{scanner: bufio.NewScanner(r)}
+	return p.parse()
+}
+
+type parser struct {
+	scanner *bufio.Scanner
+	line    int
+	record  string
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &ParseError{Line: p.line, Err: fmt.Sprintf(format, args...)}
+}
+
+// next reads the next record, skipping the padding at the end of the file.
+func (p *parser) next() (bool, error) {
+	for p.scanner.Scan() {
+		p.line++
+		p.record = strings.TrimRight(p.scanner.Text(), "\r")
+		if p.record == "" {
+			continue
+		}
+		if len(p.record) != RecordLength {
+			return false, p.errorf("record is %d characters long, not %d", len(p.record), RecordLength)
+		}
+		if p.record == strings.Repeat("9", RecordLength) {
+			continue
+		}
+		return true, nil
+	}
+	return false, p.scanner.Err()
+}
+
+// field returns the trimmed characters of the current record from the
+// one-based position start to end, inclusive, like the NACHA rules number them.
+func (p *parser) field(start, end int) string {
+	return strings.TrimSpace(p.record[start-1 : end])
+}
+
+func (p *parser) number(name string, start, end int) (int64, error) {
+	n, err := strconv.ParseInt(p.field(start, end), 10, 64)
+	if err != nil {
+		return 0, p.errorf("invalid %s %q", name, p.record[start-1:end])
+	}
+	return n, nil
+}
+
+func (p *parser) date(name string, start, end int) (time.Time, error) {
+	t, err := time.Parse("060102", p.field(start, end))
+	if err != nil {
+		return t, p.errorf("invalid %s %q", name, p.record[start-1:end])
+	}
+	return t, nil
+}
+
+func (p *parser) parse() (File, error) {
+	var file File
+	ok, err := p.next()
+	if err != nil {
+		return file, err
+	}
+	if !ok || p.record[0] != '1' {
+		return file, p.errorf("file doesn't start with a file header")
+	}
+	file.ImmediateDestination = p.field(4, 13)
+	file.ImmediateOrigin = p.field(14, 23)
+	file.CreatedAt, err = time.Parse("0601021504", p.field(24, 33))
+	if err != nil {
+		return file, p.errorf("invalid file creation date %q", p.record[23:33])
+	}
+	file.IDModifier = p.field(34, 34)
+	file.DestinationName = p.field(41, 63)
+	file.OriginName = p.field(64, 86)
+
+	var totals control
+	for {
+		if ok, err = p.next(); err != nil {
+			return file, err
+		}
+		if !ok {
+			return file, p.errorf("file has no file control record")
+		}
+		switch p.record[0] {
+		case '5':
+			batch, batchTotals, err := p.parseBatch()
+			if err != nil {
+				return file, err
+			}
+			file.Batches = append(file.Batches, batch)
+			totals.merge(batchTotals)
+		case '9':
+			if err := p.checkControl("file", totals, 14, 22, 32); err != nil {
+				return file, err
+			}
+			batches, err := p.number("batch count", 2, 7)
+			if err != nil {
+				return file, err
+			}
+			if batches != int64(totals.batches) {
+				return file, p.errorf("file control counts %d batches, the file has %d", batches, totals.batches)
+			}
+			return file, nil
+		default:
+			return file, p.errorf("unexpected record type %q outside a batch", p.record[0])
+		}
+	}
+}
+
+// parseBatch reads the batch that starts at the current record, up to and
+// including its control record.
+func (p *parser) parseBatch() (Batch, control, error) {
+	var batch Batch
+	var totals control
+	serviceClass, err := p.number("service class code", 2, 4)
+	if err != nil {
+		return batch, totals, err
+	}
+	batch.ServiceClassCode = int(serviceClass)
+	batch.CompanyName = p.field(5, 20)
+	batch.CompanyID = p.field(41, 50)
+	batch.SECCode = p.field(51, 53)
+	batch.EntryDescription = p.field(54, 63)
+	if batch.EffectiveDate, err = p.date("effective entry date", 70, 75); err != nil {
+		return batch, totals, err
+	}
+	batch.ODFI = p.field(80, 87)
+	number, err := p.number("batch number", 88, 94)
+	if err != nil {
+		return batch, totals, err
+	}
+	batch.Number = int(number)
+
+	for {
+		ok, err := p.next()
+		if err != nil {
+			return batch, totals, err
+		}
+		if !ok {
+			return batch, totals, p.errorf("batch %d has no batch control record", batch.Number)
+		}
+		switch p.record[0] {
+		case '6':
+			entry, err := p.parseEntry()
+			if err != nil {
+				return batch, totals, err
+			}
+			totals.add(entry)
+			batch.Entries = append(batch.Entries, entry)
+		case '7':
+			if len(batch.Entries) == 0 {
+				return batch, totals, p.errorf("addenda record without an entry")
+			}
+			entry := &batch.Entries[len(batch.Entries)-1]
+			if entry.Return != nil {
+				return batch, totals, p.errorf("entry %s has more than one addenda record", entry.TraceNumber)
+			}
+			if p.field(2, 3) != "99" {
+				return batch, totals, p.errorf("unsupported addenda type %q", p.field(2, 3))
+			}
+			entry.Return = &Return{
+				Code:                p.field(4, 6),
+				OriginalTraceNumber: p.field(7, 21),
+				OriginalRDFI:        p.field(28, 35),
+				Info:                p.field(36, 79),
+			}
+			totals.entries++
+		case '8':
+			if err := p.checkControl("batch", totals, 5, 11, 21); err != nil {
+				return batch, totals, err
+			}
+			return batch, totals, nil
+		default:
+			return batch, totals, p.errorf("unexpected record type %q inside batch %d", p.record[0], batch.Number)
+		}
+	}
+}
+
+func (p *parser) parseEntry() (Entry, error) {
+	var entry Entry
+	code, err := p.number("transaction code", 2, 3)
+	if err != nil {
+		return entry, err
+	}
+	entry.TransactionCode = int(code)
+	entry.RoutingNumber = p.field(4, 12)
+	entry.AccountNumber = p.field(13, 29)
+	if entry.Amount, err = p.number("amount", 30, 39); err != nil {
+		return entry, err
+	}
+	entry.IndividualID = p.field(40, 54)
+	entry.Name = p.field(55, 76)
+	entry.TraceNumber = p.field(80, 94)
+	return entry, nil
+}
+
+// checkControl compares the entry count, entry hash and totals of a control
+// record with the ones of the entries read. The fields follow each other
+// from the given positions: the count up to the ten-digit hash, then the
+// twelve-digit debit and credit totals.
+func (p *parser) checkControl(name string, totals control, countStart, hashStart, debitsStart int) error {
+	count, err := p.number("entry count", countStart, hashStart-1)
+	if err != nil {
+		return err
+	}
+	hash, err := p.number("entry hash", hashStart, hashStart+9)
+	if err != nil {
+		return err
+	}
+	debits, err := p.number("total debits", debitsStart, debitsStart+11)
+	if err != nil {
+		return err
+	}
+	credits, err := p.number("total credits", debitsStart+12, debitsStart+23)
+	if err != nil {
+		return err
+	}
+	switch {
+	case count != int64(totals.entries):
+		return p.errorf("%s control counts %d entries, the %s has %d", name, count, name, totals.entries)
+	case hash != totals.entryHash():
+		return p.errorf("%s control entry hash is %d, the entries add up to %d", name, hash, totals.entryHash())
+	case debits != totals.debits || credits != totals.credits:
+		return p.errorf("%s control totals are %d debit and %d credit, the entries add up to %d and %d",
+			name, debits, credits, totals.debits, totals.credits)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package ach
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+var errNoReturnAddenda = errors.New("entry has no return addenda")
+
+// ReturnsReport is the outcome of importing a returns file.
+type ReturnsReport struct {
+	Returned []db.ReturnOutboundPaymentTxResult `json:"returned"`
+	// AlreadyReturned counts the entries of an earlier import of the same
+	// returns, which are skipped.
+	AlreadyReturned int             `json:"already_returned"`
+	Failed          []ReturnFailure `json:"failed"`
+}
+
+// ReturnFailure is a returned entry that couldn't be applied.
+type ReturnFailure struct {
+	TraceNumber string `json:"trace_number"`
+	Code        string `json:"code"`
+	Err         error  `json:"-"`
+}
+
+// ImportReturnsFile is ImportReturns for the file at path.
+func ImportReturnsFile(ctx context.Context, store db.Store, path string) (ReturnsReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ReturnsReport{}, err
+	}
+	defer f.Close()
+	file, err := Parse(f)
+	if err != nil {
+		return ReturnsReport{}, err
+	}
+	return ImportReturns(ctx, store, file)
+}
+
+// ImportReturns credits the customers back for the payments the receiving
+// banks returned in file. Each return is applied on its own: one that fails
+// is reported and doesn't stop the others, and importing the same file
+// again changes nothing.
+func ImportReturns(ctx context.Context, store db.Store, file File) (ReturnsReport, error) {
+	var report ReturnsReport
+	for _, batch := range file.Batches {
+		for _, entry := range batch.Entries {
+			if entry.Return == nil {
+				report.Failed = append(report.Failed, ReturnFailure{TraceNumber: entry.TraceNumber, Err: errNoReturnAddenda})
+				continue
+			}
+			result, err := store.ReturnOutboundPaymentTx(ctx, db.ReturnOutboundPaymentTxParams{
+				TraceNumber:   entry.Return.OriginalTraceNumber,
+				Amount:        entry.Amount,
+				AccountNumber: entry.AccountNumber,
+				ReturnCode:    entry.Return.Code,
+			})
+			switch {
+			case err == nil:
+				report.Returned = append(report.Returned, result)
+			case errors.Is(err, db.ErrOutboundPaymentReturned):
+				report.AlreadyReturned++
+			case ctx.Err() != nil:
+				return report, ctx.Err()
+			default:
+				report.Failed = append(report.Failed, ReturnFailure{
+					TraceNumber: entry.Return.OriginalTraceNumber,
+					Code:        entry.Return.Code,
+					Err:         db.TranslateError(err),
+				})
+			}
+		}
+	}
+	return report, nil
+}
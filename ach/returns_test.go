@@ -0,0 +1,64 @@
+package ach
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+func TestImportReturnsFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ReturnOutboundPaymentTx(gomock.Any(), gomock.Eq(db.ReturnOutboundPaymentTxParams{TraceNumber: "123456780000012", Amount: 2500, AccountNumber: "12345678", ReturnCode: "R03"})).
+		Times(1).
+		Return(db.ReturnOutboundPaymentTxResult{Payment: db.OutboundPayment{ID: 12, Status: db.OutboundPaymentReturned}}, nil)
+	store.EXPECT().
+		ReturnOutboundPaymentTx(gomock.Any(), gomock.Eq(db.ReturnOutboundPaymentTxParams{TraceNumber: "123456780000013", Amount: 1000, AccountNumber: "87654321", ReturnCode: "R02"})).
+		Times(1).
+		Return(db.ReturnOutboundPaymentTxResult{}, db.ErrOutboundPaymentReturned)
+	store.EXPECT().
+		ReturnOutboundPaymentTx(gomock.Any(), gomock.Eq(db.ReturnOutboundPaymentTxParams{TraceNumber: "123456780009999", Amount: 700, AccountNumber: "55555555", ReturnCode: "R04"})).
+		Times(1).
+		Return(db.ReturnOutboundPaymentTxResult{}, sql.ErrNoRows)
+
+	report, err := ImportReturnsFile(context.Background(), store, "testdata/returns.txt")
+	require.NoError(t, err)
+	require.Len(t, report.Returned, 1)
+	require.Equal(t, int64(12), report.Returned[0].Payment.ID)
+	require.Equal(t, 1, report.AlreadyReturned)
+	require.Len(t, report.Failed, 1)
+	require.Equal(t, "123456780009999", report.Failed[0].TraceNumber)
+	require.ErrorIs(t, report.Failed[0].Err, db.ErrRecordNotFound)
+}
+
+func TestImportReturnsWithoutAddenda(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ReturnOutboundPaymentTx(gomock.Any(), gomock.Any()).Times(0)
+
+	report, err := ImportReturns(context.Background(), store, testFile())
+	require.NoError(t, err)
+	require.Empty(t, report.Returned)
+	require.Len(t, report.Failed, 2)
+}
+
+func TestImportReturnsInvalidFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	_, err := ImportReturnsFile(context.Background(), store, "testdata/missing.txt")
+	require.Error(t, err)
+	_, err = ImportReturnsFile(context.Background(), store, "file_test.go")
+	require.Error(t, err)
+}
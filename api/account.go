@@ -1,12 +1,14 @@
 package api
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
 )
 
 type createAccountRequest struct {
@@ -21,30 +23,26 @@ func (server *Server) createAccount(c *gin.Context) {
 		return
 	}
 
+	serial, err := server.store.NextAccountNumberSerial(c)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
 	arg := db.CreateAccountParams{
-		Owner:    req.Owner,
-		Currency: req.Currency,
+		Owner:         req.Owner,
+		Currency:      req.Currency,
+		AccountNumber: utils.NewAccountNumber(serial),
 	}
 	account, err := server.store.CreateAccount(c, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				c.JSON(http.StatusConflict, errorResponse(err))
-				return
-			case "foreign_key_violation":
-				c.JSON(http.StatusBadRequest, errorResponse(err))
-				return
-			}
-			c.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-		c.JSON(http.StatusOK, account)
+		writeError(c, err)
+		return
 	}
+	c.JSON(http.StatusOK, account)
 }
 
 type getAccountRequest struct {
-	ID int64 `uri:"id" binding:"required,min=1"`
+	ID string `uri:"id" binding:"required,account_ref"`
 }
 
 func (server *Server) getAccount(c *gin.Context) {
@@ -54,13 +52,9 @@ func (server *Server) getAccount(c *gin.Context) {
 		return
 	}
 
-	account, err := server.store.GetAccounts(c, req.ID)
+	account, err := server.getAccountByRef(c, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeLookupError(c, "account", err)
 		return
 	}
 	c.JSON(http.StatusOK, account)
@@ -84,15 +78,16 @@ func (server *Server) getListAccount(c *gin.Context) {
 
 	accounts, err := server.store.ListAccounts(c, args)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, accounts)
 }
 
 type UpdateAccountRequest struct {
-	ID      int64 `json:"id" binding:"required,min=1"`
-	Balance int64 `json:"balance" binding:"required"`
+	ID            int64  `json:"id" binding:"required_without=AccountNumber,omitempty,min=1"`
+	AccountNumber string `json:"account_number" binding:"required_without=ID,omitempty,account_number"`
+	Balance       int64  `json:"balance" binding:"required"`
 }
 
 func (server *Server) updateAccount(c *gin.Context) {
@@ -102,19 +97,25 @@ func (server *Server) updateAccount(c *gin.Context) {
 		return
 	}
 
+	accountID, err := server.resolveAccountID(c, req.ID, req.AccountNumber)
+	if err != nil {
+		writeLookupError(c, "account", err)
+		return
+	}
+
 	account, err := server.store.UpdateAccount(c, db.UpdateAccountParams{
-		ID:      req.ID,
+		ID:      accountID,
 		Balance: req.Balance,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, account)
 }
 
 type deleteAccountRequest struct {
-	ID int64 `uri:"id" binding:"required,min=1"`
+	ID string `uri:"id" binding:"required,account_ref"`
 }
 
 func (server *Server) deleteAccount(c *gin.Context) {
@@ -123,15 +124,78 @@ func (server *Server) deleteAccount(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	err := server.store.DeleteAccount(c, req.ID)
+	account, err := server.getAccountByRef(c, req.ID)
+	if err == nil {
+		err = server.store.DeleteAccount(c, account.ID)
+	}
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeLookupError(c, "account", err)
 		return
 	}
 	c.JSON(http.StatusOK, "Deleted successfully")
 
 }
+
+var errNotStaff = errors.New("only staff can freeze or unfreeze accounts")
+
+type freezeAccountRequest struct {
+	ID string `uri:"id" binding:"required,account_ref"`
+}
+
+func (server *Server) freezeAccount(c *gin.Context) {
+	server.setAccountFrozen(c, true)
+}
+
+func (server *Server) unfreezeAccount(c *gin.Context) {
+	server.setAccountFrozen(c, false)
+}
+
+// setAccountFrozen lets staff stop an account from sending or receiving
+// transfers, or lift that again.
+func (server *Server) setAccountFrozen(c *gin.Context, frozen bool) {
+	var req freezeAccountRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if authUser(c).Role != db.RoleStaff {
+		c.JSON(http.StatusForbidden, errorResponse(errNotStaff))
+		return
+	}
+
+	account, err := server.getAccountByRef(c, req.ID)
+	if err != nil {
+		writeLookupError(c, "account", err)
+		return
+	}
+	account, err = server.store.SetAccountFrozen(c, db.SetAccountFrozenParams{
+		ID:     account.ID,
+		Frozen: frozen,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+// getAccountByRef looks an account up by its numeric id or by its account number.
+func (server *Server) getAccountByRef(ctx context.Context, ref string) (db.Account, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return server.store.GetAccounts(ctx, id)
+	}
+	return server.store.GetAccountByNumber(ctx, utils.NormalizeAccountNumber(ref))
+}
+
+// resolveAccountID returns accountID as is, or the id of the account with
+// the given account number when one is supplied.
+func (server *Server) resolveAccountID(ctx context.Context, accountID int64, accountNumber string) (int64, error) {
+	if accountNumber == "" {
+		return accountID, nil
+	}
+	account, err := server.store.GetAccountByNumber(ctx, utils.NormalizeAccountNumber(accountNumber))
+	if err != nil {
+		return 0, err
+	}
+	return account.ID, nil
+}
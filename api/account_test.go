@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 	mockdb "tutorial.sqlc.dev/app/db/mock"
 	db "tutorial.sqlc.dev/app/db/sqlc"
@@ -23,12 +24,12 @@ func TestGetAccount(t *testing.T) {
 
 	testCase := []struct {
 		name          string
-		accountID     int64
+		accountID     string
 		buildMock     func(store *mockdb.MockStore)
 		checkResponse func(recorder *httptest.ResponseRecorder)
 	}{
 		{name: "OK",
-			accountID: account.ID,
+			accountID: fmt.Sprint(account.ID),
 			buildMock: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccounts(gomock.Any(), gomock.Eq(account.ID)).
@@ -39,8 +40,20 @@ func TestGetAccount(t *testing.T) {
 				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 		},
+		{name: "OKByAccountNumber",
+			accountID: account.AccountNumber,
+			buildMock: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
+					Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
 		{name: "NotFound",
-			accountID: account.ID,
+			accountID: fmt.Sprint(account.ID),
 			buildMock: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccounts(gomock.Any(), gomock.Eq(account.ID)).
@@ -52,7 +65,7 @@ func TestGetAccount(t *testing.T) {
 			},
 		},
 		{name: "InternalError",
-			accountID: account.ID,
+			accountID: fmt.Sprint(account.ID),
 			buildMock: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccounts(gomock.Any(), gomock.Eq(account.ID)).
@@ -63,7 +76,7 @@ func TestGetAccount(t *testing.T) {
 			},
 		},
 		{name: "InvalidID",
-			accountID: 0,
+			accountID: "0",
 			buildMock: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccounts(gomock.Any(), gomock.Any()).
@@ -73,6 +86,17 @@ func TestGetAccount(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{name: "InvalidAccountNumber",
+			accountID: account.AccountNumber[:len(account.AccountNumber)-1] + "x",
+			buildMock: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 	}
 	for i := range testCase {
 		tc := testCase[i]
@@ -82,9 +106,9 @@ func TestGetAccount(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildMock(store)
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
-			url := fmt.Sprintf("/accounts/%d", tc.accountID)
+			url := fmt.Sprintf("/accounts/%s", tc.accountID)
 			req, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
@@ -95,13 +119,171 @@ func TestGetAccount(t *testing.T) {
 	}
 }
 
+func TestCreateAccount(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().NextAccountNumberSerial(gomock.Any()).Times(1).Return(int64(42), nil)
+				arg := db.CreateAccountParams{
+					Owner:         account.Owner,
+					Currency:      account.Currency,
+					AccountNumber: utils.NewAccountNumber(42),
+				}
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name: "OwnerNotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().NextAccountNumberSerial(gomock.Any()).Times(1).Return(int64(42), nil)
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"code":"invalid_reference"`)
+			},
+		},
+		{
+			name: "DuplicateAccount",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().NextAccountNumberSerial(gomock.Any()).Times(1).Return(int64(42), nil)
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"code":"already_exists"`)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().NextAccountNumberSerial(gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"code":"internal_error"`)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(map[string]string{"owner": account.Owner, "currency": account.Currency})
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestFreezeAccount(t *testing.T) {
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+	customer, customerPassword := randomUser(t)
+	account := randomAccount()
+	frozen := account
+	frozen.Frozen = true
+
+	testCases := []struct {
+		name       string
+		user       db.User
+		password   string
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name:     "OK",
+			user:     staff,
+			password: staffPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.SetAccountFrozenParams{ID: account.ID, Frozen: true}
+				store.EXPECT().SetAccountFrozen(gomock.Any(), gomock.Eq(arg)).Times(1).Return(frozen, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "NotStaff",
+			user:     customer,
+			password: customerPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetAccountFrozen(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:     "NotFound",
+			user:     staff,
+			password: staffPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().SetAccountFrozen(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, tc.user)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/freeze", account.ID)
+			req, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+			req.SetBasicAuth(tc.user.Username, tc.password)
+
+			server.router.ServeHTTP(recorder, req)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
+
 func randomAccount() db.Account {
 	return db.Account{
-		ID:        utils.RandomInt(1, 1000),
-		Owner:     utils.RandomOwner(),
-		Currency:  utils.RandomCurrency(),
-		Balance:   utils.RandomMoney(),
-		CreatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		ID:            utils.RandomInt(1, 1000),
+		Owner:         utils.RandomOwner(),
+		Currency:      utils.RandomCurrency(),
+		Balance:       utils.RandomMoney(),
+		CreatedAt:     sql.NullTime{Time: time.Now(), Valid: true},
+		AccountNumber: utils.RandomAccountNumber(),
 	}
 }
 
@@ -116,6 +298,7 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 	require.Equal(t, account.Owner, gotAccount.Owner)
 	require.Equal(t, account.Balance, gotAccount.Balance)
 	require.Equal(t, account.Currency, gotAccount.Currency)
+	require.Equal(t, account.AccountNumber, gotAccount.AccountNumber)
 	require.Equal(t, account.CreatedAt.Valid, gotAccount.CreatedAt.Valid)
 	require.WithinDuration(t, account.CreatedAt.Time, gotAccount.CreatedAt.Time, time.Second)
 }
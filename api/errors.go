@@ -0,0 +1,132 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/iso20022"
+)
+
+// Machine-readable codes sent next to the message of an error response.
+// Clients may rely on them; don't change them.
+const (
+	codeNotFound            = "not_found"
+	codeAlreadyExists       = "already_exists"
+	codeInvalidReference    = "invalid_reference"
+	codeInsufficientFunds   = "insufficient_funds"
+	codeCurrencyMismatch    = "currency_mismatch"
+	codeAccountFrozen       = "account_frozen"
+	codeLimitExceeded       = "limit_exceeded"
+	codeTransferBlocked     = "transfer_blocked"
+	codeComplianceBlocked   = "compliance_blocked"
+	codeTxConflict          = "transaction_conflict"
+	codeUnauthenticated     = "unauthenticated"
+	codeIdempotencyKeyUsed  = "idempotency_key_reused"
+	codeIdempotencyKeyInUse = "idempotency_key_in_use"
+	codeAlreadyUsed         = "already_used"
+	codeExpired             = "expired"
+	codeFxQuoteMismatch     = "fx_quote_mismatch"
+	codeSelfApproval        = "self_approval"
+	codeNotReversible       = "not_reversible"
+	codeReversalExceeds     = "reversal_exceeds_remaining"
+	codeInvalidMessage      = "invalid_message"
+	codeInternal            = "internal_error"
+)
+
+// errorMappings lists the errors handlers turn into client errors.
+var errorMappings = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{db.ErrRecordNotFound, http.StatusNotFound, codeNotFound},
+	{db.ErrUniqueViolation, http.StatusConflict, codeAlreadyExists},
+	{db.ErrForeignKeyViolation, http.StatusBadRequest, codeInvalidReference},
+	{db.ErrInsufficientFunds, http.StatusUnprocessableEntity, codeInsufficientFunds},
+	{db.ErrCurrencyMismatch, http.StatusBadRequest, codeCurrencyMismatch},
+	{db.ErrOutboundPaymentCurrency, http.StatusBadRequest, codeCurrencyMismatch},
+	{db.ErrAccountFrozen, http.StatusForbidden, codeAccountFrozen},
+	// retrying didn't get the transaction through; the client may try later
+	{db.ErrTxConflict, http.StatusServiceUnavailable, codeTxConflict},
+	{errUnauthenticated, http.StatusUnauthorized, codeUnauthenticated},
+	{errIdempotencyKeyReused, http.StatusUnprocessableEntity, codeIdempotencyKeyUsed},
+	{db.ErrIdempotencyKeyInUse, http.StatusConflict, codeIdempotencyKeyInUse},
+	{db.ErrFxQuoteUsed, http.StatusConflict, codeAlreadyUsed},
+	{db.ErrFxQuoteExpired, http.StatusUnprocessableEntity, codeExpired},
+	{db.ErrFxQuoteMismatch, http.StatusBadRequest, codeFxQuoteMismatch},
+	{db.ErrPaymentRequestHeld, http.StatusConflict, codeAlreadyExists},
+	{db.ErrSelfApproval, http.StatusForbidden, codeSelfApproval},
+	{db.ErrPendingTransferDecided, http.StatusConflict, codeAlreadyUsed},
+	{db.ErrPendingTransferExpired, http.StatusConflict, codeExpired},
+	{db.ErrTransferConfirmationUsed, http.StatusConflict, codeAlreadyUsed},
+	{db.ErrTransferConfirmationExpired, http.StatusConflict, codeExpired},
+	{db.ErrTransferFullyReversed, http.StatusUnprocessableEntity, codeNotReversible},
+	{db.ErrTransferNotCompleted, http.StatusConflict, codeNotReversible},
+	{db.ErrOutboundPaymentReversal, http.StatusConflict, codeNotReversible},
+	{db.ErrReversalOfReversal, http.StatusBadRequest, codeNotReversible},
+	{db.ErrCrossCurrencyReversal, http.StatusBadRequest, codeNotReversible},
+	{iso20022.ErrUnreadableMessage, http.StatusBadRequest, codeInvalidMessage},
+}
+
+// mapError returns the status and code of the response for err. Errors the
+// store doesn't know about are internal errors.
+func mapError(err error) (int, string) {
+	err = db.TranslateError(err)
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.err) {
+			return mapping.status, mapping.code
+		}
+	}
+	var limitErr *db.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return http.StatusUnprocessableEntity, codeLimitExceeded
+	}
+	var exceedsErr *db.ReversalExceedsError
+	if errors.As(err, &exceedsErr) {
+		return http.StatusUnprocessableEntity, codeReversalExceeds
+	}
+	return http.StatusInternalServerError, codeInternal
+}
+
+// writeError writes the response for an error returned by the store.
+func writeError(c *gin.Context, err error) {
+	c.JSON(errorStatusResponse(err))
+}
+
+// abortWithError is writeError for middleware: it also stops the handlers
+// after it from running.
+func abortWithError(c *gin.Context, err error) {
+	c.AbortWithStatusJSON(errorStatusResponse(err))
+}
+
+func errorStatusResponse(err error) (int, gin.H) {
+	var limitErr *db.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return http.StatusUnprocessableEntity, limitErrorResponse(limitErr)
+	}
+	status, code := mapError(err)
+	response := gin.H{"error": db.TranslateError(err).Error(), "code": code}
+	var exceedsErr *db.ReversalExceedsError
+	if errors.As(err, &exceedsErr) {
+		response["requested"] = exceedsErr.Requested
+		response["remaining"] = exceedsErr.Remaining
+	}
+	return status, response
+}
+
+// writeLookupError is writeError for the error of loading a single record,
+// naming the record when it doesn't exist.
+func writeLookupError(c *gin.Context, record string, err error) {
+	if isNotFound(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": record + " not found", "code": codeNotFound})
+		return
+	}
+	writeError(c, err)
+}
+
+// isNotFound reports whether err means the record doesn't exist.
+func isNotFound(err error) bool {
+	return errors.Is(db.TranslateError(err), db.ErrRecordNotFound)
+}
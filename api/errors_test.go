@@ -0,0 +1,53 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/iso20022"
+)
+
+func TestMapError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"NoRows", sql.ErrNoRows, http.StatusNotFound, codeNotFound},
+		{"RecordNotFound", db.ErrRecordNotFound, http.StatusNotFound, codeNotFound},
+		{"UniqueViolation", &pq.Error{Code: "23505"}, http.StatusConflict, codeAlreadyExists},
+		{"ForeignKeyViolation", &pq.Error{Code: "23503"}, http.StatusBadRequest, codeInvalidReference},
+		{"InsufficientFunds", fmt.Errorf("account 1: %w", db.ErrInsufficientFunds), http.StatusUnprocessableEntity, codeInsufficientFunds},
+		{"CurrencyMismatch", db.ErrTransferGroupCurrency, http.StatusBadRequest, codeCurrencyMismatch},
+		{"AccountFrozen", fmt.Errorf("account 1: %w", db.ErrAccountFrozen), http.StatusForbidden, codeAccountFrozen},
+		{"LimitExceeded", &db.LimitExceededError{Limit: db.LimitMaxSingleAmount}, http.StatusUnprocessableEntity, codeLimitExceeded},
+		{"SerializationFailure", &pq.Error{Code: "40001"}, http.StatusServiceUnavailable, codeTxConflict},
+		{"Deadlock", fmt.Errorf("tx err: %w", &pq.Error{Code: "40P01"}), http.StatusServiceUnavailable, codeTxConflict},
+		{"Unauthenticated", errUnauthenticated, http.StatusUnauthorized, codeUnauthenticated},
+		{"IdempotencyKeyReused", errIdempotencyKeyReused, http.StatusUnprocessableEntity, codeIdempotencyKeyUsed},
+		{"IdempotencyKeyInUse", db.ErrIdempotencyKeyInUse, http.StatusConflict, codeIdempotencyKeyInUse},
+		{"FxQuoteExpired", db.ErrFxQuoteExpired, http.StatusUnprocessableEntity, codeExpired},
+		{"SelfApproval", db.ErrSelfApproval, http.StatusForbidden, codeSelfApproval},
+		{"PendingTransferDecided", db.ErrPendingTransferDecided, http.StatusConflict, codeAlreadyUsed},
+		{"TransferNotCompleted", db.ErrTransferNotCompleted, http.StatusConflict, codeNotReversible},
+		{"ReversalExceeds", &db.ReversalExceedsError{Requested: 2, Remaining: 1}, http.StatusUnprocessableEntity, codeReversalExceeds},
+		{"UnreadableMessage", fmt.Errorf("%w: EOF", iso20022.ErrUnreadableMessage), http.StatusBadRequest, codeInvalidMessage},
+		{"OtherPostgresError", &pq.Error{Code: "22003"}, http.StatusInternalServerError, codeInternal},
+		{"Unknown", errors.New("boom"), http.StatusInternalServerError, codeInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, code := mapError(tc.err)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.code, code)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+type feeQuoteRequest struct {
+	FromAccountID     int64  `json:"from_account_id" binding:"required_without=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string `json:"from_account_number" binding:"required_without=FromAccountID,omitempty,account_number"`
+	ToAccountID       int64  `json:"to_account_id" binding:"required_without=ToAccountNumber,omitempty,min=1"`
+	ToAccountNumber   string `json:"to_account_number" binding:"required_without=ToAccountID,omitempty,account_number"`
+	Amount            int64  `json:"amount" binding:"required,gt=0"`
+	Currency          string `json:"currency" binding:"required,currency"`
+	// ToCurrency is the currency of the destination account of a
+	// cross-currency transfer; it defaults to Currency.
+	ToCurrency string `json:"to_currency" binding:"omitempty,currency"`
+}
+
+type feeQuoteResponse struct {
+	Amount        int64  `json:"amount"`
+	Fee           int64  `json:"fee"`
+	Total         int64  `json:"total"`
+	Currency      string `json:"currency"`
+	CrossCurrency bool   `json:"cross_currency"`
+}
+
+// quoteTransferFee tells the user what a transfer would cost before they
+// make it. The fee is charged on top of the amount, in the source currency.
+func (server *Server) quoteTransferFee(c *gin.Context) {
+	var req feeQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.ToCurrency == "" {
+		req.ToCurrency = req.Currency
+	}
+
+	fromAccount, valid := server.validSourceAccount(c, req.FromAccountID, req.FromAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	toAccount, valid := server.validAccount(c, req.ToAccountID, req.ToAccountNumber, req.ToCurrency)
+	if !valid {
+		return
+	}
+
+	fee, err := db.QuoteTransferFee(c, server.store, fromAccount, toAccount, req.Amount)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, feeQuoteResponse{
+		Amount:        req.Amount,
+		Fee:           fee.Amount,
+		Total:         req.Amount + fee.Amount,
+		Currency:      fee.Currency,
+		CrossCurrency: fee.CrossCurrency,
+	})
+}
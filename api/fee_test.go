@@ -0,0 +1,176 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestQuoteTransferFee(t *testing.T) {
+	user, password := randomUser(t)
+	other, _ := randomUser(t)
+
+	fromAccount := randomAccount()
+	fromAccount.Owner = user.Username
+	fromAccount.Currency = utils.USD
+	toAccount := randomAccount()
+	toAccount.Owner = other.Username
+	toAccount.Currency = utils.USD
+	ownAccount := randomAccount()
+	ownAccount.Owner = user.Username
+	ownAccount.Currency = utils.USD
+	eurAccount := randomAccount()
+	eurAccount.Owner = other.Username
+	eurAccount.Currency = utils.EUR
+
+	rule := db.FeeRule{
+		ID:            utils.RandomInt(1, 1000),
+		Currency:      utils.USD,
+		FlatAmount:    5,
+		PercentageBps: 100,
+		MinAmount:     10,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_id": toAccount.ID, "amount": 1000, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					GetFeeRule(gomock.Any(), gomock.Eq(db.GetFeeRuleParams{Currency: utils.USD, CrossCurrency: false})).
+					Times(1).
+					Return(rule, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got feeQuoteResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, feeQuoteResponse{Amount: 1000, Fee: 15, Total: 1015, Currency: utils.USD}, got)
+			},
+		},
+		{
+			name: "SameOwnerIsFree",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_id": ownAccount.ID, "amount": 1000, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(ownAccount.ID)).Times(1).Return(ownAccount, nil)
+				store.EXPECT().GetFeeRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got feeQuoteResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Zero(t, got.Fee)
+				require.Equal(t, int64(1000), got.Total)
+			},
+		},
+		{
+			name: "NoRule",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_id": toAccount.ID, "amount": 1000, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetFeeRule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeRule{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got feeQuoteResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Zero(t, got.Fee)
+			},
+		},
+		{
+			name: "CrossCurrency",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   eurAccount.ID,
+				"amount":          1000,
+				"currency":        utils.USD,
+				"to_currency":     utils.EUR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(eurAccount.ID)).Times(1).Return(eurAccount, nil)
+				store.EXPECT().
+					GetFeeRule(gomock.Any(), gomock.Eq(db.GetFeeRuleParams{Currency: utils.USD, CrossCurrency: true})).
+					Times(1).
+					Return(db.FeeRule{Currency: utils.USD, CrossCurrency: true, FlatAmount: 40}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got feeQuoteResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.True(t, got.CrossCurrency)
+				require.Equal(t, int64(40), got.Fee)
+			},
+		},
+		{
+			name: "ToAccountCurrencyMismatch",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_id": eurAccount.ID, "amount": 1000, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(eurAccount.ID)).Times(1).Return(eurAccount, nil)
+				store.EXPECT().GetFeeRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_id": toAccount.ID, "amount": 0, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, user)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/fee-quote", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
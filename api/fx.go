@@ -0,0 +1,129 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+type createFxQuoteRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required,currency"`
+	ToCurrency   string `json:"to_currency" binding:"required,currency,nefield=FromCurrency"`
+}
+
+// createFxQuote locks the current rate between two currencies for the
+// configured quote TTL.
+func (server *Server) createFxQuote(c *gin.Context) {
+	var req createFxQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rate, err := db.LatestFxRate(c, server.store, req.FromCurrency, req.ToCurrency)
+	if err != nil {
+		if isNotFound(err) {
+			err = fmt.Errorf("no fx rate from %s to %s", req.FromCurrency, req.ToCurrency)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": codeNotFound})
+			return
+		}
+		writeError(c, err)
+		return
+	}
+
+	quote, err := server.store.CreateFxQuote(c, db.CreateFxQuoteParams{
+		Username:     authUser(c).Username,
+		FxRateID:     rate.ID,
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+		Rate:         rate.Rate,
+		ExpiresAt:    time.Now().Add(server.config.FXQuoteTTL),
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
+
+// createFxTransfer finishes createTransfer for a request that carries an fx
+// quote. A transfer that needs approval, that sanctions screening flagged or
+// that the risk engine wants reviewed, is held and converted at the rate of
+// the time it is approved, as the quote won't last that long.
+func (server *Server) createFxTransfer(c *gin.Context, req transferRequest, fromAccount db.Account, needsApproval bool, idempotencyKey *db.IdempotencyKeyParams) {
+	quote, valid := server.validFxQuote(c, req.FxQuoteID, req.Currency)
+	if !valid {
+		return
+	}
+	toAccount, valid := server.validAccount(c, req.ToAccountID, req.ToAccountNumber, quote.ToCurrency)
+	if !valid {
+		return
+	}
+	flagged, ok := server.screenTransferParties(c, fromAccount, toAccount)
+	if !ok {
+		return
+	}
+	decision, ok := server.screenTransfer(c, fromAccount, toAccount.ID, req.Amount)
+	if !ok {
+		return
+	}
+	if needsApproval || flagged || decision.Decision == db.RiskReview {
+		hold := req.pendingTransfer(fromAccount, toAccount)
+		hold.Kind = db.PendingTransferKindFxTransfer
+		hold.FxQuoteID = sql.NullInt64{Int64: quote.ID, Valid: true}
+		hold.RiskDecisionID = reviewDecisionID(decision)
+		server.holdTransfer(c, hold, idempotencyKey)
+		return
+	}
+	arg := db.FxTransferTxParams{
+		FromAccountID:   fromAccount.ID,
+		ToAccountID:     toAccount.ID,
+		Amount:          req.Amount,
+		FxQuoteID:       quote.ID,
+		TransferDetails: req.details(),
+		Username:        quote.Username,
+		IdempotencyKey:  idempotencyKey,
+	}
+	result, err := server.store.FxTransferTx(c, arg)
+	if err != nil {
+		server.transferError(c, err, idempotencyKey)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// validFxQuote loads the quote and checks that the authenticated user can use
+// it for a transfer out of currency. FxTransferTx checks it again under lock.
+func (server *Server) validFxQuote(c *gin.Context, quoteID int64, currency string) (db.FxQuote, bool) {
+	quote, err := server.store.GetFxQuote(c, quoteID)
+	if err != nil {
+		writeLookupError(c, "fx quote", err)
+		return quote, false
+	}
+	if quote.Username != authUser(c).Username {
+		err := errors.New("fx quote doesn't belong to the authenticated user")
+		c.JSON(http.StatusForbidden, errorResponse(err))
+		return quote, false
+	}
+	if quote.FromCurrency != currency {
+		err := fmt.Errorf("%w: quote %d is from %s, not %s", db.ErrFxQuoteMismatch, quote.ID, quote.FromCurrency, currency)
+		writeError(c, err)
+		return quote, false
+	}
+	switch {
+	case quote.TransferID.Valid:
+		err = db.ErrFxQuoteUsed
+	case time.Now().After(quote.ExpiresAt):
+		err = db.ErrFxQuoteExpired
+	}
+	if err != nil {
+		writeError(c, err)
+		return quote, false
+	}
+	return quote, true
+}
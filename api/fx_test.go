@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestCreateFxQuote(t *testing.T) {
+	user, password := randomUser(t)
+	rate := db.FxRate{
+		ID:            utils.RandomInt(1, 1000),
+		BaseCurrency:  utils.USD,
+		QuoteCurrency: utils.EUR,
+		Rate:          92_000_000,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"from_currency": utils.USD, "to_currency": utils.EUR},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().
+					GetLatestFxRate(gomock.Any(), gomock.Eq(db.GetLatestFxRateParams{BaseCurrency: utils.USD, QuoteCurrency: utils.EUR})).
+					Times(1).
+					Return(rate, nil)
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, rate.ID, arg.FxRateID)
+						require.Equal(t, rate.Rate, arg.Rate)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.ExpiresAt, time.Second)
+						return db.FxQuote{ID: 1, Rate: arg.Rate}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OKInverseRate",
+			body: gin.H{"from_currency": utils.EUR, "to_currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				gomock.InOrder(
+					store.EXPECT().
+						GetLatestFxRate(gomock.Any(), gomock.Eq(db.GetLatestFxRateParams{BaseCurrency: utils.EUR, QuoteCurrency: utils.USD})).
+						Times(1).
+						Return(db.FxRate{}, sql.ErrNoRows),
+					store.EXPECT().
+						GetLatestFxRate(gomock.Any(), gomock.Eq(db.GetLatestFxRateParams{BaseCurrency: utils.USD, QuoteCurrency: utils.EUR})).
+						Times(1).
+						Return(rate, nil),
+				)
+				store.EXPECT().
+					CreateFxQuote(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateFxQuoteParams) (db.FxQuote, error) {
+						require.Equal(t, db.InvertFxRate(rate.Rate), arg.Rate)
+						return db.FxQuote{ID: 1, Rate: arg.Rate}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NoRate",
+			body: gin.H{"from_currency": utils.USD, "to_currency": utils.EUR},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetLatestFxRate(gomock.Any(), gomock.Any()).Times(2).Return(db.FxRate{}, sql.ErrNoRows)
+				store.EXPECT().CreateFxQuote(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "SameCurrency",
+			body: gin.H{"from_currency": utils.USD, "to_currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetLatestFxRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/fx/quotes", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+)
+
+var errIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+
+// idempotencyKey reads the Idempotency-Key header. It returns nil, true when
+// the header is absent, and writes a 400 and returns false when it is invalid.
+func (server *Server) idempotencyKey(c *gin.Context, req interface{}) (*db.IdempotencyKeyParams, bool) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		return nil, true
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		c.JSON(http.StatusBadRequest, errorResponse(errors.New("idempotency key is too long")))
+		return nil, false
+	}
+	hash, err := requestHash(req)
+	if err != nil {
+		writeError(c, err)
+		return nil, false
+	}
+	return &db.IdempotencyKeyParams{
+		Username:       authUser(c).Username,
+		Key:            key,
+		RequestHash:    hash,
+		ResponseStatus: http.StatusOK,
+		ExpiresAt:      time.Now().Add(server.config.IdempotencyKeyTTL),
+	}, true
+}
+
+// replayIdempotentResponse writes the response stored under key, if there is
+// one, and reports whether it did.
+func (server *Server) replayIdempotentResponse(c *gin.Context, key *db.IdempotencyKeyParams) bool {
+	stored, err := server.store.GetIdempotencyKey(c, db.GetIdempotencyKeyParams{
+		Username:       key.Username,
+		IdempotencyKey: key.Key,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return false
+		}
+		writeError(c, err)
+		return true
+	}
+	if stored.RequestHash != key.RequestHash {
+		writeError(c, errIdempotencyKeyReused)
+		return true
+	}
+	c.Data(int(stored.ResponseStatus), "application/json; charset=utf-8", stored.ResponseBody)
+	return true
+}
+
+func requestHash(req interface{}) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
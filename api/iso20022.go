@@ -0,0 +1,295 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/iso20022"
+)
+
+// maxPain001Size is the size of the largest pain.001 message accepted.
+const maxPain001Size = 10 << 20
+
+// writeXML writes the message marshal writes as the response.
+func writeXML(c *gin.Context, status int, marshal func(io.Writer) error) {
+	var buf bytes.Buffer
+	if err := marshal(&buf); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Data(status, "application/xml; charset=utf-8", buf.Bytes())
+}
+
+// statusReportID returns the MsgId of a pain.002 report created at now.
+func statusReportID(now time.Time) string {
+	return "STS-" + now.UTC().Format("20060102150405.000000")
+}
+
+// importPain001 books the payment information blocks of a pain.001 message
+// as transfer batches, on receipt, and answers with a pain.002 report of
+// what became of every transaction. A message that breaks the rules of the
+// schema is rejected as a whole with a 422 and a pain.002 report listing
+// them.
+func (server *Server) importPain001(c *gin.Context) {
+	msg, err := iso20022.ParsePain001(http.MaxBytesReader(c.Writer, c.Request.Body, maxPain001Size))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	now := time.Now()
+	payments, err := msg.Validate()
+	var validationErrors iso20022.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		report := iso20022.RejectPain001(statusReportID(now), now, msg, validationErrors)
+		writeXML(c, http.StatusUnprocessableEntity, report.Marshal)
+		return
+	}
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	report := iso20022.StatusReport{
+		MessageID:         statusReportID(now),
+		CreatedAt:         now,
+		OriginalMessageID: msg.Initiate.GroupHeader.MessageID,
+	}
+	for _, payment := range payments {
+		outcome, err := server.bookPayment(c, report.OriginalMessageID, payment)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		report.Payments = append(report.Payments, outcome)
+	}
+	writeXML(c, http.StatusOK, iso20022.NewPain002(report).Marshal)
+}
+
+// bookPayment runs a payment of a pain.001 message as a transfer batch. A
+// payment that can't be booked comes back rejected with the reason; the
+// error is only set when the store fails. A payment above the approval
+// threshold, with a party sanctions screening flagged or with a transaction
+// the risk engine wants reviewed, is held as a whole until another user
+// approves it.
+func (server *Server) bookPayment(c *gin.Context, messageID string, payment iso20022.Payment) (iso20022.PaymentOutcome, error) {
+	outcome := iso20022.PaymentOutcome{Payment: payment}
+	reject := func(reason string, info ...string) (iso20022.PaymentOutcome, error) {
+		outcome.Reason = reason
+		outcome.Info = info
+		return outcome, nil
+	}
+	if len(payment.Transactions) > maxTransferBatchItems {
+		return reject(iso20022.ReasonNarrative, fmt.Sprintf("payment has %d transactions, at most %d are allowed", len(payment.Transactions), maxTransferBatchItems))
+	}
+
+	// the debtor account of someone else is reported as not found
+	var fromAccount db.Account
+	var err error
+	if isAccountRef(payment.DebtorAccount) {
+		fromAccount, err = server.getAccountByRef(c, payment.DebtorAccount)
+		if err == nil && fromAccount.Owner != authUser(c).Username {
+			err = db.ErrRecordNotFound
+		}
+	} else {
+		err = db.ErrRecordNotFound
+	}
+	switch {
+	case isNotFound(err):
+		return reject(iso20022.ReasonAccountNotFound, "debtor account not found")
+	case err != nil:
+		return outcome, err
+	case fromAccount.Currency != payment.Currency:
+		return reject(iso20022.ReasonWrongCurrency, fmt.Sprintf("debtor account holds %s, not %s", fromAccount.Currency, payment.Currency))
+	case fromAccount.Frozen:
+		return reject(iso20022.ReasonAccountBlocked, db.ErrAccountFrozen.Error())
+	}
+
+	reqItems := make([]transferBatchItemRequest, len(payment.Transactions))
+	for i, tx := range payment.Transactions {
+		reqItems[i] = transferBatchItemRequest{
+			ToAccount: tx.CreditorAccount,
+			Amount:    tx.Amount,
+			Reference: tx.Remittance,
+			Line:      i + 1,
+		}
+	}
+	items, lineErrors, err := server.validateBatchItems(c, fromAccount, reqItems)
+	if err != nil {
+		return outcome, err
+	}
+	if len(lineErrors) > 0 {
+		info := make([]string, len(lineErrors))
+		for i, lineError := range lineErrors {
+			if lineError.Line == 0 {
+				info[i] = lineError.Error
+				continue
+			}
+			info[i] = fmt.Sprintf("%s: %s", payment.Transactions[lineError.Line-1].EndToEndID, lineError.Error)
+		}
+		return reject(iso20022.ReasonNarrative, info...)
+	}
+	for i := range items {
+		items[i].EndToEndID = payment.Transactions[items[i].Line-1].EndToEndID
+	}
+
+	user := authUser(c)
+	flagged, blocked, err := server.screenBatchParties(c, user, fromAccount, items)
+	if err != nil {
+		return outcome, err
+	}
+	if blocked != nil {
+		return reject(iso20022.ReasonNarrative, errComplianceBlocked.Error())
+	}
+	decision, err := server.screenBatch(c, fromAccount, items)
+	if err != nil {
+		return outcome, err
+	}
+	if decision.Decision == db.RiskBlock {
+		return reject(iso20022.ReasonNarrative, db.ErrTransferBlocked.Error())
+	}
+
+	mode := db.TransferBatchPerItem
+	if payment.BatchBooking {
+		mode = db.TransferBatchAtomic
+	}
+	arg := db.CreateTransferBatchTxParams{
+		Username:             user.Username,
+		FromAccountID:        fromAccount.ID,
+		Currency:             payment.Currency,
+		Mode:                 mode,
+		Items:                items,
+		MessageID:            messageID,
+		PaymentInformationID: payment.ID,
+	}
+	if server.batchNeedsApproval(payment.Currency, items) || flagged || decision.Decision == db.RiskReview {
+		arg.Hold = server.pendingHold(reviewDecisionID(decision))
+	}
+	batch, err := server.store.CreateTransferBatchTx(c, arg)
+	if errors.Is(err, db.ErrUniqueViolation) {
+		return reject(iso20022.ReasonDuplicate, "payment information block has already been imported")
+	}
+	if err != nil {
+		return outcome, err
+	}
+	if batch.PendingTransfer != nil {
+		// reported as pending until the batch is approved
+		outcome.Batch = &batch
+		return outcome, nil
+	}
+	// finish the batch even if the client goes away
+	result, err := server.store.ExecuteTransferBatch(context.WithoutCancel(c.Request.Context()), batch.Batch.ID)
+	if err != nil {
+		return outcome, err
+	}
+	outcome.Batch = &result
+	return outcome, nil
+}
+
+// getTransferBatchPain002 reports on a batch imported from a pain.001
+// message as a pain.002 message, for clients that poll for the outcome.
+func (server *Server) getTransferBatchPain002(c *gin.Context) {
+	var req transferBatchRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	batch, err := server.store.GetTransferBatch(c, req.ID)
+	if err == nil && batch.Username != authUser(c).Username {
+		err = db.ErrRecordNotFound
+	}
+	if err != nil {
+		writeLookupError(c, "transfer batch", err)
+		return
+	}
+	if !batch.MessageID.Valid {
+		err := errors.New("transfer batch wasn't imported from a pain.001 message")
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": codeNotFound})
+		return
+	}
+	items, err := server.store.ListTransferBatchItems(c, batch.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	payment := iso20022.Payment{ID: batch.PaymentInformationID.String, Currency: batch.Currency}
+	for _, item := range items {
+		payment.Transactions = append(payment.Transactions, iso20022.Transaction{
+			EndToEndID: item.EndToEndID,
+			Amount:     item.Amount,
+			Remittance: item.Reference,
+		})
+	}
+	now := time.Now()
+	report := iso20022.NewPain002(iso20022.StatusReport{
+		MessageID:         statusReportID(now),
+		CreatedAt:         now,
+		OriginalMessageID: batch.MessageID.String,
+		Payments: []iso20022.PaymentOutcome{{
+			Payment: payment,
+			Batch:   &db.TransferBatchResult{Batch: batch, Items: items},
+		}},
+	})
+	writeXML(c, http.StatusOK, report.Marshal)
+}
+
+type accountStatementUri struct {
+	ID string `uri:"id" binding:"required,account_ref"`
+}
+
+type accountStatementQuery struct {
+	Date string `form:"date" binding:"required,datetime=2006-01-02"`
+}
+
+// getCamt053Statement returns the end of day statement of an account as a
+// camt.053 message, to its owner or to staff. Only days that are over have a
+// statement.
+func (server *Server) getCamt053Statement(c *gin.Context) {
+	var uri accountStatementUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var query accountStatementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	from, _ := time.Parse("2006-01-02", query.Date)
+	to := from.AddDate(0, 0, 1)
+	now := time.Now()
+	if to.After(now) {
+		err := fmt.Errorf("the statement of %s isn't available before the end of the day (UTC)", query.Date)
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user := authUser(c)
+	account, err := server.getAccountByRef(c, uri.ID)
+	if err == nil && user.Role != db.RoleStaff && account.Owner != user.Username {
+		err = db.ErrRecordNotFound
+	}
+	if err != nil {
+		writeLookupError(c, "account", err)
+		return
+	}
+	statement, err := db.GetAccountStatement(c, server.store, db.GetAccountStatementParams{
+		AccountID: account.ID,
+		From:      from,
+		To:        to,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	messageID := fmt.Sprintf("STMT-%d-%s", account.ID, from.Format("20060102"))
+	writeXML(c, http.StatusOK, iso20022.NewCamt053(messageID, now, statement).Marshal)
+}
@@ -0,0 +1,604 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/iso20022"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// pain001Accounts returns the accounts of ../iso20022/testdata/pain001.xml,
+// by account number, with the debtor accounts owned by owner.
+func pain001Accounts(owner string) map[string]db.Account {
+	accounts := make(map[string]db.Account)
+	for i, number := range []string{
+		"DIGI0001000000000137",
+		"DIGI0001000000000234",
+		"DIGI0001000000000331",
+		"DIGI0001000000000428",
+		"DIGI0001000000000525",
+	} {
+		account := db.Account{
+			ID:            int64(i + 1),
+			Owner:         utils.RandomOwner(),
+			Currency:      utils.USD,
+			Balance:       1_000_000,
+			AccountNumber: number,
+		}
+		if i >= 3 {
+			account.Currency = utils.EUR
+		}
+		accounts[number] = account
+	}
+	for _, number := range []string{"DIGI0001000000000137", "DIGI0001000000000428"} {
+		account := accounts[number]
+		account.Owner = owner
+		accounts[number] = account
+	}
+	return accounts
+}
+
+func expectAccountsByNumber(store *mockdb.MockStore, accounts map[string]db.Account, numbers ...string) {
+	for _, number := range numbers {
+		store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(number)).Times(1).Return(accounts[number], nil)
+	}
+}
+
+// executedBatch returns the result of a batch whose items all completed.
+func executedBatch(id int64, arg db.CreateTransferBatchTxParams) db.TransferBatchResult {
+	result := db.TransferBatchResult{Batch: db.TransferBatch{
+		ID:                   id,
+		Username:             arg.Username,
+		Status:               db.TransferBatchCompleted,
+		MessageID:            sql.NullString{String: arg.MessageID, Valid: true},
+		PaymentInformationID: sql.NullString{String: arg.PaymentInformationID, Valid: true},
+	}}
+	for i, item := range arg.Items {
+		result.Items = append(result.Items, db.TransferBatchItem{
+			ID:          id*10 + int64(i),
+			BatchID:     id,
+			Line:        item.Line,
+			ToAccountID: item.ToAccountID,
+			Amount:      item.Amount,
+			EndToEndID:  item.EndToEndID,
+			Status:      db.TransferBatchItemCompleted,
+		})
+	}
+	return result
+}
+
+func requirePain002(t *testing.T, body *bytes.Buffer) iso20022.Pain002 {
+	msg, err := iso20022.ParsePain002(body)
+	require.NoError(t, err)
+	return msg
+}
+
+func TestImportPain001(t *testing.T) {
+	user, password := randomUser(t)
+	accounts := pain001Accounts(user.Username)
+	fixture, err := os.ReadFile("../iso20022/testdata/pain001.xml")
+	require.NoError(t, err)
+	invalidFixture, err := os.ReadFile("../iso20022/testdata/pain001_invalid.xml")
+	require.NoError(t, err)
+
+	payrollArg := db.CreateTransferBatchTxParams{
+		Username:      user.Username,
+		FromAccountID: 1,
+		Currency:      utils.USD,
+		Mode:          db.TransferBatchAtomic,
+		Items: []db.TransferBatchItemParams{
+			{Line: 1, ToAccountID: 2, Amount: 100025, Reference: "Salary March", EndToEndID: "E2E-1"},
+			{Line: 2, ToAccountID: 3, Amount: 50000, EndToEndID: "E2E-2"},
+		},
+		MessageID:            "MSG-2024-0001",
+		PaymentInformationID: "PAYROLL-MARCH",
+	}
+	suppliersArg := db.CreateTransferBatchTxParams{
+		Username:      user.Username,
+		FromAccountID: 4,
+		Currency:      utils.EUR,
+		Mode:          db.TransferBatchPerItem,
+		Items: []db.TransferBatchItemParams{
+			{Line: 1, ToAccountID: 5, Amount: 25000, Reference: "Invoice 77", EndToEndID: "INV-77"},
+		},
+		MessageID:            "MSG-2024-0001",
+		PaymentInformationID: "SUPPLIERS",
+	}
+	expectPayroll := func(store *mockdb.MockStore) {
+		expectAccountsByNumber(store, accounts, "DIGI0001000000000137", "DIGI0001000000000234", "DIGI0001000000000331")
+		store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(payrollArg)).Times(1).Return(db.TransferBatchResult{Batch: db.TransferBatch{ID: 1}}, nil)
+		store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(executedBatch(1, payrollArg), nil)
+	}
+
+	testCases := []struct {
+		name string
+		body []byte
+		// approvalThresholds replaces the thresholds of the test server,
+		// which the payments of the fixture are above.
+		approvalThresholds string
+		sanctionsList      string
+		buildStubs         func(store *mockdb.MockStore)
+		checkResponse      func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: fixture,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayroll(store)
+				expectAccountsByNumber(store, accounts, "DIGI0001000000000428", "DIGI0001000000000525")
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(suppliersArg)).Times(1).Return(db.TransferBatchResult{Batch: db.TransferBatch{ID: 2}}, nil)
+				failed := executedBatch(2, suppliersArg)
+				failed.Batch.Status = db.TransferBatchFailed
+				failed.Items[0].Status = db.TransferBatchItemFailed
+				failed.Items[0].Reason = db.ErrInsufficientFunds.Error()
+				failed.Items[0].FailureCode = db.BatchItemInsufficientFunds
+				store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Eq(int64(2))).Times(1).Return(failed, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "application/xml")
+				msg := requirePain002(t, recorder.Body)
+				require.Equal(t, "MSG-2024-0001", msg.Report.OriginalGroup.MessageID)
+				require.Equal(t, iso20022.StatusPartiallyAccepted, msg.Report.OriginalGroup.Status)
+				require.Len(t, msg.Report.Payments, 2)
+				require.Equal(t, iso20022.StatusSettled, msg.Report.Payments[0].Status)
+				require.Len(t, msg.Report.Payments[0].Transactions, 2)
+				require.Equal(t, iso20022.StatusRejected, msg.Report.Payments[1].Status)
+				require.Equal(t, iso20022.ReasonInsufficientFunds, msg.Report.Payments[1].Transactions[0].Reasons[0].Code)
+			},
+		},
+		{
+			name:               "AboveApprovalThreshold",
+			body:               fixture,
+			approvalThresholds: "USD:100000,EUR:100000",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccountsByNumber(store, accounts, "DIGI0001000000000137", "DIGI0001000000000234", "DIGI0001000000000331")
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateTransferBatchTxParams) (db.TransferBatchResult, error) {
+						require.NotNil(t, arg.Hold)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.Hold.ExpiresAt, time.Minute)
+						arg.Hold = nil
+						require.Equal(t, payrollArg, arg)
+
+						held := executedBatch(1, payrollArg)
+						held.Batch.Status = db.TransferBatchHeld
+						for i := range held.Items {
+							held.Items[i].Status = db.TransferBatchItemPending
+						}
+						held.PendingTransfer = &db.PendingTransfer{ID: 3, Kind: db.PendingTransferKindTransferBatch}
+						return held, nil
+					})
+				store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Eq(int64(1))).Times(0)
+				expectAccountsByNumber(store, accounts, "DIGI0001000000000428", "DIGI0001000000000525")
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(suppliersArg)).Times(1).Return(db.TransferBatchResult{Batch: db.TransferBatch{ID: 2}}, nil)
+				store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Eq(int64(2))).Times(1).Return(executedBatch(2, suppliersArg), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				msg := requirePain002(t, recorder.Body)
+				require.Equal(t, iso20022.StatusPartiallyAccepted, msg.Report.OriginalGroup.Status)
+				require.Equal(t, iso20022.StatusPending, msg.Report.Payments[0].Status)
+				require.Equal(t, iso20022.StatusSettled, msg.Report.Payments[1].Status)
+			},
+		},
+		{
+			name: "BlockedByRiskEngine",
+			body: fixture,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccountsByNumber(store, accounts, "DIGI0001000000000137", "DIGI0001000000000234", "DIGI0001000000000331")
+				store.EXPECT().
+					ListRiskRules(gomock.Any(), gomock.Eq(utils.USD)).
+					Times(1).
+					Return([]db.RiskRule{{Name: "velocity_usd", Kind: db.RiskRuleVelocity, Action: db.RiskBlock, MaxCount: 3, WindowMinutes: 10}}, nil)
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(3), nil)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(payrollArg)).Times(0)
+				expectAccountsByNumber(store, accounts, "DIGI0001000000000428", "DIGI0001000000000525")
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(suppliersArg)).Times(1).Return(db.TransferBatchResult{Batch: db.TransferBatch{ID: 2}}, nil)
+				store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Eq(int64(2))).Times(1).Return(executedBatch(2, suppliersArg), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				msg := requirePain002(t, recorder.Body)
+				payment := msg.Report.Payments[0]
+				require.Equal(t, iso20022.StatusRejected, payment.Status)
+				require.Equal(t, iso20022.ReasonNarrative, payment.Reasons[0].Code)
+				require.Equal(t, []string{db.ErrTransferBlocked.Error()}, payment.Reasons[0].AdditionalInfo)
+				require.Equal(t, iso20022.StatusSettled, msg.Report.Payments[1].Status)
+			},
+		},
+		{
+			name:          "CreditorSanctioned",
+			body:          fixture,
+			sanctionsList: testSanctionsList,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectCreditor := func(number string, fullName string) {
+					account := accounts[number]
+					store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+					store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(account.Owner)).Times(1).Return(db.User{Username: account.Owner, FullName: fullName}, nil)
+				}
+				expectAccountsByNumber(store, accounts, "DIGI0001000000000137", "DIGI0001000000000234", "DIGI0001000000000331")
+				expectCreditor("DIGI0001000000000234", "Tran Van Duc")
+				expectCreditor("DIGI0001000000000331", "Le Van Hai")
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{ID: 1}, nil)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(payrollArg)).Times(0)
+				expectAccountsByNumber(store, accounts, "DIGI0001000000000428", "DIGI0001000000000525")
+				expectCreditor("DIGI0001000000000525", "Le Van Hai")
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(suppliersArg)).Times(1).Return(db.TransferBatchResult{Batch: db.TransferBatch{ID: 2}}, nil)
+				store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Eq(int64(2))).Times(1).Return(executedBatch(2, suppliersArg), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				msg := requirePain002(t, recorder.Body)
+				payment := msg.Report.Payments[0]
+				require.Equal(t, iso20022.StatusRejected, payment.Status)
+				require.Equal(t, iso20022.ReasonNarrative, payment.Reasons[0].Code)
+				require.Equal(t, []string{errComplianceBlocked.Error()}, payment.Reasons[0].AdditionalInfo)
+				require.Equal(t, iso20022.StatusSettled, msg.Report.Payments[1].Status)
+			},
+		},
+		{
+			name: "DebtorAccountOfSomeoneElse",
+			body: fixture,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayroll(store)
+				other := accounts["DIGI0001000000000428"]
+				other.Owner = utils.RandomOwner()
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(other.AccountNumber)).Times(1).Return(other, nil)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(suppliersArg)).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				msg := requirePain002(t, recorder.Body)
+				require.Equal(t, iso20022.StatusPartiallyAccepted, msg.Report.OriginalGroup.Status)
+				payment := msg.Report.Payments[1]
+				require.Equal(t, iso20022.StatusRejected, payment.Status)
+				require.Equal(t, iso20022.ReasonAccountNotFound, payment.Reasons[0].Code)
+				require.Empty(t, payment.Transactions)
+			},
+		},
+		{
+			name: "CreditorAccountNotFound",
+			body: fixture,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayroll(store)
+				expectAccountsByNumber(store, accounts, "DIGI0001000000000428")
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq("DIGI0001000000000525")).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(suppliersArg)).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				payment := requirePain002(t, recorder.Body).Report.Payments[1]
+				require.Equal(t, iso20022.StatusRejected, payment.Status)
+				require.Equal(t, iso20022.ReasonNarrative, payment.Reasons[0].Code)
+				require.Equal(t, []string{"INV-77: account DIGI0001000000000525 not found"}, payment.Reasons[0].AdditionalInfo)
+			},
+		},
+		{
+			name: "AlreadyImported",
+			body: fixture,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAccountsByNumber(store, accounts, "DIGI0001000000000137", "DIGI0001000000000234", "DIGI0001000000000331")
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(payrollArg)).Times(1).Return(db.TransferBatchResult{}, db.ErrUniqueViolation)
+				expectAccountsByNumber(store, accounts, "DIGI0001000000000428", "DIGI0001000000000525")
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(suppliersArg)).Times(1).Return(db.TransferBatchResult{}, db.ErrUniqueViolation)
+				store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				msg := requirePain002(t, recorder.Body)
+				require.Equal(t, iso20022.StatusRejected, msg.Report.OriginalGroup.Status)
+				for _, payment := range msg.Report.Payments {
+					require.Equal(t, iso20022.ReasonDuplicate, payment.Reasons[0].Code)
+				}
+			},
+		},
+		{
+			name: "InvalidMessage",
+			body: invalidFixture,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				msg := requirePain002(t, recorder.Body)
+				require.Equal(t, "MSG-2024-0002", msg.Report.OriginalGroup.MessageID)
+				require.Equal(t, iso20022.StatusRejected, msg.Report.OriginalGroup.Status)
+				require.NotEmpty(t, msg.Report.OriginalGroup.Reasons)
+			},
+		},
+		{
+			name: "MalformedXML",
+			body: fixture[:200],
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "OtherMessage",
+			body: []byte(strings.Replace(string(fixture), "pain.001.001.03", "pain.001.001.09", 1)),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: fixture,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, user)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+			server := newTestServer(t, store)
+			if tc.sanctionsList != "" {
+				server = newSanctionsTestServer(t, store, tc.sanctionsList)
+			}
+			server.config.ApprovalThresholds = "USD:1000000,EUR:1000000"
+			if tc.approvalThresholds != "" {
+				server.config.ApprovalThresholds = tc.approvalThresholds
+			}
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/iso20022/pain.001", bytes.NewReader(tc.body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/xml")
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetTransferBatchPain002(t *testing.T) {
+	user, password := randomUser(t)
+	batch := db.TransferBatch{
+		ID:                   7,
+		Username:             user.Username,
+		Currency:             utils.USD,
+		Status:               db.TransferBatchPartiallyCompleted,
+		MessageID:            sql.NullString{String: "MSG-2024-0001", Valid: true},
+		PaymentInformationID: sql.NullString{String: "PAYROLL-MARCH", Valid: true},
+	}
+	items := []db.TransferBatchItem{
+		{ID: 1, BatchID: 7, Line: 1, Amount: 100, EndToEndID: "E2E-1", Status: db.TransferBatchItemCompleted},
+		{ID: 2, BatchID: 7, Line: 2, Amount: 200, EndToEndID: "E2E-2", Status: db.TransferBatchItemFailed, Reason: "account 9: " + db.ErrAccountFrozen.Error(), FailureCode: db.BatchItemAccountFrozen},
+	}
+	otherBatch := batch
+	otherBatch.Username = utils.RandomOwner()
+	jsonBatch := batch
+	jsonBatch.MessageID = sql.NullString{}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(items, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				msg := requirePain002(t, recorder.Body)
+				require.Equal(t, "MSG-2024-0001", msg.Report.OriginalGroup.MessageID)
+				require.Equal(t, iso20022.StatusPartiallyAccepted, msg.Report.OriginalGroup.Status)
+				payment := msg.Report.Payments[0]
+				require.Equal(t, "PAYROLL-MARCH", payment.PaymentInformationID)
+				require.Equal(t, iso20022.StatusSettled, payment.Transactions[0].Status)
+				require.Equal(t, iso20022.StatusRejected, payment.Transactions[1].Status)
+				require.Equal(t, iso20022.ReasonAccountBlocked, payment.Transactions[1].Reasons[0].Code)
+			},
+		},
+		{
+			name: "OtherUsersBatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(otherBatch, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NotImported",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(jsonBatch, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, user)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfer-batches/%d/pain.002", batch.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetCamt053Statement(t *testing.T) {
+	user, password := randomUser(t)
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+	account := randomAccount()
+	account.Owner = user.Username
+	account.Currency = utils.USD
+	account.Balance = 10_000
+	otherAccount := account
+	otherAccount.Owner = utils.RandomOwner()
+
+	day := time.Now().UTC().AddDate(0, 0, -2).Truncate(24 * time.Hour)
+	date := day.Format("2006-01-02")
+	transfer := db.Transfer{ID: 5, Amount: 2500, Memo: "rent", Reference: sql.NullString{String: "INV-1", Valid: true}}
+	entries := []db.Entry{
+		{ID: 11, Amount: -2500, TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true}, CreatedAt: sql.NullTime{Time: day.Add(time.Hour), Valid: true}},
+	}
+
+	expectStatement := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(2).Return(account, nil)
+		store.EXPECT().
+			ListEntriesBetween(gomock.Any(), gomock.Eq(db.ListEntriesBetweenParams{AccountID: account.ID, CreatedFrom: day, CreatedTo: day.AddDate(0, 0, 1)})).
+			Times(1).
+			Return(entries, nil)
+		store.EXPECT().
+			GetEntriesTotalSince(gomock.Any(), gomock.Eq(db.GetEntriesTotalSinceParams{AccountID: account.ID, Since: day.AddDate(0, 0, 1)})).
+			Times(1).
+			Return(int64(1000), nil)
+		store.EXPECT().ListTransfersByIDs(gomock.Any(), gomock.Eq([]int64{transfer.ID})).Times(1).Return([]db.Transfer{transfer}, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		password      string
+		date          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			user:       user,
+			password:   password,
+			date:       date,
+			buildStubs: expectStatement,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				msg, err := iso20022.ParseCamt053(recorder.Body)
+				require.NoError(t, err)
+				stmt := msg.Statement.Statements[0]
+				require.Equal(t, fmt.Sprintf("STMT-%d-%s", account.ID, day.Format("20060102")), stmt.ID)
+				require.Equal(t, account.AccountNumber, stmt.Account.Number)
+				// 10000 now, 1000 credited since the day, 2500 debited on it
+				require.Equal(t, "115.00", stmt.Balances[0].Amount.Value)
+				require.Equal(t, "90.00", stmt.Balances[1].Amount.Value)
+				require.Len(t, stmt.Entries, 1)
+				require.Equal(t, iso20022.Debit, stmt.Entries[0].CreditDebit)
+				require.Equal(t, "INV-1", stmt.Entries[0].Details.EndToEndID)
+			},
+		},
+		{
+			name:       "Staff",
+			user:       staff,
+			password:   staffPassword,
+			date:       date,
+			buildStubs: expectStatement,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherUsersAccount",
+			user:     user,
+			password: password,
+			date:     date,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().ListEntriesBetween(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "Today",
+			user:     user,
+			password: password,
+			date:     time.Now().UTC().Format("2006-01-02"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidDate",
+			user:     user,
+			password: password,
+			date:     "2024-02-30",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, tc.user)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statements/camt.053?date=%s", account.ID, tc.date)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(tc.user.Username, tc.password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -3,11 +3,32 @@ package api
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
 )
 
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := utils.Config{
+		IdempotencyKeyTTL: time.Minute,
+		FXQuoteTTL:        time.Minute,
+		ReversalWindow:    time.Hour,
+		// above anything utils.RandomMoney returns
+		ApprovalThresholds:      "USD:10000,EUR:10000,VND:10000",
+		PendingTransferTTL:      time.Hour,
+		PaymentRequestTTL:       24 * time.Hour,
+		TransferConfirmationTTL: 5 * time.Minute,
+	}
+
+	server := NewServer(config, store)
+	require.NotNil(t, server)
+	return server
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
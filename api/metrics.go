@@ -0,0 +1,22 @@
+package api
+
+import (
+	"errors"
+	"expvar"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+var errNotOperator = errors.New("only staff can see metrics")
+
+// getMetrics shows staff the expvar metrics of the server, like the
+// transactions the store had to retry under db_tx, as JSON.
+func (server *Server) getMetrics(c *gin.Context) {
+	if authUser(c).Role != db.RoleStaff {
+		c.JSON(http.StatusForbidden, errorResponse(errNotOperator))
+		return
+	}
+	expvar.Handler().ServeHTTP(c.Writer, c.Request)
+}
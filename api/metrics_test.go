@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+func TestGetMetrics(t *testing.T) {
+	user, password := randomUser(t)
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		password      string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			user:     staff,
+			password: staffPassword,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var metrics map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &metrics))
+				require.Contains(t, metrics, "db_tx")
+			},
+		},
+		{
+			name:     "NotStaff",
+			user:     user,
+			password: password,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, tc.user)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/metrics", nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(tc.user.Username, tc.password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
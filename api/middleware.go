@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+const authorizationPayloadKey = "authorization_payload"
+
+var errUnauthenticated = errors.New("missing or invalid credentials")
+
+// authMiddleware authenticates the request with HTTP basic auth against the
+// users table and stores the user in the context for the handlers.
+func authMiddleware(store db.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			abortWithError(c, errUnauthenticated)
+			return
+		}
+
+		user, err := store.GetUsers(c, username)
+		if err != nil {
+			if isNotFound(err) {
+				abortWithError(c, errUnauthenticated)
+				return
+			}
+			abortWithError(c, err)
+			return
+		}
+		if err := utils.CheckPasswordHash(password, user.HashedPassword); err != nil {
+			abortWithError(c, errUnauthenticated)
+			return
+		}
+
+		c.Set(authorizationPayloadKey, user)
+		c.Next()
+	}
+}
+
+// authUser returns the user authenticated by authMiddleware.
+func authUser(c *gin.Context) db.User {
+	return c.MustGet(authorizationPayloadKey).(db.User)
+}
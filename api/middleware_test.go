@@ -0,0 +1,134 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(request *http.Request)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user.Username, password)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), user.Username)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			setupAuth: func(request *http.Request) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "WrongPassword",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user.Username, password+"x")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user.Username, password)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user.Username, password)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.router.GET("/auth", authMiddleware(store), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"username": authUser(c).Username})
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/auth", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(request)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func randomUser(t *testing.T) (user db.User, password string) {
+	password = utils.RandomString(6)
+	hashedPassword, err := utils.HashedPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		Username:       utils.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+		Tier:           "standard",
+		Role:           db.RoleCustomer,
+	}
+	return
+}
+
+// expectAuth stubs the user lookup done by authMiddleware.
+func expectAuth(store *mockdb.MockStore, user db.User) {
+	store.EXPECT().
+		GetUsers(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+}
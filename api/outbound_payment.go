@@ -0,0 +1,160 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+type createBeneficiaryRequest struct {
+	// Name is the account holder at the receiving bank.
+	Name          string `json:"name" binding:"required,max=100"`
+	RoutingNumber string `json:"routing_number" binding:"required,routing_number"`
+	AccountNumber string `json:"account_number" binding:"required,alphanum,max=17"`
+	// AccountType defaults to checking.
+	AccountType string `json:"account_type" binding:"omitempty,oneof=checking savings"`
+}
+
+// createBeneficiary saves an account at another bank the authenticated user
+// can send outbound transfers to.
+func (server *Server) createBeneficiary(c *gin.Context) {
+	var req createBeneficiaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.AccountType == "" {
+		req.AccountType = db.AccountTypeChecking
+	}
+
+	beneficiary, err := server.store.CreateBeneficiary(c, db.CreateBeneficiaryParams{
+		Owner:         authUser(c).Username,
+		Name:          req.Name,
+		RoutingNumber: req.RoutingNumber,
+		AccountNumber: req.AccountNumber,
+		AccountType:   req.AccountType,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, beneficiary)
+}
+
+type listBeneficiariesRequest struct {
+	PageID   int64 `form:"page_id" binding:"required,min=1"`
+	PageSize int64 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listBeneficiaries lists the authenticated user's beneficiaries.
+func (server *Server) listBeneficiaries(c *gin.Context) {
+	var req listBeneficiariesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	beneficiaries, err := server.store.ListBeneficiaries(c, db.ListBeneficiariesParams{
+		Owner:  authUser(c).Username,
+		Limit:  int32(req.PageSize),
+		Offset: int32((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, beneficiaries)
+}
+
+type createOutboundTransferRequest struct {
+	FromAccountID     int64  `json:"from_account_id" binding:"required_without=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string `json:"from_account_number" binding:"required_without=FromAccountID,omitempty,account_number"`
+	BeneficiaryID     int64  `json:"beneficiary_id" binding:"required,min=1"`
+	// Amount is at most ach.MaxAmount, the most an ACH entry can carry.
+	Amount    int64  `json:"amount" binding:"required,gt=0,max=9999999999"`
+	Memo      string `json:"memo,omitempty" binding:"max=500"`
+	Reference string `json:"reference,omitempty" binding:"max=64"`
+	Category  string `json:"category,omitempty" binding:"omitempty,transfer_category"`
+}
+
+// createOutboundTransfer pays one of the authenticated user's beneficiaries
+// at another bank. The amount leaves the account right away and is sent in
+// the next ACH export; only US dollars can be sent. A transfer above the
+// approval threshold, one sanctions screening flagged or one the risk engine
+// wants reviewed, is held until another user approves it.
+func (server *Server) createOutboundTransfer(c *gin.Context) {
+	var req createOutboundTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validSourceAccount(c, req.FromAccountID, req.FromAccountNumber, utils.USD)
+	if !valid {
+		return
+	}
+	user := authUser(c)
+	beneficiary, err := server.store.GetBeneficiary(c, req.BeneficiaryID)
+	if err == nil && beneficiary.Owner != user.Username {
+		err = db.ErrRecordNotFound
+	}
+	if err != nil {
+		writeLookupError(c, "beneficiary", err)
+		return
+	}
+	clearing, err := server.store.GetAccountByOwnerAndCurrency(c, db.GetAccountByOwnerAndCurrencyParams{
+		Owner:    db.ClearingOwner,
+		Currency: utils.USD,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	flagged, ok := server.screenSanctions(c, db.SanctionsOperationTransfer, user.Username,
+		sanctionsSubject{Username: user.Username, FullName: user.FullName, AccountID: sql.NullInt64{Int64: fromAccount.ID, Valid: true}},
+		sanctionsSubject{FullName: beneficiary.Name},
+	)
+	if !ok {
+		return
+	}
+	decision, ok := server.screenTransfer(c, fromAccount, clearing.ID, req.Amount)
+	if !ok {
+		return
+	}
+	if server.config.NeedsApproval(utils.USD, req.Amount) || flagged || decision.Decision == db.RiskReview {
+		server.holdTransfer(c, db.CreatePendingTransferParams{
+			Kind:           db.PendingTransferKindOutboundTransfer,
+			FromAccountID:  fromAccount.ID,
+			ToAccountID:    sql.NullInt64{Int64: clearing.ID, Valid: true},
+			Amount:         req.Amount,
+			Currency:       utils.USD,
+			Memo:           req.Memo,
+			Reference:      sql.NullString{String: req.Reference, Valid: req.Reference != ""},
+			Category:       req.Category,
+			BeneficiaryID:  sql.NullInt64{Int64: beneficiary.ID, Valid: true},
+			RiskDecisionID: reviewDecisionID(decision),
+		}, nil)
+		return
+	}
+
+	result, err := server.store.CreateOutboundTransferTx(c, db.CreateOutboundTransferTxParams{
+		Username:      user.Username,
+		FromAccountID: fromAccount.ID,
+		BeneficiaryID: beneficiary.ID,
+		Amount:        req.Amount,
+		TransferDetails: db.TransferDetails{
+			Memo:      req.Memo,
+			Reference: req.Reference,
+			Category:  req.Category,
+		},
+	})
+	if err != nil {
+		server.transferError(c, err, nil)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
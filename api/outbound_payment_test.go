@@ -0,0 +1,308 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/ach"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestCreateBeneficiary(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name       string
+		body       gin.H
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": "Jane Doe", "routing_number": "011000015", "account_number": "12345678"},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateBeneficiaryParams{
+					Owner:         user.Username,
+					Name:          "Jane Doe",
+					RoutingNumber: "011000015",
+					AccountNumber: "12345678",
+					AccountType:   db.AccountTypeChecking,
+				}
+				store.EXPECT().CreateBeneficiary(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Beneficiary{ID: 1}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "InvalidRoutingNumber",
+			body: gin.H{"name": "Jane Doe", "routing_number": "011000016", "account_number": "12345678"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateBeneficiary(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "InvalidAccountType",
+			body: gin.H{"name": "Jane Doe", "routing_number": "011000015", "account_number": "12345678", "account_type": "brokerage"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateBeneficiary(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "AlreadyExists",
+			body: gin.H{"name": "Jane Doe", "routing_number": "011000015", "account_number": "12345678", "account_type": "savings"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateBeneficiary(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Beneficiary{}, db.ErrUniqueViolation)
+			},
+			code: http.StatusConflict,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, user)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/beneficiaries", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
+
+func TestCreateOutboundTransfer(t *testing.T) {
+	user, password := randomUser(t)
+	account := randomAccount()
+	account.Owner = user.Username
+	account.Currency = utils.USD
+	eurAccount := account
+	eurAccount.Currency = utils.EUR
+	clearing := db.Account{ID: account.ID + 1, Owner: db.ClearingOwner, Currency: utils.USD}
+	beneficiary := db.Beneficiary{
+		ID:            7,
+		Owner:         user.Username,
+		Name:          "Jane Doe",
+		RoutingNumber: "011000015",
+		AccountNumber: "12345678",
+		AccountType:   db.AccountTypeChecking,
+	}
+	otherBeneficiary := beneficiary
+	otherBeneficiary.Owner = utils.RandomOwner()
+	sanctioned := beneficiary
+	sanctioned.Name = "Ivan Petrov"
+	flagged := beneficiary
+	flagged.Name = "Evan Petrov"
+
+	expectClearing := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Eq(db.GetAccountByOwnerAndCurrencyParams{Owner: db.ClearingOwner, Currency: utils.USD})).
+			Times(1).
+			Return(clearing, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		sanctionsList string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		code          int
+	}{
+		{
+			name: "OK",
+			body: gin.H{"from_account_id": account.ID, "beneficiary_id": beneficiary.ID, "amount": 100, "memo": "rent"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(beneficiary, nil)
+				expectClearing(store)
+				arg := db.CreateOutboundTransferTxParams{
+					Username:        user.Username,
+					FromAccountID:   account.ID,
+					BeneficiaryID:   beneficiary.ID,
+					Amount:          100,
+					TransferDetails: db.TransferDetails{Memo: "rent"},
+				}
+				store.EXPECT().
+					CreateOutboundTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.OutboundTransferTxResult{Payment: db.OutboundPayment{ID: 3, Status: db.OutboundPaymentPending}}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "AboveApprovalThreshold",
+			body: gin.H{"from_account_id": account.ID, "beneficiary_id": beneficiary.ID, "amount": 20000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(beneficiary, nil)
+				expectClearing(store)
+				store.EXPECT().CreateOutboundTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreatePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePendingTransferTxParams) (db.PendingTransfer, error) {
+						require.Equal(t, db.PendingTransferKindOutboundTransfer, arg.Kind)
+						require.Equal(t, sql.NullInt64{Int64: beneficiary.ID, Valid: true}, arg.BeneficiaryID)
+						require.Equal(t, sql.NullInt64{Int64: clearing.ID, Valid: true}, arg.ToAccountID)
+						require.Equal(t, int64(20000), arg.Amount)
+						return db.PendingTransfer{ID: 1, Kind: arg.Kind}, nil
+					})
+			},
+			code: http.StatusAccepted,
+		},
+		{
+			name: "NotUSD",
+			body: gin.H{"from_account_id": account.ID, "beneficiary_id": beneficiary.ID, "amount": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(eurAccount, nil)
+				store.EXPECT().CreateOutboundTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "OtherUsersBeneficiary",
+			body: gin.H{"from_account_id": account.ID, "beneficiary_id": beneficiary.ID, "amount": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(otherBeneficiary, nil)
+				store.EXPECT().CreateOutboundTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "BeneficiaryNotFound",
+			body: gin.H{"from_account_id": account.ID, "beneficiary_id": beneficiary.ID, "amount": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(db.Beneficiary{}, sql.ErrNoRows)
+				store.EXPECT().CreateOutboundTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name:          "BeneficiarySanctioned",
+			sanctionsList: testSanctionsList,
+			body:          gin.H{"from_account_id": account.ID, "beneficiary_id": sanctioned.ID, "amount": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(sanctioned.ID)).Times(1).Return(sanctioned, nil)
+				expectClearing(store)
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().
+					CreateSanctionsCase(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateSanctionsCaseParams) (db.SanctionsCase, error) {
+						require.Equal(t, db.SanctionsOperationTransfer, arg.Operation)
+						require.Equal(t, sanctioned.Name, arg.ScreenedName)
+						require.Empty(t, arg.ScreenedUsername)
+						return db.SanctionsCase{ID: 1, Action: arg.Action}, nil
+					})
+				store.EXPECT().CreateOutboundTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:          "BeneficiaryFlagged",
+			sanctionsList: testSanctionsList,
+			body:          gin.H{"from_account_id": account.ID, "beneficiary_id": flagged.ID, "amount": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(flagged.ID)).Times(1).Return(flagged, nil)
+				expectClearing(store)
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{ID: 2}, nil)
+				store.EXPECT().CreateOutboundTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreatePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePendingTransferTxParams) (db.PendingTransfer, error) {
+						require.Equal(t, db.PendingTransferKindOutboundTransfer, arg.Kind)
+						require.Equal(t, flagged.ID, arg.BeneficiaryID.Int64)
+						return db.PendingTransfer{ID: 1}, nil
+					})
+			},
+			code: http.StatusAccepted,
+		},
+		{
+			name: "InsufficientFunds",
+			body: gin.H{"from_account_id": account.ID, "beneficiary_id": beneficiary.ID, "amount": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetBeneficiary(gomock.Any(), gomock.Eq(beneficiary.ID)).Times(1).Return(beneficiary, nil)
+				expectClearing(store)
+				store.EXPECT().
+					CreateOutboundTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OutboundTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "MissingBeneficiary",
+			body: gin.H{"from_account_id": account.ID, "amount": 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateOutboundTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "AmountTooLarge",
+			body: gin.H{"from_account_id": account.ID, "beneficiary_id": beneficiary.ID, "amount": ach.MaxAmount + 1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateOutboundTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, user)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+			server := newTestServer(t, store)
+			if tc.sanctionsList != "" {
+				server = newSanctionsTestServer(t, store, tc.sanctionsList)
+			}
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/outbound-transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+var (
+	errNotRequesterAccount  = errors.New("requester account doesn't belong to the authenticated user")
+	errSelfPaymentRequest   = errors.New("a payment request can't be addressed to its requester")
+	errPaymentRequestExpiry = errors.New("expires_at must be in the future and within the maximum payment request lifetime")
+)
+
+type createPaymentRequestRequest struct {
+	RequesterAccountID     int64  `json:"requester_account_id" binding:"required_without=RequesterAccountNumber,omitempty,min=1"`
+	RequesterAccountNumber string `json:"requester_account_number" binding:"required_without=RequesterAccountID,omitempty,account_number"`
+	Payer                  string `json:"payer" binding:"required,alphanum"`
+	Amount                 int64  `json:"amount" binding:"required,gt=0"`
+	Currency               string `json:"currency" binding:"required,currency"`
+	Note                   string `json:"note" binding:"max=500"`
+	// ExpiresAt defaults to the configured payment request lifetime.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// createPaymentRequest asks another user to pay an amount into one of the
+// authenticated user's accounts.
+func (server *Server) createPaymentRequest(c *gin.Context) {
+	var req createPaymentRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	maxExpiry := time.Now().Add(server.config.PaymentRequestTTL)
+	if req.ExpiresAt.IsZero() {
+		req.ExpiresAt = maxExpiry
+	}
+	if !req.ExpiresAt.After(time.Now()) || req.ExpiresAt.After(maxExpiry) {
+		c.JSON(http.StatusBadRequest, errorResponse(errPaymentRequestExpiry))
+		return
+	}
+
+	account, valid := server.validAccount(c, req.RequesterAccountID, req.RequesterAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	user := authUser(c)
+	if account.Owner != user.Username {
+		c.JSON(http.StatusForbidden, errorResponse(errNotRequesterAccount))
+		return
+	}
+	if req.Payer == user.Username {
+		c.JSON(http.StatusBadRequest, errorResponse(errSelfPaymentRequest))
+		return
+	}
+	if _, err := server.store.GetUsers(c, req.Payer); err != nil {
+		writeLookupError(c, "payer", err)
+		return
+	}
+
+	request, err := server.store.CreatePaymentRequest(c, db.CreatePaymentRequestParams{
+		RequesterAccountID: account.ID,
+		Payer:              req.Payer,
+		Amount:             req.Amount,
+		Currency:           req.Currency,
+		Note:               req.Note,
+		ExpiresAt:          req.ExpiresAt,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+type listPaymentRequestsRequest struct {
+	PageID   int64 `form:"page_id" binding:"required,min=1"`
+	PageSize int64 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listIncomingPaymentRequests lists the requests addressed to the
+// authenticated user, newest first.
+func (server *Server) listIncomingPaymentRequests(c *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	requests, err := server.store.ListIncomingPaymentRequests(c, db.ListIncomingPaymentRequestsParams{
+		Payer:  authUser(c).Username,
+		Limit:  int32(req.PageSize),
+		Offset: int32((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// listOutgoingPaymentRequests lists the requests the authenticated user sent
+// from any of their accounts, newest first.
+func (server *Server) listOutgoingPaymentRequests(c *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	requests, err := server.store.ListOutgoingPaymentRequests(c, db.ListOutgoingPaymentRequestsParams{
+		Owner:  authUser(c).Username,
+		Limit:  int32(req.PageSize),
+		Offset: int32((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+type paymentRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// incomingPaymentRequest loads the payment request in the URI and checks that
+// it is addressed to the authenticated user. It writes the error response and
+// returns false when it isn't.
+func (server *Server) incomingPaymentRequest(c *gin.Context) (db.PaymentRequest, bool) {
+	var uri paymentRequestURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.PaymentRequest{}, false
+	}
+
+	request, err := server.store.GetPaymentRequest(c, uri.ID)
+	if err == nil && request.Payer != authUser(c).Username {
+		// don't tell other users which ids exist
+		err = db.ErrRecordNotFound
+	}
+	if err != nil {
+		writeLookupError(c, "payment request", err)
+		return request, false
+	}
+	return request, true
+}
+
+// declinePaymentRequest lets the payer turn a pending request down.
+func (server *Server) declinePaymentRequest(c *gin.Context) {
+	request, ok := server.incomingPaymentRequest(c)
+	if !ok {
+		return
+	}
+
+	request, err := server.store.DecidePaymentRequest(c, db.DecidePaymentRequestParams{
+		ID:     request.ID,
+		Status: db.PaymentRequestDeclined,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			// it was paid or declined since we read it
+			err = db.ErrPaymentRequestClosed
+		}
+		server.paymentRequestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+type payPaymentRequestRequest struct {
+	FromAccountID     int64  `json:"from_account_id" binding:"required_without=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string `json:"from_account_number" binding:"required_without=FromAccountID,omitempty,account_number"`
+}
+
+// payPaymentRequest pays a request addressed to the authenticated user from
+// one of their accounts in the request's currency. A payment above the
+// approval threshold, one sanctions screening flagged or one the risk engine
+// wants reviewed, is held until another user approves it; the request stays
+// pending until then.
+func (server *Server) payPaymentRequest(c *gin.Context) {
+	request, ok := server.incomingPaymentRequest(c)
+	if !ok {
+		return
+	}
+	var req payPaymentRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	switch {
+	case request.Status != db.PaymentRequestPending:
+		server.paymentRequestError(c, db.ErrPaymentRequestClosed)
+		return
+	case !time.Now().Before(request.ExpiresAt):
+		server.paymentRequestError(c, db.ErrPaymentRequestExpired)
+		return
+	}
+	fromAccount, valid := server.validSourceAccount(c, req.FromAccountID, req.FromAccountNumber, request.Currency)
+	if !valid {
+		return
+	}
+	flagged := false
+	if server.sanctions.Len() > 0 {
+		toAccount, err := server.store.GetAccounts(c, request.RequesterAccountID)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		if flagged, ok = server.screenTransferParties(c, fromAccount, toAccount); !ok {
+			return
+		}
+	}
+	decision, ok := server.screenTransfer(c, fromAccount, request.RequesterAccountID, request.Amount)
+	if !ok {
+		return
+	}
+	if server.config.NeedsApproval(request.Currency, request.Amount) || flagged || decision.Decision == db.RiskReview {
+		server.holdTransfer(c, db.CreatePendingTransferParams{
+			Kind:             db.PendingTransferKindPaymentRequest,
+			FromAccountID:    fromAccount.ID,
+			ToAccountID:      sql.NullInt64{Int64: request.RequesterAccountID, Valid: true},
+			Amount:           request.Amount,
+			Currency:         request.Currency,
+			Memo:             request.Note,
+			PaymentRequestID: sql.NullInt64{Int64: request.ID, Valid: true},
+			RiskDecisionID:   reviewDecisionID(decision),
+		}, nil)
+		return
+	}
+
+	result, err := server.store.PayPaymentRequestTx(c, db.PayPaymentRequestTxParams{
+		ID:            request.ID,
+		Payer:         request.Payer,
+		FromAccountID: fromAccount.ID,
+	})
+	if err != nil {
+		server.paymentRequestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// paymentRequestError writes the response for an error from deciding or
+// paying a payment request.
+func (server *Server) paymentRequestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, db.ErrPaymentRequestClosed), errors.Is(err, db.ErrPaymentRequestExpired):
+		c.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, db.ErrNotPaymentRequestPayer):
+		c.JSON(http.StatusForbidden, errorResponse(err))
+	default:
+		server.transferError(c, err, nil)
+	}
+}
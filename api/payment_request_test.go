@@ -0,0 +1,474 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestCreatePaymentRequest(t *testing.T) {
+	requester, requesterPassword := randomUser(t)
+	payer, _ := randomUser(t)
+
+	account := randomAccount()
+	account.Owner = requester.Username
+	account.Currency = utils.USD
+	otherAccount := randomAccount()
+	otherAccount.Currency = utils.USD
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"requester_account_id": account.ID,
+				"payer":                payer.Username,
+				"amount":               500,
+				"currency":             utils.USD,
+				"note":                 "pizza",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+				store.EXPECT().
+					CreatePaymentRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePaymentRequestParams) (db.PaymentRequest, error) {
+						require.Equal(t, account.ID, arg.RequesterAccountID)
+						require.Equal(t, payer.Username, arg.Payer)
+						require.Equal(t, int64(500), arg.Amount)
+						require.Equal(t, utils.USD, arg.Currency)
+						require.Equal(t, "pizza", arg.Note)
+						require.WithinDuration(t, time.Now().Add(24*time.Hour), arg.ExpiresAt, time.Minute)
+						return db.PaymentRequest{ID: 1, Status: db.PaymentRequestPending}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotOwnAccount",
+			body: gin.H{
+				"requester_account_id": otherAccount.ID,
+				"payer":                payer.Username,
+				"amount":               500,
+				"currency":             utils.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(otherAccount.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "SelfRequest",
+			body: gin.H{
+				"requester_account_id": account.ID,
+				"payer":                requester.Username,
+				"amount":               500,
+				"currency":             utils.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PayerNotFound",
+			body: gin.H{
+				"requester_account_id": account.ID,
+				"payer":                payer.Username,
+				"amount":               500,
+				"currency":             utils.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiryTooFar",
+			body: gin.H{
+				"requester_account_id": account.ID,
+				"payer":                payer.Username,
+				"amount":               500,
+				"currency":             utils.USD,
+				"expires_at":           time.Now().Add(48 * time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			body: gin.H{
+				"requester_account_id": account.ID,
+				"payer":                payer.Username,
+				"amount":               -1,
+				"currency":             utils.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, requester)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/payment-requests", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(requester.Username, requesterPassword)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListPaymentRequests(t *testing.T) {
+	user, password := randomUser(t)
+	requests := []db.PaymentRequest{{ID: 1, Payer: user.Username, Amount: 10}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(user.Username)).Times(2).Return(user, nil)
+	store.EXPECT().
+		ListIncomingPaymentRequests(gomock.Any(), gomock.Eq(db.ListIncomingPaymentRequestsParams{Payer: user.Username, Limit: 5, Offset: 5})).
+		Times(1).
+		Return(requests, nil)
+	store.EXPECT().
+		ListOutgoingPaymentRequests(gomock.Any(), gomock.Eq(db.ListOutgoingPaymentRequestsParams{Owner: user.Username, Limit: 5, Offset: 0})).
+		Times(1).
+		Return([]db.PaymentRequest{}, nil)
+
+	server := newTestServer(t, store)
+	for _, url := range []string{
+		"/payment-requests/incoming?page_id=2&page_size=5",
+		"/payment-requests/outgoing?page_id=1&page_size=5",
+	} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		request.SetBasicAuth(user.Username, password)
+
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+}
+
+func TestPayPaymentRequest(t *testing.T) {
+	payer, payerPassword := randomUser(t)
+	other, otherPassword := randomUser(t)
+
+	account := randomAccount()
+	account.Owner = payer.Username
+	account.Currency = utils.USD
+	eurAccount := account
+	eurAccount.Currency = utils.EUR
+
+	pending := db.PaymentRequest{
+		ID:                 utils.RandomInt(1, 1000),
+		RequesterAccountID: utils.RandomInt(1, 1000),
+		Payer:              payer.Username,
+		Amount:             500,
+		Currency:           utils.USD,
+		Status:             db.PaymentRequestPending,
+		ExpiresAt:          time.Now().Add(time.Hour),
+	}
+	paid := pending
+	paid.Status = db.PaymentRequestPaid
+	expired := pending
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	large := pending
+	large.Amount = 20000
+
+	testCases := []struct {
+		name       string
+		user       db.User
+		password   string
+		body       gin.H
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name:     "OK",
+			user:     payer,
+			password: payerPassword,
+			body:     gin.H{"from_account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.PayPaymentRequestTxParams{
+					ID:            pending.ID,
+					Payer:         payer.Username,
+					FromAccountID: account.ID,
+				}
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "NotPayer",
+			user:     other,
+			password: otherPassword,
+			body:     gin.H{"from_account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name:     "AlreadyPaid",
+			user:     payer,
+			password: payerPassword,
+			body:     gin.H{"from_account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(paid, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusConflict,
+		},
+		{
+			name:     "PaidConcurrently",
+			user:     payer,
+			password: payerPassword,
+			body:     gin.H{"from_account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					PayPaymentRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PayPaymentRequestTxResult{}, db.ErrPaymentRequestClosed)
+			},
+			code: http.StatusConflict,
+		},
+		{
+			name:     "NotPayerInStore",
+			user:     payer,
+			password: payerPassword,
+			body:     gin.H{"from_account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					PayPaymentRequestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PayPaymentRequestTxResult{}, db.ErrNotPaymentRequestPayer)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:     "Expired",
+			user:     payer,
+			password: payerPassword,
+			body:     gin.H{"from_account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(expired, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusConflict,
+		},
+		{
+			name:     "AboveApprovalThreshold",
+			user:     payer,
+			password: payerPassword,
+			body:     gin.H{"from_account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(large, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreatePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreatePendingTransferTxParams) (db.PendingTransfer, error) {
+						require.Equal(t, db.PendingTransferKindPaymentRequest, arg.Kind)
+						require.Equal(t, sql.NullInt64{Int64: large.ID, Valid: true}, arg.PaymentRequestID)
+						require.Equal(t, account.ID, arg.FromAccountID)
+						require.Equal(t, sql.NullInt64{Int64: large.RequesterAccountID, Valid: true}, arg.ToAccountID)
+						require.Equal(t, payer.Username, arg.CreatedBy)
+						return db.PendingTransfer{ID: 1, Kind: arg.Kind}, nil
+					})
+			},
+			code: http.StatusAccepted,
+		},
+		{
+			name:     "AlreadyWaitingForApproval",
+			user:     payer,
+			password: payerPassword,
+			body:     gin.H{"from_account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(large, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.PendingTransfer{}, db.ErrPaymentRequestHeld)
+			},
+			code: http.StatusConflict,
+		},
+		{
+			name:     "CurrencyMismatch",
+			user:     payer,
+			password: payerPassword,
+			body:     gin.H{"from_account_id": eurAccount.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(eurAccount.ID)).Times(1).Return(eurAccount, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:     "MissingAccount",
+			user:     payer,
+			password: payerPassword,
+			body:     gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, tc.user)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			url := fmt.Sprintf("/payment-requests/%d/pay", pending.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(tc.user.Username, tc.password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
+
+func TestDeclinePaymentRequest(t *testing.T) {
+	payer, payerPassword := randomUser(t)
+	pending := db.PaymentRequest{
+		ID:        utils.RandomInt(1, 1000),
+		Payer:     payer.Username,
+		Amount:    500,
+		Status:    db.PaymentRequestPending,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	declined := pending
+	declined.Status = db.PaymentRequestDeclined
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				arg := db.DecidePaymentRequestParams{ID: pending.ID, Status: db.PaymentRequestDeclined}
+				store.EXPECT().DecidePaymentRequest(gomock.Any(), gomock.Eq(arg)).Times(1).Return(declined, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "AlreadyDecided",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().DecidePaymentRequest(gomock.Any(), gomock.Any()).Times(1).Return(db.PaymentRequest{}, sql.ErrNoRows)
+			},
+			code: http.StatusConflict,
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(db.PaymentRequest{}, sql.ErrNoRows)
+				store.EXPECT().DecidePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, payer)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/payment-requests/%d/decline", pending.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(payer.Username, payerPassword)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
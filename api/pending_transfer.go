@@ -0,0 +1,173 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+var errNotChecker = errors.New("only staff can approve or reject pending transfers")
+
+// holdTransfer holds a transfer above the approval threshold of its
+// currency, or one the risk engine wants reviewed, until another user
+// approves it, and answers 202 with the pending transfer. arg says what the
+// approval runs; the maker and the expiry are filled in here.
+func (server *Server) holdTransfer(c *gin.Context, arg db.CreatePendingTransferParams, idempotencyKey *db.IdempotencyKeyParams) {
+	if idempotencyKey != nil {
+		idempotencyKey.ResponseStatus = http.StatusAccepted
+	}
+	arg.CreatedBy = authUser(c).Username
+	arg.ExpiresAt = server.pendingHold(arg.RiskDecisionID).ExpiresAt
+	pending, err := server.store.CreatePendingTransferTx(c, db.CreatePendingTransferTxParams{
+		CreatePendingTransferParams: arg,
+		IdempotencyKey:              idempotencyKey,
+	})
+	if err != nil {
+		server.transferError(c, err, idempotencyKey)
+		return
+	}
+	c.JSON(http.StatusAccepted, pending)
+}
+
+// pendingHold builds the expiry of a held transfer or batch and links it to
+// the risk decision that held it, if any.
+func (server *Server) pendingHold(riskDecisionID sql.NullInt64) *db.HoldParams {
+	return &db.HoldParams{
+		ExpiresAt:      time.Now().Add(server.config.PendingTransferTTL),
+		RiskDecisionID: riskDecisionID,
+	}
+}
+
+type listPendingTransfersRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending approved rejected expired"`
+	PageID   int64  `form:"page_id" binding:"required,min=1"`
+	PageSize int64  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listPendingTransfers shows staff the requests in a status, pending by
+// default, oldest first. Other users see the requests they created.
+func (server *Server) listPendingTransfers(c *gin.Context) {
+	var req listPendingTransfersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var pending []db.PendingTransfer
+	var err error
+	user := authUser(c)
+	if user.Role == db.RoleStaff {
+		if req.Status == "" {
+			req.Status = db.PendingTransferPending
+		}
+		pending, err = server.store.ListPendingTransfers(c, db.ListPendingTransfersParams{
+			Status: req.Status,
+			Limit:  int32(req.PageSize),
+			Offset: int32((req.PageID - 1) * req.PageSize),
+		})
+	} else {
+		pending, err = server.store.ListPendingTransfersByCreator(c, db.ListPendingTransfersByCreatorParams{
+			CreatedBy: user.Username,
+			Limit:     int32(req.PageSize),
+			Offset:    int32((req.PageID - 1) * req.PageSize),
+		})
+	}
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pending)
+}
+
+type pendingTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type pendingTransferResponse struct {
+	db.PendingTransfer
+	Approvals []db.PendingTransferApproval `json:"approvals"`
+}
+
+// getPendingTransfer returns a pending transfer with its approval trail. Only
+// staff and the user who created it can see it.
+func (server *Server) getPendingTransfer(c *gin.Context) {
+	var req pendingTransferRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user := authUser(c)
+	pending, err := server.store.GetPendingTransfer(c, req.ID)
+	if err == nil && user.Role != db.RoleStaff && pending.CreatedBy != user.Username {
+		// don't tell other users which ids exist
+		err = db.ErrRecordNotFound
+	}
+	if err != nil {
+		writeLookupError(c, "pending transfer", err)
+		return
+	}
+
+	approvals, err := server.store.ListPendingTransferApprovals(c, pending.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pendingTransferResponse{PendingTransfer: pending, Approvals: approvals})
+}
+
+type decidePendingTransferRequest struct {
+	Comment string `json:"comment" binding:"max=500"`
+}
+
+func (server *Server) approvePendingTransfer(c *gin.Context) {
+	server.decidePendingTransfer(c, db.DecisionApproved)
+}
+
+func (server *Server) rejectPendingTransfer(c *gin.Context) {
+	server.decidePendingTransfer(c, db.DecisionRejected)
+}
+
+// decidePendingTransfer records a staff member's decision on a pending
+// transfer. The store refuses decisions by the user who created the request.
+func (server *Server) decidePendingTransfer(c *gin.Context, decision string) {
+	var uri pendingTransferRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req decidePendingTransferRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	user := authUser(c)
+	if user.Role != db.RoleStaff {
+		c.JSON(http.StatusForbidden, errorResponse(errNotChecker))
+		return
+	}
+
+	result, err := server.store.DecidePendingTransferTx(c, db.DecidePendingTransferTxParams{
+		ID:       uri.ID,
+		Username: user.Username,
+		Decision: decision,
+		Comment:  req.Comment,
+	})
+	if err != nil {
+		switch {
+		case isNotFound(err):
+			writeLookupError(c, "pending transfer", err)
+		default:
+			server.transferError(c, err, nil)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,388 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func randomPendingTransfer(createdBy string) db.PendingTransfer {
+	return db.PendingTransfer{
+		ID:            utils.RandomInt(1, 1000),
+		CreatedBy:     createdBy,
+		FromAccountID: utils.RandomInt(1, 1000),
+		ToAccountID:   sql.NullInt64{Int64: utils.RandomInt(1, 1000), Valid: true},
+		Amount:        20000,
+		Currency:      utils.USD,
+		Status:        db.PendingTransferPending,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		CreatedAt:     time.Now(),
+	}
+}
+
+func TestDecidePendingTransfer(t *testing.T) {
+	maker, makerPassword := randomUser(t)
+	checker, checkerPassword := randomUser(t)
+	checker.Role = db.RoleStaff
+	pending := randomPendingTransfer(maker.Username)
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		setupAuth     func(request *http.Request)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Approve",
+			action: "approve",
+			body:   gin.H{"comment": "checked with the customer"},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(checker.Username, checkerPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, checker)
+				arg := db.DecidePendingTransferTxParams{
+					ID:       pending.ID,
+					Username: checker.Username,
+					Decision: db.DecisionApproved,
+					Comment:  "checked with the customer",
+				}
+				store.EXPECT().DecidePendingTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "RejectWithoutComment",
+			action: "reject",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(checker.Username, checkerPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, checker)
+				arg := db.DecidePendingTransferTxParams{
+					ID:       pending.ID,
+					Username: checker.Username,
+					Decision: db.DecisionRejected,
+				}
+				store.EXPECT().DecidePendingTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "NotStaff",
+			action: "approve",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(maker.Username, makerPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, maker)
+				store.EXPECT().DecidePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "SelfApproval",
+			action: "approve",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(checker.Username, checkerPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, checker)
+				store.EXPECT().
+					DecidePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DecidePendingTransferTxResult{}, db.ErrSelfApproval)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "AlreadyDecided",
+			action: "reject",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(checker.Username, checkerPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, checker)
+				store.EXPECT().
+					DecidePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DecidePendingTransferTxResult{}, db.ErrPendingTransferDecided)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "Expired",
+			action: "approve",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(checker.Username, checkerPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, checker)
+				store.EXPECT().
+					DecidePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DecidePendingTransferTxResult{}, db.ErrPendingTransferExpired)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "LimitExceededOnApproval",
+			action: "approve",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(checker.Username, checkerPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, checker)
+				store.EXPECT().
+					DecidePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DecidePendingTransferTxResult{}, &db.LimitExceededError{Limit: db.LimitMaxDailyAmount})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			action: "approve",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(checker.Username, checkerPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, checker)
+				store.EXPECT().
+					DecidePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DecidePendingTransferTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body []byte
+			if tc.body != nil {
+				var err error
+				body, err = json.Marshal(tc.body)
+				require.NoError(t, err)
+			}
+			url := fmt.Sprintf("/pending-transfers/%d/%s", pending.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+			tc.setupAuth(request)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetPendingTransfer(t *testing.T) {
+	maker, makerPassword := randomUser(t)
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+	other, otherPassword := randomUser(t)
+	pending := randomPendingTransfer(maker.Username)
+	approvals := []db.PendingTransferApproval{{
+		ID:                1,
+		PendingTransferID: pending.ID,
+		Username:          staff.Username,
+		Decision:          db.DecisionRejected,
+		Comment:           "unknown beneficiary",
+	}}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		password      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Maker",
+			user:     maker,
+			password: makerPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().ListPendingTransferApprovals(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(approvals, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got pendingTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, pending.ID, got.ID)
+				require.Len(t, got.Approvals, 1)
+				require.Equal(t, "unknown beneficiary", got.Approvals[0].Comment)
+			},
+		},
+		{
+			name:     "Staff",
+			user:     staff,
+			password: staffPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().ListPendingTransferApprovals(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(approvals, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherUser",
+			user:     other,
+			password: otherPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().ListPendingTransferApprovals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, tc.user)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/pending-transfers/%d", pending.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(tc.user.Username, tc.password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListPendingTransfers(t *testing.T) {
+	maker, makerPassword := randomUser(t)
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+
+	testCases := []struct {
+		name       string
+		user       db.User
+		password   string
+		query      string
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name:     "StaffQueue",
+			user:     staff,
+			password: staffPassword,
+			query:    "page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListPendingTransfersParams{Status: db.PendingTransferPending, Limit: 5, Offset: 0}
+				store.EXPECT().ListPendingTransfers(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "StaffByStatus",
+			user:     staff,
+			password: staffPassword,
+			query:    "status=expired&page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListPendingTransfersParams{Status: db.PendingTransferExpired, Limit: 5, Offset: 5}
+				store.EXPECT().ListPendingTransfers(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "OwnRequests",
+			user:     maker,
+			password: makerPassword,
+			query:    "page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListPendingTransfersByCreatorParams{CreatedBy: maker.Username, Limit: 5, Offset: 0}
+				store.EXPECT().ListPendingTransfersByCreator(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().ListPendingTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "InvalidStatus",
+			user:     staff,
+			password: staffPassword,
+			query:    "status=done&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPendingTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, tc.user)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/pending-transfers?"+tc.query, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(tc.user.Username, tc.password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+var errNotAuditor = errors.New("only staff can see reconciliation runs")
+
+type getReconciliationRequest struct {
+	RunID int64 `form:"run_id" binding:"omitempty,min=1"`
+}
+
+// getReconciliation shows staff a reconciliation run, the latest unless
+// run_id says otherwise, with the discrepancies it found.
+func (server *Server) getReconciliation(c *gin.Context) {
+	if authUser(c).Role != db.RoleStaff {
+		c.JSON(http.StatusForbidden, errorResponse(errNotAuditor))
+		return
+	}
+	var req getReconciliationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var run db.ReconciliationRun
+	var err error
+	if req.RunID == 0 {
+		run, err = server.store.GetLatestReconciliationRun(c)
+	} else {
+		run, err = server.store.GetReconciliationRun(c, req.RunID)
+	}
+	if err != nil {
+		writeLookupError(c, "reconciliation run", err)
+		return
+	}
+	discrepancies, err := server.store.ListReconciliationDiscrepancies(c, run.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, db.ReconciliationResult{Run: run, Discrepancies: discrepancies})
+}
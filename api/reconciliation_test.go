@@ -0,0 +1,123 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestGetReconciliation(t *testing.T) {
+	user, password := randomUser(t)
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+
+	run := db.ReconciliationRun{ID: 7, AccountsChecked: 12, Discrepancies: 1}
+	discrepancies := []db.ReconciliationDiscrepancy{
+		{ID: 1, RunID: run.ID, AccountID: sql.NullInt64{Int64: 3, Valid: true}, Currency: utils.USD, Balance: 100, EntriesTotal: 80, Difference: 20},
+		{ID: 2, RunID: run.ID, Currency: utils.USD, Balance: 1000, EntriesTotal: 980, Difference: 20},
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		password      string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Latest",
+			user:     staff,
+			password: staffPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestReconciliationRun(gomock.Any()).Times(1).Return(run, nil)
+				store.EXPECT().ListReconciliationDiscrepancies(gomock.Any(), gomock.Eq(run.ID)).Times(1).Return(discrepancies, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var got db.ReconciliationResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, run.ID, got.Run.ID)
+				require.Equal(t, discrepancies, got.Discrepancies)
+			},
+		},
+		{
+			name:     "ByRunID",
+			user:     staff,
+			password: staffPassword,
+			query:    "?run_id=7",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Eq(run.ID)).Times(1).Return(run, nil)
+				store.EXPECT().ListReconciliationDiscrepancies(gomock.Any(), gomock.Eq(run.ID)).Times(1).Return([]db.ReconciliationDiscrepancy{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NoRuns",
+			user:     staff,
+			password: staffPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestReconciliationRun(gomock.Any()).Times(1).Return(db.ReconciliationRun{}, sql.ErrNoRows)
+				store.EXPECT().ListReconciliationDiscrepancies(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidRunID",
+			user:     staff,
+			password: staffPassword,
+			query:    "?run_id=-1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetLatestReconciliationRun(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotStaff",
+			user:     user,
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestReconciliationRun(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, tc.user)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/reconciliation"+tc.query, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(tc.user.Username, tc.password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
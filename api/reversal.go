@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+var (
+	errNotTransferSender     = errors.New("only staff or the sender can reverse a transfer")
+	errReversalWindowExpired = errors.New("the transfer is too old to be reversed by its sender")
+)
+
+type reverseTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reverseTransferRequest struct {
+	// Amount to reverse; omit it to reverse everything that is left.
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// reverseTransfer sends a transfer, or part of it, back to its sender. Staff
+// can reverse any transfer; senders only their own, within the reversal window.
+func (server *Server) reverseTransfer(c *gin.Context) {
+	var uri reverseTransferURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req reverseTransferRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	transfer, err := server.store.GetTransfer(c, uri.ID)
+	if err != nil {
+		writeLookupError(c, "transfer", err)
+		return
+	}
+	if !server.canReverse(c, transfer) {
+		return
+	}
+
+	result, err := server.store.ReverseTransferTx(c, db.ReverseTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// canReverse checks that the authenticated user may reverse the transfer and
+// writes the error response when they may not.
+func (server *Server) canReverse(c *gin.Context, transfer db.Transfer) bool {
+	user := authUser(c)
+	if user.Role == db.RoleStaff {
+		return true
+	}
+
+	sender, err := server.store.GetAccounts(c, transfer.FromAccountID.Int64)
+	if err != nil {
+		writeError(c, err)
+		return false
+	}
+	if sender.Owner != user.Username {
+		c.JSON(http.StatusForbidden, errorResponse(errNotTransferSender))
+		return false
+	}
+	if time.Since(transfer.CreatedAt.Time) > server.config.ReversalWindow {
+		c.JSON(http.StatusForbidden, errorResponse(errReversalWindowExpired))
+		return false
+	}
+	return true
+}
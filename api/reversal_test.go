@@ -0,0 +1,224 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestReverseTransfer(t *testing.T) {
+	sender, senderPassword := randomUser(t)
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+	other, otherPassword := randomUser(t)
+
+	fromAccount := randomAccount()
+	fromAccount.Owner = sender.Username
+	toAccount := randomAccount()
+
+	transfer := db.Transfer{
+		ID:            utils.RandomInt(1, 1000),
+		FromAccountID: sql.NullInt64{Int64: fromAccount.ID, Valid: true},
+		ToAccountID:   sql.NullInt64{Int64: toAccount.ID, Valid: true},
+		Amount:        100,
+		CreatedAt:     sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+	}
+	oldTransfer := transfer
+	oldTransfer.CreatedAt.Time = time.Now().Add(-2 * time.Hour)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(request *http.Request)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "SenderWithinWindow",
+			body: gin.H{"amount": 40},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(sender.Username, senderPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, sender)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				arg := db.ReverseTransferTxParams{TransferID: transfer.ID, Amount: 40}
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "SenderWindowExpired",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(sender.Username, senderPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, sender)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(oldTransfer, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "StaffAfterWindow",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(staff.Username, staffPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, staff)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(oldTransfer, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+				arg := db.ReverseTransferTxParams{TransferID: transfer.ID}
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotSender",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(other.Username, otherPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, other)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "ExceedsRemaining",
+			body: gin.H{"amount": 80},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(staff.Username, staffPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, staff)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, &db.ReversalExceedsError{Requested: 80, Remaining: 60})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, float64(60), body["remaining"])
+			},
+		},
+		{
+			name: "TransferNotCompleted",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(staff.Username, staffPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, staff)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrTransferNotCompleted)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "OutboundPayment",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(staff.Username, staffPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, staff)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrOutboundPaymentReversal)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "TransferNotFound",
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(staff.Username, staffPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, staff)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			body: gin.H{"amount": -5},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(staff.Username, staffPassword)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, staff)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body []byte
+			if tc.body != nil {
+				var err error
+				body, err = json.Marshal(tc.body)
+				require.NoError(t, err)
+			}
+			url := fmt.Sprintf("/transfers/%d/reverse", transfer.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+			require.NoError(t, err)
+			tc.setupAuth(request)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
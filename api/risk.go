@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+var errNotRiskReviewer = errors.New("only staff can see risk decisions")
+
+// screenTransfer runs a transfer through the risk engine before it is
+// executed. It writes the error response and returns false when the transfer
+// is blocked; a review decision is left to the caller, which holds the
+// transfer for approval.
+func (server *Server) screenTransfer(c *gin.Context, fromAccount db.Account, toAccountID int64, amount int64) (db.RiskDecision, bool) {
+	decision, err := db.ScreenTransfer(c, server.store, db.ScreenTransferParams{
+		FromAccount: fromAccount,
+		ToAccountID: toAccountID,
+		Amount:      amount,
+	})
+	if err != nil {
+		writeError(c, err)
+		return decision, false
+	}
+	if decision.Decision == db.RiskBlock {
+		writeTransferBlocked(c, decision)
+		return decision, false
+	}
+	return decision, true
+}
+
+// screenBatch runs every item of a batch through the risk engine and returns
+// the strictest decision: the first block, or else the first review.
+func (server *Server) screenBatch(ctx context.Context, fromAccount db.Account, items []db.TransferBatchItemParams) (db.RiskDecision, error) {
+	var strictest db.RiskDecision
+	for _, item := range items {
+		decision, err := db.ScreenTransfer(ctx, server.store, db.ScreenTransferParams{
+			FromAccount: fromAccount,
+			ToAccountID: item.ToAccountID,
+			Amount:      item.Amount,
+		})
+		if err != nil || decision.Decision == db.RiskBlock {
+			return decision, err
+		}
+		if strictest.Decision != db.RiskReview {
+			strictest = decision
+		}
+	}
+	return strictest, nil
+}
+
+func writeTransferBlocked(c *gin.Context, decision db.RiskDecision) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error": db.ErrTransferBlocked.Error(),
+		"code":  codeTransferBlocked,
+		"rules": decision.FiredRules,
+	})
+}
+
+// reviewDecisionID returns the id to link a pending transfer to when the
+// risk engine held it for review.
+func reviewDecisionID(decision db.RiskDecision) sql.NullInt64 {
+	return sql.NullInt64{Int64: decision.ID, Valid: decision.Decision == db.RiskReview}
+}
+
+type listRiskDecisionsRequest struct {
+	Decision string `form:"decision" binding:"omitempty,oneof=allow review block"`
+	PageID   int64  `form:"page_id" binding:"required,min=1"`
+	PageSize int64  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listRiskDecisions shows staff the decisions of the risk engine, newest
+// first, optionally only those of one kind.
+func (server *Server) listRiskDecisions(c *gin.Context) {
+	if authUser(c).Role != db.RoleStaff {
+		c.JSON(http.StatusForbidden, errorResponse(errNotRiskReviewer))
+		return
+	}
+	var req listRiskDecisionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	decisions := []string{db.RiskAllow, db.RiskReview, db.RiskBlock}
+	if req.Decision != "" {
+		decisions = []string{req.Decision}
+	}
+	list, err := server.store.ListRiskDecisions(c, db.ListRiskDecisionsParams{
+		Decisions: decisions,
+		Limit:     int32(req.PageSize),
+		Offset:    int32((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
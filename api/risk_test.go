@@ -0,0 +1,344 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// expectNoRiskRules lets every transfer through the risk engine.
+func expectNoRiskRules(store *mockdb.MockStore) {
+	store.EXPECT().ListRiskRules(gomock.Any(), gomock.Any()).AnyTimes().Return([]db.RiskRule{}, nil)
+	store.EXPECT().
+		CreateRiskDecision(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ interface{}, arg db.CreateRiskDecisionParams) (db.RiskDecision, error) {
+			return db.RiskDecision{ID: 1, Decision: arg.Decision, FiredRules: arg.FiredRules}, nil
+		})
+}
+
+func TestCreateTransferRisk(t *testing.T) {
+	user, password := randomUser(t)
+	account1 := randomAccount()
+	account1.Owner = user.Username
+	account1.Currency = utils.USD
+	account2 := randomAccount()
+	account2.Currency = utils.USD
+
+	velocity := func(action string) db.RiskRule {
+		return db.RiskRule{Name: "velocity_" + action, Kind: db.RiskRuleVelocity, Action: action, MaxCount: 3, WindowMinutes: 10}
+	}
+	expectDecision := func(store *mockdb.MockStore, rules []db.RiskRule, recent int64, decision string) {
+		store.EXPECT().ListRiskRules(gomock.Any(), gomock.Eq(utils.USD)).Times(1).Return(rules, nil)
+		store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(len(rules)).Return(recent, nil)
+		store.EXPECT().
+			CreateRiskDecision(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ interface{}, arg db.CreateRiskDecisionParams) (db.RiskDecision, error) {
+				require.Equal(t, user.Username, arg.Username)
+				require.Equal(t, account1.ID, arg.FromAccountID)
+				require.Equal(t, account2.ID, arg.ToAccountID)
+				require.Equal(t, decision, arg.Decision)
+				return db.RiskDecision{ID: 7, Decision: arg.Decision, FiredRules: arg.FiredRules}, nil
+			})
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Allow",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectDecision(store, []db.RiskRule{velocity(db.RiskReview)}, 1, db.RiskAllow)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Review",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectDecision(store, []db.RiskRule{velocity(db.RiskReview)}, 3, db.RiskReview)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreatePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePendingTransferTxParams) (db.PendingTransfer, error) {
+						require.True(t, arg.RiskDecisionID.Valid)
+						require.Equal(t, int64(7), arg.RiskDecisionID.Int64)
+						return db.PendingTransfer{ID: 1, Status: db.PendingTransferPending, RiskDecisionID: arg.RiskDecisionID}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "BlockWinsOverReview",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectDecision(store, []db.RiskRule{velocity(db.RiskReview), velocity(db.RiskBlock)}, 3, db.RiskBlock)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+
+				var got struct {
+					Code  string   `json:"code"`
+					Rules []string `json:"rules"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, codeTransferBlocked, got.Code)
+				require.Equal(t, []string{"velocity_review", "velocity_block"}, got.Rules)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListRiskRules(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, user)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCreateTransferBatchRisk(t *testing.T) {
+	user, password := randomUser(t)
+	fromAccount := randomAccount()
+	fromAccount.Owner = user.Username
+	fromAccount.Currency = utils.USD
+	fromAccount.Balance = 1000
+	toAccount1 := randomAccount()
+	toAccount1.ID = fromAccount.ID + 1
+	toAccount1.Currency = utils.USD
+	toAccount2 := randomAccount()
+	toAccount2.ID = fromAccount.ID + 2
+	toAccount2.Currency = utils.USD
+
+	// only the transfers to toAccount2 go to a new recipient
+	expectNewRecipient := func(store *mockdb.MockStore, action string) {
+		rule := db.RiskRule{Name: "new_recipient_" + action, Kind: db.RiskRuleNewRecipient, Action: action}
+		store.EXPECT().ListRiskRules(gomock.Any(), gomock.Eq(utils.USD)).Times(2).Return([]db.RiskRule{rule}, nil)
+		store.EXPECT().
+			CountTransfersTo(gomock.Any(), gomock.Any()).
+			Times(2).
+			DoAndReturn(func(_ interface{}, arg db.CountTransfersToParams) (int64, error) {
+				if arg.ToAccountID == toAccount2.ID {
+					return 0, nil
+				}
+				return 1, nil
+			})
+		store.EXPECT().
+			CreateRiskDecision(gomock.Any(), gomock.Any()).
+			Times(2).
+			DoAndReturn(func(_ interface{}, arg db.CreateRiskDecisionParams) (db.RiskDecision, error) {
+				return db.RiskDecision{ID: arg.ToAccountID, Decision: arg.Decision, FiredRules: arg.FiredRules}, nil
+			})
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Review",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectNewRecipient(store, db.RiskReview)
+				store.EXPECT().
+					CreateTransferBatchTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateTransferBatchTxParams) (db.TransferBatchResult, error) {
+						require.NotNil(t, arg.Hold)
+						require.Equal(t, sql.NullInt64{Int64: toAccount2.ID, Valid: true}, arg.Hold.RiskDecisionID)
+						return db.TransferBatchResult{
+							Batch:           db.TransferBatch{ID: 7, Status: db.TransferBatchHeld},
+							PendingTransfer: &db.PendingTransfer{ID: 3},
+						}, nil
+					})
+				store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "Block",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectNewRecipient(store, db.RiskBlock)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), codeTransferBlocked)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, user)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(1).Return(toAccount1, nil)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount2.ID)).Times(1).Return(toAccount2, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        utils.USD,
+				"mode":            db.TransferBatchPerItem,
+				"items": []gin.H{
+					{"to_account": fmt.Sprint(toAccount1.ID), "amount": 100},
+					{"to_account": fmt.Sprint(toAccount2.ID), "amount": 100},
+				},
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfer-batches", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListRiskDecisions(t *testing.T) {
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+	user, userPassword := randomUser(t)
+
+	testCases := []struct {
+		name       string
+		user       db.User
+		password   string
+		query      string
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name:     "AllDecisions",
+			user:     staff,
+			password: staffPassword,
+			query:    "?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListRiskDecisionsParams{
+					Decisions: []string{db.RiskAllow, db.RiskReview, db.RiskBlock},
+					Limit:     5,
+					Offset:    0,
+				}
+				store.EXPECT().ListRiskDecisions(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.RiskDecision{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "OnlyBlocked",
+			user:     staff,
+			password: staffPassword,
+			query:    "?decision=block&page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListRiskDecisionsParams{Decisions: []string{db.RiskBlock}, Limit: 5, Offset: 5}
+				store.EXPECT().ListRiskDecisions(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.RiskDecision{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "InvalidDecision",
+			user:     staff,
+			password: staffPassword,
+			query:    "?decision=maybe&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListRiskDecisions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:     "NotStaff",
+			user:     user,
+			password: userPassword,
+			query:    "?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListRiskDecisions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, tc.user)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/risk-decisions"+tc.query, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(tc.user.Username, tc.password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+var (
+	errComplianceBlocked = errors.New("request was referred to compliance and can't be completed")
+	errNotCompliance     = errors.New("only staff can manage sanctions screening")
+)
+
+// ReloadSanctions reads the sanctions list from its file again. Screening
+// keeps using the entries loaded before if it fails.
+func (server *Server) ReloadSanctions() (int, error) {
+	return server.sanctions.Reload()
+}
+
+// sanctionsSubject is a user, or the holder of an account at another bank,
+// whose full name is screened.
+type sanctionsSubject struct {
+	// Username is empty for the holder of an account at another bank.
+	Username string
+	FullName string
+	// AccountID is the user's account taking part in a transfer.
+	AccountID sql.NullInt64
+}
+
+// screenSanctions matches the full names of subjects against the sanctions
+// list and opens a case for every match compliance hasn't cleared as a false
+// positive. It writes the error response and returns false when a match is
+// close enough to block the operation; flagged reports whether any subject
+// was flagged.
+func (server *Server) screenSanctions(c *gin.Context, operation string, initiatedBy string, subjects ...sanctionsSubject) (flagged bool, ok bool) {
+	flagged, blocked, err := server.matchSanctions(c, operation, initiatedBy, subjects...)
+	if err != nil {
+		writeError(c, err)
+		return false, false
+	}
+	if blocked != nil {
+		writeComplianceBlocked(c, *blocked)
+		return false, false
+	}
+	return flagged, true
+}
+
+// matchSanctions does the work of screenSanctions for callers that report a
+// block their own way. blocked is the case of the first match that blocks
+// the operation; subjects after it aren't screened.
+func (server *Server) matchSanctions(ctx context.Context, operation string, initiatedBy string, subjects ...sanctionsSubject) (flagged bool, blocked *db.SanctionsCase, err error) {
+	for _, subject := range subjects {
+		match, found := server.sanctions.Match(subject.FullName, server.config.SanctionsFlagThreshold)
+		if !found {
+			continue
+		}
+		cleared, err := server.store.CountClearedSanctionsMatches(ctx, db.CountClearedSanctionsMatchesParams{
+			ScreenedName: subject.FullName,
+			ListEntryID:  match.Entry.ID,
+		})
+		if err != nil {
+			return false, nil, err
+		}
+		if cleared > 0 {
+			continue
+		}
+
+		action := db.SanctionsFlag
+		if match.Score >= server.config.SanctionsBlockThreshold {
+			action = db.SanctionsBlock
+		}
+		sanctionsCase, err := server.store.CreateSanctionsCase(ctx, db.CreateSanctionsCaseParams{
+			Operation:        operation,
+			InitiatedBy:      initiatedBy,
+			ScreenedName:     subject.FullName,
+			ScreenedUsername: subject.Username,
+			AccountID:        subject.AccountID,
+			ListEntryID:      match.Entry.ID,
+			ListEntryName:    match.Entry.Name,
+			Score:            match.Score,
+			Action:           action,
+		})
+		if err != nil {
+			return false, nil, err
+		}
+		if action == db.SanctionsBlock {
+			return false, &sanctionsCase, nil
+		}
+		flagged = true
+	}
+	return flagged, nil, nil
+}
+
+func writeComplianceBlocked(c *gin.Context, sanctionsCase db.SanctionsCase) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   errComplianceBlocked.Error(),
+		"code":    codeComplianceBlocked,
+		"case_id": sanctionsCase.ID,
+	})
+}
+
+// screenTransferParties screens the sender of a transfer and the owner of
+// the account it goes to.
+func (server *Server) screenTransferParties(c *gin.Context, fromAccount db.Account, toAccount db.Account) (flagged bool, ok bool) {
+	if server.sanctions.Len() == 0 {
+		// nothing to screen against; don't load the recipient
+		return false, true
+	}
+	sender := authUser(c)
+	recipient, err := server.store.GetUsers(c, toAccount.Owner)
+	if err != nil {
+		writeError(c, err)
+		return false, false
+	}
+	return server.screenSanctions(c, db.SanctionsOperationTransfer, sender.Username,
+		sanctionsSubject{Username: sender.Username, FullName: sender.FullName, AccountID: sql.NullInt64{Int64: fromAccount.ID, Valid: true}},
+		sanctionsSubject{Username: recipient.Username, FullName: recipient.FullName, AccountID: sql.NullInt64{Int64: toAccount.ID, Valid: true}},
+	)
+}
+
+// screenBatchParties screens the sender of a batch once and the owner of
+// every account its items go to.
+func (server *Server) screenBatchParties(ctx context.Context, sender db.User, fromAccount db.Account, items []db.TransferBatchItemParams) (flagged bool, blocked *db.SanctionsCase, err error) {
+	if server.sanctions.Len() == 0 {
+		return false, nil, nil
+	}
+	subjects := []sanctionsSubject{
+		{Username: sender.Username, FullName: sender.FullName, AccountID: sql.NullInt64{Int64: fromAccount.ID, Valid: true}},
+	}
+	seen := make(map[int64]bool, len(items))
+	for _, item := range items {
+		if seen[item.ToAccountID] {
+			continue
+		}
+		seen[item.ToAccountID] = true
+		toAccount, err := server.store.GetAccounts(ctx, item.ToAccountID)
+		if err != nil {
+			return false, nil, err
+		}
+		recipient, err := server.store.GetUsers(ctx, toAccount.Owner)
+		if err != nil {
+			return false, nil, err
+		}
+		subjects = append(subjects, sanctionsSubject{
+			Username:  recipient.Username,
+			FullName:  recipient.FullName,
+			AccountID: sql.NullInt64{Int64: toAccount.ID, Valid: true},
+		})
+	}
+	return server.matchSanctions(ctx, db.SanctionsOperationTransfer, sender.Username, subjects...)
+}
+
+type reloadSanctionsResponse struct {
+	Entries  int       `json:"entries"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// reloadSanctions lets staff load a new version of the sanctions list
+// without restarting the server.
+func (server *Server) reloadSanctions(c *gin.Context) {
+	if authUser(c).Role != db.RoleStaff {
+		c.JSON(http.StatusForbidden, errorResponse(errNotCompliance))
+		return
+	}
+	entries, err := server.ReloadSanctions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "code": codeInternal})
+		return
+	}
+	c.JSON(http.StatusOK, reloadSanctionsResponse{Entries: entries, LoadedAt: server.sanctions.LoadedAt()})
+}
+
+type listSanctionsCasesRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=open cleared"`
+	PageID   int64  `form:"page_id" binding:"required,min=1"`
+	PageSize int64  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listSanctionsCases shows staff the cases in a status, open by default,
+// newest first.
+func (server *Server) listSanctionsCases(c *gin.Context) {
+	if authUser(c).Role != db.RoleStaff {
+		c.JSON(http.StatusForbidden, errorResponse(errNotCompliance))
+		return
+	}
+	var req listSanctionsCasesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.Status == "" {
+		req.Status = db.SanctionsCaseOpen
+	}
+
+	cases, err := server.store.ListSanctionsCases(c, db.ListSanctionsCasesParams{
+		Status: req.Status,
+		Limit:  int32(req.PageSize),
+		Offset: int32((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cases)
+}
+
+type sanctionsCaseURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type clearSanctionsCaseRequest struct {
+	Note string `json:"note" binding:"required,max=500"`
+}
+
+// clearSanctionsCase lets staff close an open case as a false positive. The
+// same name is no longer matched against the same list entry afterwards.
+func (server *Server) clearSanctionsCase(c *gin.Context) {
+	user := authUser(c)
+	if user.Role != db.RoleStaff {
+		c.JSON(http.StatusForbidden, errorResponse(errNotCompliance))
+		return
+	}
+	var uri sanctionsCaseURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req clearSanctionsCaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	sanctionsCase, err := server.store.ClearSanctionsCase(c, db.ClearSanctionsCaseParams{
+		ID:             uri.ID,
+		ResolvedBy:     user.Username,
+		ResolutionNote: req.Note,
+	})
+	if err != nil {
+		writeLookupError(c, "open sanctions case", err)
+		return
+	}
+	c.JSON(http.StatusOK, sanctionsCase)
+}
@@ -0,0 +1,703 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/sanctions"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// newSanctionsTestServer is newTestServer screening against the list at path.
+func newSanctionsTestServer(t *testing.T, store db.Store, path string) *Server {
+	server := newTestServer(t, store)
+	server.config.SanctionsFlagThreshold = 0.88
+	server.config.SanctionsBlockThreshold = 0.95
+	server.sanctions = sanctions.NewList(path)
+	_, err := server.ReloadSanctions()
+	require.NoError(t, err)
+	return server
+}
+
+const testSanctionsList = "../sanctions/testdata/list.csv"
+
+func TestCreateUserSanctions(t *testing.T) {
+	expectCase := func(store *mockdb.MockStore, fullName string, action string) {
+		store.EXPECT().
+			CountClearedSanctionsMatches(gomock.Any(), gomock.Eq(db.CountClearedSanctionsMatchesParams{ScreenedName: fullName, ListEntryID: "SDN-1001"})).
+			Times(1).
+			Return(int64(0), nil)
+		store.EXPECT().
+			CreateSanctionsCase(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ interface{}, arg db.CreateSanctionsCaseParams) (db.SanctionsCase, error) {
+				require.Equal(t, db.SanctionsOperationCreateUser, arg.Operation)
+				require.Equal(t, fullName, arg.ScreenedName)
+				require.Equal(t, arg.InitiatedBy, arg.ScreenedUsername)
+				require.Equal(t, "SDN-1001", arg.ListEntryID)
+				require.Equal(t, action, arg.Action)
+				return db.SanctionsCase{ID: 3, Action: arg.Action}, nil
+			})
+	}
+
+	testCases := []struct {
+		name       string
+		fullName   string
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name:     "NoMatch",
+			fullName: "Nguyen Thi Mai",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "Blocked",
+			fullName: "Petrov, Ivan",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectCase(store, "Petrov, Ivan", db.SanctionsBlock)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:     "Flagged",
+			fullName: "Evan Petrov",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectCase(store, "Evan Petrov", db.SanctionsFlag)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "ClearedFalsePositive",
+			fullName: "Ivan Petrov",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, nil)
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			server := newSanctionsTestServer(t, store, testSanctionsList)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"username":  utils.RandomOwner(),
+				"password":  "secret",
+				"full_name": tc.fullName,
+				"email":     utils.RandomEmail(),
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/users", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+			if tc.code == http.StatusForbidden {
+				require.Contains(t, recorder.Body.String(), codeComplianceBlocked)
+				require.NotContains(t, recorder.Body.String(), "Petrov")
+			}
+		})
+	}
+}
+
+func TestCreateTransferSanctions(t *testing.T) {
+	sender, password := randomUser(t)
+	sender.FullName = "Nguyen Thi Mai"
+	recipient, _ := randomUser(t)
+
+	fromAccount := randomAccount()
+	fromAccount.Owner = sender.Username
+	fromAccount.Currency = utils.USD
+	toAccount := randomAccount()
+	toAccount.Owner = recipient.Username
+	toAccount.Currency = utils.USD
+
+	testCases := []struct {
+		name          string
+		recipientName string
+		buildStubs    func(store *mockdb.MockStore)
+		code          int
+	}{
+		{
+			name:          "NoMatch",
+			recipientName: "Le Van Hai",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:          "RecipientBlocked",
+			recipientName: "Tran Van Duc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().
+					CreateSanctionsCase(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateSanctionsCaseParams) (db.SanctionsCase, error) {
+						require.Equal(t, db.SanctionsOperationTransfer, arg.Operation)
+						require.Equal(t, sender.Username, arg.InitiatedBy)
+						require.Equal(t, recipient.Username, arg.ScreenedUsername)
+						require.Equal(t, sql.NullInt64{Int64: toAccount.ID, Valid: true}, arg.AccountID)
+						require.Equal(t, db.SanctionsBlock, arg.Action)
+						return db.SanctionsCase{ID: 1, Action: arg.Action}, nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:          "RecipientFlagged",
+			recipientName: "Tran Minh Duc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{ID: 2}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.PendingTransfer{ID: 1}, nil)
+			},
+			code: http.StatusAccepted,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			recipient := recipient
+			recipient.FullName = tc.recipientName
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, sender)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+
+			server := newSanctionsTestServer(t, store, testSanctionsList)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(sender.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
+
+func TestCreateTransferConfirmationSanctions(t *testing.T) {
+	sender, password := randomUser(t)
+	sender.FullName = "Nguyen Thi Mai"
+	recipient, _ := randomUser(t)
+
+	fromAccount := randomAccount()
+	fromAccount.Owner = sender.Username
+	fromAccount.Currency = utils.USD
+	toAccount := randomAccount()
+	toAccount.Owner = recipient.Username
+	toAccount.Currency = utils.USD
+
+	testCases := []struct {
+		name          string
+		recipientName string
+		buildStubs    func(store *mockdb.MockStore)
+		code          int
+	}{
+		{
+			name:          "RecipientBlocked",
+			recipientName: "Tran Van Duc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{ID: 1}, nil)
+				store.EXPECT().CreateTransferConfirmation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			// the case is opened on the accounts, and confirming holds the
+			// transfer while it is open
+			name:          "RecipientFlagged",
+			recipientName: "Tran Minh Duc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().
+					CreateSanctionsCase(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateSanctionsCaseParams) (db.SanctionsCase, error) {
+						require.Equal(t, recipient.Username, arg.ScreenedUsername)
+						require.Equal(t, sql.NullInt64{Int64: toAccount.ID, Valid: true}, arg.AccountID)
+						require.Equal(t, db.SanctionsFlag, arg.Action)
+						return db.SanctionsCase{ID: 2, Action: arg.Action}, nil
+					})
+				store.EXPECT().GetFeeRule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeRule{}, sql.ErrNoRows)
+				store.EXPECT().CreateTransferConfirmation(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferConfirmation{ID: 1}, nil)
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			recipient := recipient
+			recipient.FullName = tc.recipientName
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, sender)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+			store.EXPECT().GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Any()).Times(1).Return(toAccount, nil)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+
+			server := newSanctionsTestServer(t, store, testSanctionsList)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_alias":        recipient.Username,
+				"amount":          10,
+				"currency":        utils.USD,
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfer-confirmations", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(sender.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
+
+func TestPayPaymentRequestSanctions(t *testing.T) {
+	payer, password := randomUser(t)
+	payer.FullName = "Nguyen Thi Mai"
+	requester, _ := randomUser(t)
+
+	fromAccount := randomAccount()
+	fromAccount.Owner = payer.Username
+	fromAccount.Currency = utils.USD
+	requesterAccount := randomAccount()
+	requesterAccount.Owner = requester.Username
+	requesterAccount.Currency = utils.USD
+	paymentRequest := db.PaymentRequest{
+		ID:                 utils.RandomInt(1, 1000),
+		RequesterAccountID: requesterAccount.ID,
+		Payer:              payer.Username,
+		Amount:             500,
+		Currency:           utils.USD,
+		Status:             db.PaymentRequestPending,
+		ExpiresAt:          time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		requesterName string
+		buildStubs    func(store *mockdb.MockStore)
+		code          int
+	}{
+		{
+			name:          "RequesterBlocked",
+			requesterName: "Tran Van Duc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{ID: 1}, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:          "RequesterFlagged",
+			requesterName: "Tran Minh Duc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{ID: 2}, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreatePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePendingTransferTxParams) (db.PendingTransfer, error) {
+						require.Equal(t, db.PendingTransferKindPaymentRequest, arg.Kind)
+						require.Equal(t, paymentRequest.ID, arg.PaymentRequestID.Int64)
+						return db.PendingTransfer{ID: 1}, nil
+					})
+			},
+			code: http.StatusAccepted,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			requester := requester
+			requester.FullName = tc.requesterName
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, payer)
+			store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(requesterAccount.ID)).Times(1).Return(requesterAccount, nil)
+			store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(requester.Username)).Times(1).Return(requester, nil)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+
+			server := newSanctionsTestServer(t, store, testSanctionsList)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"from_account_id": fromAccount.ID})
+			require.NoError(t, err)
+			url := fmt.Sprintf("/payment-requests/%d/pay", paymentRequest.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(payer.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
+
+func TestCreateScheduledTransferSanctions(t *testing.T) {
+	sender, password := randomUser(t)
+	sender.FullName = "Nguyen Thi Mai"
+	recipient, _ := randomUser(t)
+	recipient.FullName = "Tran Van Duc"
+
+	fromAccount := randomAccount()
+	fromAccount.Owner = sender.Username
+	fromAccount.Currency = utils.USD
+	toAccount := randomAccount()
+	toAccount.Owner = recipient.Username
+	toAccount.Currency = utils.USD
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectAuth(store, sender)
+	store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+	store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{ID: 1}, nil)
+	store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newSanctionsTestServer(t, store, testSanctionsList)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{
+		"from_account_id": fromAccount.ID,
+		"to_account_id":   toAccount.ID,
+		"amount":          10,
+		"currency":        utils.USD,
+		"execute_at":      time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/transfers/scheduled", bytes.NewReader(data))
+	require.NoError(t, err)
+	request.SetBasicAuth(sender.Username, password)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Contains(t, recorder.Body.String(), codeComplianceBlocked)
+}
+
+func TestCreateTransferBatchSanctions(t *testing.T) {
+	sender, password := randomUser(t)
+	sender.FullName = "Nguyen Thi Mai"
+	clean, _ := randomUser(t)
+	clean.FullName = "Le Van Hai"
+	creditor, _ := randomUser(t)
+
+	fromAccount := randomAccount()
+	fromAccount.Owner = sender.Username
+	fromAccount.Currency = utils.USD
+	fromAccount.Balance = 1000
+	cleanAccount := randomAccount()
+	cleanAccount.ID = fromAccount.ID + 1
+	cleanAccount.Owner = clean.Username
+	cleanAccount.Currency = utils.USD
+	creditorAccount := randomAccount()
+	creditorAccount.ID = fromAccount.ID + 2
+	creditorAccount.Owner = creditor.Username
+	creditorAccount.Currency = utils.USD
+
+	testCases := []struct {
+		name         string
+		creditorName string
+		buildStubs   func(store *mockdb.MockStore)
+		code         int
+	}{
+		{
+			name:         "CreditorBlocked",
+			creditorName: "Tran Van Duc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CreateSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{ID: 1}, nil)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:         "CreditorFlagged",
+			creditorName: "Tran Minh Duc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountClearedSanctionsMatches(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().
+					CreateSanctionsCase(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateSanctionsCaseParams) (db.SanctionsCase, error) {
+						require.Equal(t, creditor.Username, arg.ScreenedUsername)
+						require.Equal(t, sql.NullInt64{Int64: creditorAccount.ID, Valid: true}, arg.AccountID)
+						return db.SanctionsCase{ID: 2, Action: arg.Action}, nil
+					})
+				store.EXPECT().
+					CreateTransferBatchTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateTransferBatchTxParams) (db.TransferBatchResult, error) {
+						require.NotNil(t, arg.Hold)
+						return db.TransferBatchResult{PendingTransfer: &db.PendingTransfer{ID: 1}}, nil
+					})
+				store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusAccepted,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			creditor := creditor
+			creditor.FullName = tc.creditorName
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, sender)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			// once for every item and once more to screen the owner, who is
+			// screened once however many items go to them
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(cleanAccount.ID)).Times(3).Return(cleanAccount, nil)
+			store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(creditorAccount.ID)).Times(2).Return(creditorAccount, nil)
+			store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(clean.Username)).Times(1).Return(clean, nil)
+			store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(creditor.Username)).Times(1).Return(creditor, nil)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+
+			server := newSanctionsTestServer(t, store, testSanctionsList)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        utils.USD,
+				"mode":            db.TransferBatchAtomic,
+				"items": []gin.H{
+					{"to_account": fmt.Sprint(cleanAccount.ID), "amount": 100},
+					{"to_account": fmt.Sprint(creditorAccount.ID), "amount": 200},
+					{"to_account": fmt.Sprint(cleanAccount.ID), "amount": 300},
+				},
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfer-batches", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(sender.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
+
+func TestReloadSanctions(t *testing.T) {
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+	customer, customerPassword := randomUser(t)
+
+	path := filepath.Join(t.TempDir(), "list.csv")
+	require.NoError(t, os.WriteFile(path, []byte("id,name\nX-1,Ivan Petrov\n"), 0o600))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+	server := newSanctionsTestServer(t, store, path)
+	require.NoError(t, os.WriteFile(path, []byte("id,name\nX-1,Ivan Petrov\nX-2,Maria Garcia\n"), 0o600))
+
+	reload := func(user db.User, password string) *httptest.ResponseRecorder {
+		expectAuth(store, user)
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodPost, "/admin/sanctions/reload", nil)
+		require.NoError(t, err)
+		request.SetBasicAuth(user.Username, password)
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := reload(customer, customerPassword)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Equal(t, 1, server.sanctions.Len())
+
+	recorder = reload(staff, staffPassword)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), `"entries":2`)
+	require.Equal(t, 2, server.sanctions.Len())
+
+	require.NoError(t, os.Remove(path))
+	recorder = reload(staff, staffPassword)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	require.Equal(t, 2, server.sanctions.Len())
+}
+
+func TestClearSanctionsCase(t *testing.T) {
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+	customer, customerPassword := randomUser(t)
+	caseID := utils.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name       string
+		user       db.User
+		password   string
+		body       gin.H
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name:     "OK",
+			user:     staff,
+			password: staffPassword,
+			body:     gin.H{"note": "different date of birth"},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ClearSanctionsCaseParams{ID: caseID, ResolvedBy: staff.Username, ResolutionNote: "different date of birth"}
+				store.EXPECT().
+					ClearSanctionsCase(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.SanctionsCase{ID: caseID, Status: db.SanctionsCaseCleared}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "NotOpen",
+			user:     staff,
+			password: staffPassword,
+			body:     gin.H{"note": "again"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ClearSanctionsCase(gomock.Any(), gomock.Any()).Times(1).Return(db.SanctionsCase{}, sql.ErrNoRows)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name:     "MissingNote",
+			user:     staff,
+			password: staffPassword,
+			body:     gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ClearSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name:     "NotStaff",
+			user:     customer,
+			password: customerPassword,
+			body:     gin.H{"note": "it's me"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ClearSanctionsCase(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, tc.user)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			url := fmt.Sprintf("/admin/sanctions-cases/%d/clear", caseID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(tc.user.Username, tc.password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
+
+func TestListSanctionsCases(t *testing.T) {
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	expectAuth(store, staff)
+	arg := db.ListSanctionsCasesParams{Status: db.SanctionsCaseOpen, Limit: 5, Offset: 5}
+	store.EXPECT().
+		ListSanctionsCases(gomock.Any(), gomock.Eq(arg)).
+		Times(1).
+		Return([]db.SanctionsCase{{ID: 1, Status: db.SanctionsCaseOpen}}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/admin/sanctions-cases?page_id=2&page_size=5", nil)
+	require.NoError(t, err)
+	request.SetBasicAuth(staff.Username, staffPassword)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
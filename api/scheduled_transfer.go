@@ -0,0 +1,155 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+var errScheduledTransferNotCancellable = errors.New("scheduled transfer can no longer be cancelled")
+
+type createScheduledTransferRequest struct {
+	FromAccountID     int64     `json:"from_account_id" binding:"required_without=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string    `json:"from_account_number" binding:"required_without=FromAccountID,omitempty,account_number"`
+	ToAccountID       int64     `json:"to_account_id" binding:"required_without=ToAccountNumber,omitempty,min=1"`
+	ToAccountNumber   string    `json:"to_account_number" binding:"required_without=ToAccountID,omitempty,account_number"`
+	Amount            int64     `json:"amount" binding:"required,gt=0"`
+	Currency          string    `json:"currency" binding:"required,currency"`
+	ExecuteAt         time.Time `json:"execute_at" binding:"required"`
+}
+
+func (server *Server) createScheduledTransfer(c *gin.Context) {
+	var req createScheduledTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if !req.ExecuteAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, errorResponse(errors.New("execute_at must be in the future")))
+		return
+	}
+
+	fromAccount, valid := server.validSourceAccount(c, req.FromAccountID, req.FromAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	toAccount, valid := server.validAccount(c, req.ToAccountID, req.ToAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	// a review is left to the worker, which screens the transfer again and
+	// holds it when it is due; so is a sanctions flag, as the worker holds
+	// transfers between accounts with an open case
+	if _, ok := server.screenTransferParties(c, fromAccount, toAccount); !ok {
+		return
+	}
+	if _, ok := server.screenTransfer(c, fromAccount, toAccount.ID, req.Amount); !ok {
+		return
+	}
+
+	scheduled, err := server.store.CreateScheduledTransfer(c, db.CreateScheduledTransferParams{
+		Username:      authUser(c).Username,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		ExecuteAt:     req.ExecuteAt,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, scheduled)
+}
+
+type listScheduledTransfersRequest struct {
+	PageID   int64 `form:"page_id" binding:"required,min=1"`
+	PageSize int64 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listScheduledTransfers(c *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, err := server.store.ListScheduledTransfers(c, db.ListScheduledTransfersParams{
+		Username: authUser(c).Username,
+		Limit:    int32(req.PageSize),
+		Offset:   int32((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, scheduled)
+}
+
+type scheduledTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type scheduledTransferResponse struct {
+	db.ScheduledTransfer
+	Runs []db.ScheduledTransferRun `json:"runs"`
+}
+
+func (server *Server) getScheduledTransfer(c *gin.Context) {
+	var req scheduledTransferRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	scheduled, ok := server.ownScheduledTransfer(c, req.ID)
+	if !ok {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(c, scheduled.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, scheduledTransferResponse{ScheduledTransfer: scheduled, Runs: runs})
+}
+
+func (server *Server) cancelScheduledTransfer(c *gin.Context) {
+	var req scheduledTransferRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, ok := server.ownScheduledTransfer(c, req.ID); !ok {
+		return
+	}
+
+	scheduled, err := server.store.CancelScheduledTransfer(c, req.ID)
+	if err != nil {
+		if isNotFound(err) {
+			// already executed, failed or cancelled
+			c.JSON(http.StatusConflict, errorResponse(errScheduledTransferNotCancellable))
+			return
+		}
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// ownScheduledTransfer loads a scheduled transfer of the authenticated user.
+func (server *Server) ownScheduledTransfer(c *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	scheduled, err := server.store.GetScheduledTransfer(c, id)
+	if err == nil && scheduled.Username != authUser(c).Username {
+		// don't tell other users which ids exist
+		err = db.ErrRecordNotFound
+	}
+	if err != nil {
+		writeLookupError(c, "scheduled transfer", err)
+		return scheduled, false
+	}
+	return scheduled, true
+}
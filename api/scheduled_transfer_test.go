@@ -0,0 +1,228 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	user, password := randomUser(t)
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account1.Owner = user.Username
+	account1.Currency = utils.USD
+	account2.Currency = utils.USD
+	executeAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+				"execute_at":      executeAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.CreateScheduledTransferParams{
+					Username:      user.Username,
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        10,
+					Currency:      utils.USD,
+					ExecuteAt:     executeAt,
+				}
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BlockedByRiskEngine",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+				"execute_at":      executeAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					ListRiskRules(gomock.Any(), gomock.Eq(utils.USD)).
+					Times(1).
+					Return([]db.RiskRule{{Name: "new_recipient_usd", Kind: db.RiskRuleNewRecipient, Action: db.RiskBlock}}, nil)
+				store.EXPECT().CountTransfersTo(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), codeTransferBlocked)
+			},
+		},
+		{
+			name: "ExecuteAtInPast",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+				"execute_at":      time.Now().Add(-time.Minute),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			body: gin.H{
+				"from_account_id": account2.ID,
+				"to_account_id":   account1.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+				"execute_at":      executeAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfers/scheduled", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCancelScheduledTransfer(t *testing.T) {
+	user, password := randomUser(t)
+	scheduled := db.ScheduledTransfer{
+		ID:       utils.RandomInt(1, 1000),
+		Username: user.Username,
+		Amount:   10,
+		Currency: utils.USD,
+		Status:   db.ScheduledTransferScheduled,
+	}
+	otherUsers := scheduled
+	otherUsers.Username = utils.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				cancelled := scheduled
+				cancelled.Status = db.ScheduledTransferCancelled
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(cancelled, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), db.ScheduledTransferCancelled)
+			},
+		},
+		{
+			name: "AlreadyExecuted",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, nil)
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "OtherUsersTransfer",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(otherUsers, nil)
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/scheduled/%d/cancel", scheduled.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -5,23 +5,34 @@ import (
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/sanctions"
+	"tutorial.sqlc.dev/app/utils"
 )
 
 // Server will serve HTTP requests for banking service.
 type Server struct {
-	store  db.Store
-	router *gin.Engine
+	config    utils.Config
+	store     db.Store
+	sanctions *sanctions.List
+	router    *gin.Engine
 }
 
-// Function to create a new server with the given store
-func NewServer(store db.Store) *Server {
+// Function to create a new server with the given config and store
+func NewServer(config utils.Config, store db.Store) *Server {
 	server := &Server{
-		store: store}
+		config:    config,
+		store:     store,
+		sanctions: sanctions.NewList(config.SanctionsListPath),
+	}
 	router := gin.Default()
 
 	//binding validator
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrencies)
+		v.RegisterValidation("account_number", validAccountNumber)
+		v.RegisterValidation("account_ref", validAccountRef)
+		v.RegisterValidation("transfer_category", validTransferCategory)
+		v.RegisterValidation("routing_number", validRoutingNumber)
 	}
 	// Account routes
 	router.POST("/accounts", server.createAccount)
@@ -29,11 +40,67 @@ func NewServer(store db.Store) *Server {
 	router.GET("/accounts", server.getListAccount)
 	router.PUT("/accounts", server.updateAccount)
 	router.DELETE("/accounts/:id", server.deleteAccount)
-	//Transfer routes
-	router.POST("/transfer", server.createTransfer)
 	// User routes
 	router.POST("/users", server.createUser)
 
+	authRoutes := router.Group("/").Use(authMiddleware(server.store))
+	authRoutes.POST("/users/:username/verify-email", server.verifyUserEmail)
+	authRoutes.POST("/accounts/:id/freeze", server.freezeAccount)
+	authRoutes.POST("/accounts/:id/unfreeze", server.unfreezeAccount)
+	//Transfer routes
+	authRoutes.POST("/transfer", server.createTransfer)
+	authRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+	authRoutes.POST("/transfers/fee-quote", server.quoteTransferFee)
+	authRoutes.GET("/transfers/search", server.searchTransfers)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.POST("/transfers/scheduled", server.createScheduledTransfer)
+	authRoutes.GET("/transfers/scheduled", server.listScheduledTransfers)
+	authRoutes.GET("/transfers/scheduled/:id", server.getScheduledTransfer)
+	authRoutes.POST("/transfers/scheduled/:id/cancel", server.cancelScheduledTransfer)
+	authRoutes.POST("/transfer-confirmations", server.createTransferConfirmation)
+	authRoutes.POST("/transfer-confirmations/:id/confirm", server.confirmTransfer)
+	authRoutes.POST("/transfer-batches", server.createTransferBatch)
+	authRoutes.GET("/transfer-batches/:id", server.getTransferBatch)
+	// ISO 20022 routes
+	authRoutes.POST("/iso20022/pain.001", server.importPain001)
+	authRoutes.GET("/transfer-batches/:id/pain.002", server.getTransferBatchPain002)
+	authRoutes.GET("/accounts/:id/statements/camt.053", server.getCamt053Statement)
+	// Risk routes
+	authRoutes.GET("/risk-decisions", server.listRiskDecisions)
+	// Compliance routes
+	authRoutes.POST("/admin/sanctions/reload", server.reloadSanctions)
+	authRoutes.GET("/admin/sanctions-cases", server.listSanctionsCases)
+	authRoutes.POST("/admin/sanctions-cases/:id/clear", server.clearSanctionsCase)
+	// Maker-checker routes
+	authRoutes.GET("/pending-transfers", server.listPendingTransfers)
+	authRoutes.GET("/pending-transfers/:id", server.getPendingTransfer)
+	authRoutes.POST("/pending-transfers/:id/approve", server.approvePendingTransfer)
+	authRoutes.POST("/pending-transfers/:id/reject", server.rejectPendingTransfer)
+	// Payment request routes
+	authRoutes.POST("/payment-requests", server.createPaymentRequest)
+	authRoutes.GET("/payment-requests/incoming", server.listIncomingPaymentRequests)
+	authRoutes.GET("/payment-requests/outgoing", server.listOutgoingPaymentRequests)
+	authRoutes.POST("/payment-requests/:id/decline", server.declinePaymentRequest)
+	authRoutes.POST("/payment-requests/:id/pay", server.payPaymentRequest)
+	// Standing order routes
+	authRoutes.POST("/standing-orders", server.createStandingOrder)
+	authRoutes.GET("/standing-orders", server.listStandingOrders)
+	authRoutes.GET("/standing-orders/:id", server.getStandingOrder)
+	authRoutes.PUT("/standing-orders/:id", server.updateStandingOrder)
+	authRoutes.GET("/standing-orders/:id/occurrences", server.listStandingOrderOccurrences)
+	authRoutes.POST("/standing-orders/:id/pause", server.pauseStandingOrder)
+	authRoutes.POST("/standing-orders/:id/resume", server.resumeStandingOrder)
+	authRoutes.POST("/standing-orders/:id/cancel", server.cancelStandingOrder)
+	// Outbound payment routes
+	authRoutes.POST("/beneficiaries", server.createBeneficiary)
+	authRoutes.GET("/beneficiaries", server.listBeneficiaries)
+	authRoutes.POST("/outbound-transfers", server.createOutboundTransfer)
+	// FX routes
+	authRoutes.POST("/fx/quotes", server.createFxQuote)
+	// Operations routes
+	authRoutes.GET("/admin/metrics", server.getMetrics)
+	authRoutes.GET("/admin/reconciliation", server.getReconciliation)
+
 	server.router = router
 	return server
 }
@@ -0,0 +1,322 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/schedule"
+)
+
+const dateLayout = "2006-01-02"
+
+var errStandingOrderNotPausable = errors.New("only active standing orders can be paused")
+
+// standingOrderSchedule holds the fields of a standing order that can be edited.
+type standingOrderSchedule struct {
+	Amount         int64  `json:"amount" binding:"required,gt=0"`
+	Frequency      string `json:"frequency" binding:"required,oneof=weekly monthly last_business_day"`
+	Weekday        *int32 `json:"weekday" binding:"omitempty,min=0,max=6"`
+	DayOfMonth     int32  `json:"day_of_month" binding:"omitempty,min=1,max=31"`
+	EndDate        string `json:"end_date" binding:"omitempty,datetime=2006-01-02"`
+	MaxOccurrences int32  `json:"max_occurrences" binding:"omitempty,min=1"`
+}
+
+// changes validates the schedule and converts it to what the store expects.
+func (req standingOrderSchedule) changes() (db.StandingOrderChanges, error) {
+	changes := db.StandingOrderChanges{
+		Amount:         req.Amount,
+		Frequency:      req.Frequency,
+		DayOfMonth:     sql.NullInt32{Int32: req.DayOfMonth, Valid: req.DayOfMonth != 0},
+		MaxOccurrences: sql.NullInt32{Int32: req.MaxOccurrences, Valid: req.MaxOccurrences != 0},
+	}
+	if req.Weekday != nil {
+		changes.Weekday = sql.NullInt32{Int32: *req.Weekday, Valid: true}
+	}
+	if req.EndDate != "" {
+		endDate, _ := time.Parse(dateLayout, req.EndDate)
+		changes.EndDate = sql.NullTime{Time: endDate, Valid: true}
+	}
+	if req.Frequency == string(schedule.Weekly) && req.Weekday == nil {
+		return changes, errors.New("weekday is required for weekly standing orders")
+	}
+	rule := db.StandingOrder{
+		Frequency:  changes.Frequency,
+		Weekday:    changes.Weekday,
+		DayOfMonth: changes.DayOfMonth,
+	}.Rule()
+	return changes, rule.Validate()
+}
+
+type createStandingOrderRequest struct {
+	FromAccountID     int64  `json:"from_account_id" binding:"required_without=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string `json:"from_account_number" binding:"required_without=FromAccountID,omitempty,account_number"`
+	ToAccountID       int64  `json:"to_account_id" binding:"required_without=ToAccountNumber,omitempty,min=1"`
+	ToAccountNumber   string `json:"to_account_number" binding:"required_without=ToAccountID,omitempty,account_number"`
+	Currency          string `json:"currency" binding:"required,currency"`
+	StartDate         string `json:"start_date" binding:"required,datetime=2006-01-02"`
+	standingOrderSchedule
+}
+
+func (server *Server) createStandingOrder(c *gin.Context) {
+	var req createStandingOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	changes, err := req.changes()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	startDate, _ := time.Parse(dateLayout, req.StartDate)
+	today := schedule.Date(time.Now())
+	if startDate.Before(today) {
+		c.JSON(http.StatusBadRequest, errorResponse(errors.New("start_date can't be in the past")))
+		return
+	}
+
+	fromAccount, valid := server.validSourceAccount(c, req.FromAccountID, req.FromAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	toAccount, valid := server.validAccount(c, req.ToAccountID, req.ToAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	// every occurrence is screened again when the worker runs it, and held
+	// on review or while a sanctions case flagged here is open
+	if _, ok := server.screenTransferParties(c, fromAccount, toAccount); !ok {
+		return
+	}
+	if _, ok := server.screenTransfer(c, fromAccount, toAccount.ID, changes.Amount); !ok {
+		return
+	}
+
+	calendar, err := db.LoadCalendar(c, server.store, startDate)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	order := db.StandingOrder{
+		Frequency:      changes.Frequency,
+		Weekday:        changes.Weekday,
+		DayOfMonth:     changes.DayOfMonth,
+		StartDate:      startDate,
+		EndDate:        changes.EndDate,
+		MaxOccurrences: changes.MaxOccurrences,
+	}
+	order.ScheduleFrom(startDate, calendar)
+	if order.Status == db.StandingOrderCompleted {
+		c.JSON(http.StatusBadRequest, errorResponse(errors.New("end_date is before the first occurrence")))
+		return
+	}
+
+	order, err = server.store.CreateStandingOrder(c, db.CreateStandingOrderParams{
+		Username:        authUser(c).Username,
+		FromAccountID:   fromAccount.ID,
+		ToAccountID:     toAccount.ID,
+		Amount:          changes.Amount,
+		Currency:        req.Currency,
+		Frequency:       changes.Frequency,
+		Weekday:         changes.Weekday,
+		DayOfMonth:      changes.DayOfMonth,
+		StartDate:       startDate,
+		EndDate:         changes.EndDate,
+		MaxOccurrences:  changes.MaxOccurrences,
+		NextNominalDate: order.NextNominalDate,
+		NextRunDate:     order.NextRunDate,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+type listStandingOrdersRequest struct {
+	PageID   int64 `form:"page_id" binding:"required,min=1"`
+	PageSize int64 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listStandingOrders(c *gin.Context) {
+	var req listStandingOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	orders, err := server.store.ListStandingOrders(c, db.ListStandingOrdersParams{
+		Username: authUser(c).Username,
+		Limit:    int32(req.PageSize),
+		Offset:   int32((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+type standingOrderRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getStandingOrder(c *gin.Context) {
+	var req standingOrderRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	order, ok := server.ownStandingOrder(c, req.ID)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// listStandingOrderOccurrences returns the scheduled transfers an order has
+// produced, newest first, along with their outcome.
+func (server *Server) listStandingOrderOccurrences(c *gin.Context) {
+	var uri standingOrderRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req listStandingOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, ok := server.ownStandingOrder(c, uri.ID); !ok {
+		return
+	}
+
+	occurrences, err := server.store.ListStandingOrderOccurrences(c, db.ListStandingOrderOccurrencesParams{
+		StandingOrderID: sql.NullInt64{Int64: uri.ID, Valid: true},
+		Limit:           int32(req.PageSize),
+		Offset:          int32((req.PageID - 1) * req.PageSize),
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, occurrences)
+}
+
+func (server *Server) updateStandingOrder(c *gin.Context) {
+	var uri standingOrderRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req standingOrderSchedule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	changes, err := req.changes()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, ok := server.ownStandingOrder(c, uri.ID); !ok {
+		return
+	}
+
+	server.rescheduleStandingOrder(c, db.UpdateStandingOrderTxParams{
+		ID:      uri.ID,
+		Changes: &changes,
+		Today:   time.Now(),
+	})
+}
+
+func (server *Server) resumeStandingOrder(c *gin.Context) {
+	var req standingOrderRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, ok := server.ownStandingOrder(c, req.ID); !ok {
+		return
+	}
+
+	server.rescheduleStandingOrder(c, db.UpdateStandingOrderTxParams{
+		ID:     req.ID,
+		Resume: true,
+		Today:  time.Now(),
+	})
+}
+
+func (server *Server) rescheduleStandingOrder(c *gin.Context, arg db.UpdateStandingOrderTxParams) {
+	order, err := server.store.UpdateStandingOrderTx(c, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrStandingOrderClosed) {
+			c.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+func (server *Server) pauseStandingOrder(c *gin.Context) {
+	var req standingOrderRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, ok := server.ownStandingOrder(c, req.ID); !ok {
+		return
+	}
+
+	order, err := server.store.PauseStandingOrder(c, req.ID)
+	if err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusConflict, errorResponse(errStandingOrderNotPausable))
+			return
+		}
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+func (server *Server) cancelStandingOrder(c *gin.Context) {
+	var req standingOrderRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, ok := server.ownStandingOrder(c, req.ID); !ok {
+		return
+	}
+
+	order, err := server.store.CancelStandingOrder(c, req.ID)
+	if err != nil {
+		if isNotFound(err) {
+			c.JSON(http.StatusConflict, errorResponse(db.ErrStandingOrderClosed))
+			return
+		}
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+// ownStandingOrder loads a standing order of the authenticated user.
+func (server *Server) ownStandingOrder(c *gin.Context, id int64) (db.StandingOrder, bool) {
+	order, err := server.store.GetStandingOrder(c, id)
+	if err == nil && order.Username != authUser(c).Username {
+		err = db.ErrRecordNotFound
+	}
+	if err != nil {
+		writeLookupError(c, "standing order", err)
+		return order, false
+	}
+	return order, true
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/schedule"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestCreateStandingOrder(t *testing.T) {
+	user, password := randomUser(t)
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account1.Owner = user.Username
+	account1.Currency = utils.USD
+	account2.Currency = utils.USD
+	startDate := schedule.Date(time.Now()).AddDate(0, 0, 1)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+				"frequency":       "weekly",
+				"weekday":         0,
+				"start_date":      startDate.Format(dateLayout),
+				"max_occurrences": 12,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().ListHolidays(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().
+					CreateStandingOrder(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateStandingOrderParams) (db.StandingOrder, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, "weekly", arg.Frequency)
+						require.Equal(t, sql.NullInt32{Int32: 0, Valid: true}, arg.Weekday)
+						require.Equal(t, sql.NullInt32{Int32: 12, Valid: true}, arg.MaxOccurrences)
+						require.Equal(t, startDate, arg.StartDate)
+						// a Sunday, moved onto the next business day
+						require.Equal(t, time.Sunday, arg.NextNominalDate.Weekday())
+						require.Equal(t, arg.NextNominalDate.AddDate(0, 0, 1), arg.NextRunDate)
+						return db.StandingOrder{ID: 1}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WeeklyWithoutWeekday",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+				"frequency":       "weekly",
+				"start_date":      startDate.Format(dateLayout),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidFrequency",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+				"frequency":       "daily",
+				"start_date":      startDate.Format(dateLayout),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "StartDateInPast",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+				"frequency":       "last_business_day",
+				"start_date":      startDate.AddDate(0, 0, -7).Format(dateLayout),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/standing-orders", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestStandingOrderActions(t *testing.T) {
+	user, password := randomUser(t)
+	order := db.StandingOrder{
+		ID:        utils.RandomInt(1, 1000),
+		Username:  user.Username,
+		Amount:    10,
+		Currency:  utils.USD,
+		Frequency: string(schedule.LastBusinessDay),
+		Status:    db.StandingOrderPaused,
+	}
+
+	testCases := []struct {
+		name          string
+		action        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Resume",
+			action: "resume",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().
+					UpdateStandingOrderTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpdateStandingOrderTxParams) (db.StandingOrder, error) {
+						require.Equal(t, order.ID, arg.ID)
+						require.True(t, arg.Resume)
+						require.Nil(t, arg.Changes)
+						return order, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ResumeCancelled",
+			action: "resume",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().UpdateStandingOrderTx(gomock.Any(), gomock.Any()).Times(1).Return(db.StandingOrder{}, db.ErrStandingOrderClosed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "PauseNotActive",
+			action: "pause",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().PauseStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(db.StandingOrder{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "Cancel",
+			action: "cancel",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().CancelStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "OtherUsersOrder",
+			action: "cancel",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				other := order
+				other.Username = utils.RandomOwner()
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(other, nil)
+				store.EXPECT().CancelStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/standing-orders/%d/%s", order.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
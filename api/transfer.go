@@ -2,6 +2,7 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -9,11 +10,50 @@ import (
 	db "tutorial.sqlc.dev/app/db/sqlc"
 )
 
+var (
+	errTransferReferenceUsed = errors.New("reference is already used by another transfer from this account")
+	errFxTransferAsync       = errors.New("cross-currency transfers can't be queued")
+)
+
 type transferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID     int64  `json:"from_account_id" binding:"required_without=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string `json:"from_account_number" binding:"required_without=FromAccountID,omitempty,account_number"`
+	ToAccountID       int64  `json:"to_account_id" binding:"required_without=ToAccountNumber,omitempty,min=1"`
+	ToAccountNumber   string `json:"to_account_number" binding:"required_without=ToAccountID,omitempty,account_number"`
+	Amount            int64  `json:"amount" binding:"required,gt=0"`
+	Currency          string `json:"currency" binding:"required,currency"`
+	// FxQuoteID turns the request into a cross-currency transfer; Currency is
+	// then the currency of the source account.
+	FxQuoteID int64  `json:"fx_quote_id,omitempty" binding:"omitempty,min=1"`
+	Memo      string `json:"memo,omitempty" binding:"max=500"`
+	// Reference must be unique among the transfers from the source account.
+	Reference string `json:"reference,omitempty" binding:"max=64"`
+	Category  string `json:"category,omitempty" binding:"omitempty,transfer_category"`
+	// Async queues the transfer and answers 202 right away; a worker
+	// settles it later. Poll GET /transfers/:id for the outcome.
+	Async bool `json:"async,omitempty"`
+}
+
+func (req transferRequest) details() db.TransferDetails {
+	return db.TransferDetails{
+		Memo:      req.Memo,
+		Reference: req.Reference,
+		Category:  req.Category,
+	}
+}
+
+// pendingTransfer is what holding the transfer from fromAccount to toAccount
+// records.
+func (req transferRequest) pendingTransfer(fromAccount db.Account, toAccount db.Account) db.CreatePendingTransferParams {
+	return db.CreatePendingTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   sql.NullInt64{Int64: toAccount.ID, Valid: true},
+		Amount:        req.Amount,
+		Currency:      fromAccount.Currency,
+		Memo:          req.Memo,
+		Reference:     sql.NullString{String: req.Reference, Valid: req.Reference != ""},
+		Category:      req.Category,
+	}
 }
 
 func (server *Server) createTransfer(c *gin.Context) {
@@ -22,44 +62,199 @@ func (server *Server) createTransfer(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	if !server.validAccount(c, req.FromAccountID, req.Currency) {
+	idempotencyKey, ok := server.idempotencyKey(c, req)
+	if !ok {
 		return
 	}
-	if !server.validAccount(c, req.ToAccountID, req.Currency) {
+	if idempotencyKey != nil && server.replayIdempotentResponse(c, idempotencyKey) {
+		return
+	}
+
+	fromAccount, valid := server.validSourceAccount(c, req.FromAccountID, req.FromAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	needsApproval := server.config.NeedsApproval(req.Currency, req.Amount)
+	if req.FxQuoteID != 0 {
+		if req.Async {
+			c.JSON(http.StatusBadRequest, errorResponse(errFxTransferAsync))
+			return
+		}
+		server.createFxTransfer(c, req, fromAccount, needsApproval, idempotencyKey)
+		return
+	}
+	toAccount, valid := server.validAccount(c, req.ToAccountID, req.ToAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	flagged, ok := server.screenTransferParties(c, fromAccount, toAccount)
+	if !ok {
+		return
+	}
+	decision, ok := server.screenTransfer(c, fromAccount, toAccount.ID, req.Amount)
+	if !ok {
+		return
+	}
+	if needsApproval || flagged || decision.Decision == db.RiskReview {
+		arg := req.pendingTransfer(fromAccount, toAccount)
+		arg.RiskDecisionID = reviewDecisionID(decision)
+		server.holdTransfer(c, arg, idempotencyKey)
 		return
 	}
 	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+		FromAccountID:   fromAccount.ID,
+		ToAccountID:     toAccount.ID,
+		Amount:          req.Amount,
+		TransferDetails: req.details(),
+		IdempotencyKey:  idempotencyKey,
+	}
+	if req.Async {
+		server.queueTransfer(c, arg)
+		return
 	}
 	result, err := server.store.TransferTx(c, arg)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+		server.transferError(c, err, idempotencyKey)
 		return
 	}
 	c.JSON(http.StatusOK, result)
 }
 
-func (server *Server) validAccount(c *gin.Context, accountID int64, currency string) bool {
-	account, err := server.store.GetAccounts(c, accountID)
+// queueTransfer hands the transfer to the transfer worker and answers 202
+// with the pending transfer.
+func (server *Server) queueTransfer(c *gin.Context, arg db.TransferTxParams) {
+	if arg.IdempotencyKey != nil {
+		arg.IdempotencyKey.ResponseStatus = http.StatusAccepted
+	}
+	transfer, err := server.store.QueueTransferTx(c, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, errorResponse(err))
-			return false
+		server.transferError(c, err, arg.IdempotencyKey)
+		return
+	}
+	c.JSON(http.StatusAccepted, transfer)
+}
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getTransfer returns a transfer with its status. Staff can see any
+// transfer; other users only those into or out of their accounts.
+func (server *Server) getTransfer(c *gin.Context) {
+	var req getTransferRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(c, req.ID)
+	if err == nil {
+		var allowed bool
+		allowed, err = server.isTransferParty(c, transfer)
+		if err == nil && !allowed {
+			// don't tell other users which ids exist
+			err = db.ErrRecordNotFound
 		}
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+	if err != nil {
+		writeLookupError(c, "transfer", err)
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
+
+// isTransferParty reports whether the authenticated user is staff or owns
+// one of the accounts of transfer.
+func (server *Server) isTransferParty(c *gin.Context, transfer db.Transfer) (bool, error) {
+	user := authUser(c)
+	if user.Role == db.RoleStaff {
+		return true, nil
+	}
+	for _, accountID := range []sql.NullInt64{transfer.FromAccountID, transfer.ToAccountID} {
+		if !accountID.Valid {
+			continue
+		}
+		account, err := server.store.GetAccounts(c, accountID.Int64)
+		if err != nil {
+			return false, err
+		}
+		if account.Owner == user.Username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// transferError writes the response for an error returned by one of the
+// transfer transactions.
+func (server *Server) transferError(c *gin.Context, err error, idempotencyKey *db.IdempotencyKeyParams) {
+	if errors.Is(err, db.ErrIdempotencyKeyInUse) {
+		// a concurrent request with the same key committed first
+		if !server.replayIdempotentResponse(c, idempotencyKey) {
+			writeError(c, err)
+		}
+		return
+	}
+	if errors.Is(db.TranslateError(err), db.ErrUniqueViolation) {
+		c.JSON(http.StatusConflict, gin.H{"error": errTransferReferenceUsed.Error(), "code": codeAlreadyExists})
+		return
+	}
+	writeError(c, err)
+}
+
+func limitErrorResponse(err *db.LimitExceededError) gin.H {
+	return gin.H{
+		"error":     err.Error(),
+		"code":      codeLimitExceeded,
+		"limit":     err.Limit,
+		"currency":  err.Currency,
+		"max":       err.Max,
+		"available": err.Available,
+	}
+}
+
+// validSourceAccount is validAccount for the account money is sent from,
+// which must also belong to the authenticated user.
+func (server *Server) validSourceAccount(c *gin.Context, accountID int64, accountNumber string, currency string) (db.Account, bool) {
+	account, valid := server.validAccount(c, accountID, accountNumber, currency)
+	if !valid {
+		return account, false
+	}
+	if account.Owner != authUser(c).Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		c.JSON(http.StatusForbidden, errorResponse(err))
+		return account, false
+	}
+	return account, true
+}
+
+// validAccount loads the account by id, or by account number when one is
+// given, and checks that it can take part in a transfer in currency.
+func (server *Server) validAccount(c *gin.Context, accountID int64, accountNumber string, currency string) (db.Account, bool) {
+	var account db.Account
+	var err error
+	if accountNumber != "" {
+		account, err = server.getAccountByRef(c, accountNumber)
+	} else {
+		account, err = server.store.GetAccounts(c, accountID)
+	}
+	if err != nil {
+		writeLookupError(c, "account", err)
+		return account, false
 	}
 	if account.Currency != currency {
-		err := fmt.Errorf("account %d currency mismatch: %s vs %s", accountID, account.Currency, currency)
-		c.JSON(http.StatusBadRequest, errorResponse(err))
-		return false
+		err := fmt.Errorf("account %d holds %s, not %s: %w", account.ID, account.Currency, currency, db.ErrCurrencyMismatch)
+		writeError(c, err)
+		return account, false
+	}
+	if account.Frozen {
+		writeError(c, fmt.Errorf("account %d: %w", account.ID, db.ErrAccountFrozen))
+		return account, false
 	}
 	if account.Balance < 0 {
-		err := fmt.Errorf("account %d has insufficient funds", accountID)
-		c.JSON(http.StatusBadRequest, errorResponse(err))
-		return false
+		writeError(c, fmt.Errorf("account %d: %w", account.ID, db.ErrInsufficientFunds))
+		return account, false
 	}
 
-	return true
+	return account, true
 }
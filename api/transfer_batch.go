@@ -0,0 +1,315 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+const (
+	maxTransferBatchItems = 1000
+	maxReferenceLength    = 140
+)
+
+type createTransferBatchRequest struct {
+	FromAccountID     int64                      `json:"from_account_id" form:"from_account_id" binding:"required_without=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string                     `json:"from_account_number" form:"from_account_number" binding:"required_without=FromAccountID,omitempty,account_number"`
+	Currency          string                     `json:"currency" form:"currency" binding:"required,currency"`
+	Mode              string                     `json:"mode" form:"mode" binding:"required,oneof=atomic per_item"`
+	Items             []transferBatchItemRequest `json:"items" form:"-"`
+}
+
+// transferBatchItemRequest is one row of a batch. ToAccount is an account id
+// or an account number.
+type transferBatchItemRequest struct {
+	ToAccount string `json:"to_account"`
+	Amount    int64  `json:"amount"`
+	Reference string `json:"reference"`
+	// Line is set for items read from CSV; JSON items are numbered by position.
+	Line int `json:"-"`
+}
+
+// batchLineError reports why a line of a batch can't be run. Lines start at 1
+// and don't count the CSV header.
+type batchLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// createTransferBatch accepts a batch of transfers from one account, either
+// as JSON or as a CSV file (columns to_account, amount, reference) uploaded
+// in the "file" field of a multipart form. The whole batch is validated
+// before anything runs, and every item goes through sanctions screening and
+// the risk engine. A batch above the approval threshold, with a party
+// sanctions screening flagged or with an item the risk engine wants
+// reviewed, is held until another user approves it.
+func (server *Server) createTransferBatch(c *gin.Context) {
+	var req createTransferBatchRequest
+	isUpload := strings.HasPrefix(c.ContentType(), "multipart/")
+	var err error
+	if isUpload {
+		err = c.ShouldBindWith(&req, binding.FormMultipart)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var lineErrors []batchLineError
+	if isUpload {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		defer f.Close()
+		req.Items, lineErrors, err = parseBatchCSV(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+	if len(req.Items) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(errors.New("batch has no items")))
+		return
+	}
+	if len(req.Items) > maxTransferBatchItems {
+		err := fmt.Errorf("batch has %d items, at most %d are allowed", len(req.Items), maxTransferBatchItems)
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validSourceAccount(c, req.FromAccountID, req.FromAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+	items, itemErrors, err := server.validateBatchItems(c, fromAccount, req.Items)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	lineErrors = append(lineErrors, itemErrors...)
+	if len(lineErrors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "batch has invalid lines",
+			"lines": lineErrors,
+		})
+		return
+	}
+
+	user := authUser(c)
+	flagged, blocked, err := server.screenBatchParties(c, user, fromAccount, items)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if blocked != nil {
+		writeComplianceBlocked(c, *blocked)
+		return
+	}
+	decision, err := server.screenBatch(c, fromAccount, items)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if decision.Decision == db.RiskBlock {
+		writeTransferBlocked(c, decision)
+		return
+	}
+
+	arg := db.CreateTransferBatchTxParams{
+		Username:      user.Username,
+		FromAccountID: fromAccount.ID,
+		Currency:      req.Currency,
+		Mode:          req.Mode,
+		Items:         items,
+	}
+	if server.batchNeedsApproval(req.Currency, items) || flagged || decision.Decision == db.RiskReview {
+		arg.Hold = server.pendingHold(reviewDecisionID(decision))
+	}
+	batch, err := server.store.CreateTransferBatchTx(c, arg)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if batch.PendingTransfer != nil {
+		// approving the pending transfer runs the batch
+		c.JSON(http.StatusAccepted, batch)
+		return
+	}
+	// finish the batch even if the client goes away
+	result, err := server.store.ExecuteTransferBatch(context.WithoutCancel(c.Request.Context()), batch.Batch.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// batchNeedsApproval reports whether a batch has to be approved as a whole
+// before it runs. Its items are positive, so the total is above the approval
+// threshold whenever one of them is.
+func (server *Server) batchNeedsApproval(currency string, items []db.TransferBatchItemParams) bool {
+	var total int64
+	for _, item := range items {
+		total += item.Amount
+	}
+	return server.config.NeedsApproval(currency, total)
+}
+
+// parseBatchCSV reads batch items from a CSV file with a header row. Lines
+// that can't be parsed are reported in the returned line errors.
+func parseBatchCSV(r io.Reader) ([]transferBatchItemRequest, []batchLineError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"to_account", "amount"} {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("csv header has no %s column", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var items []transferBatchItemRequest
+	var lineErrors []batchLineError
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read csv line %d: %w", line, err)
+		}
+		item := transferBatchItemRequest{
+			ToAccount: field(record, "to_account"),
+			Reference: field(record, "reference"),
+			Line:      line,
+		}
+		if amount := field(record, "amount"); amount != "" {
+			item.Amount, err = strconv.ParseInt(amount, 10, 64)
+			if err != nil {
+				lineErrors = append(lineErrors, batchLineError{Line: line, Error: "amount is not a whole number"})
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+	return items, lineErrors, nil
+}
+
+// validateBatchItems checks every item and resolves its destination account.
+func (server *Server) validateBatchItems(ctx context.Context, fromAccount db.Account, reqItems []transferBatchItemRequest) ([]db.TransferBatchItemParams, []batchLineError, error) {
+	items := make([]db.TransferBatchItemParams, 0, len(reqItems))
+	var lineErrors []batchLineError
+	var total int64
+	for i, reqItem := range reqItems {
+		line := reqItem.Line
+		if line == 0 {
+			line = i + 1
+		}
+		fail := func(format string, args ...interface{}) {
+			lineErrors = append(lineErrors, batchLineError{Line: line, Error: fmt.Sprintf(format, args...)})
+		}
+
+		if reqItem.Amount <= 0 {
+			fail("amount must be positive")
+			continue
+		}
+		if len(reqItem.Reference) > maxReferenceLength {
+			fail("reference is longer than %d characters", maxReferenceLength)
+			continue
+		}
+		ref := utils.NormalizeAccountNumber(reqItem.ToAccount)
+		if !isAccountRef(ref) {
+			fail("to_account %q is not an account id or a valid account number", reqItem.ToAccount)
+			continue
+		}
+		toAccount, err := server.getAccountByRef(ctx, ref)
+		if isNotFound(err) {
+			fail("account %s not found", reqItem.ToAccount)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if toAccount.ID == fromAccount.ID {
+			fail("can't transfer to the source account")
+			continue
+		}
+		if toAccount.Currency != fromAccount.Currency {
+			fail("account %s currency mismatch: %s vs %s", reqItem.ToAccount, toAccount.Currency, fromAccount.Currency)
+			continue
+		}
+
+		total += reqItem.Amount
+		items = append(items, db.TransferBatchItemParams{
+			Line:        int32(line),
+			ToAccountID: toAccount.ID,
+			Amount:      reqItem.Amount,
+			Reference:   reqItem.Reference,
+		})
+	}
+	if len(lineErrors) == 0 && total > fromAccount.Balance {
+		lineErrors = append(lineErrors, batchLineError{
+			Error: fmt.Sprintf("batch total %d is more than the balance %d", total, fromAccount.Balance),
+		})
+	}
+	return items, lineErrors, nil
+}
+
+type transferBatchRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getTransferBatch(c *gin.Context) {
+	var req transferBatchRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	batch, err := server.store.GetTransferBatch(c, req.ID)
+	if err == nil && batch.Username != authUser(c).Username {
+		err = db.ErrRecordNotFound
+	}
+	if err != nil {
+		writeLookupError(c, "transfer batch", err)
+		return
+	}
+	items, err := server.store.ListTransferBatchItems(c, batch.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, db.TransferBatchResult{Batch: batch, Items: items})
+}
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestCreateTransferBatch(t *testing.T) {
+	user, password := randomUser(t)
+	fromAccount := randomAccount()
+	fromAccount.Owner = user.Username
+	fromAccount.Currency = utils.USD
+	fromAccount.Balance = 1000
+	richAccount := fromAccount
+	richAccount.Balance = 50000
+	toAccount1 := randomAccount()
+	toAccount1.ID = fromAccount.ID + 1
+	toAccount1.Currency = utils.USD
+	toAccount2 := randomAccount()
+	toAccount2.ID = fromAccount.ID + 2
+	toAccount2.Currency = utils.USD
+
+	jsonRequest := func(body gin.H) func(t *testing.T) *http.Request {
+		return func(t *testing.T) *http.Request {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfer-batches", bytes.NewReader(data))
+			require.NoError(t, err)
+			return request
+		}
+	}
+	csvRequest := func(mode string, csv string) func(t *testing.T) *http.Request {
+		return func(t *testing.T) *http.Request {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			require.NoError(t, writer.WriteField("from_account_id", fmt.Sprint(fromAccount.ID)))
+			require.NoError(t, writer.WriteField("currency", utils.USD))
+			require.NoError(t, writer.WriteField("mode", mode))
+			part, err := writer.CreateFormFile("file", "payroll.csv")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(csv))
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer-batches", &body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+			return request
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		newRequest    func(t *testing.T) *http.Request
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OKJSON",
+			newRequest: jsonRequest(gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        utils.USD,
+				"mode":            db.TransferBatchAtomic,
+				"items": []gin.H{
+					{"to_account": fmt.Sprint(toAccount1.ID), "amount": 100, "reference": "salary"},
+					{"to_account": toAccount2.AccountNumber, "amount": 200},
+				},
+			}),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(1).Return(toAccount1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(toAccount2.AccountNumber)).Times(1).Return(toAccount2, nil)
+
+				arg := db.CreateTransferBatchTxParams{
+					Username:      user.Username,
+					FromAccountID: fromAccount.ID,
+					Currency:      utils.USD,
+					Mode:          db.TransferBatchAtomic,
+					Items: []db.TransferBatchItemParams{
+						{Line: 1, ToAccountID: toAccount1.ID, Amount: 100, Reference: "salary"},
+						{Line: 2, ToAccountID: toAccount2.ID, Amount: 200},
+					},
+				}
+				batch := db.TransferBatch{ID: 7}
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferBatchResult{Batch: batch}, nil)
+				store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OKCSV",
+			newRequest: csvRequest(db.TransferBatchPerItem,
+				"to_account,amount,reference\n"+
+					fmt.Sprintf("%d,100,salary\n", toAccount1.ID)),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(1).Return(toAccount1, nil)
+				store.EXPECT().
+					CreateTransferBatchTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateTransferBatchTxParams) (db.TransferBatchResult, error) {
+						require.Equal(t, db.TransferBatchPerItem, arg.Mode)
+						require.Equal(t, []db.TransferBatchItemParams{{Line: 1, ToAccountID: toAccount1.ID, Amount: 100, Reference: "salary"}}, arg.Items)
+						return db.TransferBatchResult{Batch: db.TransferBatch{ID: 7}}, nil
+					})
+				store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "AboveApprovalThreshold",
+			newRequest: jsonRequest(gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        utils.USD,
+				"mode":            db.TransferBatchPerItem,
+				"items": []gin.H{
+					{"to_account": fmt.Sprint(toAccount1.ID), "amount": 6000},
+					{"to_account": fmt.Sprint(toAccount1.ID), "amount": 6000},
+				},
+			}),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(richAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(2).Return(toAccount1, nil)
+				store.EXPECT().
+					CreateTransferBatchTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateTransferBatchTxParams) (db.TransferBatchResult, error) {
+						// each item is below the threshold, the batch isn't
+						require.NotNil(t, arg.Hold)
+						return db.TransferBatchResult{
+							Batch:           db.TransferBatch{ID: 7, Status: db.TransferBatchHeld},
+							PendingTransfer: &db.PendingTransfer{ID: 3, Kind: db.PendingTransferKindTransferBatch},
+						}, nil
+					})
+				store.EXPECT().ExecuteTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Contains(t, recorder.Body.String(), db.TransferBatchHeld)
+			},
+		},
+		{
+			name: "InvalidLines",
+			newRequest: csvRequest(db.TransferBatchAtomic,
+				"to_account,amount,reference\n"+
+					fmt.Sprintf("%d,100,salary\n", toAccount1.ID)+
+					fmt.Sprintf("%d,abc,bonus\n", toAccount2.ID)+
+					fmt.Sprintf("%d,5,\n", toAccount2.ID)+
+					"not-an-account,5,\n"),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(1).Return(toAccount1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount2.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var body struct {
+					Lines []batchLineError `json:"lines"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Len(t, body.Lines, 3)
+				lines := []int{body.Lines[0].Line, body.Lines[1].Line, body.Lines[2].Line}
+				require.ElementsMatch(t, []int{2, 3, 4}, lines)
+			},
+		},
+		{
+			name: "OverBalance",
+			newRequest: jsonRequest(gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        utils.USD,
+				"mode":            db.TransferBatchAtomic,
+				"items": []gin.H{
+					{"to_account": fmt.Sprint(toAccount1.ID), "amount": fromAccount.Balance + 1},
+				},
+			}),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(1).Return(toAccount1, nil)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code, recorder.Body.String())
+			},
+		},
+		{
+			name: "NoItems",
+			newRequest: jsonRequest(gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        utils.USD,
+				"mode":            db.TransferBatchAtomic,
+			}),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidMode",
+			newRequest: jsonRequest(gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        utils.USD,
+				"mode":            "eventually",
+				"items":           []gin.H{{"to_account": fmt.Sprint(toAccount1.ID), "amount": 1}},
+			}),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request := tc.newRequest(t)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetTransferBatch(t *testing.T) {
+	user, password := randomUser(t)
+	batch := db.TransferBatch{
+		ID:       utils.RandomInt(1, 1000),
+		Username: user.Username,
+		Status:   db.TransferBatchPartiallyCompleted,
+	}
+	items := []db.TransferBatchItem{
+		{ID: 1, BatchID: batch.ID, Line: 1, Status: db.TransferBatchItemCompleted},
+		{ID: 2, BatchID: batch.ID, Line: 2, Status: db.TransferBatchItemFailed, Reason: "insufficient funds"},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(items, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.TransferBatchResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.Equal(t, batch.Status, result.Batch.Status)
+				require.Equal(t, items, result.Items)
+			},
+		},
+		{
+			name: "OtherUsersBatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				other := batch
+				other.Username = utils.RandomOwner()
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(other, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfer-batches/%d", batch.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(user.Username, password)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+type createTransferConfirmationRequest struct {
+	FromAccountID     int64  `json:"from_account_id" binding:"required_without=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string `json:"from_account_number" binding:"required_without=FromAccountID,omitempty,account_number"`
+	// ToAlias is the recipient's username, verified email or phone number.
+	ToAlias   string `json:"to_alias" binding:"required,max=255"`
+	Amount    int64  `json:"amount" binding:"required,gt=0"`
+	Currency  string `json:"currency" binding:"required,currency"`
+	Memo      string `json:"memo,omitempty" binding:"max=500"`
+	Reference string `json:"reference,omitempty" binding:"max=64"`
+	Category  string `json:"category,omitempty" binding:"omitempty,transfer_category"`
+}
+
+type transferConfirmationResponse struct {
+	ID    int64  `json:"id"`
+	Alias string `json:"alias"`
+	// RecipientName is masked; the sender only gets enough to recognise them.
+	RecipientName string    `json:"recipient_name"`
+	Amount        int64     `json:"amount"`
+	Fee           int64     `json:"fee"`
+	Currency      string    `json:"currency"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// createTransferConfirmation resolves an alias to the recipient's account in
+// the currency of the transfer and returns who the money would go to. Nothing
+// moves until the sender confirms it.
+func (server *Server) createTransferConfirmation(c *gin.Context) {
+	var req createTransferConfirmationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validSourceAccount(c, req.FromAccountID, req.FromAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+
+	recipient, err := server.resolveAlias(c, req.ToAlias)
+	var toAccount db.Account
+	if err == nil {
+		toAccount, err = server.store.GetAccountByOwnerAndCurrency(c, db.GetAccountByOwnerAndCurrencyParams{
+			Owner:    recipient.Username,
+			Currency: req.Currency,
+		})
+	}
+	if err != nil {
+		writeLookupError(c, "recipient", err)
+		return
+	}
+	if toAccount.Frozen {
+		writeError(c, db.ErrAccountFrozen)
+		return
+	}
+	// a flag opens a case on the accounts, and confirming holds the transfer
+	// while it is open
+	sender := authUser(c)
+	_, ok := server.screenSanctions(c, db.SanctionsOperationTransfer, sender.Username,
+		sanctionsSubject{Username: sender.Username, FullName: sender.FullName, AccountID: sql.NullInt64{Int64: fromAccount.ID, Valid: true}},
+		sanctionsSubject{Username: recipient.Username, FullName: recipient.FullName, AccountID: sql.NullInt64{Int64: toAccount.ID, Valid: true}},
+	)
+	if !ok {
+		return
+	}
+	decision, ok := server.screenTransfer(c, fromAccount, toAccount.ID, req.Amount)
+	if !ok {
+		return
+	}
+
+	fee, err := db.QuoteTransferFee(c, server.store, fromAccount, toAccount, req.Amount)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	category := req.Category
+	if category == "" {
+		category = utils.CategoryGeneral
+	}
+	confirmation, err := server.store.CreateTransferConfirmation(c, db.CreateTransferConfirmationParams{
+		Username:      sender.Username,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Alias:         req.ToAlias,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		Memo:          req.Memo,
+		Reference:     sql.NullString{String: req.Reference, Valid: req.Reference != ""},
+		Category:      category,
+		ExpiresAt:     time.Now().Add(server.config.TransferConfirmationTTL),
+		// confirming holds the transfer when the risk engine wants it reviewed
+		RiskDecisionID: reviewDecisionID(decision),
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transferConfirmationResponse{
+		ID:            confirmation.ID,
+		Alias:         confirmation.Alias,
+		RecipientName: utils.MaskName(recipient.FullName),
+		Amount:        confirmation.Amount,
+		Fee:           fee.Amount,
+		Currency:      confirmation.Currency,
+		ExpiresAt:     confirmation.ExpiresAt,
+	})
+}
+
+// resolveAlias finds the user an alias stands for: a phone number when it
+// starts with +, a verified email when it has an @, and a username otherwise.
+func (server *Server) resolveAlias(ctx context.Context, alias string) (db.User, error) {
+	switch {
+	case strings.HasPrefix(alias, "+"):
+		return server.store.GetUserByPhone(ctx, sql.NullString{String: alias, Valid: true})
+	case strings.Contains(alias, "@"):
+		return server.store.GetUserByVerifiedEmail(ctx, alias)
+	default:
+		return server.store.GetUsers(ctx, alias)
+	}
+}
+
+type transferConfirmationURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// confirmTransfer commits a transfer to an alias that the authenticated user
+// asked for with createTransferConfirmation. A transfer above the approval
+// threshold, one the risk engine wants reviewed, or one between accounts with
+// an open sanctions case, is held until another user approves it.
+func (server *Server) confirmTransfer(c *gin.Context) {
+	var uri transferConfirmationURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	confirmation, err := server.store.GetTransferConfirmation(c, uri.ID)
+	if err == nil && confirmation.Username != authUser(c).Username {
+		// don't tell other users which ids exist
+		err = db.ErrRecordNotFound
+	}
+	if err != nil {
+		writeLookupError(c, "transfer confirmation", err)
+		return
+	}
+	arg := db.ConfirmTransferTxParams{
+		ID:       confirmation.ID,
+		Username: confirmation.Username,
+	}
+	openCases, err := server.store.CountOpenSanctionsCasesForTransfer(c, db.CountOpenSanctionsCasesForTransferParams{
+		FromAccountID: confirmation.FromAccountID,
+		ToAccountID:   confirmation.ToAccountID,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if server.config.NeedsApproval(confirmation.Currency, confirmation.Amount) || confirmation.RiskDecisionID.Valid || openCases > 0 {
+		arg.Hold = server.pendingHold(confirmation.RiskDecisionID)
+	}
+
+	result, err := server.store.ConfirmTransferTx(c, arg)
+	if err != nil {
+		switch {
+		case isNotFound(err):
+			writeLookupError(c, "transfer confirmation", err)
+		default:
+			server.transferError(c, err, nil)
+		}
+		return
+	}
+	if result.PendingTransfer != nil {
+		c.JSON(http.StatusAccepted, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,352 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestCreateTransferConfirmation(t *testing.T) {
+	sender, senderPassword := randomUser(t)
+	recipient, _ := randomUser(t)
+	recipient.FullName = "Nguyen Van An"
+	recipient.Phone = sql.NullString{String: "+84912345678", Valid: true}
+
+	fromAccount := randomAccount()
+	fromAccount.Owner = sender.Username
+	fromAccount.Currency = utils.USD
+	toAccount := randomAccount()
+	toAccount.Owner = recipient.Username
+	toAccount.Currency = utils.USD
+	frozenAccount := toAccount
+	frozenAccount.Frozen = true
+
+	expectRecipientAccount := func(store *mockdb.MockStore, account db.Account) {
+		arg := db.GetAccountByOwnerAndCurrencyParams{Owner: recipient.Username, Currency: utils.USD}
+		store.EXPECT().GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+	}
+	expectConfirmation := func(store *mockdb.MockStore, alias string) {
+		store.EXPECT().GetFeeRule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeRule{}, sql.ErrNoRows)
+		store.EXPECT().
+			CreateTransferConfirmation(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ interface{}, arg db.CreateTransferConfirmationParams) (db.TransferConfirmation, error) {
+				require.Equal(t, sender.Username, arg.Username)
+				require.Equal(t, fromAccount.ID, arg.FromAccountID)
+				require.Equal(t, toAccount.ID, arg.ToAccountID)
+				require.Equal(t, alias, arg.Alias)
+				require.Equal(t, utils.CategoryGeneral, arg.Category)
+				require.WithinDuration(t, time.Now().Add(5*time.Minute), arg.ExpiresAt, time.Minute)
+				return db.TransferConfirmation{
+					ID:        1,
+					Alias:     arg.Alias,
+					Amount:    arg.Amount,
+					Currency:  arg.Currency,
+					ExpiresAt: arg.ExpiresAt,
+				}, nil
+			})
+	}
+	body := func(alias string, amount int64) gin.H {
+		return gin.H{
+			"from_account_id": fromAccount.ID,
+			"to_alias":        alias,
+			"amount":          amount,
+			"currency":        utils.USD,
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ByUsername",
+			body: body(recipient.Username, 100),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+				expectRecipientAccount(store, toAccount)
+				expectConfirmation(store, recipient.Username)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got transferConfirmationResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, int64(1), got.ID)
+				require.Equal(t, "N***** V** A*", got.RecipientName)
+				require.Equal(t, int64(100), got.Amount)
+				require.NotContains(t, recorder.Body.String(), recipient.FullName)
+			},
+		},
+		{
+			name: "ByPhone",
+			body: body(recipient.Phone.String, 100),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUserByPhone(gomock.Any(), gomock.Eq(recipient.Phone)).Times(1).Return(recipient, nil)
+				expectRecipientAccount(store, toAccount)
+				expectConfirmation(store, recipient.Phone.String)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ByEmail",
+			body: body(recipient.Email, 100),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUserByVerifiedEmail(gomock.Any(), gomock.Eq(recipient.Email)).Times(1).Return(recipient, nil)
+				expectRecipientAccount(store, toAccount)
+				expectConfirmation(store, recipient.Email)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownAlias",
+			body: body(recipient.Email, 100),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUserByVerifiedEmail(gomock.Any(), gomock.Eq(recipient.Email)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateTransferConfirmation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "recipient not found")
+			},
+		},
+		{
+			name: "NoAccountInCurrency",
+			body: body(recipient.Username, 100),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+				store.EXPECT().GetAccountByOwnerAndCurrency(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().CreateTransferConfirmation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "RecipientFrozen",
+			body: body(recipient.Username, 100),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+				expectRecipientAccount(store, frozenAccount)
+				store.EXPECT().CreateTransferConfirmation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NeedsReview",
+			body: body(recipient.Username, 100),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUsers(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+				expectRecipientAccount(store, toAccount)
+				store.EXPECT().
+					ListRiskRules(gomock.Any(), gomock.Eq(utils.USD)).
+					Times(1).
+					Return([]db.RiskRule{{Name: "new_recipient_usd", Kind: db.RiskRuleNewRecipient, Action: db.RiskReview}}, nil)
+				store.EXPECT().CountTransfersTo(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetFeeRule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeRule{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateTransferConfirmation(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateTransferConfirmationParams) (db.TransferConfirmation, error) {
+						// confirming it holds the transfer for the review
+						require.True(t, arg.RiskDecisionID.Valid)
+						return db.TransferConfirmation{ID: 1, RiskDecisionID: arg.RiskDecisionID}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MissingAlias",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"amount":          100,
+				"currency":        utils.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTransferConfirmation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, sender)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfer-confirmations", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.SetBasicAuth(sender.Username, senderPassword)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestConfirmTransfer(t *testing.T) {
+	sender, senderPassword := randomUser(t)
+	confirmation := db.TransferConfirmation{
+		ID:        utils.RandomInt(1, 1000),
+		Username:  sender.Username,
+		Amount:    100,
+		Currency:  utils.USD,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	arg := db.ConfirmTransferTxParams{ID: confirmation.ID, Username: sender.Username}
+	large := confirmation
+	large.Amount = 20000
+	others := confirmation
+	others.Username = utils.RandomOwner()
+	reviewed := confirmation
+	reviewed.RiskDecisionID = sql.NullInt64{Int64: 9, Valid: true}
+
+	expectConfirm := func(err error) func(store *mockdb.MockStore) {
+		return func(store *mockdb.MockStore) {
+			store.EXPECT().GetTransferConfirmation(gomock.Any(), gomock.Eq(confirmation.ID)).Times(1).Return(confirmation, nil)
+			store.EXPECT().ConfirmTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.ConfirmTransferTxResult{}, err)
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{"OK", expectConfirm(nil), http.StatusOK},
+		{"AlreadyConfirmed", expectConfirm(db.ErrTransferConfirmationUsed), http.StatusConflict},
+		{"Expired", expectConfirm(db.ErrTransferConfirmationExpired), http.StatusConflict},
+		{"Frozen", expectConfirm(db.ErrAccountFrozen), http.StatusForbidden},
+		{"InternalError", expectConfirm(sql.ErrConnDone), http.StatusInternalServerError},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferConfirmation(gomock.Any(), gomock.Eq(confirmation.ID)).Times(1).Return(db.TransferConfirmation{}, sql.ErrNoRows)
+				store.EXPECT().ConfirmTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "OtherUsersConfirmation",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferConfirmation(gomock.Any(), gomock.Eq(confirmation.ID)).Times(1).Return(others, nil)
+				store.EXPECT().ConfirmTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "NeedsReview",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferConfirmation(gomock.Any(), gomock.Eq(confirmation.ID)).Times(1).Return(reviewed, nil)
+				store.EXPECT().
+					ConfirmTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, got db.ConfirmTransferTxParams) (db.ConfirmTransferTxResult, error) {
+						require.NotNil(t, got.Hold)
+						require.Equal(t, reviewed.RiskDecisionID, got.Hold.RiskDecisionID)
+						return db.ConfirmTransferTxResult{PendingTransfer: &db.PendingTransfer{ID: 1}}, nil
+					})
+			},
+			code: http.StatusAccepted,
+		},
+		{
+			name: "AboveApprovalThreshold",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferConfirmation(gomock.Any(), gomock.Eq(confirmation.ID)).Times(1).Return(large, nil)
+				store.EXPECT().
+					ConfirmTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, got db.ConfirmTransferTxParams) (db.ConfirmTransferTxResult, error) {
+						require.Equal(t, arg.ID, got.ID)
+						require.NotNil(t, got.Hold)
+						require.WithinDuration(t, time.Now().Add(time.Hour), got.Hold.ExpiresAt, time.Minute)
+						return db.ConfirmTransferTxResult{PendingTransfer: &db.PendingTransfer{ID: 1}}, nil
+					})
+			},
+			code: http.StatusAccepted,
+		},
+		{
+			name: "OpenSanctionsCase",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferConfirmation(gomock.Any(), gomock.Eq(confirmation.ID)).Times(1).Return(confirmation, nil)
+				store.EXPECT().CountOpenSanctionsCasesForTransfer(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().
+					ConfirmTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, got db.ConfirmTransferTxParams) (db.ConfirmTransferTxResult, error) {
+						require.NotNil(t, got.Hold)
+						require.False(t, got.Hold.RiskDecisionID.Valid)
+						return db.ConfirmTransferTxResult{PendingTransfer: &db.PendingTransfer{ID: 1}}, nil
+					})
+			},
+			code: http.StatusAccepted,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, sender)
+			tc.buildStubs(store)
+			store.EXPECT().CountOpenSanctionsCasesForTransfer(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(0), nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfer-confirmations/%d/confirm", confirmation.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(sender.Username, senderPassword)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+type searchTransfersRequest struct {
+	// Query is matched against the memo with Postgres full-text search.
+	Query string `form:"q" binding:"max=200"`
+	// Counterparty is the id or account number of the other side.
+	Counterparty string    `form:"counterparty" binding:"omitempty,account_ref"`
+	Category     string    `form:"category" binding:"omitempty,transfer_category"`
+	From         time.Time `form:"from"`
+	To           time.Time `form:"to" binding:"omitempty,gtfield=From"`
+	MinAmount    int64     `form:"min_amount" binding:"omitempty,min=1"`
+	MaxAmount    int64     `form:"max_amount" binding:"omitempty,min=1,gtefield=MinAmount"`
+	PageID       int64     `form:"page_id" binding:"required,min=1"`
+	PageSize     int64     `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// searchTransfers finds the transfers into or out of the authenticated
+// user's accounts that match every filter given, newest first.
+func (server *Server) searchTransfers(c *gin.Context) {
+	var req searchTransfersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.SearchTransfersParams{
+		Owner:       authUser(c).Username,
+		Query:       sql.NullString{String: req.Query, Valid: req.Query != ""},
+		Category:    sql.NullString{String: req.Category, Valid: req.Category != ""},
+		CreatedFrom: sql.NullTime{Time: req.From, Valid: !req.From.IsZero()},
+		CreatedTo:   sql.NullTime{Time: req.To, Valid: !req.To.IsZero()},
+		MinAmount:   sql.NullInt64{Int64: req.MinAmount, Valid: req.MinAmount != 0},
+		MaxAmount:   sql.NullInt64{Int64: req.MaxAmount, Valid: req.MaxAmount != 0},
+		Limit:       int32(req.PageSize),
+		Offset:      int32((req.PageID - 1) * req.PageSize),
+	}
+	if req.Counterparty != "" {
+		counterparty, err := server.getAccountByRef(c, req.Counterparty)
+		if err != nil {
+			writeLookupError(c, "counterparty account", err)
+			return
+		}
+		arg.CounterpartyAccountID = sql.NullInt64{Int64: counterparty.ID, Valid: true}
+	}
+
+	transfers, err := server.store.SearchTransfers(c, arg)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
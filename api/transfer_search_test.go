@@ -0,0 +1,221 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestSearchTransfers(t *testing.T) {
+	user, password := randomUser(t)
+	counterparty := randomAccount()
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	transfers := []db.Transfer{
+		{ID: 2, Amount: 500, Memo: "March rent", Category: utils.CategoryRent},
+		{ID: 1, Amount: 700, Memo: "rent deposit", Category: utils.CategoryRent},
+	}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		setupAuth     func(request *http.Request)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			query: url.Values{
+				"q":            {"rent"},
+				"counterparty": {counterparty.AccountNumber},
+				"category":     {utils.CategoryRent},
+				"from":         {from.Format(time.RFC3339)},
+				"to":           {to.Format(time.RFC3339)},
+				"min_amount":   {"100"},
+				"max_amount":   {"1000"},
+				"page_id":      {"1"},
+				"page_size":    {"5"},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user.Username, password)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(counterparty.AccountNumber)).
+					Times(1).
+					Return(counterparty, nil)
+				arg := db.SearchTransfersParams{
+					Owner:                 user.Username,
+					Query:                 sql.NullString{String: "rent", Valid: true},
+					CounterpartyAccountID: sql.NullInt64{Int64: counterparty.ID, Valid: true},
+					Category:              sql.NullString{String: utils.CategoryRent, Valid: true},
+					CreatedFrom:           sql.NullTime{Time: from, Valid: true},
+					CreatedTo:             sql.NullTime{Time: to, Valid: true},
+					MinAmount:             sql.NullInt64{Int64: 100, Valid: true},
+					MaxAmount:             sql.NullInt64{Int64: 1000, Valid: true},
+					Limit:                 5,
+					Offset:                0,
+				}
+				store.EXPECT().
+					SearchTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, got db.SearchTransfersParams) ([]db.Transfer, error) {
+						require.True(t, arg.CreatedFrom.Time.Equal(got.CreatedFrom.Time))
+						require.True(t, arg.CreatedTo.Time.Equal(got.CreatedTo.Time))
+						got.CreatedFrom.Time, got.CreatedTo.Time = arg.CreatedFrom.Time, arg.CreatedTo.Time
+						require.Equal(t, arg, got)
+						return transfers, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var got []db.Transfer
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, transfers, got)
+			},
+		},
+		{
+			name: "NoFilters",
+			query: url.Values{
+				"page_id":   {"2"},
+				"page_size": {"5"},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user.Username, password)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				arg := db.SearchTransfersParams{
+					Owner:  user.Username,
+					Limit:  5,
+					Offset: 5,
+				}
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Transfer{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "CounterpartyNotFound",
+			query: url.Values{
+				"counterparty": {"42"},
+				"page_id":      {"1"},
+				"page_size":    {"5"},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user.Username, password)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(int64(42))).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDateRange",
+			query: url.Values{
+				"from":      {to.Format(time.RFC3339)},
+				"to":        {from.Format(time.RFC3339)},
+				"page_id":   {"1"},
+				"page_size": {"5"},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user.Username, password)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmountRange",
+			query: url.Values{
+				"min_amount": {"1000"},
+				"max_amount": {"100"},
+				"page_id":    {"1"},
+				"page_size":  {"5"},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user.Username, password)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			query: url.Values{
+				"page_id":   {"1"},
+				"page_size": {"5"},
+			},
+			setupAuth: func(request *http.Request) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			query: url.Values{
+				"page_id":   {"1"},
+				"page_size": {"5"},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user.Username, password)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/transfers/search?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+			tc.setupAuth(request)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
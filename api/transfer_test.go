@@ -0,0 +1,896 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestCreateTransfer(t *testing.T) {
+	amount := int64(10)
+
+	user1, password1 := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account3 := randomAccount()
+	account1.Owner = user1.Username
+	account2.Owner = user2.Username
+	account3.Owner = user2.Username
+	account1.Currency = utils.USD
+	account2.Currency = utils.USD
+	account3.Currency = utils.EUR
+
+	transferBody := gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          amount,
+		"currency":        utils.USD,
+	}
+	hash, err := requestHash(transferRequest{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		Currency:      utils.USD,
+	})
+	require.NoError(t, err)
+	storedKey := db.IdempotencyKey{
+		Username:       user1.Username,
+		IdempotencyKey: "key-1",
+		RequestHash:    hash,
+		ResponseStatus: http.StatusOK,
+		ResponseBody:   []byte(`{"transfer":{"id":42}}`),
+	}
+
+	quote := db.FxQuote{
+		ID:           utils.RandomInt(1, 1000),
+		Username:     user1.Username,
+		FromCurrency: utils.USD,
+		ToCurrency:   utils.EUR,
+		Rate:         92_000_000,
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+	expiredQuote := quote
+	expiredQuote.ExpiresAt = time.Now().Add(-time.Second)
+	othersQuote := quote
+	othersQuote.Username = utils.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(request *http.Request)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OKByAccountNumber",
+			body: gin.H{
+				"from_account_number": account1.AccountNumber,
+				"to_account_number":   account2.AccountNumber,
+				"amount":              amount,
+				"currency":            utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account1.AccountNumber)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account2.AccountNumber)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAccountNumber",
+			body: gin.H{
+				"from_account_number": account1.AccountNumber[:19] + "x",
+				"to_account_id":       account2.ID,
+				"amount":              amount,
+				"currency":            utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingToAccount",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "FromAccountNotFound",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "ToAccountCurrencyMismatch",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "LimitExceeded",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, &db.LimitExceededError{
+						Limit:     db.LimitMaxDailyAmount,
+						Currency:  utils.USD,
+						Max:       100,
+						Available: 5,
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, db.LimitMaxDailyAmount, body["limit"])
+				require.Equal(t, float64(5), body["available"])
+			},
+		},
+		{
+			// without credentials nobody can debit another user's account
+			name: "NoAuthorization",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1+"x")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			body: gin.H{
+				"from_account_id": account2.ID,
+				"to_account_id":   account1.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "IdempotencyKeyStored",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+				request.Header.Set(idempotencyKeyHeader, "key-1")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{
+					Username:       user1.Username,
+					IdempotencyKey: "key-1",
+				})).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.NotNil(t, arg.IdempotencyKey)
+						require.Equal(t, user1.Username, arg.IdempotencyKey.Username)
+						require.Equal(t, "key-1", arg.IdempotencyKey.Key)
+						require.NotEmpty(t, arg.IdempotencyKey.RequestHash)
+						require.Equal(t, int32(http.StatusOK), arg.IdempotencyKey.ResponseStatus)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.IdempotencyKey.ExpiresAt, time.Second)
+						return db.TransferTxResult{}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "IdempotentReplay",
+			body: transferBody,
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+				request.Header.Set(idempotencyKeyHeader, "key-1")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(storedKey, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, string(storedKey.ResponseBody), recorder.Body.String())
+			},
+		},
+		{
+			name: "IdempotencyKeyReused",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount + 1,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+				request.Header.Set(idempotencyKeyHeader, "key-1")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(storedKey, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "IdempotencyKeyRace",
+			body: transferBody,
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+				request.Header.Set(idempotencyKeyHeader, "key-1")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				gomock.InOrder(
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows),
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(storedKey, nil),
+				)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrIdempotencyKeyInUse)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, string(storedKey.ResponseBody), recorder.Body.String())
+			},
+		},
+		{
+			name: "OKCrossCurrency",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+				"fx_quote_id":     quote.ID,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(quote, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+
+				arg := db.FxTransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account3.ID,
+					Amount:        amount,
+					FxQuoteID:     quote.ID,
+					Username:      user1.Username,
+				}
+				store.EXPECT().FxTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "FxQuoteExpired",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+				"fx_quote_id":     expiredQuote.ID,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(expiredQuote.ID)).Times(1).Return(expiredQuote, nil)
+				store.EXPECT().FxTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "OtherUsersFxQuote",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+				"fx_quote_id":     othersQuote.ID,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(othersQuote.ID)).Times(1).Return(othersQuote, nil)
+				store.EXPECT().FxTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "FxQuoteUsedConcurrently",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+				"fx_quote_id":     quote.ID,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(quote, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().FxTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.FxTransferTxResult{}, db.ErrFxQuoteUsed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "FxInsufficientFunds",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+				"fx_quote_id":     quote.ID,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(quote, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().FxTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.FxTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "AboveApprovalThreshold",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          20000,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreatePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePendingTransferTxParams) (db.PendingTransfer, error) {
+						require.Equal(t, user1.Username, arg.CreatedBy)
+						require.Equal(t, account1.ID, arg.FromAccountID)
+						require.Equal(t, sql.NullInt64{Int64: account2.ID, Valid: true}, arg.ToAccountID)
+						require.Equal(t, int64(20000), arg.Amount)
+						require.True(t, arg.ExpiresAt.After(time.Now()))
+						return db.PendingTransfer{ID: 1, Status: db.PendingTransferPending}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "CrossCurrencyAboveApprovalThreshold",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"amount":          20000,
+				"currency":        utils.USD,
+				"fx_quote_id":     quote.ID,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(quote, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().FxTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreatePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePendingTransferTxParams) (db.PendingTransfer, error) {
+						require.Equal(t, db.PendingTransferKindFxTransfer, arg.Kind)
+						require.Equal(t, sql.NullInt64{Int64: quote.ID, Valid: true}, arg.FxQuoteID)
+						require.Equal(t, sql.NullInt64{Int64: account3.ID, Valid: true}, arg.ToAccountID)
+						require.Equal(t, utils.USD, arg.Currency)
+						return db.PendingTransfer{ID: 1, Status: db.PendingTransferPending}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "OKWithDetails",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+				"memo":            "March rent",
+				"reference":       "INV-2024-03",
+				"category":        utils.CategoryRent,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					TransferDetails: db.TransferDetails{
+						Memo:      "March rent",
+						Reference: "INV-2024-03",
+						Category:  utils.CategoryRent,
+					},
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ReferenceAlreadyUsed",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+				"reference":       "INV-2024-03",
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.TranslateError(&pq.Error{Code: "23505"}))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"code":"already_exists"`)
+			},
+		},
+		{
+			name: "InvalidCategory",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+				"category":        "gambling",
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Async",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+				"async":           true,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+				}
+				store.EXPECT().
+					QueueTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Transfer{ID: 7, Amount: amount, Status: db.TransferPending}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var transfer db.Transfer
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &transfer))
+				require.Equal(t, int64(7), transfer.ID)
+				require.Equal(t, db.TransferPending, transfer.Status)
+			},
+		},
+		{
+			name: "AsyncCrossCurrency",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account3.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+				"fx_quote_id":     quote.ID,
+				"async":           true,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().FxTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().QueueTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(user1.Username, password1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectAuth(store, user1)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, sql.ErrTxDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			expectNoRiskRules(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(data))
+			require.NoError(t, err)
+			tc.setupAuth(request)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetTransfer(t *testing.T) {
+	sender, senderPassword := randomUser(t)
+	recipient, recipientPassword := randomUser(t)
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+	other, otherPassword := randomUser(t)
+
+	fromAccount := randomAccount()
+	fromAccount.Owner = sender.Username
+	toAccount := randomAccount()
+	toAccount.Owner = recipient.Username
+
+	transfer := db.Transfer{
+		ID:            utils.RandomInt(1, 1000),
+		FromAccountID: sql.NullInt64{Int64: fromAccount.ID, Valid: true},
+		ToAccountID:   sql.NullInt64{Int64: toAccount.ID, Valid: true},
+		Amount:        100,
+		Status:        db.TransferProcessing,
+	}
+
+	testCases := []struct {
+		name       string
+		user       db.User
+		password   string
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name:     "Sender",
+			user:     sender,
+			password: senderPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "Recipient",
+			user:     recipient,
+			password: recipientPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "Staff",
+			user:     staff,
+			password: staffPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "NotParty",
+			user:     other,
+			password: otherPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name:     "NotFound",
+			user:     sender,
+			password: senderPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name:     "InternalError",
+			user:     sender,
+			password: senderPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.Transfer{}, sql.ErrConnDone)
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, tc.user)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/transfers/%d", transfer.ID), nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(tc.user.Username, tc.password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+			if tc.code == http.StatusOK {
+				var got db.Transfer
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, db.TransferProcessing, got.Status)
+			}
+		})
+	}
+}
@@ -1,11 +1,11 @@
 package api
 
 import (
+	"database/sql"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 	db "tutorial.sqlc.dev/app/db/sqlc"
 	"tutorial.sqlc.dev/app/utils"
 )
@@ -15,47 +15,82 @@ type createUserRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"full_name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
+	// Phone is optional and must be in E.164 form, like +84912345678.
+	Phone string `json:"phone" binding:"omitempty,e164"`
 }
 type createUserResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	EmailVerified     bool      `json:"email_verified"`
+	Phone             string    `json:"phone,omitempty"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
 
+func newUserResponse(user db.User) createUserResponse {
+	return createUserResponse{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		EmailVerified:     user.EmailVerifiedAt.Valid,
+		Phone:             user.Phone.String,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt.Time,
+	}
+}
+
 func (server *Server) createUser(c *gin.Context) {
 	var req = createUserRequest{}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	if _, ok := server.screenSanctions(c, db.SanctionsOperationCreateUser, req.Username,
+		sanctionsSubject{Username: req.Username, FullName: req.FullName}); !ok {
+		return
+	}
 	hashedPassword, err := utils.HashedPassword(req.Password)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
 	arg := db.CreateUserParams{
 		Username:       req.Username,
 		HashedPassword: hashedPassword,
 		FullName:       req.FullName,
 		Email:          req.Email,
+		Phone:          sql.NullString{String: req.Phone, Valid: req.Phone != ""},
 	}
 	user, err := server.store.CreateUser(c, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				c.JSON(http.StatusConflict, errorResponse(err))
-				return
-			}
-			c.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-	}
-	resp := createUserResponse{
-		Username:          user.Username,
-		FullName:          user.FullName,
-		Email:             user.Email,
-		PasswordChangedAt: user.PasswordChangedAt,
-		CreatedAt:         user.CreatedAt.Time,
+		writeError(c, err)
+		return
 	}
-	c.JSON(http.StatusOK, resp)
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
 
+type verifyUserEmailRequest struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// verifyUserEmail lets staff mark a user's email as verified, which makes it
+// usable as a transfer alias.
+func (server *Server) verifyUserEmail(c *gin.Context) {
+	var req verifyUserEmailRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if authUser(c).Role != db.RoleStaff {
+		c.JSON(http.StatusForbidden, errorResponse(errNotStaff))
+		return
+	}
+
+	user, err := server.store.VerifyUserEmail(c, req.Username)
+	if err != nil {
+		writeLookupError(c, "user", err)
+		return
+	}
+	c.JSON(http.StatusOK, newUserResponse(user))
 }
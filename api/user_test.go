@@ -0,0 +1,153 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+func TestCreateUserPhone(t *testing.T) {
+	user, password := randomUser(t)
+	user.Phone = sql.NullString{String: "+84912345678", Valid: true}
+
+	testCases := []struct {
+		name       string
+		phone      string
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name:  "OK",
+			phone: user.Phone.String,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateUserParams) (db.User, error) {
+						require.Equal(t, user.Phone, arg.Phone)
+						return user, nil
+					})
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:  "InvalidPhone",
+			phone: "0912345678",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"username":  user.Username,
+				"password":  password,
+				"full_name": user.FullName,
+				"email":     user.Email,
+				"phone":     tc.phone,
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/users", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+			if tc.code == http.StatusOK {
+				require.Contains(t, recorder.Body.String(), `"phone":"+84912345678"`)
+				require.NotContains(t, recorder.Body.String(), "hashed_password")
+			}
+		})
+	}
+}
+
+func TestVerifyUserEmail(t *testing.T) {
+	staff, staffPassword := randomUser(t)
+	staff.Role = db.RoleStaff
+	customer, customerPassword := randomUser(t)
+	verified := customer
+	verified.EmailVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	testCases := []struct {
+		name       string
+		user       db.User
+		password   string
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name:     "OK",
+			user:     staff,
+			password: staffPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyUserEmail(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(verified, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "NotStaff",
+			user:     customer,
+			password: customerPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyUserEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:     "UserNotFound",
+			user:     staff,
+			password: staffPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyUserEmail(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			expectAuth(store, tc.user)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%s/verify-email", customer.Username)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+			request.SetBasicAuth(tc.user.Username, tc.password)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+			if tc.code == http.StatusOK {
+				require.Contains(t, recorder.Body.String(), `"email_verified":true`)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"strconv"
+
 	"github.com/go-playground/validator/v10"
 	"tutorial.sqlc.dev/app/utils"
 )
@@ -11,3 +13,39 @@ var validCurrencies validator.Func = func(fieldLevel validator.FieldLevel) bool
 	}
 	return false
 }
+
+var validAccountNumber validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if number, ok := fieldLevel.Field().Interface().(string); ok {
+		return utils.IsValidAccountNumber(utils.NormalizeAccountNumber(number))
+	}
+	return false
+}
+
+var validTransferCategory validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if category, ok := fieldLevel.Field().Interface().(string); ok {
+		return utils.IsValidCategory(category)
+	}
+	return false
+}
+
+var validRoutingNumber validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if routingNumber, ok := fieldLevel.Field().Interface().(string); ok {
+		return utils.IsValidRoutingNumber(routingNumber)
+	}
+	return false
+}
+
+// validAccountRef accepts either a positive account id or an account number.
+var validAccountRef validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if ref, ok := fieldLevel.Field().Interface().(string); ok {
+		return isAccountRef(ref)
+	}
+	return false
+}
+
+func isAccountRef(ref string) bool {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return id >= 1
+	}
+	return utils.IsValidAccountNumber(utils.NormalizeAccountNumber(ref))
+}
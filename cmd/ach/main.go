@@ -0,0 +1,73 @@
+// Command ach runs the ACH export outside the nightly schedule and imports
+// the returns files receiving banks send back.
+//
+// Usage:
+//
+//	ach export          write the pending outbound payments to the outbox
+//	ach returns <file>  credit back the payments returned in a NACHA returns file
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"tutorial.sqlc.dev/app/ach"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+	"tutorial.sqlc.dev/app/worker"
+)
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] == "returns") != (len(os.Args) == 3) {
+		fmt.Fprintln(os.Stderr, "usage: ach export | ach returns <file>")
+		os.Exit(2)
+	}
+	config, err := utils.LoadConfig(".")
+	if err != nil {
+		log.Fatalf("cannot load config: %v", err)
+	}
+	conn, err := db.NewPool(context.Background(), config)
+	if err != nil {
+		log.Fatalf("cannot connect to db: %v", err)
+	}
+	store := db.NewStore(conn)
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "export":
+		exportWorker, err := worker.NewACHExportWorker(config, store)
+		if err != nil {
+			log.Fatalf("cannot create ACH export: %v", err)
+		}
+		paths, err := exportWorker.RunOnce(ctx)
+		if err != nil {
+			log.Fatalf("cannot export outbound payments: %v", err)
+		}
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+	case "returns":
+		report, err := ach.ImportReturnsFile(ctx, store, os.Args[2])
+		if err != nil {
+			log.Fatalf("cannot import returns: %v", err)
+		}
+		for _, result := range report.Returned {
+			fmt.Printf("returned payment %d (%s), reversal transfer %d\n",
+				result.Payment.ID, result.Payment.ReturnCode.String, result.Reversal.Transfer.ID)
+		}
+		if report.AlreadyReturned > 0 {
+			fmt.Printf("skipped %d payments returned by an earlier import\n", report.AlreadyReturned)
+		}
+		for _, failure := range report.Failed {
+			log.Printf("cannot return %s (%s): %v", failure.TraceNumber, failure.Code, failure.Err)
+		}
+		if len(report.Failed) > 0 {
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		os.Exit(2)
+	}
+}
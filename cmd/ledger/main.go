@@ -0,0 +1,88 @@
+// Command ledger checks the hash chains of the entries and writes and checks
+// the signed checkpoints of their heads.
+//
+// Usage:
+//
+//	ledger verify                    walk every chain and report the first broken link
+//	ledger checkpoint                sign the chain heads and write a checkpoint now
+//	ledger verify-checkpoint <file>  check a checkpoint's signature and that the chains still lead through it
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/ledger"
+	"tutorial.sqlc.dev/app/utils"
+	"tutorial.sqlc.dev/app/worker"
+)
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] == "verify-checkpoint") != (len(os.Args) == 3) {
+		fmt.Fprintln(os.Stderr, "usage: ledger verify | ledger checkpoint | ledger verify-checkpoint <file>")
+		os.Exit(2)
+	}
+	config, err := utils.LoadConfig(".")
+	if err != nil {
+		log.Fatalf("cannot load config: %v", err)
+	}
+	conn, err := db.NewPool(context.Background(), config)
+	if err != nil {
+		log.Fatalf("cannot connect to db: %v", err)
+	}
+	store := db.NewStore(conn)
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "verify":
+		result, err := ledger.Verify(ctx, store)
+		if err != nil {
+			log.Fatalf("cannot verify the ledger: %v", err)
+		}
+		if result.Broken != nil {
+			fmt.Println(result.Broken)
+			os.Exit(1)
+		}
+		fmt.Printf("checked %d entries of %d accounts, every chain holds\n", result.Entries, result.Accounts)
+	case "checkpoint":
+		checkpointWorker, err := worker.NewLedgerCheckpointWorker(config, store)
+		if err != nil {
+			log.Fatalf("cannot create ledger checkpoint: %v", err)
+		}
+		path, err := checkpointWorker.RunOnce(ctx, time.Now())
+		if err != nil {
+			log.Fatalf("cannot write ledger checkpoint: %v", err)
+		}
+		fmt.Println(path)
+	case "verify-checkpoint":
+		key, err := ledger.LoadSigningKey(config.LedgerSigningKey)
+		if err != nil {
+			log.Fatalf("cannot load ledger signing key: %v", err)
+		}
+		checkpoint, err := ledger.ReadCheckpoint(os.Args[2])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := checkpoint.VerifySignature(key.Public().(ed25519.PublicKey)); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		broken, err := checkpoint.CheckHeads(ctx, store)
+		if err != nil {
+			log.Fatalf("cannot check the checkpoint: %v", err)
+		}
+		if broken != nil {
+			fmt.Println(broken)
+			os.Exit(1)
+		}
+		fmt.Printf("checkpoint of %s holds for %d chains\n", checkpoint.CreatedAt.Format(time.RFC3339), len(checkpoint.Heads))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		os.Exit(2)
+	}
+}
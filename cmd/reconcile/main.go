@@ -0,0 +1,49 @@
+// Command reconcile checks, outside the nightly schedule, that the balance of
+// every account matches the sum of its entries, and records the run.
+//
+// Usage:
+//
+//	reconcile          report the accounts and currencies that drifted
+//	reconcile -repair  also post adjustments for the accounts that drifted
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func main() {
+	repair := flag.Bool("repair", false, "post adjustments for the accounts that drifted")
+	flag.Parse()
+	if flag.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: reconcile [-repair]")
+		os.Exit(2)
+	}
+	config, err := utils.LoadConfig(".")
+	if err != nil {
+		log.Fatalf("cannot load config: %v", err)
+	}
+	conn, err := db.NewPool(context.Background(), config)
+	if err != nil {
+		log.Fatalf("cannot connect to db: %v", err)
+	}
+	store := db.NewStore(conn)
+
+	result, err := store.Reconcile(context.Background(), db.ReconcileParams{Repair: *repair})
+	if err != nil {
+		log.Fatalf("cannot reconcile the ledger: %v", err)
+	}
+	for _, discrepancy := range result.Discrepancies {
+		fmt.Println(discrepancy)
+	}
+	fmt.Printf("run %d: checked %d accounts, %d drifted\n", result.Run.ID, result.Run.AccountsChecked, result.Run.Discrepancies)
+	if len(result.Discrepancies) > 0 && !*repair {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_account_number_key";
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "account_number";
+DROP SEQUENCE IF EXISTS "account_number_seq";
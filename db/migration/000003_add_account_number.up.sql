@@ -0,0 +1,22 @@
+CREATE SEQUENCE IF NOT EXISTS "account_number_seq";
+
+ALTER TABLE "accounts" ADD COLUMN "account_number" varchar;
+
+-- Backfill existing accounts with the same layout utils.NewAccountNumber
+-- produces: bank code, branch code, 10 digit serial and ISO 7064 mod 97-10
+-- check digits. '131816180001' is the numeric form of 'DIGI0001'.
+WITH numbered AS (
+  SELECT id, lpad(nextval('account_number_seq')::text, 10, '0') AS serial
+  FROM (SELECT id FROM "accounts" ORDER BY id) AS ordered
+)
+UPDATE "accounts" a
+SET "account_number" = 'DIGI0001' || n.serial ||
+  lpad((98 - (('131816180001' || n.serial)::numeric * 100 % 97))::text, 2, '0')
+FROM numbered n
+WHERE a.id = n.id;
+
+ALTER TABLE "accounts" ALTER COLUMN "account_number" SET NOT NULL;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_account_number_key" UNIQUE ("account_number");
+
+COMMENT ON COLUMN "accounts"."account_number" IS 'bank code, branch code, serial and mod 97-10 check digits';
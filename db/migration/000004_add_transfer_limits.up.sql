@@ -0,0 +1,25 @@
+ALTER TABLE "users" ADD COLUMN "tier" varchar NOT NULL DEFAULT 'standard';
+
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint,
+  "tier" varchar,
+  "currency" varchar NOT NULL,
+  "max_single_amount" bigint,
+  "max_daily_amount" bigint,
+  "max_daily_count" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "transfer_limits_scope_check" CHECK (("account_id" IS NULL) <> ("tier" IS NULL))
+);
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("account_id", "currency") WHERE "account_id" IS NOT NULL;
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("tier", "currency") WHERE "tier" IS NOT NULL;
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+COMMENT ON COLUMN "transfer_limits"."account_id" IS 'set for a per account limit, overrides the tier limit';
+
+COMMENT ON COLUMN "transfer_limits"."tier" IS 'set for a limit applying to every user of the tier';
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
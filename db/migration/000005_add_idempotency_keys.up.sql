@@ -0,0 +1,18 @@
+CREATE TABLE "idempotency_keys" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "idempotency_key" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response_status" integer NOT NULL,
+  "response_body" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'sha256 of the request the key was first used with';
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "idempotency_keys" ADD CONSTRAINT "idempotency_keys_username_idempotency_key_key" UNIQUE ("username", "idempotency_key");
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "fx_quotes";
+DROP TABLE IF EXISTS "fx_rates";
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bankfx');
+DELETE FROM "accounts" WHERE "owner" = 'bankfx';
+DELETE FROM "users" WHERE "username" = 'bankfx';
+DROP FUNCTION IF EXISTS next_account_number();
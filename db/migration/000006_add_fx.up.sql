@@ -0,0 +1,57 @@
+-- Same layout as utils.NewAccountNumber, for accounts the bank creates itself.
+-- '131816180001' is 'DIGI0001' with letters converted to ISO 7064 digits.
+CREATE OR REPLACE FUNCTION next_account_number() RETURNS varchar AS $$
+DECLARE
+  serial text := lpad(nextval('account_number_seq')::text, 10, '0');
+BEGIN
+  RETURN 'DIGI0001' || serial ||
+    lpad((98 - (('131816180001' || serial)::numeric * 100 % 97))::text, 2, '0');
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE "fx_rates" (
+  "id" bigserial PRIMARY KEY,
+  "base_currency" varchar NOT NULL,
+  "quote_currency" varchar NOT NULL,
+  "rate" bigint NOT NULL,
+  "valid_from" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "fx_rates_rate_check" CHECK ("rate" > 0)
+);
+
+CREATE TABLE "fx_quotes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "fx_rate_id" bigint NOT NULL,
+  "from_currency" varchar NOT NULL,
+  "to_currency" varchar NOT NULL,
+  "rate" bigint NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "fx_rates" ("base_currency", "quote_currency", "valid_from");
+
+CREATE INDEX ON "fx_quotes" ("username");
+
+COMMENT ON COLUMN "fx_rates"."rate" IS 'quote currency units per base currency unit, scaled by 10^8';
+
+COMMENT ON COLUMN "fx_quotes"."rate" IS 'to currency units per from currency unit, scaled by 10^8';
+
+COMMENT ON COLUMN "fx_quotes"."transfer_id" IS 'set once the quote has been used';
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("fx_rate_id") REFERENCES "fx_rates" ("id");
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- The bank's FX position accounts, one per currency, take the other side of
+-- both legs of a cross-currency transfer.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('bankfx', '!', 'Bank FX position', 'bankfx@digi-bank.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "account_number")
+SELECT 'bankfx', 0, c, next_account_number()
+FROM unnest(ARRAY['USD', 'EUR', 'VND']) AS c;
@@ -0,0 +1,45 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "execute_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'scheduled',
+  "attempts" integer NOT NULL DEFAULT 0,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "scheduled_transfers_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "scheduled_transfers_status_check" CHECK ("status" IN ('scheduled', 'executed', 'failed', 'cancelled'))
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "outcome" varchar NOT NULL,
+  "reason" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "scheduled_transfer_runs_outcome_check" CHECK ("outcome" IN ('executed', 'failed', 'retried'))
+);
+
+CREATE INDEX ON "scheduled_transfers" ("username");
+
+CREATE INDEX ON "scheduled_transfers" ("execute_at") WHERE "status" = 'scheduled';
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+COMMENT ON COLUMN "scheduled_transfers"."execute_at" IS 'next time the worker should try the transfer';
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
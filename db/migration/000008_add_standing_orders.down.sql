@@ -0,0 +1,4 @@
+ALTER TABLE "scheduled_transfers" DROP COLUMN IF EXISTS "occurrence_date";
+ALTER TABLE "scheduled_transfers" DROP COLUMN IF EXISTS "standing_order_id";
+DROP TABLE IF EXISTS "holidays";
+DROP TABLE IF EXISTS "standing_orders";
@@ -0,0 +1,56 @@
+CREATE TABLE "standing_orders" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "frequency" varchar NOT NULL,
+  "weekday" integer,
+  "day_of_month" integer,
+  "start_date" date NOT NULL,
+  "end_date" date,
+  "max_occurrences" integer,
+  "occurrences" integer NOT NULL DEFAULT 0,
+  "next_nominal_date" date NOT NULL,
+  "next_run_date" date NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "standing_orders_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "standing_orders_frequency_check" CHECK (
+    ("frequency" = 'weekly' AND "weekday" BETWEEN 0 AND 6) OR
+    ("frequency" = 'monthly' AND "day_of_month" BETWEEN 1 AND 31) OR
+    "frequency" = 'last_business_day'
+  ),
+  CONSTRAINT "standing_orders_status_check" CHECK ("status" IN ('active', 'paused', 'completed', 'cancelled'))
+);
+
+CREATE TABLE "holidays" (
+  "date" date PRIMARY KEY,
+  "name" varchar NOT NULL
+);
+
+ALTER TABLE "scheduled_transfers" ADD COLUMN "standing_order_id" bigint;
+
+ALTER TABLE "scheduled_transfers" ADD COLUMN "occurrence_date" date;
+
+CREATE INDEX ON "standing_orders" ("username");
+
+CREATE INDEX ON "standing_orders" ("next_run_date") WHERE "status" = 'active';
+
+CREATE UNIQUE INDEX ON "scheduled_transfers" ("standing_order_id", "occurrence_date");
+
+COMMENT ON COLUMN "standing_orders"."weekday" IS '0 is Sunday';
+
+COMMENT ON COLUMN "standing_orders"."next_nominal_date" IS 'next date the rule produces, before moving it onto a business day';
+
+COMMENT ON COLUMN "scheduled_transfers"."occurrence_date" IS 'nominal date of the standing order occurrence';
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("standing_order_id") REFERENCES "standing_orders" ("id");
@@ -0,0 +1,2 @@
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reversal_of_transfer_id";
@@ -0,0 +1,11 @@
+ALTER TABLE "transfers" ADD COLUMN "reversal_of_transfer_id" bigint;
+
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'customer';
+
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('customer', 'staff'));
+
+CREATE INDEX ON "transfers" ("reversal_of_transfer_id");
+
+COMMENT ON COLUMN "transfers"."reversal_of_transfer_id" IS 'transfer this one (partially) reverses';
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of_transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,47 @@
+CREATE TABLE "transfer_batches" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "mode" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "item_count" integer NOT NULL,
+  "total_amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "completed_at" timestamptz,
+  CONSTRAINT "transfer_batches_mode_check" CHECK ("mode" IN ('atomic', 'per_item')),
+  CONSTRAINT "transfer_batches_status_check" CHECK ("status" IN ('pending', 'processing', 'completed', 'partially_completed', 'failed'))
+);
+
+CREATE TABLE "transfer_batch_items" (
+  "id" bigserial PRIMARY KEY,
+  "batch_id" bigint NOT NULL,
+  "line" integer NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "reference" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "reason" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "transfer_batch_items_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "transfer_batch_items_status_check" CHECK ("status" IN ('pending', 'completed', 'failed'))
+);
+
+CREATE INDEX ON "transfer_batches" ("username");
+
+CREATE UNIQUE INDEX ON "transfer_batch_items" ("batch_id", "line");
+
+COMMENT ON COLUMN "transfer_batches"."mode" IS 'atomic runs every item in one transaction, per_item runs each on its own';
+
+COMMENT ON COLUMN "transfer_batch_items"."line" IS 'position of the item in the uploaded file, starting at 1';
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("batch_id") REFERENCES "transfer_batches" ("id");
+
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,2 @@
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "transfer_group_id";
+DROP TABLE IF EXISTS "transfer_groups";
@@ -0,0 +1,16 @@
+CREATE TABLE "transfer_groups" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "total_amount" bigint NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "transfer_groups_total_amount_check" CHECK ("total_amount" > 0)
+);
+
+ALTER TABLE "entries" ADD COLUMN "transfer_group_id" bigint;
+
+CREATE INDEX ON "entries" ("transfer_group_id");
+
+COMMENT ON COLUMN "transfer_groups"."total_amount" IS 'sum of the debit legs, which equals the sum of the credit legs';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_group_id") REFERENCES "transfer_groups" ("id");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "pending_transfer_approvals";
+DROP TABLE IF EXISTS "pending_transfers";
@@ -0,0 +1,45 @@
+CREATE TABLE "pending_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "created_by" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "expires_at" timestamptz NOT NULL,
+  "transfer_id" bigint,
+  "decided_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "pending_transfers_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "pending_transfers_status_check" CHECK ("status" IN ('pending', 'approved', 'rejected', 'expired'))
+);
+
+CREATE TABLE "pending_transfer_approvals" (
+  "id" bigserial PRIMARY KEY,
+  "pending_transfer_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "decision" varchar NOT NULL,
+  "comment" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "pending_transfer_approvals_decision_check" CHECK ("decision" IN ('approved', 'rejected'))
+);
+
+CREATE INDEX ON "pending_transfers" ("created_by");
+
+CREATE INDEX ON "pending_transfers" ("expires_at") WHERE "status" = 'pending';
+
+CREATE INDEX ON "pending_transfer_approvals" ("pending_transfer_id");
+
+COMMENT ON COLUMN "pending_transfers"."created_by" IS 'maker of the request, who can never approve it';
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "pending_transfer_approvals" ADD FOREIGN KEY ("pending_transfer_id") REFERENCES "pending_transfers" ("id");
+
+ALTER TABLE "pending_transfer_approvals" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
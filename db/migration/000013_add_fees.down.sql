@@ -0,0 +1,6 @@
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "fee_transfer_id";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "fee";
+DROP TABLE IF EXISTS "fee_rules";
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bankfee');
+DELETE FROM "accounts" WHERE "owner" = 'bankfee';
+DELETE FROM "users" WHERE "username" = 'bankfee';
@@ -0,0 +1,35 @@
+CREATE TABLE "fee_rules" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "cross_currency" boolean NOT NULL DEFAULT false,
+  "flat_amount" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" integer NOT NULL DEFAULT 0,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "max_amount" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "fee_rules_amounts_check" CHECK ("flat_amount" >= 0 AND "min_amount" >= 0 AND ("max_amount" IS NULL OR "max_amount" >= "min_amount")),
+  CONSTRAINT "fee_rules_percentage_bps_check" CHECK ("percentage_bps" BETWEEN 0 AND 10000)
+);
+
+CREATE UNIQUE INDEX ON "fee_rules" ("currency", "cross_currency");
+
+COMMENT ON COLUMN "fee_rules"."currency" IS 'currency of the source account; fees are charged in it';
+
+COMMENT ON COLUMN "fee_rules"."percentage_bps" IS 'percentage of the amount in basis points, added to flat_amount';
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "entries" ADD COLUMN "fee_transfer_id" bigint;
+
+COMMENT ON COLUMN "entries"."fee_transfer_id" IS 'set on the two entries that post the fee of a transfer';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("fee_transfer_id") REFERENCES "transfers" ("id");
+
+-- The bank's fee income accounts, one per currency, are credited with the
+-- fee of every transfer.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('bankfee', '!', 'Bank fee income', 'bankfee@digi-bank.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "account_number")
+SELECT 'bankfee', 0, c, next_account_number()
+FROM unnest(ARRAY['USD', 'EUR', 'VND']) AS c;
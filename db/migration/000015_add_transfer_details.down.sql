@@ -0,0 +1,7 @@
+ALTER TABLE "pending_transfers" DROP COLUMN IF EXISTS "category";
+ALTER TABLE "pending_transfers" DROP COLUMN IF EXISTS "reference";
+ALTER TABLE "pending_transfers" DROP COLUMN IF EXISTS "memo";
+DROP INDEX IF EXISTS "transfers_memo_search_idx";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "category";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reference";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "memo";
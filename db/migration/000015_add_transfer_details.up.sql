@@ -0,0 +1,15 @@
+ALTER TABLE "transfers" ADD COLUMN "memo" varchar NOT NULL DEFAULT '';
+ALTER TABLE "transfers" ADD COLUMN "reference" varchar;
+ALTER TABLE "transfers" ADD COLUMN "category" varchar NOT NULL DEFAULT 'general';
+
+COMMENT ON COLUMN "transfers"."memo" IS 'free text from the sender, searchable';
+
+COMMENT ON COLUMN "transfers"."reference" IS 'reference supplied by the client, unique per source account';
+
+CREATE UNIQUE INDEX ON "transfers" ("from_account_id", "reference") WHERE "reference" IS NOT NULL;
+
+CREATE INDEX "transfers_memo_search_idx" ON "transfers" USING GIN (to_tsvector('simple', "memo"));
+
+ALTER TABLE "pending_transfers" ADD COLUMN "memo" varchar NOT NULL DEFAULT '';
+ALTER TABLE "pending_transfers" ADD COLUMN "reference" varchar;
+ALTER TABLE "pending_transfers" ADD COLUMN "category" varchar NOT NULL DEFAULT 'general';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "transfers_queued_idx";
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_status_check";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status_changed_at";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "failure_reason";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,10 @@
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'completed';
+ALTER TABLE "transfers" ADD COLUMN "failure_reason" varchar NOT NULL DEFAULT '';
+ALTER TABLE "transfers" ADD COLUMN "status_changed_at" timestamptz NOT NULL DEFAULT (now());
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_status_check"
+  CHECK ("status" IN ('pending', 'processing', 'completed', 'failed', 'reversed'));
+
+COMMENT ON COLUMN "transfers"."status" IS 'pending and processing transfers have no entries yet';
+
+CREATE INDEX "transfers_queued_idx" ON "transfers" ("id") WHERE "status" = 'pending';
@@ -0,0 +1,29 @@
+CREATE TABLE "payment_requests" (
+  "id" bigserial PRIMARY KEY,
+  "requester_account_id" bigint NOT NULL,
+  "payer" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "note" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "expires_at" timestamptz NOT NULL,
+  "transfer_id" bigint,
+  "decided_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "payment_requests_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "payment_requests_status_check" CHECK ("status" IN ('pending', 'paid', 'declined'))
+);
+
+CREATE INDEX ON "payment_requests" ("payer");
+
+CREATE INDEX ON "payment_requests" ("requester_account_id");
+
+COMMENT ON COLUMN "payment_requests"."requester_account_id" IS 'account the money is paid into';
+
+COMMENT ON COLUMN "payment_requests"."transfer_id" IS 'set once the request is paid';
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("requester_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("payer") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "transfer_confirmations";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email_verified_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "phone";
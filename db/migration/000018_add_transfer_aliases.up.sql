@@ -0,0 +1,35 @@
+ALTER TABLE "users" ADD COLUMN "phone" varchar UNIQUE;
+ALTER TABLE "users" ADD COLUMN "email_verified_at" timestamptz;
+
+COMMENT ON COLUMN "users"."phone" IS 'E.164 phone number, usable as a transfer alias';
+
+COMMENT ON COLUMN "users"."email_verified_at" IS 'only verified emails can be used as a transfer alias';
+
+CREATE TABLE "transfer_confirmations" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "alias" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "memo" varchar NOT NULL DEFAULT '',
+  "reference" varchar,
+  "category" varchar NOT NULL DEFAULT 'general',
+  "expires_at" timestamptz NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "transfer_confirmations_amount_check" CHECK ("amount" > 0)
+);
+
+COMMENT ON TABLE "transfer_confirmations" IS 'transfers to an alias, waiting for the sender to confirm the recipient';
+
+COMMENT ON COLUMN "transfer_confirmations"."transfer_id" IS 'set once confirmed; a confirmation can only be used once';
+
+ALTER TABLE "transfer_confirmations" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_confirmations" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_confirmations" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_confirmations" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,3 @@
+ALTER TABLE "pending_transfers" DROP COLUMN IF EXISTS "risk_decision_id";
+DROP TABLE IF EXISTS "risk_decisions";
+DROP TABLE IF EXISTS "risk_rules";
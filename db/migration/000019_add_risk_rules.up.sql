@@ -0,0 +1,64 @@
+CREATE TABLE "risk_rules" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar UNIQUE NOT NULL,
+  "kind" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "currency" varchar,
+  "max_count" integer NOT NULL DEFAULT 0,
+  "window_minutes" integer NOT NULL DEFAULT 0,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "multiplier" integer NOT NULL DEFAULT 0,
+  "enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "risk_rules_kind_check" CHECK ("kind" IN ('velocity', 'new_recipient', 'above_average', 'after_password_change')),
+  CONSTRAINT "risk_rules_action_check" CHECK ("action" IN ('review', 'block')),
+  CONSTRAINT "risk_rules_params_check" CHECK ("max_count" >= 0 AND "window_minutes" >= 0 AND "min_amount" >= 0 AND "multiplier" >= 0)
+);
+
+CREATE TABLE "risk_decisions" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "decision" varchar NOT NULL,
+  "fired_rules" varchar[] NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "risk_decisions_decision_check" CHECK ("decision" IN ('allow', 'review', 'block'))
+);
+
+CREATE INDEX ON "risk_decisions" ("from_account_id", "created_at");
+
+COMMENT ON COLUMN "risk_rules"."currency" IS 'the rule only applies to transfers in this currency; NULL for all';
+
+COMMENT ON COLUMN "risk_rules"."max_count" IS 'velocity: transfers allowed in the window; above_average: history needed before the rule applies';
+
+COMMENT ON COLUMN "risk_rules"."window_minutes" IS 'velocity: length of the window; after_password_change: how long after the change the rule applies';
+
+COMMENT ON COLUMN "risk_rules"."min_amount" IS 'the rule only fires for transfers of at least this amount';
+
+COMMENT ON COLUMN "risk_rules"."multiplier" IS 'above_average: fires when the amount exceeds the average by this factor';
+
+COMMENT ON COLUMN "risk_decisions"."fired_rules" IS 'names of the rules that fired, in the order they were checked';
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "pending_transfers" ADD COLUMN "risk_decision_id" bigint;
+
+COMMENT ON COLUMN "pending_transfers"."risk_decision_id" IS 'set when the transfer was held for review by the risk engine';
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("risk_decision_id") REFERENCES "risk_decisions" ("id");
+
+INSERT INTO "risk_rules" ("name", "kind", "action", "currency", "max_count", "window_minutes", "min_amount", "multiplier") VALUES
+  ('velocity_review', 'velocity', 'review', NULL, 5, 10, 0, 0),
+  ('velocity_block', 'velocity', 'block', NULL, 20, 10, 0, 0),
+  ('new_recipient_usd', 'new_recipient', 'review', 'USD', 0, 0, 200000, 0),
+  ('new_recipient_eur', 'new_recipient', 'review', 'EUR', 0, 0, 200000, 0),
+  ('new_recipient_vnd', 'new_recipient', 'review', 'VND', 0, 0, 5000000000, 0),
+  ('above_average', 'above_average', 'review', NULL, 5, 0, 0, 10),
+  ('after_password_change', 'after_password_change', 'review', NULL, 0, 1440, 0, 0);
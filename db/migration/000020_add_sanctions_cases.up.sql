@@ -0,0 +1,38 @@
+CREATE TABLE "sanctions_cases" (
+  "id" bigserial PRIMARY KEY,
+  "operation" varchar NOT NULL,
+  "initiated_by" varchar NOT NULL,
+  "screened_name" varchar NOT NULL,
+  "screened_username" varchar NOT NULL,
+  "account_id" bigint,
+  "list_entry_id" varchar NOT NULL,
+  "list_entry_name" varchar NOT NULL,
+  "score" double precision NOT NULL,
+  "action" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'open',
+  "resolved_by" varchar,
+  "resolution_note" varchar NOT NULL DEFAULT '',
+  "resolved_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "sanctions_cases_operation_check" CHECK ("operation" IN ('create_user', 'transfer')),
+  CONSTRAINT "sanctions_cases_action_check" CHECK ("action" IN ('flag', 'block')),
+  CONSTRAINT "sanctions_cases_status_check" CHECK ("status" IN ('open', 'cleared'))
+);
+
+CREATE INDEX ON "sanctions_cases" ("status", "created_at");
+
+CREATE INDEX ON "sanctions_cases" ("screened_name", "list_entry_id") WHERE "status" = 'cleared';
+
+COMMENT ON COLUMN "sanctions_cases"."initiated_by" IS 'user who made the request; not a foreign key because a blocked user is never created';
+
+COMMENT ON COLUMN "sanctions_cases"."screened_username" IS 'user whose full name matched the list';
+
+COMMENT ON COLUMN "sanctions_cases"."account_id" IS 'counterparty account of a transfer';
+
+COMMENT ON COLUMN "sanctions_cases"."action" IS 'flag lets the operation go on or holds it for review; block refuses it';
+
+COMMENT ON COLUMN "sanctions_cases"."status" IS 'cleared cases are false positives; the same name no longer matches the same entry';
+
+ALTER TABLE "sanctions_cases" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "sanctions_cases" ADD FOREIGN KEY ("resolved_by") REFERENCES "users" ("username");
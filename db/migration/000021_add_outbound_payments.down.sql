@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS "outbound_payments";
+DROP TABLE IF EXISTS "ach_files";
+DROP TABLE IF EXISTS "beneficiaries";
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bankclearing');
+DELETE FROM "transfers" WHERE "from_account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bankclearing')
+  OR "to_account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bankclearing');
+DELETE FROM "accounts" WHERE "owner" = 'bankclearing';
+DELETE FROM "users" WHERE "username" = 'bankclearing';
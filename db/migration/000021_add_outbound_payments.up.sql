@@ -0,0 +1,77 @@
+CREATE TABLE "beneficiaries" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "routing_number" varchar NOT NULL,
+  "account_number" varchar NOT NULL,
+  "account_type" varchar NOT NULL DEFAULT 'checking',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "beneficiaries_routing_number_check" CHECK ("routing_number" ~ '^[0-9]{9}$'),
+  CONSTRAINT "beneficiaries_account_number_check" CHECK ("account_number" ~ '^[0-9A-Za-z-]{1,17}$'),
+  CONSTRAINT "beneficiaries_account_type_check" CHECK ("account_type" IN ('checking', 'savings'))
+);
+
+CREATE TABLE "ach_files" (
+  "id" bigserial PRIMARY KEY,
+  "file_name" varchar UNIQUE NOT NULL,
+  "file_id_modifier" varchar NOT NULL,
+  "entry_count" integer NOT NULL,
+  "total_amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "outbound_payments" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint UNIQUE NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "beneficiary_id" bigint NOT NULL,
+  "beneficiary_name" varchar NOT NULL,
+  "routing_number" varchar NOT NULL,
+  "account_number" varchar NOT NULL,
+  "account_type" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "ach_file_id" bigint,
+  "trace_number" varchar UNIQUE,
+  "return_code" varchar,
+  "return_transfer_id" bigint,
+  "exported_at" timestamptz,
+  "returned_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "outbound_payments_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "outbound_payments_status_check" CHECK ("status" IN ('pending', 'exported', 'returned'))
+);
+
+CREATE UNIQUE INDEX ON "beneficiaries" ("owner", "routing_number", "account_number");
+
+CREATE INDEX ON "outbound_payments" ("id") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "beneficiaries"."name" IS 'account holder at the receiving bank, as sent in the ACH entry';
+
+COMMENT ON COLUMN "outbound_payments"."transfer_id" IS 'debit of the customer into the clearing suspense account';
+
+COMMENT ON COLUMN "outbound_payments"."beneficiary_name" IS 'beneficiary details are copied so later edits never change a payment';
+
+COMMENT ON COLUMN "outbound_payments"."trace_number" IS 'ACH trace number, set when the payment is exported';
+
+COMMENT ON COLUMN "outbound_payments"."return_transfer_id" IS 'reversal that credited the customer back when the receiving bank returned the payment';
+
+ALTER TABLE "beneficiaries" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "outbound_payments" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "outbound_payments" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "outbound_payments" ADD FOREIGN KEY ("beneficiary_id") REFERENCES "beneficiaries" ("id");
+
+ALTER TABLE "outbound_payments" ADD FOREIGN KEY ("ach_file_id") REFERENCES "ach_files" ("id");
+
+ALTER TABLE "outbound_payments" ADD FOREIGN KEY ("return_transfer_id") REFERENCES "transfers" ("id");
+
+-- Outbound payments are debited into the clearing suspense account until
+-- they settle at the receiving bank. ACH only carries US dollars.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('bankclearing', '!', 'Bank clearing suspense', 'bankclearing@digi-bank.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "account_number")
+VALUES ('bankclearing', 0, 'USD', next_account_number());
@@ -0,0 +1,4 @@
+ALTER TABLE "transfer_batch_items" DROP COLUMN IF EXISTS "end_to_end_id";
+ALTER TABLE "transfer_batches" DROP COLUMN IF EXISTS "payment_information_id";
+ALTER TABLE "transfer_batches" DROP COLUMN IF EXISTS "message_id";
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "transfer_id";
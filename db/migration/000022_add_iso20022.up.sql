@@ -0,0 +1,19 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer the entry posts; null on entries written before it was recorded';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_batches" ADD COLUMN "message_id" varchar;
+
+ALTER TABLE "transfer_batches" ADD COLUMN "payment_information_id" varchar;
+
+COMMENT ON COLUMN "transfer_batches"."message_id" IS 'MsgId of the pain.001 message the batch was imported from';
+
+COMMENT ON COLUMN "transfer_batches"."payment_information_id" IS 'PmtInfId of the payment information block the batch was imported from';
+
+CREATE UNIQUE INDEX ON "transfer_batches" ("username", "message_id", "payment_information_id") WHERE "message_id" IS NOT NULL;
+
+ALTER TABLE "transfer_batch_items" ADD COLUMN "end_to_end_id" varchar NOT NULL DEFAULT '';
@@ -0,0 +1,164 @@
+CREATE TABLE "journal_entries" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint,
+  "transfer_group_id" bigint,
+  "description" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "postings" (
+  "id" bigserial PRIMARY KEY,
+  "journal_entry_id" bigint NOT NULL,
+  "entry_id" bigint NOT NULL,
+  "account_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "amount" bigint NOT NULL
+);
+
+CREATE UNIQUE INDEX ON "journal_entries" ("transfer_id");
+
+CREATE UNIQUE INDEX ON "journal_entries" ("transfer_group_id");
+
+CREATE INDEX ON "postings" ("journal_entry_id");
+
+CREATE UNIQUE INDEX ON "postings" ("entry_id");
+
+CREATE INDEX ON "postings" ("account_id");
+
+COMMENT ON COLUMN "journal_entries"."transfer_id" IS 'transfer the journal entry posts, with its fee';
+
+COMMENT ON COLUMN "journal_entries"."transfer_group_id" IS 'transfer group the journal entry posts';
+
+COMMENT ON COLUMN "postings"."entry_id" IS 'entry of the account the posting is booked as';
+
+COMMENT ON COLUMN "postings"."currency" IS 'currency of the account; the postings of a journal entry sum to zero in every currency';
+
+ALTER TABLE "journal_entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "journal_entries" ADD FOREIGN KEY ("transfer_group_id") REFERENCES "transfer_groups" ("id");
+
+ALTER TABLE "postings" ADD FOREIGN KEY ("journal_entry_id") REFERENCES "journal_entries" ("id");
+
+ALTER TABLE "postings" ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+
+ALTER TABLE "postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+-- Tie the entries written before entries.transfer_id to their transfers. The
+-- entries of a transfer were written in its transaction, so they share its
+-- created_at; identical transfers of one transaction are paired with their
+-- entries in id order. A transfer is only tied when both of its entries are
+-- found. Cross-currency transfers, whose legs go through the FX position
+-- accounts, are left out.
+WITH "legs" AS (
+  SELECT "id" AS "transfer_id", "created_at", "from_account_id" AS "account_id", -"amount" AS "amount"
+  FROM "transfers"
+  WHERE "status" IN ('completed', 'reversed')
+    AND NOT EXISTS (SELECT 1 FROM "entries" WHERE "entries"."transfer_id" = "transfers"."id")
+    AND NOT EXISTS (SELECT 1 FROM "fx_quotes" WHERE "fx_quotes"."transfer_id" = "transfers"."id")
+  UNION ALL
+  SELECT "id", "created_at", "to_account_id", "amount"
+  FROM "transfers"
+  WHERE "status" IN ('completed', 'reversed')
+    AND NOT EXISTS (SELECT 1 FROM "entries" WHERE "entries"."transfer_id" = "transfers"."id")
+    AND NOT EXISTS (SELECT 1 FROM "fx_quotes" WHERE "fx_quotes"."transfer_id" = "transfers"."id")
+), "numbered_legs" AS (
+  SELECT *, row_number() OVER (PARTITION BY "created_at", "account_id", "amount" ORDER BY "transfer_id") AS "n"
+  FROM "legs"
+), "numbered_entries" AS (
+  SELECT "id", "created_at", "account_id", "amount",
+    row_number() OVER (PARTITION BY "created_at", "account_id", "amount" ORDER BY "id") AS "n"
+  FROM "entries"
+  WHERE "transfer_id" IS NULL AND "fee_transfer_id" IS NULL AND "transfer_group_id" IS NULL
+), "matches" AS (
+  SELECT "numbered_entries"."id" AS "entry_id", "numbered_legs"."transfer_id"
+  FROM "numbered_entries" JOIN "numbered_legs" USING ("created_at", "account_id", "amount", "n")
+)
+UPDATE "entries" SET "transfer_id" = "matches"."transfer_id"
+FROM "matches"
+WHERE "entries"."id" = "matches"."entry_id"
+  AND "matches"."transfer_id" IN (SELECT "transfer_id" FROM "matches" GROUP BY "transfer_id" HAVING count(*) = 2);
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer the entry posts; null on entries written before it was recorded that could not be tied to their transfer';
+
+-- One journal entry per transfer, holding the entries of the transfer and of
+-- its fee, and one per transfer group.
+INSERT INTO "journal_entries" ("transfer_id", "created_at")
+SELECT "id", COALESCE("created_at", now())
+FROM "transfers"
+WHERE EXISTS (
+  SELECT 1 FROM "entries"
+  WHERE "entries"."transfer_id" = "transfers"."id" OR "entries"."fee_transfer_id" = "transfers"."id"
+)
+ORDER BY "id";
+
+INSERT INTO "journal_entries" ("transfer_group_id", "description", "created_at")
+SELECT "id", "description", "created_at"
+FROM "transfer_groups"
+ORDER BY "id";
+
+INSERT INTO "postings" ("journal_entry_id", "entry_id", "account_id", "currency", "amount")
+SELECT "journal_entries"."id", "entries"."id", "entries"."account_id", "accounts"."currency", "entries"."amount"
+FROM "entries"
+JOIN "accounts" ON "accounts"."id" = "entries"."account_id"
+JOIN "journal_entries" ON "journal_entries"."transfer_id" = COALESCE("entries"."transfer_id", "entries"."fee_transfer_id")
+  OR "journal_entries"."transfer_group_id" = "entries"."transfer_group_id"
+ORDER BY "entries"."id";
+
+-- Whatever is left could not be tied to what it posts. It should balance as
+-- a whole, so it goes into one journal entry of its own.
+WITH "journal" AS (
+  INSERT INTO "journal_entries" ("description")
+  SELECT 'entries written before journal entries that could not be tied to their transfer'
+  WHERE EXISTS (
+    SELECT 1 FROM "entries"
+    WHERE "account_id" IS NOT NULL
+      AND NOT EXISTS (SELECT 1 FROM "postings" WHERE "postings"."entry_id" = "entries"."id")
+  )
+  RETURNING "id"
+)
+INSERT INTO "postings" ("journal_entry_id", "entry_id", "account_id", "currency", "amount")
+SELECT "journal"."id", "entries"."id", "entries"."account_id", "accounts"."currency", "entries"."amount"
+FROM "journal", "entries"
+JOIN "accounts" ON "accounts"."id" = "entries"."account_id"
+WHERE NOT EXISTS (SELECT 1 FROM "postings" WHERE "postings"."entry_id" = "entries"."id")
+ORDER BY "entries"."id";
+
+-- check_journal_balanced rejects a change to the postings that leaves a
+-- journal entry not summing to zero in some currency. It runs at commit, so a
+-- transaction may write the postings of a journal entry one at a time.
+CREATE OR REPLACE FUNCTION check_journal_balanced() RETURNS trigger AS $$
+DECLARE
+  journal_ids bigint[];
+  journal_id bigint;
+  unbalanced record;
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    journal_ids := ARRAY[NEW."journal_entry_id"];
+  ELSIF TG_OP = 'UPDATE' THEN
+    journal_ids := ARRAY[NEW."journal_entry_id", OLD."journal_entry_id"];
+  ELSE
+    journal_ids := ARRAY[OLD."journal_entry_id"];
+  END IF;
+  FOREACH journal_id IN ARRAY journal_ids LOOP
+    SELECT "currency", sum("amount") AS "total" INTO unbalanced
+    FROM "postings"
+    WHERE "journal_entry_id" = journal_id
+    GROUP BY "currency"
+    HAVING sum("amount") <> 0
+    LIMIT 1;
+    IF FOUND THEN
+      RAISE EXCEPTION 'journal entry % does not balance: its % postings sum to %',
+        journal_id, unbalanced."currency", unbalanced."total"
+        USING ERRCODE = 'check_violation', CONSTRAINT = 'postings_balanced';
+    END IF;
+  END LOOP;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- The backfill is not checked here: journal entries of legacy entries that
+-- don't balance are posted to a suspense account by a later migration.
+CREATE CONSTRAINT TRIGGER "postings_balanced"
+AFTER INSERT OR UPDATE OR DELETE ON "postings"
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION check_journal_balanced();
@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS "reconciliation_discrepancies";
+DROP TABLE IF EXISTS "reconciliation_runs";
+-- take the adjustments back out of the ledger, whole journal entries at a time
+WITH "adjustments" AS (
+  DELETE FROM "postings"
+  WHERE "journal_entry_id" IN (
+    SELECT "journal_entry_id" FROM "postings"
+    WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bankadjustment')
+  )
+  RETURNING "journal_entry_id", "entry_id"
+), "journal_entries" AS (
+  DELETE FROM "journal_entries" WHERE "id" IN (SELECT "journal_entry_id" FROM "adjustments")
+)
+DELETE FROM "entries" WHERE "id" IN (SELECT "entry_id" FROM "adjustments");
+DELETE FROM "accounts" WHERE "owner" = 'bankadjustment';
+DELETE FROM "users" WHERE "username" = 'bankadjustment';
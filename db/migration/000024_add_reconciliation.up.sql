@@ -0,0 +1,49 @@
+CREATE TABLE "reconciliation_runs" (
+  "id" bigserial PRIMARY KEY,
+  "repair" boolean NOT NULL DEFAULT false,
+  "accounts_checked" bigint NOT NULL,
+  "discrepancies" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "reconciliation_discrepancies" (
+  "id" bigserial PRIMARY KEY,
+  "run_id" bigint NOT NULL,
+  "account_id" bigint,
+  "currency" varchar NOT NULL,
+  "balance" bigint NOT NULL,
+  "entries_total" bigint NOT NULL,
+  "difference" bigint NOT NULL,
+  "journal_entry_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "reconciliation_discrepancies" ("run_id");
+
+CREATE INDEX ON "reconciliation_discrepancies" ("account_id");
+
+COMMENT ON COLUMN "reconciliation_runs"."discrepancies" IS 'accounts whose balance differs from the sum of their entries';
+
+COMMENT ON COLUMN "reconciliation_discrepancies"."account_id" IS 'null on the grand total of the currency';
+
+COMMENT ON COLUMN "reconciliation_discrepancies"."balance" IS 'balance of the account, or of all the accounts in the currency';
+
+COMMENT ON COLUMN "reconciliation_discrepancies"."difference" IS 'balance minus entries_total';
+
+COMMENT ON COLUMN "reconciliation_discrepancies"."journal_entry_id" IS 'adjustment posted by a repair run to bring the entries in line with the balance';
+
+ALTER TABLE "reconciliation_discrepancies" ADD FOREIGN KEY ("run_id") REFERENCES "reconciliation_runs" ("id");
+
+ALTER TABLE "reconciliation_discrepancies" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "reconciliation_discrepancies" ADD FOREIGN KEY ("journal_entry_id") REFERENCES "journal_entries" ("id");
+
+-- The bank's ledger adjustment accounts, one per currency, take the other
+-- side of the adjustments a repair run posts, until someone finds out where
+-- the difference came from.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('bankadjustment', '!', 'Bank ledger adjustments', 'bankadjustment@digi-bank.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "account_number")
+SELECT 'bankadjustment', 0, c, next_account_number()
+FROM unnest(ARRAY['USD', 'EUR', 'VND']) AS c;
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS "entries_chain" ON "entries";
+DROP FUNCTION IF EXISTS chain_entry();
+DROP FUNCTION IF EXISTS entry_chain_text("entries");
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "last_entry_hash";
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "last_entry_seq";
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "hash";
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "chain_seq";
@@ -0,0 +1,86 @@
+ALTER TABLE "entries" ADD COLUMN "chain_seq" bigint;
+
+ALTER TABLE "entries" ADD COLUMN "hash" bytea;
+
+ALTER TABLE "accounts" ADD COLUMN "last_entry_seq" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD COLUMN "last_entry_hash" bytea;
+
+COMMENT ON COLUMN "entries"."chain_seq" IS 'position of the entry in the hash chain of its account, from 1';
+
+COMMENT ON COLUMN "entries"."hash" IS 'SHA-256 of the hash of the previous entry of the account and the contents of this one; see ledger.EntryHash';
+
+COMMENT ON COLUMN "accounts"."last_entry_seq" IS 'chain_seq of the latest entry of the account';
+
+COMMENT ON COLUMN "accounts"."last_entry_hash" IS 'hash of the latest entry of the account, the head of its chain';
+
+-- entry_chain_text is what the hash of an entry covers besides the hash of
+-- the previous entry. ledger.EntryHash must write exactly the same.
+CREATE OR REPLACE FUNCTION entry_chain_text(e "entries") RETURNS text AS $$
+  SELECT concat_ws('|',
+    coalesce(e."account_id"::text, ''),
+    coalesce(e."chain_seq"::text, ''),
+    e."id"::text,
+    e."amount"::text,
+    coalesce(to_char(e."created_at" AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'), ''),
+    coalesce(e."transfer_id"::text, ''),
+    coalesce(e."transfer_group_id"::text, ''),
+    coalesce(e."fee_transfer_id"::text, ''));
+$$ LANGUAGE sql STABLE;
+
+-- Chain the entries of every account, oldest first.
+DO $$
+DECLARE
+  e "entries";
+  prev_account_id bigint;
+  seq bigint;
+  prev_hash bytea;
+BEGIN
+  FOR e IN SELECT * FROM "entries" WHERE "account_id" IS NOT NULL ORDER BY "account_id", "id" LOOP
+    IF e."account_id" IS DISTINCT FROM prev_account_id THEN
+      prev_account_id := e."account_id";
+      seq := 0;
+      prev_hash := ''::bytea;
+    END IF;
+    seq := seq + 1;
+    e."chain_seq" := seq;
+    prev_hash := sha256(prev_hash || convert_to(entry_chain_text(e), 'UTF8'));
+    UPDATE "entries" SET "chain_seq" = seq, "hash" = prev_hash WHERE "id" = e."id";
+    UPDATE "accounts" SET "last_entry_seq" = seq, "last_entry_hash" = prev_hash WHERE "id" = e."account_id";
+  END LOOP;
+END;
+$$;
+
+CREATE UNIQUE INDEX ON "entries" ("account_id", "chain_seq");
+
+-- chain_entry links a new entry to the head of the chain of its account. It
+-- updates the account, so writers of entries to the same account take turns,
+-- and a transaction that read an older head fails to serialize rather than
+-- forking the chain. Entries without an account belong to no chain.
+CREATE OR REPLACE FUNCTION chain_entry() RETURNS trigger AS $$
+DECLARE
+  head record;
+BEGIN
+  IF NEW."account_id" IS NULL THEN
+    RETURN NEW;
+  END IF;
+  SELECT "last_entry_seq", "last_entry_hash" INTO head
+  FROM "accounts"
+  WHERE "id" = NEW."account_id"
+  FOR NO KEY UPDATE;
+  IF NOT FOUND THEN
+    -- the foreign key rejects the entry
+    RETURN NEW;
+  END IF;
+  NEW."chain_seq" := head."last_entry_seq" + 1;
+  NEW."hash" := sha256(coalesce(head."last_entry_hash", ''::bytea) || convert_to(entry_chain_text(NEW), 'UTF8'));
+  UPDATE "accounts"
+  SET "last_entry_seq" = NEW."chain_seq", "last_entry_hash" = NEW."hash"
+  WHERE "id" = NEW."account_id";
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "entries_chain"
+BEFORE INSERT ON "entries"
+FOR EACH ROW EXECUTE FUNCTION chain_entry();
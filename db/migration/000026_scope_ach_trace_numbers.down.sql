@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "outbound_payments_trace_number_idx";
+DROP INDEX IF EXISTS "outbound_payments_trace_date_trace_number_idx";
+ALTER TABLE "outbound_payments" ADD CONSTRAINT "outbound_payments_trace_number_key" UNIQUE ("trace_number");
+ALTER TABLE "outbound_payments" DROP COLUMN IF EXISTS "trace_date";
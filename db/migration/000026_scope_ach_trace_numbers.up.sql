@@ -0,0 +1,15 @@
+-- Trace numbers only have seven digits after the bank's prefix, so they
+-- restart every day instead of being unique forever.
+ALTER TABLE "outbound_payments" ADD COLUMN "trace_date" date;
+
+UPDATE "outbound_payments"
+SET "trace_date" = ("exported_at" AT TIME ZONE 'UTC')::date
+WHERE "trace_number" IS NOT NULL;
+
+ALTER TABLE "outbound_payments" DROP CONSTRAINT "outbound_payments_trace_number_key";
+
+CREATE UNIQUE INDEX ON "outbound_payments" ("trace_date", "trace_number");
+
+CREATE INDEX ON "outbound_payments" ("trace_number");
+
+COMMENT ON COLUMN "outbound_payments"."trace_date" IS 'day (UTC) of the ACH file; trace numbers are unique within a day';
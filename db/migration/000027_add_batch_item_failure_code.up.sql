@@ -0,0 +1,17 @@
+ALTER TABLE "transfer_batch_items" ADD COLUMN "failure_code" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfer_batch_items" ADD CONSTRAINT "transfer_batch_items_failure_code_check"
+  CHECK ("failure_code" IN ('', 'insufficient_funds', 'account_frozen', 'currency_mismatch', 'account_not_found', 'other'));
+
+-- Items that failed before the code was recorded only have the error text.
+UPDATE "transfer_batch_items"
+SET "failure_code" = CASE
+  WHEN "reason" LIKE '%insufficient funds%' THEN 'insufficient_funds'
+  WHEN "reason" LIKE '%account is frozen%' THEN 'account_frozen'
+  WHEN "reason" LIKE '%currency mismatch%' THEN 'currency_mismatch'
+  WHEN "reason" LIKE '%no rows%' OR "reason" LIKE '%referenced record%' THEN 'account_not_found'
+  ELSE 'other'
+END
+WHERE "status" = 'failed';
+
+COMMENT ON COLUMN "transfer_batch_items"."failure_code" IS 'why the item failed, for reports; reason holds the error text';
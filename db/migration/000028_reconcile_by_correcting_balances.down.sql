@@ -0,0 +1,2 @@
+ALTER TABLE "reconciliation_discrepancies" DROP COLUMN IF EXISTS "corrected";
+COMMENT ON COLUMN "reconciliation_discrepancies"."journal_entry_id" IS 'adjustment posted by a repair run to bring the entries in line with the balance';
@@ -0,0 +1,8 @@
+-- Repair runs set the balance of an account that drifted back to the sum of
+-- its entries, instead of posting an adjustment that would make whatever
+-- overwrote the balance part of the ledger.
+ALTER TABLE "reconciliation_discrepancies" ADD COLUMN "corrected" boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "reconciliation_discrepancies"."corrected" IS 'a repair run set the balance of the account to entries_total';
+
+COMMENT ON COLUMN "reconciliation_discrepancies"."journal_entry_id" IS 'adjustment posted by a repair run before repairs corrected the balance instead';
@@ -0,0 +1,47 @@
+-- The older schema can only hold transfers between two accounts.
+UPDATE "transfer_confirmations" SET "pending_transfer_id" = NULL
+WHERE "pending_transfer_id" IN (SELECT "id" FROM "pending_transfers" WHERE "kind" <> 'transfer');
+
+DELETE FROM "pending_transfer_approvals"
+WHERE "pending_transfer_id" IN (SELECT "id" FROM "pending_transfers" WHERE "kind" <> 'transfer');
+
+DELETE FROM "pending_transfers" WHERE "kind" <> 'transfer';
+
+UPDATE "transfer_batches" SET "status" = 'failed' WHERE "status" IN ('held', 'rejected');
+
+UPDATE "scheduled_transfers" SET "status" = 'failed' WHERE "status" = 'held';
+
+UPDATE "scheduled_transfer_runs" SET "outcome" = 'failed' WHERE "outcome" = 'held';
+
+ALTER TABLE "scheduled_transfer_runs" DROP CONSTRAINT "scheduled_transfer_runs_outcome_check";
+
+ALTER TABLE "scheduled_transfer_runs" ADD CONSTRAINT "scheduled_transfer_runs_outcome_check"
+  CHECK ("outcome" IN ('executed', 'failed', 'retried'));
+
+ALTER TABLE "scheduled_transfers" DROP CONSTRAINT "scheduled_transfers_status_check";
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_transfers_status_check"
+  CHECK ("status" IN ('scheduled', 'executed', 'failed', 'cancelled'));
+
+ALTER TABLE "transfer_batches" DROP CONSTRAINT "transfer_batches_status_check";
+
+ALTER TABLE "transfer_batches" ADD CONSTRAINT "transfer_batches_status_check"
+  CHECK ("status" IN ('pending', 'processing', 'completed', 'partially_completed', 'failed'));
+
+ALTER TABLE "transfer_confirmations" DROP COLUMN IF EXISTS "pending_transfer_id";
+
+ALTER TABLE "pending_transfers" DROP CONSTRAINT "pending_transfers_kind_check";
+
+ALTER TABLE "pending_transfers" ALTER COLUMN "to_account_id" SET NOT NULL;
+
+ALTER TABLE "pending_transfers" DROP COLUMN IF EXISTS "scheduled_transfer_id";
+
+ALTER TABLE "pending_transfers" DROP COLUMN IF EXISTS "transfer_batch_id";
+
+ALTER TABLE "pending_transfers" DROP COLUMN IF EXISTS "beneficiary_id";
+
+ALTER TABLE "pending_transfers" DROP COLUMN IF EXISTS "payment_request_id";
+
+ALTER TABLE "pending_transfers" DROP COLUMN IF EXISTS "fx_quote_id";
+
+ALTER TABLE "pending_transfers" DROP COLUMN IF EXISTS "kind";
@@ -0,0 +1,63 @@
+ALTER TABLE "pending_transfers" ADD COLUMN "kind" varchar NOT NULL DEFAULT 'transfer';
+
+ALTER TABLE "pending_transfers" ADD COLUMN "fx_quote_id" bigint;
+
+ALTER TABLE "pending_transfers" ADD COLUMN "payment_request_id" bigint;
+
+ALTER TABLE "pending_transfers" ADD COLUMN "beneficiary_id" bigint;
+
+ALTER TABLE "pending_transfers" ADD COLUMN "transfer_batch_id" bigint;
+
+ALTER TABLE "pending_transfers" ADD COLUMN "scheduled_transfer_id" bigint;
+
+ALTER TABLE "pending_transfers" ALTER COLUMN "to_account_id" DROP NOT NULL;
+
+ALTER TABLE "pending_transfers" ADD CONSTRAINT "pending_transfers_kind_check" CHECK (
+  ("kind" = 'transfer' AND "to_account_id" IS NOT NULL) OR
+  ("kind" = 'fx_transfer' AND "to_account_id" IS NOT NULL AND "fx_quote_id" IS NOT NULL) OR
+  ("kind" = 'payment_request' AND "to_account_id" IS NOT NULL AND "payment_request_id" IS NOT NULL) OR
+  ("kind" = 'outbound_transfer' AND "to_account_id" IS NOT NULL AND "beneficiary_id" IS NOT NULL) OR
+  ("kind" = 'transfer_batch' AND "to_account_id" IS NULL AND "transfer_batch_id" IS NOT NULL)
+);
+
+-- a payment request can only be waiting for one approval at a time
+CREATE UNIQUE INDEX ON "pending_transfers" ("payment_request_id") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "pending_transfers"."kind" IS 'what an approval runs: a transfer, a cross-currency transfer at the rate of the time of approval, the payment of a payment request, an outbound transfer or a whole transfer batch';
+
+COMMENT ON COLUMN "pending_transfers"."to_account_id" IS 'null for a transfer batch, whose items go to many accounts';
+
+COMMENT ON COLUMN "pending_transfers"."fx_quote_id" IS 'quote the maker was shown; approval converts at the latest rate';
+
+COMMENT ON COLUMN "pending_transfers"."scheduled_transfer_id" IS 'set when the scheduled transfer worker held the transfer; the decision is recorded on it';
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("fx_quote_id") REFERENCES "fx_quotes" ("id");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("payment_request_id") REFERENCES "payment_requests" ("id");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("beneficiary_id") REFERENCES "beneficiaries" ("id");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("transfer_batch_id") REFERENCES "transfer_batches" ("id");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "transfer_confirmations" ADD COLUMN "pending_transfer_id" bigint;
+
+COMMENT ON COLUMN "transfer_confirmations"."pending_transfer_id" IS 'set when confirming held the transfer for approval; a confirmation can only be used once';
+
+ALTER TABLE "transfer_confirmations" ADD FOREIGN KEY ("pending_transfer_id") REFERENCES "pending_transfers" ("id");
+
+ALTER TABLE "transfer_batches" DROP CONSTRAINT "transfer_batches_status_check";
+
+ALTER TABLE "transfer_batches" ADD CONSTRAINT "transfer_batches_status_check"
+  CHECK ("status" IN ('held', 'pending', 'processing', 'completed', 'partially_completed', 'failed', 'rejected'));
+
+ALTER TABLE "scheduled_transfers" DROP CONSTRAINT "scheduled_transfers_status_check";
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "scheduled_transfers_status_check"
+  CHECK ("status" IN ('scheduled', 'held', 'executed', 'failed', 'cancelled'));
+
+ALTER TABLE "scheduled_transfer_runs" DROP CONSTRAINT "scheduled_transfer_runs_outcome_check";
+
+ALTER TABLE "scheduled_transfer_runs" ADD CONSTRAINT "scheduled_transfer_runs_outcome_check"
+  CHECK ("outcome" IN ('executed', 'held', 'failed', 'retried'));
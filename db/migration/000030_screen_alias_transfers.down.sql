@@ -0,0 +1 @@
+ALTER TABLE "transfer_confirmations" DROP COLUMN IF EXISTS "risk_decision_id";
@@ -0,0 +1,5 @@
+ALTER TABLE "transfer_confirmations" ADD COLUMN "risk_decision_id" bigint;
+
+COMMENT ON COLUMN "transfer_confirmations"."risk_decision_id" IS 'set when the risk engine asked for a review; confirming holds the transfer for approval';
+
+ALTER TABLE "transfer_confirmations" ADD FOREIGN KEY ("risk_decision_id") REFERENCES "risk_decisions" ("id");
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "sanctions_cases_open_account_id_idx";
@@ -0,0 +1,2 @@
+-- transfers between accounts with an open case are held for approval
+CREATE INDEX "sanctions_cases_open_account_id_idx" ON "sanctions_cases" ("account_id") WHERE "status" = 'open';
@@ -0,0 +1,5 @@
+ALTER TABLE "reconciliation_discrepancies" ADD COLUMN "corrected" boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "reconciliation_discrepancies"."corrected" IS 'a repair run set the balance of the account to entries_total';
+
+COMMENT ON COLUMN "reconciliation_discrepancies"."journal_entry_id" IS 'adjustment posted by a repair run before repairs corrected the balance instead';
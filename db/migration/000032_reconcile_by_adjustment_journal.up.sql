@@ -0,0 +1,6 @@
+-- Repair runs post a balanced adjustment journal against the bank's ledger
+-- adjustment account again, instead of overwriting the balance outside the
+-- ledger.
+ALTER TABLE "reconciliation_discrepancies" DROP COLUMN IF EXISTS "corrected";
+
+COMMENT ON COLUMN "reconciliation_discrepancies"."journal_entry_id" IS 'adjustment posted by a repair run to bring the entries in line with the balance';
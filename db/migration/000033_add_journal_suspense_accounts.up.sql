@@ -0,0 +1,44 @@
+-- Legacy entries that don't balance can't be fixed after the fact, so the
+-- difference of every backfilled journal entry that doesn't sum to zero is
+-- posted to the migration suspense account of its currency, for operations
+-- to look into.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('bankmigration', '!', 'Bank journal migration suspense', 'bankmigration@digi-bank.internal');
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "account_number")
+SELECT 'bankmigration', 0, c, next_account_number()
+FROM unnest(ARRAY['USD', 'EUR', 'VND']) AS c;
+
+DO $$
+DECLARE
+  unbalanced record;
+  suspense_id bigint;
+  suspense_entry_id bigint;
+BEGIN
+  FOR unbalanced IN
+    SELECT "journal_entry_id", "currency", sum("amount") AS "total"
+    FROM "postings"
+    GROUP BY "journal_entry_id", "currency"
+    HAVING sum("amount") <> 0
+    ORDER BY "journal_entry_id", "currency"
+  LOOP
+    SELECT "id" INTO suspense_id FROM "accounts"
+    WHERE "owner" = 'bankmigration' AND "currency" = unbalanced."currency";
+    IF NOT FOUND THEN
+      INSERT INTO "accounts" ("owner", "balance", "currency", "account_number")
+      VALUES ('bankmigration', 0, unbalanced."currency", next_account_number())
+      RETURNING "id" INTO suspense_id;
+    END IF;
+
+    INSERT INTO "entries" ("account_id", "amount")
+    VALUES (suspense_id, -unbalanced."total")
+    RETURNING "id" INTO suspense_entry_id;
+    INSERT INTO "postings" ("journal_entry_id", "entry_id", "account_id", "currency", "amount")
+    VALUES (unbalanced."journal_entry_id", suspense_entry_id, suspense_id, unbalanced."currency", -unbalanced."total");
+    UPDATE "accounts" SET "balance" = "balance" - unbalanced."total" WHERE "id" = suspense_id;
+
+    RAISE WARNING 'journal entry % did not balance: its % postings summed to %; posted % to suspense account %',
+      unbalanced."journal_entry_id", unbalanced."currency", unbalanced."total", -unbalanced."total", suspense_id;
+  END LOOP;
+END;
+$$;
@@ -0,0 +1,32 @@
+-- check_journal_balanced rejects a change to the postings that leaves a
+-- journal entry not summing to zero in some currency. It runs at commit, so a
+-- transaction may write the postings of a journal entry one at a time.
+CREATE OR REPLACE FUNCTION check_journal_balanced() RETURNS trigger AS $$
+DECLARE
+  journal_ids bigint[];
+  journal_id bigint;
+  unbalanced record;
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    journal_ids := ARRAY[NEW."journal_entry_id"];
+  ELSIF TG_OP = 'UPDATE' THEN
+    journal_ids := ARRAY[NEW."journal_entry_id", OLD."journal_entry_id"];
+  ELSE
+    journal_ids := ARRAY[OLD."journal_entry_id"];
+  END IF;
+  FOREACH journal_id IN ARRAY journal_ids LOOP
+    SELECT "currency", sum("amount") AS "total" INTO unbalanced
+    FROM "postings"
+    WHERE "journal_entry_id" = journal_id
+    GROUP BY "currency"
+    HAVING sum("amount") <> 0
+    LIMIT 1;
+    IF FOUND THEN
+      RAISE EXCEPTION 'journal entry % does not balance: its % postings sum to %',
+        journal_id, unbalanced."currency", unbalanced."total"
+        USING ERRCODE = 'check_violation', CONSTRAINT = 'postings_balanced';
+    END IF;
+  END LOOP;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,39 @@
+-- check_journal_balanced runs once per posting written, at commit, so it
+-- checks each journal entry only the first time it runs for it in the
+-- transaction; by then all of its postings are written. The journal entries
+-- already checked are kept in a setting local to the transaction, which a
+-- rolled back savepoint takes back with the postings.
+CREATE OR REPLACE FUNCTION check_journal_balanced() RETURNS trigger AS $$
+DECLARE
+  journal_ids bigint[];
+  journal_id bigint;
+  checked text;
+  unbalanced record;
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    journal_ids := ARRAY[NEW."journal_entry_id"];
+  ELSIF TG_OP = 'UPDATE' THEN
+    journal_ids := ARRAY[NEW."journal_entry_id", OLD."journal_entry_id"];
+  ELSE
+    journal_ids := ARRAY[OLD."journal_entry_id"];
+  END IF;
+  checked := coalesce(nullif(current_setting('ledger.checked_journal_entries', true), ''), ',');
+  FOREACH journal_id IN ARRAY journal_ids LOOP
+    CONTINUE WHEN position(',' || journal_id || ',' IN checked) > 0;
+    SELECT "currency", sum("amount") AS "total" INTO unbalanced
+    FROM "postings"
+    WHERE "journal_entry_id" = journal_id
+    GROUP BY "currency"
+    HAVING sum("amount") <> 0
+    LIMIT 1;
+    IF FOUND THEN
+      RAISE EXCEPTION 'journal entry % does not balance: its % postings sum to %',
+        journal_id, unbalanced."currency", unbalanced."total"
+        USING ERRCODE = 'check_violation', CONSTRAINT = 'postings_balanced';
+    END IF;
+    checked := checked || journal_id || ',';
+  END LOOP;
+  PERFORM set_config('ledger.checked_journal_entries', checked, true);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -6,7 +6,9 @@ package mockdb
 
 import (
 	context "context"
+	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	db "tutorial.sqlc.dev/app/db/sqlc"
@@ -50,198 +52,2356 @@ func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
 }
 
+// CancelScheduledTransfer mocks base method.
+func (m *MockStore) CancelScheduledTransfer(arg0 context.Context, arg1 int64) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelScheduledTransfer indicates an expected call of CancelScheduledTransfer.
+func (mr *MockStoreMockRecorder) CancelScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledTransfer", reflect.TypeOf((*MockStore)(nil).CancelScheduledTransfer), arg0, arg1)
+}
+
+// CancelStandingOrder mocks base method.
+func (m *MockStore) CancelStandingOrder(arg0 context.Context, arg1 int64) (db.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(db.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelStandingOrder indicates an expected call of CancelStandingOrder.
+func (mr *MockStoreMockRecorder) CancelStandingOrder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelStandingOrder", reflect.TypeOf((*MockStore)(nil).CancelStandingOrder), arg0, arg1)
+}
+
+// ClaimDueScheduledTransfer mocks base method.
+func (m *MockStore) ClaimDueScheduledTransfer(arg0 context.Context) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueScheduledTransfer", arg0)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueScheduledTransfer indicates an expected call of ClaimDueScheduledTransfer.
+func (mr *MockStoreMockRecorder) ClaimDueScheduledTransfer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueScheduledTransfer", reflect.TypeOf((*MockStore)(nil).ClaimDueScheduledTransfer), arg0)
+}
+
+// ClaimDueStandingOrder mocks base method.
+func (m *MockStore) ClaimDueStandingOrder(arg0 context.Context, arg1 time.Time) (db.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(db.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueStandingOrder indicates an expected call of ClaimDueStandingOrder.
+func (mr *MockStoreMockRecorder) ClaimDueStandingOrder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueStandingOrder", reflect.TypeOf((*MockStore)(nil).ClaimDueStandingOrder), arg0, arg1)
+}
+
+// ClaimQueuedTransfer mocks base method.
+func (m *MockStore) ClaimQueuedTransfer(arg0 context.Context) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimQueuedTransfer", arg0)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimQueuedTransfer indicates an expected call of ClaimQueuedTransfer.
+func (mr *MockStoreMockRecorder) ClaimQueuedTransfer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimQueuedTransfer", reflect.TypeOf((*MockStore)(nil).ClaimQueuedTransfer), arg0)
+}
+
+// ClearSanctionsCase mocks base method.
+func (m *MockStore) ClearSanctionsCase(arg0 context.Context, arg1 db.ClearSanctionsCaseParams) (db.SanctionsCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearSanctionsCase", arg0, arg1)
+	ret0, _ := ret[0].(db.SanctionsCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClearSanctionsCase indicates an expected call of ClearSanctionsCase.
+func (mr *MockStoreMockRecorder) ClearSanctionsCase(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearSanctionsCase", reflect.TypeOf((*MockStore)(nil).ClearSanctionsCase), arg0, arg1)
+}
+
+// CompleteTransferBatchItem mocks base method.
+func (m *MockStore) CompleteTransferBatchItem(arg0 context.Context, arg1 db.CompleteTransferBatchItemParams) (db.TransferBatchItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTransferBatchItem", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferBatchItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTransferBatchItem indicates an expected call of CompleteTransferBatchItem.
+func (mr *MockStoreMockRecorder) CompleteTransferBatchItem(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTransferBatchItem", reflect.TypeOf((*MockStore)(nil).CompleteTransferBatchItem), arg0, arg1)
+}
+
+// ConfirmTransferTx mocks base method.
+func (m *MockStore) ConfirmTransferTx(arg0 context.Context, arg1 db.ConfirmTransferTxParams) (db.ConfirmTransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ConfirmTransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTransferTx indicates an expected call of ConfirmTransferTx.
+func (mr *MockStoreMockRecorder) ConfirmTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTransferTx", reflect.TypeOf((*MockStore)(nil).ConfirmTransferTx), arg0, arg1)
+}
+
+// CountACHFilesSince mocks base method.
+func (m *MockStore) CountACHFilesSince(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountACHFilesSince", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountACHFilesSince indicates an expected call of CountACHFilesSince.
+func (mr *MockStoreMockRecorder) CountACHFilesSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountACHFilesSince", reflect.TypeOf((*MockStore)(nil).CountACHFilesSince), arg0, arg1)
+}
+
+// CountAccounts mocks base method.
+func (m *MockStore) CountAccounts(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAccounts", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAccounts indicates an expected call of CountAccounts.
+func (mr *MockStoreMockRecorder) CountAccounts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAccounts", reflect.TypeOf((*MockStore)(nil).CountAccounts), arg0)
+}
+
+// CountClearedSanctionsMatches mocks base method.
+func (m *MockStore) CountClearedSanctionsMatches(arg0 context.Context, arg1 db.CountClearedSanctionsMatchesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountClearedSanctionsMatches", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountClearedSanctionsMatches indicates an expected call of CountClearedSanctionsMatches.
+func (mr *MockStoreMockRecorder) CountClearedSanctionsMatches(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountClearedSanctionsMatches", reflect.TypeOf((*MockStore)(nil).CountClearedSanctionsMatches), arg0, arg1)
+}
+
+// CountOpenSanctionsCasesForTransfer mocks base method.
+func (m *MockStore) CountOpenSanctionsCasesForTransfer(arg0 context.Context, arg1 db.CountOpenSanctionsCasesForTransferParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOpenSanctionsCasesForTransfer", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOpenSanctionsCasesForTransfer indicates an expected call of CountOpenSanctionsCasesForTransfer.
+func (mr *MockStoreMockRecorder) CountOpenSanctionsCasesForTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOpenSanctionsCasesForTransfer", reflect.TypeOf((*MockStore)(nil).CountOpenSanctionsCasesForTransfer), arg0, arg1)
+}
+
+// CountTransfersSince mocks base method.
+func (m *MockStore) CountTransfersSince(arg0 context.Context, arg1 db.CountTransfersSinceParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTransfersSince", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTransfersSince indicates an expected call of CountTransfersSince.
+func (mr *MockStoreMockRecorder) CountTransfersSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTransfersSince", reflect.TypeOf((*MockStore)(nil).CountTransfersSince), arg0, arg1)
+}
+
+// CountTransfersTo mocks base method.
+func (m *MockStore) CountTransfersTo(arg0 context.Context, arg1 db.CountTransfersToParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTransfersTo", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTransfersTo indicates an expected call of CountTransfersTo.
+func (mr *MockStoreMockRecorder) CountTransfersTo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTransfersTo", reflect.TypeOf((*MockStore)(nil).CountTransfersTo), arg0, arg1)
+}
+
+// CreateACHFile mocks base method.
+func (m *MockStore) CreateACHFile(arg0 context.Context, arg1 db.CreateACHFileParams) (db.AchFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateACHFile", arg0, arg1)
+	ret0, _ := ret[0].(db.AchFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateACHFile indicates an expected call of CreateACHFile.
+func (mr *MockStoreMockRecorder) CreateACHFile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateACHFile", reflect.TypeOf((*MockStore)(nil).CreateACHFile), arg0, arg1)
+}
+
 // CreateAccount mocks base method.
 func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
+}
+
+// CreateBeneficiary mocks base method.
+func (m *MockStore) CreateBeneficiary(arg0 context.Context, arg1 db.CreateBeneficiaryParams) (db.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBeneficiary", arg0, arg1)
+	ret0, _ := ret[0].(db.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBeneficiary indicates an expected call of CreateBeneficiary.
+func (mr *MockStoreMockRecorder) CreateBeneficiary(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBeneficiary", reflect.TypeOf((*MockStore)(nil).CreateBeneficiary), arg0, arg1)
+}
+
+// CreateEntry mocks base method.
+func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEntry indicates an expected call of CreateEntry.
+func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
+}
+
+// CreateFeeEntry mocks base method.
+func (m *MockStore) CreateFeeEntry(arg0 context.Context, arg1 db.CreateFeeEntryParams) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFeeEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFeeEntry indicates an expected call of CreateFeeEntry.
+func (mr *MockStoreMockRecorder) CreateFeeEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeeEntry", reflect.TypeOf((*MockStore)(nil).CreateFeeEntry), arg0, arg1)
+}
+
+// CreateFeeRule mocks base method.
+func (m *MockStore) CreateFeeRule(arg0 context.Context, arg1 db.CreateFeeRuleParams) (db.FeeRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFeeRule", arg0, arg1)
+	ret0, _ := ret[0].(db.FeeRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFeeRule indicates an expected call of CreateFeeRule.
+func (mr *MockStoreMockRecorder) CreateFeeRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeeRule", reflect.TypeOf((*MockStore)(nil).CreateFeeRule), arg0, arg1)
+}
+
+// CreateFxQuote mocks base method.
+func (m *MockStore) CreateFxQuote(arg0 context.Context, arg1 db.CreateFxQuoteParams) (db.FxQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFxQuote", arg0, arg1)
+	ret0, _ := ret[0].(db.FxQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFxQuote indicates an expected call of CreateFxQuote.
+func (mr *MockStoreMockRecorder) CreateFxQuote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFxQuote", reflect.TypeOf((*MockStore)(nil).CreateFxQuote), arg0, arg1)
+}
+
+// CreateFxRate mocks base method.
+func (m *MockStore) CreateFxRate(arg0 context.Context, arg1 db.CreateFxRateParams) (db.FxRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFxRate", arg0, arg1)
+	ret0, _ := ret[0].(db.FxRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFxRate indicates an expected call of CreateFxRate.
+func (mr *MockStoreMockRecorder) CreateFxRate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFxRate", reflect.TypeOf((*MockStore)(nil).CreateFxRate), arg0, arg1)
+}
+
+// CreateHoliday mocks base method.
+func (m *MockStore) CreateHoliday(arg0 context.Context, arg1 db.CreateHolidayParams) (db.Holiday, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHoliday", arg0, arg1)
+	ret0, _ := ret[0].(db.Holiday)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHoliday indicates an expected call of CreateHoliday.
+func (mr *MockStoreMockRecorder) CreateHoliday(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHoliday", reflect.TypeOf((*MockStore)(nil).CreateHoliday), arg0, arg1)
+}
+
+// CreateIdempotencyKey mocks base method.
+func (m *MockStore) CreateIdempotencyKey(arg0 context.Context, arg1 db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockStoreMockRecorder) CreateIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CreateIdempotencyKey), arg0, arg1)
+}
+
+// CreateJournalEntry mocks base method.
+func (m *MockStore) CreateJournalEntry(arg0 context.Context, arg1 db.CreateJournalEntryParams) (db.JournalEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJournalEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.JournalEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJournalEntry indicates an expected call of CreateJournalEntry.
+func (mr *MockStoreMockRecorder) CreateJournalEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJournalEntry", reflect.TypeOf((*MockStore)(nil).CreateJournalEntry), arg0, arg1)
+}
+
+// CreateOutboundPayment mocks base method.
+func (m *MockStore) CreateOutboundPayment(arg0 context.Context, arg1 db.CreateOutboundPaymentParams) (db.OutboundPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboundPayment", arg0, arg1)
+	ret0, _ := ret[0].(db.OutboundPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOutboundPayment indicates an expected call of CreateOutboundPayment.
+func (mr *MockStoreMockRecorder) CreateOutboundPayment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboundPayment", reflect.TypeOf((*MockStore)(nil).CreateOutboundPayment), arg0, arg1)
+}
+
+// CreateOutboundTransferTx mocks base method.
+func (m *MockStore) CreateOutboundTransferTx(arg0 context.Context, arg1 db.CreateOutboundTransferTxParams) (db.OutboundTransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboundTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.OutboundTransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOutboundTransferTx indicates an expected call of CreateOutboundTransferTx.
+func (mr *MockStoreMockRecorder) CreateOutboundTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboundTransferTx", reflect.TypeOf((*MockStore)(nil).CreateOutboundTransferTx), arg0, arg1)
+}
+
+// CreatePaymentRequest mocks base method.
+func (m *MockStore) CreatePaymentRequest(arg0 context.Context, arg1 db.CreatePaymentRequestParams) (db.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentRequest", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentRequest indicates an expected call of CreatePaymentRequest.
+func (mr *MockStoreMockRecorder) CreatePaymentRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentRequest", reflect.TypeOf((*MockStore)(nil).CreatePaymentRequest), arg0, arg1)
+}
+
+// CreatePendingTransfer mocks base method.
+func (m *MockStore) CreatePendingTransfer(arg0 context.Context, arg1 db.CreatePendingTransferParams) (db.PendingTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePendingTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.PendingTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePendingTransfer indicates an expected call of CreatePendingTransfer.
+func (mr *MockStoreMockRecorder) CreatePendingTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePendingTransfer", reflect.TypeOf((*MockStore)(nil).CreatePendingTransfer), arg0, arg1)
+}
+
+// CreatePendingTransferApproval mocks base method.
+func (m *MockStore) CreatePendingTransferApproval(arg0 context.Context, arg1 db.CreatePendingTransferApprovalParams) (db.PendingTransferApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePendingTransferApproval", arg0, arg1)
+	ret0, _ := ret[0].(db.PendingTransferApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePendingTransferApproval indicates an expected call of CreatePendingTransferApproval.
+func (mr *MockStoreMockRecorder) CreatePendingTransferApproval(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePendingTransferApproval", reflect.TypeOf((*MockStore)(nil).CreatePendingTransferApproval), arg0, arg1)
+}
+
+// CreatePendingTransferTx mocks base method.
+func (m *MockStore) CreatePendingTransferTx(arg0 context.Context, arg1 db.CreatePendingTransferTxParams) (db.PendingTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePendingTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.PendingTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePendingTransferTx indicates an expected call of CreatePendingTransferTx.
+func (mr *MockStoreMockRecorder) CreatePendingTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePendingTransferTx", reflect.TypeOf((*MockStore)(nil).CreatePendingTransferTx), arg0, arg1)
+}
+
+// CreatePostings mocks base method.
+func (m *MockStore) CreatePostings(arg0 context.Context, arg1 db.CreatePostingsParams) ([]db.Posting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePostings", arg0, arg1)
+	ret0, _ := ret[0].([]db.Posting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePostings indicates an expected call of CreatePostings.
+func (mr *MockStoreMockRecorder) CreatePostings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePostings", reflect.TypeOf((*MockStore)(nil).CreatePostings), arg0, arg1)
+}
+
+// CreateReconciliationDiscrepancy mocks base method.
+func (m *MockStore) CreateReconciliationDiscrepancy(arg0 context.Context, arg1 db.CreateReconciliationDiscrepancyParams) (db.ReconciliationDiscrepancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReconciliationDiscrepancy", arg0, arg1)
+	ret0, _ := ret[0].(db.ReconciliationDiscrepancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReconciliationDiscrepancy indicates an expected call of CreateReconciliationDiscrepancy.
+func (mr *MockStoreMockRecorder) CreateReconciliationDiscrepancy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReconciliationDiscrepancy", reflect.TypeOf((*MockStore)(nil).CreateReconciliationDiscrepancy), arg0, arg1)
+}
+
+// CreateReconciliationRun mocks base method.
+func (m *MockStore) CreateReconciliationRun(arg0 context.Context, arg1 db.CreateReconciliationRunParams) (db.ReconciliationRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReconciliationRun", arg0, arg1)
+	ret0, _ := ret[0].(db.ReconciliationRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReconciliationRun indicates an expected call of CreateReconciliationRun.
+func (mr *MockStoreMockRecorder) CreateReconciliationRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReconciliationRun", reflect.TypeOf((*MockStore)(nil).CreateReconciliationRun), arg0, arg1)
+}
+
+// CreateReversalTransfer mocks base method.
+func (m *MockStore) CreateReversalTransfer(arg0 context.Context, arg1 db.CreateReversalTransferParams) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReversalTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReversalTransfer indicates an expected call of CreateReversalTransfer.
+func (mr *MockStoreMockRecorder) CreateReversalTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReversalTransfer", reflect.TypeOf((*MockStore)(nil).CreateReversalTransfer), arg0, arg1)
+}
+
+// CreateRiskDecision mocks base method.
+func (m *MockStore) CreateRiskDecision(arg0 context.Context, arg1 db.CreateRiskDecisionParams) (db.RiskDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRiskDecision", arg0, arg1)
+	ret0, _ := ret[0].(db.RiskDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRiskDecision indicates an expected call of CreateRiskDecision.
+func (mr *MockStoreMockRecorder) CreateRiskDecision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRiskDecision", reflect.TypeOf((*MockStore)(nil).CreateRiskDecision), arg0, arg1)
+}
+
+// CreateRiskRule mocks base method.
+func (m *MockStore) CreateRiskRule(arg0 context.Context, arg1 db.CreateRiskRuleParams) (db.RiskRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRiskRule", arg0, arg1)
+	ret0, _ := ret[0].(db.RiskRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRiskRule indicates an expected call of CreateRiskRule.
+func (mr *MockStoreMockRecorder) CreateRiskRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRiskRule", reflect.TypeOf((*MockStore)(nil).CreateRiskRule), arg0, arg1)
+}
+
+// CreateSanctionsCase mocks base method.
+func (m *MockStore) CreateSanctionsCase(arg0 context.Context, arg1 db.CreateSanctionsCaseParams) (db.SanctionsCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSanctionsCase", arg0, arg1)
+	ret0, _ := ret[0].(db.SanctionsCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSanctionsCase indicates an expected call of CreateSanctionsCase.
+func (mr *MockStoreMockRecorder) CreateSanctionsCase(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSanctionsCase", reflect.TypeOf((*MockStore)(nil).CreateSanctionsCase), arg0, arg1)
+}
+
+// CreateScheduledTransfer mocks base method.
+func (m *MockStore) CreateScheduledTransfer(arg0 context.Context, arg1 db.CreateScheduledTransferParams) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledTransfer indicates an expected call of CreateScheduledTransfer.
+func (mr *MockStoreMockRecorder) CreateScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransfer", reflect.TypeOf((*MockStore)(nil).CreateScheduledTransfer), arg0, arg1)
+}
+
+// CreateScheduledTransferRun mocks base method.
+func (m *MockStore) CreateScheduledTransferRun(arg0 context.Context, arg1 db.CreateScheduledTransferRunParams) (db.ScheduledTransferRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledTransferRun", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransferRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledTransferRun indicates an expected call of CreateScheduledTransferRun.
+func (mr *MockStoreMockRecorder) CreateScheduledTransferRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransferRun", reflect.TypeOf((*MockStore)(nil).CreateScheduledTransferRun), arg0, arg1)
+}
+
+// CreateStandingOrder mocks base method.
+func (m *MockStore) CreateStandingOrder(arg0 context.Context, arg1 db.CreateStandingOrderParams) (db.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(db.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStandingOrder indicates an expected call of CreateStandingOrder.
+func (mr *MockStoreMockRecorder) CreateStandingOrder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStandingOrder", reflect.TypeOf((*MockStore)(nil).CreateStandingOrder), arg0, arg1)
+}
+
+// CreateStandingOrderOccurrence mocks base method.
+func (m *MockStore) CreateStandingOrderOccurrence(arg0 context.Context, arg1 db.CreateStandingOrderOccurrenceParams) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStandingOrderOccurrence", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStandingOrderOccurrence indicates an expected call of CreateStandingOrderOccurrence.
+func (mr *MockStoreMockRecorder) CreateStandingOrderOccurrence(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStandingOrderOccurrence", reflect.TypeOf((*MockStore)(nil).CreateStandingOrderOccurrence), arg0, arg1)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+}
+
+// CreateTransferBatch mocks base method.
+func (m *MockStore) CreateTransferBatch(arg0 context.Context, arg1 db.CreateTransferBatchParams) (db.TransferBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferBatch", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferBatch indicates an expected call of CreateTransferBatch.
+func (mr *MockStoreMockRecorder) CreateTransferBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferBatch", reflect.TypeOf((*MockStore)(nil).CreateTransferBatch), arg0, arg1)
+}
+
+// CreateTransferBatchItem mocks base method.
+func (m *MockStore) CreateTransferBatchItem(arg0 context.Context, arg1 db.CreateTransferBatchItemParams) (db.TransferBatchItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferBatchItem", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferBatchItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferBatchItem indicates an expected call of CreateTransferBatchItem.
+func (mr *MockStoreMockRecorder) CreateTransferBatchItem(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferBatchItem", reflect.TypeOf((*MockStore)(nil).CreateTransferBatchItem), arg0, arg1)
+}
+
+// CreateTransferBatchTx mocks base method.
+func (m *MockStore) CreateTransferBatchTx(arg0 context.Context, arg1 db.CreateTransferBatchTxParams) (db.TransferBatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferBatchTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferBatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferBatchTx indicates an expected call of CreateTransferBatchTx.
+func (mr *MockStoreMockRecorder) CreateTransferBatchTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferBatchTx", reflect.TypeOf((*MockStore)(nil).CreateTransferBatchTx), arg0, arg1)
+}
+
+// CreateTransferConfirmation mocks base method.
+func (m *MockStore) CreateTransferConfirmation(arg0 context.Context, arg1 db.CreateTransferConfirmationParams) (db.TransferConfirmation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferConfirmation", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferConfirmation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferConfirmation indicates an expected call of CreateTransferConfirmation.
+func (mr *MockStoreMockRecorder) CreateTransferConfirmation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferConfirmation", reflect.TypeOf((*MockStore)(nil).CreateTransferConfirmation), arg0, arg1)
+}
+
+// CreateTransferGroup mocks base method.
+func (m *MockStore) CreateTransferGroup(arg0 context.Context, arg1 db.CreateTransferGroupParams) (db.TransferGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferGroup", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferGroup indicates an expected call of CreateTransferGroup.
+func (mr *MockStoreMockRecorder) CreateTransferGroup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferGroup", reflect.TypeOf((*MockStore)(nil).CreateTransferGroup), arg0, arg1)
+}
+
+// CreateTransferGroupEntry mocks base method.
+func (m *MockStore) CreateTransferGroupEntry(arg0 context.Context, arg1 db.CreateTransferGroupEntryParams) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferGroupEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferGroupEntry indicates an expected call of CreateTransferGroupEntry.
+func (mr *MockStoreMockRecorder) CreateTransferGroupEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferGroupEntry", reflect.TypeOf((*MockStore)(nil).CreateTransferGroupEntry), arg0, arg1)
+}
+
+// CreateTransferLimit mocks base method.
+func (m *MockStore) CreateTransferLimit(arg0 context.Context, arg1 db.CreateTransferLimitParams) (db.TransferLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferLimit", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferLimit indicates an expected call of CreateTransferLimit.
+func (mr *MockStoreMockRecorder) CreateTransferLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferLimit", reflect.TypeOf((*MockStore)(nil).CreateTransferLimit), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+}
+
+// DecideHeldScheduledTransfer mocks base method.
+func (m *MockStore) DecideHeldScheduledTransfer(arg0 context.Context, arg1 db.DecideHeldScheduledTransferParams) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideHeldScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideHeldScheduledTransfer indicates an expected call of DecideHeldScheduledTransfer.
+func (mr *MockStoreMockRecorder) DecideHeldScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideHeldScheduledTransfer", reflect.TypeOf((*MockStore)(nil).DecideHeldScheduledTransfer), arg0, arg1)
+}
+
+// DecideHeldTransferBatch mocks base method.
+func (m *MockStore) DecideHeldTransferBatch(arg0 context.Context, arg1 db.DecideHeldTransferBatchParams) (db.TransferBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideHeldTransferBatch", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideHeldTransferBatch indicates an expected call of DecideHeldTransferBatch.
+func (mr *MockStoreMockRecorder) DecideHeldTransferBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideHeldTransferBatch", reflect.TypeOf((*MockStore)(nil).DecideHeldTransferBatch), arg0, arg1)
+}
+
+// DecidePaymentRequest mocks base method.
+func (m *MockStore) DecidePaymentRequest(arg0 context.Context, arg1 db.DecidePaymentRequestParams) (db.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecidePaymentRequest", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecidePaymentRequest indicates an expected call of DecidePaymentRequest.
+func (mr *MockStoreMockRecorder) DecidePaymentRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecidePaymentRequest", reflect.TypeOf((*MockStore)(nil).DecidePaymentRequest), arg0, arg1)
+}
+
+// DecidePendingTransfer mocks base method.
+func (m *MockStore) DecidePendingTransfer(arg0 context.Context, arg1 db.DecidePendingTransferParams) (db.PendingTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecidePendingTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.PendingTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecidePendingTransfer indicates an expected call of DecidePendingTransfer.
+func (mr *MockStoreMockRecorder) DecidePendingTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecidePendingTransfer", reflect.TypeOf((*MockStore)(nil).DecidePendingTransfer), arg0, arg1)
+}
+
+// DecidePendingTransferTx mocks base method.
+func (m *MockStore) DecidePendingTransferTx(arg0 context.Context, arg1 db.DecidePendingTransferTxParams) (db.DecidePendingTransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecidePendingTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.DecidePendingTransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecidePendingTransferTx indicates an expected call of DecidePendingTransferTx.
+func (mr *MockStoreMockRecorder) DecidePendingTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecidePendingTransferTx", reflect.TypeOf((*MockStore)(nil).DecidePendingTransferTx), arg0, arg1)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockStore) DeleteExpiredIdempotencyKeys(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockStoreMockRecorder) DeleteExpiredIdempotencyKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockStore)(nil).DeleteExpiredIdempotencyKeys), arg0)
+}
+
+// DeleteFeeRule mocks base method.
+func (m *MockStore) DeleteFeeRule(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeeRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFeeRule indicates an expected call of DeleteFeeRule.
+func (mr *MockStoreMockRecorder) DeleteFeeRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeeRule", reflect.TypeOf((*MockStore)(nil).DeleteFeeRule), arg0, arg1)
+}
+
+// DeleteRiskRule mocks base method.
+func (m *MockStore) DeleteRiskRule(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRiskRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRiskRule indicates an expected call of DeleteRiskRule.
+func (mr *MockStoreMockRecorder) DeleteRiskRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRiskRule", reflect.TypeOf((*MockStore)(nil).DeleteRiskRule), arg0, arg1)
+}
+
+// ExecuteTransferBatch mocks base method.
+func (m *MockStore) ExecuteTransferBatch(arg0 context.Context, arg1 int64) (db.TransferBatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteTransferBatch", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferBatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteTransferBatch indicates an expected call of ExecuteTransferBatch.
+func (mr *MockStoreMockRecorder) ExecuteTransferBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteTransferBatch", reflect.TypeOf((*MockStore)(nil).ExecuteTransferBatch), arg0, arg1)
+}
+
+// ExpirePendingTransfers mocks base method.
+func (m *MockStore) ExpirePendingTransfers(arg0 context.Context) ([]db.PendingTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePendingTransfers", arg0)
+	ret0, _ := ret[0].([]db.PendingTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpirePendingTransfers indicates an expected call of ExpirePendingTransfers.
+func (mr *MockStoreMockRecorder) ExpirePendingTransfers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePendingTransfers", reflect.TypeOf((*MockStore)(nil).ExpirePendingTransfers), arg0)
+}
+
+// ExpirePendingTransfersTx mocks base method.
+func (m *MockStore) ExpirePendingTransfersTx(arg0 context.Context) ([]db.PendingTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePendingTransfersTx", arg0)
+	ret0, _ := ret[0].([]db.PendingTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpirePendingTransfersTx indicates an expected call of ExpirePendingTransfersTx.
+func (mr *MockStoreMockRecorder) ExpirePendingTransfersTx(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePendingTransfersTx", reflect.TypeOf((*MockStore)(nil).ExpirePendingTransfersTx), arg0)
+}
+
+// ExportOutboundPaymentsTx mocks base method.
+func (m *MockStore) ExportOutboundPaymentsTx(arg0 context.Context, arg1 db.ExportOutboundPaymentsTxParams) (db.ExportOutboundPaymentsTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportOutboundPaymentsTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ExportOutboundPaymentsTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportOutboundPaymentsTx indicates an expected call of ExportOutboundPaymentsTx.
+func (mr *MockStoreMockRecorder) ExportOutboundPaymentsTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportOutboundPaymentsTx", reflect.TypeOf((*MockStore)(nil).ExportOutboundPaymentsTx), arg0, arg1)
+}
+
+// FailTransferBatchItem mocks base method.
+func (m *MockStore) FailTransferBatchItem(arg0 context.Context, arg1 db.FailTransferBatchItemParams) (db.TransferBatchItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailTransferBatchItem", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferBatchItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailTransferBatchItem indicates an expected call of FailTransferBatchItem.
+func (mr *MockStoreMockRecorder) FailTransferBatchItem(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailTransferBatchItem", reflect.TypeOf((*MockStore)(nil).FailTransferBatchItem), arg0, arg1)
+}
+
+// FinishTransferBatch mocks base method.
+func (m *MockStore) FinishTransferBatch(arg0 context.Context, arg1 db.FinishTransferBatchParams) (db.TransferBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishTransferBatch", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishTransferBatch indicates an expected call of FinishTransferBatch.
+func (mr *MockStoreMockRecorder) FinishTransferBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishTransferBatch", reflect.TypeOf((*MockStore)(nil).FinishTransferBatch), arg0, arg1)
+}
+
+// FxTransferTx mocks base method.
+func (m *MockStore) FxTransferTx(arg0 context.Context, arg1 db.FxTransferTxParams) (db.FxTransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FxTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.FxTransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FxTransferTx indicates an expected call of FxTransferTx.
+func (mr *MockStoreMockRecorder) FxTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FxTransferTx", reflect.TypeOf((*MockStore)(nil).FxTransferTx), arg0, arg1)
+}
+
+// GetAccountByNumber mocks base method.
+func (m *MockStore) GetAccountByNumber(arg0 context.Context, arg1 string) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByNumber", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByNumber indicates an expected call of GetAccountByNumber.
+func (mr *MockStoreMockRecorder) GetAccountByNumber(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByNumber", reflect.TypeOf((*MockStore)(nil).GetAccountByNumber), arg0, arg1)
+}
+
+// GetAccountByOwnerAndCurrency mocks base method.
+func (m *MockStore) GetAccountByOwnerAndCurrency(arg0 context.Context, arg1 db.GetAccountByOwnerAndCurrencyParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByOwnerAndCurrency", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByOwnerAndCurrency indicates an expected call of GetAccountByOwnerAndCurrency.
+func (mr *MockStoreMockRecorder) GetAccountByOwnerAndCurrency(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByOwnerAndCurrency", reflect.TypeOf((*MockStore)(nil).GetAccountByOwnerAndCurrency), arg0, arg1)
+}
+
+// GetAccountForUpdate mocks base method.
+func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+}
+
+// GetAccounts mocks base method.
+func (m *MockStore) GetAccounts(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccounts", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccounts indicates an expected call of GetAccounts.
+func (mr *MockStoreMockRecorder) GetAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockStore)(nil).GetAccounts), arg0, arg1)
+}
+
+// GetBeneficiary mocks base method.
+func (m *MockStore) GetBeneficiary(arg0 context.Context, arg1 int64) (db.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBeneficiary", arg0, arg1)
+	ret0, _ := ret[0].(db.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBeneficiary indicates an expected call of GetBeneficiary.
+func (mr *MockStoreMockRecorder) GetBeneficiary(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBeneficiary", reflect.TypeOf((*MockStore)(nil).GetBeneficiary), arg0, arg1)
+}
+
+// GetChainEntry mocks base method.
+func (m *MockStore) GetChainEntry(arg0 context.Context, arg1 db.GetChainEntryParams) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChainEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChainEntry indicates an expected call of GetChainEntry.
+func (mr *MockStoreMockRecorder) GetChainEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChainEntry", reflect.TypeOf((*MockStore)(nil).GetChainEntry), arg0, arg1)
+}
+
+// GetDailyDebitUsage mocks base method.
+func (m *MockStore) GetDailyDebitUsage(arg0 context.Context, arg1 db.GetDailyDebitUsageParams) (db.GetDailyDebitUsageRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDailyDebitUsage", arg0, arg1)
+	ret0, _ := ret[0].(db.GetDailyDebitUsageRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDailyDebitUsage indicates an expected call of GetDailyDebitUsage.
+func (mr *MockStoreMockRecorder) GetDailyDebitUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDailyDebitUsage", reflect.TypeOf((*MockStore)(nil).GetDailyDebitUsage), arg0, arg1)
+}
+
+// GetEntriesTotalSince mocks base method.
+func (m *MockStore) GetEntriesTotalSince(arg0 context.Context, arg1 db.GetEntriesTotalSinceParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntriesTotalSince", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntriesTotalSince indicates an expected call of GetEntriesTotalSince.
+func (mr *MockStoreMockRecorder) GetEntriesTotalSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntriesTotalSince", reflect.TypeOf((*MockStore)(nil).GetEntriesTotalSince), arg0, arg1)
+}
+
+// GetEntry mocks base method.
+func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+}
+
+// GetFeeRule mocks base method.
+func (m *MockStore) GetFeeRule(arg0 context.Context, arg1 db.GetFeeRuleParams) (db.FeeRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeRule", arg0, arg1)
+	ret0, _ := ret[0].(db.FeeRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeRule indicates an expected call of GetFeeRule.
+func (mr *MockStoreMockRecorder) GetFeeRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeRule", reflect.TypeOf((*MockStore)(nil).GetFeeRule), arg0, arg1)
+}
+
+// GetFxQuote mocks base method.
+func (m *MockStore) GetFxQuote(arg0 context.Context, arg1 int64) (db.FxQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFxQuote", arg0, arg1)
+	ret0, _ := ret[0].(db.FxQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFxQuote indicates an expected call of GetFxQuote.
+func (mr *MockStoreMockRecorder) GetFxQuote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFxQuote", reflect.TypeOf((*MockStore)(nil).GetFxQuote), arg0, arg1)
+}
+
+// GetFxQuoteForUpdate mocks base method.
+func (m *MockStore) GetFxQuoteForUpdate(arg0 context.Context, arg1 int64) (db.FxQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFxQuoteForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.FxQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFxQuoteForUpdate indicates an expected call of GetFxQuoteForUpdate.
+func (mr *MockStoreMockRecorder) GetFxQuoteForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFxQuoteForUpdate", reflect.TypeOf((*MockStore)(nil).GetFxQuoteForUpdate), arg0, arg1)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockStore) GetIdempotencyKey(arg0 context.Context, arg1 db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockStoreMockRecorder) GetIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockStore)(nil).GetIdempotencyKey), arg0, arg1)
+}
+
+// GetJournalEntry mocks base method.
+func (m *MockStore) GetJournalEntry(arg0 context.Context, arg1 int64) (db.JournalEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJournalEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.JournalEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJournalEntry indicates an expected call of GetJournalEntry.
+func (mr *MockStoreMockRecorder) GetJournalEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJournalEntry", reflect.TypeOf((*MockStore)(nil).GetJournalEntry), arg0, arg1)
+}
+
+// GetJournalEntryByTransfer mocks base method.
+func (m *MockStore) GetJournalEntryByTransfer(arg0 context.Context, arg1 sql.NullInt64) (db.JournalEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJournalEntryByTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.JournalEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJournalEntryByTransfer indicates an expected call of GetJournalEntryByTransfer.
+func (mr *MockStoreMockRecorder) GetJournalEntryByTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJournalEntryByTransfer", reflect.TypeOf((*MockStore)(nil).GetJournalEntryByTransfer), arg0, arg1)
+}
+
+// GetLatestFxRate mocks base method.
+func (m *MockStore) GetLatestFxRate(arg0 context.Context, arg1 db.GetLatestFxRateParams) (db.FxRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestFxRate", arg0, arg1)
+	ret0, _ := ret[0].(db.FxRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestFxRate indicates an expected call of GetLatestFxRate.
+func (mr *MockStoreMockRecorder) GetLatestFxRate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestFxRate", reflect.TypeOf((*MockStore)(nil).GetLatestFxRate), arg0, arg1)
+}
+
+// GetLatestReconciliationRun mocks base method.
+func (m *MockStore) GetLatestReconciliationRun(arg0 context.Context) (db.ReconciliationRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestReconciliationRun", arg0)
+	ret0, _ := ret[0].(db.ReconciliationRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestReconciliationRun indicates an expected call of GetLatestReconciliationRun.
+func (mr *MockStoreMockRecorder) GetLatestReconciliationRun(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestReconciliationRun", reflect.TypeOf((*MockStore)(nil).GetLatestReconciliationRun), arg0)
+}
+
+// GetOutboundPayment mocks base method.
+func (m *MockStore) GetOutboundPayment(arg0 context.Context, arg1 int64) (db.OutboundPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboundPayment", arg0, arg1)
+	ret0, _ := ret[0].(db.OutboundPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboundPayment indicates an expected call of GetOutboundPayment.
+func (mr *MockStoreMockRecorder) GetOutboundPayment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboundPayment", reflect.TypeOf((*MockStore)(nil).GetOutboundPayment), arg0, arg1)
+}
+
+// GetOutboundPaymentByTraceForUpdate mocks base method.
+func (m *MockStore) GetOutboundPaymentByTraceForUpdate(arg0 context.Context, arg1 db.GetOutboundPaymentByTraceForUpdateParams) (db.OutboundPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboundPaymentByTraceForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.OutboundPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboundPaymentByTraceForUpdate indicates an expected call of GetOutboundPaymentByTraceForUpdate.
+func (mr *MockStoreMockRecorder) GetOutboundPaymentByTraceForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboundPaymentByTraceForUpdate", reflect.TypeOf((*MockStore)(nil).GetOutboundPaymentByTraceForUpdate), arg0, arg1)
+}
+
+// GetOutboundPaymentByTransfer mocks base method.
+func (m *MockStore) GetOutboundPaymentByTransfer(arg0 context.Context, arg1 int64) (db.OutboundPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboundPaymentByTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.OutboundPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboundPaymentByTransfer indicates an expected call of GetOutboundPaymentByTransfer.
+func (mr *MockStoreMockRecorder) GetOutboundPaymentByTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboundPaymentByTransfer", reflect.TypeOf((*MockStore)(nil).GetOutboundPaymentByTransfer), arg0, arg1)
+}
+
+// GetPaymentRequest mocks base method.
+func (m *MockStore) GetPaymentRequest(arg0 context.Context, arg1 int64) (db.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequest", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequest indicates an expected call of GetPaymentRequest.
+func (mr *MockStoreMockRecorder) GetPaymentRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequest", reflect.TypeOf((*MockStore)(nil).GetPaymentRequest), arg0, arg1)
+}
+
+// GetPaymentRequestForUpdate mocks base method.
+func (m *MockStore) GetPaymentRequestForUpdate(arg0 context.Context, arg1 int64) (db.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentRequestForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentRequestForUpdate indicates an expected call of GetPaymentRequestForUpdate.
+func (mr *MockStoreMockRecorder) GetPaymentRequestForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentRequestForUpdate", reflect.TypeOf((*MockStore)(nil).GetPaymentRequestForUpdate), arg0, arg1)
+}
+
+// GetPendingTransfer mocks base method.
+func (m *MockStore) GetPendingTransfer(arg0 context.Context, arg1 int64) (db.PendingTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.PendingTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingTransfer indicates an expected call of GetPendingTransfer.
+func (mr *MockStoreMockRecorder) GetPendingTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingTransfer", reflect.TypeOf((*MockStore)(nil).GetPendingTransfer), arg0, arg1)
+}
+
+// GetPendingTransferForUpdate mocks base method.
+func (m *MockStore) GetPendingTransferForUpdate(arg0 context.Context, arg1 int64) (db.PendingTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingTransferForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.PendingTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingTransferForUpdate indicates an expected call of GetPendingTransferForUpdate.
+func (mr *MockStoreMockRecorder) GetPendingTransferForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingTransferForUpdate", reflect.TypeOf((*MockStore)(nil).GetPendingTransferForUpdate), arg0, arg1)
+}
+
+// GetReconciliationRun mocks base method.
+func (m *MockStore) GetReconciliationRun(arg0 context.Context, arg1 int64) (db.ReconciliationRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReconciliationRun", arg0, arg1)
+	ret0, _ := ret[0].(db.ReconciliationRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReconciliationRun indicates an expected call of GetReconciliationRun.
+func (mr *MockStoreMockRecorder) GetReconciliationRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReconciliationRun", reflect.TypeOf((*MockStore)(nil).GetReconciliationRun), arg0, arg1)
+}
+
+// GetReversedAmount mocks base method.
+func (m *MockStore) GetReversedAmount(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReversedAmount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReversedAmount indicates an expected call of GetReversedAmount.
+func (mr *MockStoreMockRecorder) GetReversedAmount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReversedAmount", reflect.TypeOf((*MockStore)(nil).GetReversedAmount), arg0, arg1)
+}
+
+// GetSanctionsCase mocks base method.
+func (m *MockStore) GetSanctionsCase(arg0 context.Context, arg1 int64) (db.SanctionsCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSanctionsCase", arg0, arg1)
+	ret0, _ := ret[0].(db.SanctionsCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSanctionsCase indicates an expected call of GetSanctionsCase.
+func (mr *MockStoreMockRecorder) GetSanctionsCase(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSanctionsCase", reflect.TypeOf((*MockStore)(nil).GetSanctionsCase), arg0, arg1)
+}
+
+// GetScheduledTransfer mocks base method.
+func (m *MockStore) GetScheduledTransfer(arg0 context.Context, arg1 int64) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTransfer indicates an expected call of GetScheduledTransfer.
+func (mr *MockStoreMockRecorder) GetScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTransfer", reflect.TypeOf((*MockStore)(nil).GetScheduledTransfer), arg0, arg1)
+}
+
+// GetStandingOrder mocks base method.
+func (m *MockStore) GetStandingOrder(arg0 context.Context, arg1 int64) (db.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(db.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStandingOrder indicates an expected call of GetStandingOrder.
+func (mr *MockStoreMockRecorder) GetStandingOrder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStandingOrder", reflect.TypeOf((*MockStore)(nil).GetStandingOrder), arg0, arg1)
+}
+
+// GetStandingOrderForUpdate mocks base method.
+func (m *MockStore) GetStandingOrderForUpdate(arg0 context.Context, arg1 int64) (db.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStandingOrderForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStandingOrderForUpdate indicates an expected call of GetStandingOrderForUpdate.
+func (mr *MockStoreMockRecorder) GetStandingOrderForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStandingOrderForUpdate", reflect.TypeOf((*MockStore)(nil).GetStandingOrderForUpdate), arg0, arg1)
+}
+
+// GetTransfer mocks base method.
+func (m *MockStore) GetTransfer(arg0 context.Context, arg1 int64) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfer indicates an expected call of GetTransfer.
+func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
+}
+
+// GetTransferAverage mocks base method.
+func (m *MockStore) GetTransferAverage(arg0 context.Context, arg1 int64) (db.GetTransferAverageRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferAverage", arg0, arg1)
+	ret0, _ := ret[0].(db.GetTransferAverageRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferAverage indicates an expected call of GetTransferAverage.
+func (mr *MockStoreMockRecorder) GetTransferAverage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferAverage", reflect.TypeOf((*MockStore)(nil).GetTransferAverage), arg0, arg1)
+}
+
+// GetTransferBatch mocks base method.
+func (m *MockStore) GetTransferBatch(arg0 context.Context, arg1 int64) (db.TransferBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferBatch", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferBatch indicates an expected call of GetTransferBatch.
+func (mr *MockStoreMockRecorder) GetTransferBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferBatch", reflect.TypeOf((*MockStore)(nil).GetTransferBatch), arg0, arg1)
+}
+
+// GetTransferConfirmation mocks base method.
+func (m *MockStore) GetTransferConfirmation(arg0 context.Context, arg1 int64) (db.TransferConfirmation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferConfirmation", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferConfirmation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferConfirmation indicates an expected call of GetTransferConfirmation.
+func (mr *MockStoreMockRecorder) GetTransferConfirmation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferConfirmation", reflect.TypeOf((*MockStore)(nil).GetTransferConfirmation), arg0, arg1)
+}
+
+// GetTransferConfirmationForUpdate mocks base method.
+func (m *MockStore) GetTransferConfirmationForUpdate(arg0 context.Context, arg1 int64) (db.TransferConfirmation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferConfirmationForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferConfirmation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferConfirmationForUpdate indicates an expected call of GetTransferConfirmationForUpdate.
+func (mr *MockStoreMockRecorder) GetTransferConfirmationForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferConfirmationForUpdate", reflect.TypeOf((*MockStore)(nil).GetTransferConfirmationForUpdate), arg0, arg1)
+}
+
+// GetTransferForUpdate mocks base method.
+func (m *MockStore) GetTransferForUpdate(arg0 context.Context, arg1 int64) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferForUpdate indicates an expected call of GetTransferForUpdate.
+func (mr *MockStoreMockRecorder) GetTransferForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferForUpdate", reflect.TypeOf((*MockStore)(nil).GetTransferForUpdate), arg0, arg1)
+}
+
+// GetTransferGroup mocks base method.
+func (m *MockStore) GetTransferGroup(arg0 context.Context, arg1 int64) (db.TransferGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferGroup", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferGroup indicates an expected call of GetTransferGroup.
+func (mr *MockStoreMockRecorder) GetTransferGroup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferGroup", reflect.TypeOf((*MockStore)(nil).GetTransferGroup), arg0, arg1)
+}
+
+// GetTransferLimit mocks base method.
+func (m *MockStore) GetTransferLimit(arg0 context.Context, arg1 db.GetTransferLimitParams) (db.TransferLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferLimit", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferLimit indicates an expected call of GetTransferLimit.
+func (mr *MockStoreMockRecorder) GetTransferLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferLimit", reflect.TypeOf((*MockStore)(nil).GetTransferLimit), arg0, arg1)
+}
+
+// GetUserByPhone mocks base method.
+func (m *MockStore) GetUserByPhone(arg0 context.Context, arg1 sql.NullString) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByPhone", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByPhone indicates an expected call of GetUserByPhone.
+func (mr *MockStoreMockRecorder) GetUserByPhone(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByPhone", reflect.TypeOf((*MockStore)(nil).GetUserByPhone), arg0, arg1)
+}
+
+// GetUserByVerifiedEmail mocks base method.
+func (m *MockStore) GetUserByVerifiedEmail(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByVerifiedEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByVerifiedEmail indicates an expected call of GetUserByVerifiedEmail.
+func (mr *MockStoreMockRecorder) GetUserByVerifiedEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByVerifiedEmail", reflect.TypeOf((*MockStore)(nil).GetUserByVerifiedEmail), arg0, arg1)
+}
+
+// GetUsers mocks base method.
+func (m *MockStore) GetUsers(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockStoreMockRecorder) GetUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockStore)(nil).GetUsers), arg0, arg1)
+}
+
+// ListAccountDrift mocks base method.
+func (m *MockStore) ListAccountDrift(arg0 context.Context) ([]db.ListAccountDriftRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountDrift", arg0)
+	ret0, _ := ret[0].([]db.ListAccountDriftRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountDrift indicates an expected call of ListAccountDrift.
+func (mr *MockStoreMockRecorder) ListAccountDrift(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountDrift", reflect.TypeOf((*MockStore)(nil).ListAccountDrift), arg0)
+}
+
+// ListAccounts mocks base method.
+func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+}
+
+// ListBeneficiaries mocks base method.
+func (m *MockStore) ListBeneficiaries(arg0 context.Context, arg1 db.ListBeneficiariesParams) ([]db.Beneficiary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBeneficiaries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Beneficiary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBeneficiaries indicates an expected call of ListBeneficiaries.
+func (mr *MockStoreMockRecorder) ListBeneficiaries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBeneficiaries", reflect.TypeOf((*MockStore)(nil).ListBeneficiaries), arg0, arg1)
+}
+
+// ListChainEntries mocks base method.
+func (m *MockStore) ListChainEntries(arg0 context.Context, arg1 db.ListChainEntriesParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChainEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChainEntries indicates an expected call of ListChainEntries.
+func (mr *MockStoreMockRecorder) ListChainEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChainEntries", reflect.TypeOf((*MockStore)(nil).ListChainEntries), arg0, arg1)
+}
+
+// ListChainHeads mocks base method.
+func (m *MockStore) ListChainHeads(arg0 context.Context) ([]db.ListChainHeadsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChainHeads", arg0)
+	ret0, _ := ret[0].([]db.ListChainHeadsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChainHeads indicates an expected call of ListChainHeads.
+func (mr *MockStoreMockRecorder) ListChainHeads(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChainHeads", reflect.TypeOf((*MockStore)(nil).ListChainHeads), arg0)
+}
+
+// ListCurrencyTotals mocks base method.
+func (m *MockStore) ListCurrencyTotals(arg0 context.Context) ([]db.ListCurrencyTotalsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCurrencyTotals", arg0)
+	ret0, _ := ret[0].([]db.ListCurrencyTotalsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCurrencyTotals indicates an expected call of ListCurrencyTotals.
+func (mr *MockStoreMockRecorder) ListCurrencyTotals(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCurrencyTotals", reflect.TypeOf((*MockStore)(nil).ListCurrencyTotals), arg0)
+}
+
+// ListEntries mocks base method.
+func (m *MockStore) ListEntries(arg0 context.Context, arg1 db.ListEntriesParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
+}
+
+// ListEntriesBetween mocks base method.
+func (m *MockStore) ListEntriesBetween(arg0 context.Context, arg1 db.ListEntriesBetweenParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntriesBetween", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntriesBetween indicates an expected call of ListEntriesBetween.
+func (mr *MockStoreMockRecorder) ListEntriesBetween(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntriesBetween", reflect.TypeOf((*MockStore)(nil).ListEntriesBetween), arg0, arg1)
+}
+
+// ListHolidays mocks base method.
+func (m *MockStore) ListHolidays(arg0 context.Context, arg1 db.ListHolidaysParams) ([]db.Holiday, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHolidays", arg0, arg1)
+	ret0, _ := ret[0].([]db.Holiday)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHolidays indicates an expected call of ListHolidays.
+func (mr *MockStoreMockRecorder) ListHolidays(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHolidays", reflect.TypeOf((*MockStore)(nil).ListHolidays), arg0, arg1)
+}
+
+// ListIncomingPaymentRequests mocks base method.
+func (m *MockStore) ListIncomingPaymentRequests(arg0 context.Context, arg1 db.ListIncomingPaymentRequestsParams) ([]db.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIncomingPaymentRequests", arg0, arg1)
+	ret0, _ := ret[0].([]db.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIncomingPaymentRequests indicates an expected call of ListIncomingPaymentRequests.
+func (mr *MockStoreMockRecorder) ListIncomingPaymentRequests(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIncomingPaymentRequests", reflect.TypeOf((*MockStore)(nil).ListIncomingPaymentRequests), arg0, arg1)
+}
+
+// ListOutgoingPaymentRequests mocks base method.
+func (m *MockStore) ListOutgoingPaymentRequests(arg0 context.Context, arg1 db.ListOutgoingPaymentRequestsParams) ([]db.PaymentRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOutgoingPaymentRequests", arg0, arg1)
+	ret0, _ := ret[0].([]db.PaymentRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOutgoingPaymentRequests indicates an expected call of ListOutgoingPaymentRequests.
+func (mr *MockStoreMockRecorder) ListOutgoingPaymentRequests(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOutgoingPaymentRequests", reflect.TypeOf((*MockStore)(nil).ListOutgoingPaymentRequests), arg0, arg1)
+}
+
+// ListPendingOutboundPaymentsForUpdate mocks base method.
+func (m *MockStore) ListPendingOutboundPaymentsForUpdate(arg0 context.Context, arg1 int32) ([]db.OutboundPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingOutboundPaymentsForUpdate", arg0, arg1)
+	ret0, _ := ret[0].([]db.OutboundPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingOutboundPaymentsForUpdate indicates an expected call of ListPendingOutboundPaymentsForUpdate.
+func (mr *MockStoreMockRecorder) ListPendingOutboundPaymentsForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingOutboundPaymentsForUpdate", reflect.TypeOf((*MockStore)(nil).ListPendingOutboundPaymentsForUpdate), arg0, arg1)
+}
+
+// ListPendingTransferApprovals mocks base method.
+func (m *MockStore) ListPendingTransferApprovals(arg0 context.Context, arg1 int64) ([]db.PendingTransferApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingTransferApprovals", arg0, arg1)
+	ret0, _ := ret[0].([]db.PendingTransferApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingTransferApprovals indicates an expected call of ListPendingTransferApprovals.
+func (mr *MockStoreMockRecorder) ListPendingTransferApprovals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingTransferApprovals", reflect.TypeOf((*MockStore)(nil).ListPendingTransferApprovals), arg0, arg1)
+}
+
+// ListPendingTransfers mocks base method.
+func (m *MockStore) ListPendingTransfers(arg0 context.Context, arg1 db.ListPendingTransfersParams) ([]db.PendingTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.PendingTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingTransfers indicates an expected call of ListPendingTransfers.
+func (mr *MockStoreMockRecorder) ListPendingTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingTransfers", reflect.TypeOf((*MockStore)(nil).ListPendingTransfers), arg0, arg1)
+}
+
+// ListPendingTransfersByCreator mocks base method.
+func (m *MockStore) ListPendingTransfersByCreator(arg0 context.Context, arg1 db.ListPendingTransfersByCreatorParams) ([]db.PendingTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingTransfersByCreator", arg0, arg1)
+	ret0, _ := ret[0].([]db.PendingTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingTransfersByCreator indicates an expected call of ListPendingTransfersByCreator.
+func (mr *MockStoreMockRecorder) ListPendingTransfersByCreator(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingTransfersByCreator", reflect.TypeOf((*MockStore)(nil).ListPendingTransfersByCreator), arg0, arg1)
+}
+
+// ListPostings mocks base method.
+func (m *MockStore) ListPostings(arg0 context.Context, arg1 int64) ([]db.Posting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPostings", arg0, arg1)
+	ret0, _ := ret[0].([]db.Posting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPostings indicates an expected call of ListPostings.
+func (mr *MockStoreMockRecorder) ListPostings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPostings", reflect.TypeOf((*MockStore)(nil).ListPostings), arg0, arg1)
+}
+
+// ListReconciliationDiscrepancies mocks base method.
+func (m *MockStore) ListReconciliationDiscrepancies(arg0 context.Context, arg1 int64) ([]db.ReconciliationDiscrepancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReconciliationDiscrepancies", arg0, arg1)
+	ret0, _ := ret[0].([]db.ReconciliationDiscrepancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReconciliationDiscrepancies indicates an expected call of ListReconciliationDiscrepancies.
+func (mr *MockStoreMockRecorder) ListReconciliationDiscrepancies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReconciliationDiscrepancies", reflect.TypeOf((*MockStore)(nil).ListReconciliationDiscrepancies), arg0, arg1)
+}
+
+// ListRiskDecisions mocks base method.
+func (m *MockStore) ListRiskDecisions(arg0 context.Context, arg1 db.ListRiskDecisionsParams) ([]db.RiskDecision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRiskDecisions", arg0, arg1)
+	ret0, _ := ret[0].([]db.RiskDecision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRiskDecisions indicates an expected call of ListRiskDecisions.
+func (mr *MockStoreMockRecorder) ListRiskDecisions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRiskDecisions", reflect.TypeOf((*MockStore)(nil).ListRiskDecisions), arg0, arg1)
+}
+
+// ListRiskRules mocks base method.
+func (m *MockStore) ListRiskRules(arg0 context.Context, arg1 string) ([]db.RiskRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRiskRules", arg0, arg1)
+	ret0, _ := ret[0].([]db.RiskRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRiskRules indicates an expected call of ListRiskRules.
+func (mr *MockStoreMockRecorder) ListRiskRules(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRiskRules", reflect.TypeOf((*MockStore)(nil).ListRiskRules), arg0, arg1)
+}
+
+// ListSanctionsCases mocks base method.
+func (m *MockStore) ListSanctionsCases(arg0 context.Context, arg1 db.ListSanctionsCasesParams) ([]db.SanctionsCase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSanctionsCases", arg0, arg1)
+	ret0, _ := ret[0].([]db.SanctionsCase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSanctionsCases indicates an expected call of ListSanctionsCases.
+func (mr *MockStoreMockRecorder) ListSanctionsCases(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSanctionsCases", reflect.TypeOf((*MockStore)(nil).ListSanctionsCases), arg0, arg1)
+}
+
+// ListScheduledTransferRuns mocks base method.
+func (m *MockStore) ListScheduledTransferRuns(arg0 context.Context, arg1 int64) ([]db.ScheduledTransferRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledTransferRuns", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScheduledTransferRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledTransferRuns indicates an expected call of ListScheduledTransferRuns.
+func (mr *MockStoreMockRecorder) ListScheduledTransferRuns(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledTransferRuns", reflect.TypeOf((*MockStore)(nil).ListScheduledTransferRuns), arg0, arg1)
+}
+
+// ListScheduledTransfers mocks base method.
+func (m *MockStore) ListScheduledTransfers(arg0 context.Context, arg1 db.ListScheduledTransfersParams) ([]db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledTransfers indicates an expected call of ListScheduledTransfers.
+func (mr *MockStoreMockRecorder) ListScheduledTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledTransfers", reflect.TypeOf((*MockStore)(nil).ListScheduledTransfers), arg0, arg1)
+}
+
+// ListStandingOrderOccurrences mocks base method.
+func (m *MockStore) ListStandingOrderOccurrences(arg0 context.Context, arg1 db.ListStandingOrderOccurrencesParams) ([]db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStandingOrderOccurrences", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScheduledTransfer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateAccount indicates an expected call of CreateAccount.
-func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.Call {
+// ListStandingOrderOccurrences indicates an expected call of ListStandingOrderOccurrences.
+func (mr *MockStoreMockRecorder) ListStandingOrderOccurrences(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStandingOrderOccurrences", reflect.TypeOf((*MockStore)(nil).ListStandingOrderOccurrences), arg0, arg1)
 }
 
-// CreateEntry mocks base method.
-func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
+// ListStandingOrders mocks base method.
+func (m *MockStore) ListStandingOrders(arg0 context.Context, arg1 db.ListStandingOrdersParams) ([]db.StandingOrder, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
-	ret0, _ := ret[0].(db.Entry)
+	ret := m.ctrl.Call(m, "ListStandingOrders", arg0, arg1)
+	ret0, _ := ret[0].([]db.StandingOrder)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateEntry indicates an expected call of CreateEntry.
-func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Call {
+// ListStandingOrders indicates an expected call of ListStandingOrders.
+func (mr *MockStoreMockRecorder) ListStandingOrders(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStandingOrders", reflect.TypeOf((*MockStore)(nil).ListStandingOrders), arg0, arg1)
 }
 
-// CreateTransfer mocks base method.
-func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
+// ListTransferBatchItems mocks base method.
+func (m *MockStore) ListTransferBatchItems(arg0 context.Context, arg1 int64) ([]db.TransferBatchItem, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
-	ret0, _ := ret[0].(db.Transfer)
+	ret := m.ctrl.Call(m, "ListTransferBatchItems", arg0, arg1)
+	ret0, _ := ret[0].([]db.TransferBatchItem)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateTransfer indicates an expected call of CreateTransfer.
-func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+// ListTransferBatchItems indicates an expected call of ListTransferBatchItems.
+func (mr *MockStoreMockRecorder) ListTransferBatchItems(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransferBatchItems", reflect.TypeOf((*MockStore)(nil).ListTransferBatchItems), arg0, arg1)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+// ListTransferGroupEntries mocks base method.
+func (m *MockStore) ListTransferGroupEntries(arg0 context.Context, arg1 sql.NullInt64) ([]db.Entry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "ListTransferGroupEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+// ListTransferGroupEntries indicates an expected call of ListTransferGroupEntries.
+func (mr *MockStoreMockRecorder) ListTransferGroupEntries(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransferGroupEntries", reflect.TypeOf((*MockStore)(nil).ListTransferGroupEntries), arg0, arg1)
 }
 
-// DeleteAccount mocks base method.
-func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+// ListTransfers mocks base method.
+func (m *MockStore) ListTransfers(arg0 context.Context, arg1 db.ListTransfersParams) ([]db.Transfer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteAccount indicates an expected call of DeleteAccount.
-func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+// ListTransfers indicates an expected call of ListTransfers.
+func (mr *MockStoreMockRecorder) ListTransfers(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), arg0, arg1)
 }
 
-// GetAccountForUpdate mocks base method.
-func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
+// ListTransfersByIDs mocks base method.
+func (m *MockStore) ListTransfersByIDs(arg0 context.Context, arg1 []int64) ([]db.Transfer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "ListTransfersByIDs", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transfer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
-func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+// ListTransfersByIDs indicates an expected call of ListTransfersByIDs.
+func (mr *MockStoreMockRecorder) ListTransfersByIDs(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfersByIDs", reflect.TypeOf((*MockStore)(nil).ListTransfersByIDs), arg0, arg1)
 }
 
-// GetAccounts mocks base method.
-func (m *MockStore) GetAccounts(arg0 context.Context, arg1 int64) (db.Account, error) {
+// MarkOutboundPaymentExported mocks base method.
+func (m *MockStore) MarkOutboundPaymentExported(arg0 context.Context, arg1 db.MarkOutboundPaymentExportedParams) (db.OutboundPayment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccounts", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "MarkOutboundPaymentExported", arg0, arg1)
+	ret0, _ := ret[0].(db.OutboundPayment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccounts indicates an expected call of GetAccounts.
-func (mr *MockStoreMockRecorder) GetAccounts(arg0, arg1 interface{}) *gomock.Call {
+// MarkOutboundPaymentExported indicates an expected call of MarkOutboundPaymentExported.
+func (mr *MockStoreMockRecorder) MarkOutboundPaymentExported(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockStore)(nil).GetAccounts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboundPaymentExported", reflect.TypeOf((*MockStore)(nil).MarkOutboundPaymentExported), arg0, arg1)
 }
 
-// GetEntry mocks base method.
-func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+// MarkOutboundPaymentReturned mocks base method.
+func (m *MockStore) MarkOutboundPaymentReturned(arg0 context.Context, arg1 db.MarkOutboundPaymentReturnedParams) (db.OutboundPayment, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
-	ret0, _ := ret[0].(db.Entry)
+	ret := m.ctrl.Call(m, "MarkOutboundPaymentReturned", arg0, arg1)
+	ret0, _ := ret[0].(db.OutboundPayment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntry indicates an expected call of GetEntry.
-func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 interface{}) *gomock.Call {
+// MarkOutboundPaymentReturned indicates an expected call of MarkOutboundPaymentReturned.
+func (mr *MockStoreMockRecorder) MarkOutboundPaymentReturned(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboundPaymentReturned", reflect.TypeOf((*MockStore)(nil).MarkOutboundPaymentReturned), arg0, arg1)
 }
 
-// GetTransfer mocks base method.
-func (m *MockStore) GetTransfer(arg0 context.Context, arg1 int64) (db.Transfer, error) {
+// MaterializeStandingOrders mocks base method.
+func (m *MockStore) MaterializeStandingOrders(arg0 context.Context, arg1 time.Time) ([]db.ScheduledTransfer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransfer", arg0, arg1)
+	ret := m.ctrl.Call(m, "MaterializeStandingOrders", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MaterializeStandingOrders indicates an expected call of MaterializeStandingOrders.
+func (mr *MockStoreMockRecorder) MaterializeStandingOrders(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaterializeStandingOrders", reflect.TypeOf((*MockStore)(nil).MaterializeStandingOrders), arg0, arg1)
+}
+
+// MultiTransferTx mocks base method.
+func (m *MockStore) MultiTransferTx(arg0 context.Context, arg1 db.MultiTransferTxParams) (db.MultiTransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MultiTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.MultiTransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MultiTransferTx indicates an expected call of MultiTransferTx.
+func (mr *MockStoreMockRecorder) MultiTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MultiTransferTx", reflect.TypeOf((*MockStore)(nil).MultiTransferTx), arg0, arg1)
+}
+
+// NextAccountNumberSerial mocks base method.
+func (m *MockStore) NextAccountNumberSerial(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextAccountNumberSerial", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextAccountNumberSerial indicates an expected call of NextAccountNumberSerial.
+func (mr *MockStoreMockRecorder) NextAccountNumberSerial(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextAccountNumberSerial", reflect.TypeOf((*MockStore)(nil).NextAccountNumberSerial), arg0)
+}
+
+// PauseStandingOrder mocks base method.
+func (m *MockStore) PauseStandingOrder(arg0 context.Context, arg1 int64) (db.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(db.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PauseStandingOrder indicates an expected call of PauseStandingOrder.
+func (mr *MockStoreMockRecorder) PauseStandingOrder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseStandingOrder", reflect.TypeOf((*MockStore)(nil).PauseStandingOrder), arg0, arg1)
+}
+
+// PayPaymentRequestTx mocks base method.
+func (m *MockStore) PayPaymentRequestTx(arg0 context.Context, arg1 db.PayPaymentRequestTxParams) (db.PayPaymentRequestTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PayPaymentRequestTx", arg0, arg1)
+	ret0, _ := ret[0].(db.PayPaymentRequestTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PayPaymentRequestTx indicates an expected call of PayPaymentRequestTx.
+func (mr *MockStoreMockRecorder) PayPaymentRequestTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayPaymentRequestTx", reflect.TypeOf((*MockStore)(nil).PayPaymentRequestTx), arg0, arg1)
+}
+
+// ProcessQueuedTransfers mocks base method.
+func (m *MockStore) ProcessQueuedTransfers(arg0 context.Context, arg1 int) ([]db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessQueuedTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessQueuedTransfers indicates an expected call of ProcessQueuedTransfers.
+func (mr *MockStoreMockRecorder) ProcessQueuedTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessQueuedTransfers", reflect.TypeOf((*MockStore)(nil).ProcessQueuedTransfers), arg0, arg1)
+}
+
+// QueueTransferTx mocks base method.
+func (m *MockStore) QueueTransferTx(arg0 context.Context, arg1 db.TransferTxParams) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueueTransferTx", arg0, arg1)
 	ret0, _ := ret[0].(db.Transfer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransfer indicates an expected call of GetTransfer.
-func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 interface{}) *gomock.Call {
+// QueueTransferTx indicates an expected call of QueueTransferTx.
+func (mr *MockStoreMockRecorder) QueueTransferTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueTransferTx", reflect.TypeOf((*MockStore)(nil).QueueTransferTx), arg0, arg1)
 }
 
-// GetUsers mocks base method.
-func (m *MockStore) GetUsers(arg0 context.Context, arg1 string) (db.User, error) {
+// Reconcile mocks base method.
+func (m *MockStore) Reconcile(arg0 context.Context, arg1 db.ReconcileParams) (db.ReconciliationResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUsers", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "Reconcile", arg0, arg1)
+	ret0, _ := ret[0].(db.ReconciliationResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUsers indicates an expected call of GetUsers.
-func (mr *MockStoreMockRecorder) GetUsers(arg0, arg1 interface{}) *gomock.Call {
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockStoreMockRecorder) Reconcile(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockStore)(nil).GetUsers), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockStore)(nil).Reconcile), arg0, arg1)
 }
 
-// ListAccounts mocks base method.
-func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.Account, error) {
+// ReturnOutboundPaymentTx mocks base method.
+func (m *MockStore) ReturnOutboundPaymentTx(arg0 context.Context, arg1 db.ReturnOutboundPaymentTxParams) (db.ReturnOutboundPaymentTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
-	ret0, _ := ret[0].([]db.Account)
+	ret := m.ctrl.Call(m, "ReturnOutboundPaymentTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ReturnOutboundPaymentTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAccounts indicates an expected call of ListAccounts.
-func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 interface{}) *gomock.Call {
+// ReturnOutboundPaymentTx indicates an expected call of ReturnOutboundPaymentTx.
+func (mr *MockStoreMockRecorder) ReturnOutboundPaymentTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReturnOutboundPaymentTx", reflect.TypeOf((*MockStore)(nil).ReturnOutboundPaymentTx), arg0, arg1)
 }
 
-// ListEntries mocks base method.
-func (m *MockStore) ListEntries(arg0 context.Context, arg1 db.ListEntriesParams) ([]db.Entry, error) {
+// ReverseTransferTx mocks base method.
+func (m *MockStore) ReverseTransferTx(arg0 context.Context, arg1 db.ReverseTransferTxParams) (db.ReverseTransferTxResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
-	ret0, _ := ret[0].([]db.Entry)
+	ret := m.ctrl.Call(m, "ReverseTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ReverseTransferTxResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListEntries indicates an expected call of ListEntries.
-func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 interface{}) *gomock.Call {
+// ReverseTransferTx indicates an expected call of ReverseTransferTx.
+func (mr *MockStoreMockRecorder) ReverseTransferTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransferTx", reflect.TypeOf((*MockStore)(nil).ReverseTransferTx), arg0, arg1)
 }
 
-// ListTransfers mocks base method.
-func (m *MockStore) ListTransfers(arg0 context.Context, arg1 db.ListTransfersParams) ([]db.Transfer, error) {
+// RunDueScheduledTransfers mocks base method.
+func (m *MockStore) RunDueScheduledTransfers(arg0 context.Context, arg1 db.RunScheduledTransfersParams) ([]db.ScheduledTransferRun, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTransfers", arg0, arg1)
+	ret := m.ctrl.Call(m, "RunDueScheduledTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScheduledTransferRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunDueScheduledTransfers indicates an expected call of RunDueScheduledTransfers.
+func (mr *MockStoreMockRecorder) RunDueScheduledTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunDueScheduledTransfers", reflect.TypeOf((*MockStore)(nil).RunDueScheduledTransfers), arg0, arg1)
+}
+
+// SearchTransfers mocks base method.
+func (m *MockStore) SearchTransfers(arg0 context.Context, arg1 db.SearchTransfersParams) ([]db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchTransfers", arg0, arg1)
 	ret0, _ := ret[0].([]db.Transfer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListTransfers indicates an expected call of ListTransfers.
-func (mr *MockStoreMockRecorder) ListTransfers(arg0, arg1 interface{}) *gomock.Call {
+// SearchTransfers indicates an expected call of SearchTransfers.
+func (mr *MockStoreMockRecorder) SearchTransfers(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTransfers", reflect.TypeOf((*MockStore)(nil).SearchTransfers), arg0, arg1)
+}
+
+// SetAccountFrozen mocks base method.
+func (m *MockStore) SetAccountFrozen(arg0 context.Context, arg1 db.SetAccountFrozenParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccountFrozen", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetAccountFrozen indicates an expected call of SetAccountFrozen.
+func (mr *MockStoreMockRecorder) SetAccountFrozen(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountFrozen", reflect.TypeOf((*MockStore)(nil).SetAccountFrozen), arg0, arg1)
+}
+
+// SetFxQuoteTransfer mocks base method.
+func (m *MockStore) SetFxQuoteTransfer(arg0 context.Context, arg1 db.SetFxQuoteTransferParams) (db.FxQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFxQuoteTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.FxQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFxQuoteTransfer indicates an expected call of SetFxQuoteTransfer.
+func (mr *MockStoreMockRecorder) SetFxQuoteTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFxQuoteTransfer", reflect.TypeOf((*MockStore)(nil).SetFxQuoteTransfer), arg0, arg1)
+}
+
+// SetTransferConfirmationPendingTransfer mocks base method.
+func (m *MockStore) SetTransferConfirmationPendingTransfer(arg0 context.Context, arg1 db.SetTransferConfirmationPendingTransferParams) (db.TransferConfirmation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTransferConfirmationPendingTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferConfirmation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTransferConfirmationPendingTransfer indicates an expected call of SetTransferConfirmationPendingTransfer.
+func (mr *MockStoreMockRecorder) SetTransferConfirmationPendingTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransferConfirmationPendingTransfer", reflect.TypeOf((*MockStore)(nil).SetTransferConfirmationPendingTransfer), arg0, arg1)
+}
+
+// SetTransferConfirmationTransfer mocks base method.
+func (m *MockStore) SetTransferConfirmationTransfer(arg0 context.Context, arg1 db.SetTransferConfirmationTransferParams) (db.TransferConfirmation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTransferConfirmationTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferConfirmation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTransferConfirmationTransfer indicates an expected call of SetTransferConfirmationTransfer.
+func (mr *MockStoreMockRecorder) SetTransferConfirmationTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransferConfirmationTransfer", reflect.TypeOf((*MockStore)(nil).SetTransferConfirmationTransfer), arg0, arg1)
+}
+
+// SetTransferFee mocks base method.
+func (m *MockStore) SetTransferFee(arg0 context.Context, arg1 db.SetTransferFeeParams) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTransferFee", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTransferFee indicates an expected call of SetTransferFee.
+func (mr *MockStoreMockRecorder) SetTransferFee(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransferFee", reflect.TypeOf((*MockStore)(nil).SetTransferFee), arg0, arg1)
+}
+
+// StartTransferBatch mocks base method.
+func (m *MockStore) StartTransferBatch(arg0 context.Context, arg1 int64) (db.TransferBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartTransferBatch", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartTransferBatch indicates an expected call of StartTransferBatch.
+func (mr *MockStoreMockRecorder) StartTransferBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTransferBatch", reflect.TypeOf((*MockStore)(nil).StartTransferBatch), arg0, arg1)
+}
+
+// SumACHFileEntriesSince mocks base method.
+func (m *MockStore) SumACHFileEntriesSince(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumACHFileEntriesSince", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumACHFileEntriesSince indicates an expected call of SumACHFileEntriesSince.
+func (mr *MockStoreMockRecorder) SumACHFileEntriesSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumACHFileEntriesSince", reflect.TypeOf((*MockStore)(nil).SumACHFileEntriesSince), arg0, arg1)
 }
 
 // TransferTx mocks base method.
@@ -273,3 +2433,78 @@ func (mr *MockStoreMockRecorder) UpdateAccount(arg0, arg1 interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockStore)(nil).UpdateAccount), arg0, arg1)
 }
+
+// UpdateScheduledTransferRun mocks base method.
+func (m *MockStore) UpdateScheduledTransferRun(arg0 context.Context, arg1 db.UpdateScheduledTransferRunParams) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduledTransferRun", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScheduledTransferRun indicates an expected call of UpdateScheduledTransferRun.
+func (mr *MockStoreMockRecorder) UpdateScheduledTransferRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduledTransferRun", reflect.TypeOf((*MockStore)(nil).UpdateScheduledTransferRun), arg0, arg1)
+}
+
+// UpdateStandingOrder mocks base method.
+func (m *MockStore) UpdateStandingOrder(arg0 context.Context, arg1 db.UpdateStandingOrderParams) (db.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStandingOrder", arg0, arg1)
+	ret0, _ := ret[0].(db.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStandingOrder indicates an expected call of UpdateStandingOrder.
+func (mr *MockStoreMockRecorder) UpdateStandingOrder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStandingOrder", reflect.TypeOf((*MockStore)(nil).UpdateStandingOrder), arg0, arg1)
+}
+
+// UpdateStandingOrderTx mocks base method.
+func (m *MockStore) UpdateStandingOrderTx(arg0 context.Context, arg1 db.UpdateStandingOrderTxParams) (db.StandingOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStandingOrderTx", arg0, arg1)
+	ret0, _ := ret[0].(db.StandingOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStandingOrderTx indicates an expected call of UpdateStandingOrderTx.
+func (mr *MockStoreMockRecorder) UpdateStandingOrderTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStandingOrderTx", reflect.TypeOf((*MockStore)(nil).UpdateStandingOrderTx), arg0, arg1)
+}
+
+// UpdateTransferStatus mocks base method.
+func (m *MockStore) UpdateTransferStatus(arg0 context.Context, arg1 db.UpdateTransferStatusParams) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTransferStatus", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTransferStatus indicates an expected call of UpdateTransferStatus.
+func (mr *MockStoreMockRecorder) UpdateTransferStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTransferStatus", reflect.TypeOf((*MockStore)(nil).UpdateTransferStatus), arg0, arg1)
+}
+
+// VerifyUserEmail mocks base method.
+func (m *MockStore) VerifyUserEmail(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyUserEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyUserEmail indicates an expected call of VerifyUserEmail.
+func (mr *MockStoreMockRecorder) VerifyUserEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyUserEmail", reflect.TypeOf((*MockStore)(nil).VerifyUserEmail), arg0, arg1)
+}
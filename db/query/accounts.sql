@@ -1,9 +1,18 @@
 -- name: CreateAccount :one
-INSERT INTO accounts(owner, balance, currency) VALUES ($1, $2, $3) RETURNING *;
+INSERT INTO accounts(owner, balance, currency, account_number) VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: NextAccountNumberSerial :one
+SELECT nextval('account_number_seq')::bigint;
 
 -- name: GetAccounts :one
 SELECT * FROM accounts WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountByNumber :one
+SELECT * FROM accounts WHERE account_number = $1 LIMIT 1;
+
+-- name: GetAccountByOwnerAndCurrency :one
+SELECT * FROM accounts WHERE owner = $1 AND currency = $2 LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE;
 
@@ -19,3 +28,11 @@ UPDATE accounts SET balance = $2 WHERE id = $1 RETURNING *;
 -- name: DeleteAccount :exec
 DELETE FROM accounts WHERE id = $1;
 
+
+-- name: SetAccountFrozen :one
+UPDATE accounts SET frozen = $2 WHERE id = $1 RETURNING *;
+
+-- name: ListChainHeads :many
+SELECT id, last_entry_seq, last_entry_hash FROM accounts
+WHERE last_entry_seq > 0
+ORDER BY id;
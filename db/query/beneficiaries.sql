@@ -0,0 +1,21 @@
+-- name: CreateBeneficiary :one
+INSERT INTO beneficiaries (
+  owner,
+  name,
+  routing_number,
+  account_number,
+  account_type
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetBeneficiary :one
+SELECT * FROM beneficiaries
+WHERE id = $1 LIMIT 1;
+
+-- name: ListBeneficiaries :many
+SELECT * FROM beneficiaries
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
@@ -1,9 +1,19 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3
+) RETURNING *;
+
+-- name: CreateFeeEntry :one
+INSERT INTO entries (
+  account_id,
+  amount,
+  fee_transfer_id
+) VALUES (
+  $1, $2, $3
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -15,4 +25,41 @@ SELECT * FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- name: GetDailyDebitUsage :one
+SELECT COALESCE(SUM(-entries.amount), 0)::bigint AS total_amount, COUNT(*) AS debit_count
+FROM entries
+LEFT JOIN transfers ON transfers.id = entries.transfer_id
+WHERE entries.account_id = sqlc.arg(account_id)::bigint
+  AND entries.amount < 0
+  AND entries.fee_transfer_id IS NULL
+  AND entries.created_at >= sqlc.arg(since)::timestamptz
+  AND (
+    entries.transfer_group_id IS NOT NULL
+    OR (transfers.from_account_id = entries.account_id AND transfers.reversal_of_transfer_id IS NULL)
+  );
+
+-- name: ListEntriesBetween :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)::bigint
+  AND created_at >= sqlc.arg(created_from)::timestamptz
+  AND created_at < sqlc.arg(created_to)::timestamptz
+ORDER BY id;
+
+-- name: GetEntriesTotalSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint FROM entries
+WHERE account_id = sqlc.arg(account_id)::bigint
+  AND created_at >= sqlc.arg(since)::timestamptz;
+
+-- name: ListChainEntries :many
+SELECT * FROM entries
+WHERE account_id IS NOT NULL
+  AND (account_id, chain_seq) > (sqlc.arg(after_account_id)::bigint, sqlc.arg(after_chain_seq)::bigint)
+ORDER BY account_id, chain_seq
+LIMIT sqlc.arg(page_size)::int;
+
+-- name: GetChainEntry :one
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)::bigint AND chain_seq = sqlc.arg(chain_seq)::bigint
+LIMIT 1;
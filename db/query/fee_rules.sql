@@ -0,0 +1,20 @@
+-- name: CreateFeeRule :one
+INSERT INTO fee_rules (
+  currency,
+  cross_currency,
+  flat_amount,
+  percentage_bps,
+  min_amount,
+  max_amount
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetFeeRule :one
+SELECT * FROM fee_rules
+WHERE currency = $1 AND cross_currency = $2
+LIMIT 1;
+
+-- name: DeleteFeeRule :exec
+DELETE FROM fee_rules
+WHERE id = $1;
@@ -0,0 +1,41 @@
+-- name: CreateFxRate :one
+INSERT INTO fx_rates (
+  base_currency,
+  quote_currency,
+  rate,
+  valid_from
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetLatestFxRate :one
+SELECT * FROM fx_rates
+WHERE base_currency = $1 AND quote_currency = $2 AND valid_from <= now()
+ORDER BY valid_from DESC
+LIMIT 1;
+
+-- name: CreateFxQuote :one
+INSERT INTO fx_quotes (
+  username,
+  fx_rate_id,
+  from_currency,
+  to_currency,
+  rate,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetFxQuote :one
+SELECT * FROM fx_quotes
+WHERE id = $1 LIMIT 1;
+
+-- name: GetFxQuoteForUpdate :one
+SELECT * FROM fx_quotes
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: SetFxQuoteTransfer :one
+UPDATE fx_quotes SET transfer_id = $2
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,12 @@
+-- name: CreateHoliday :one
+INSERT INTO holidays (
+  date,
+  name
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: ListHolidays :many
+SELECT * FROM holidays
+WHERE date BETWEEN sqlc.arg(from_date)::date AND sqlc.arg(to_date)::date
+ORDER BY date;
@@ -0,0 +1,30 @@
+-- name: CreateJournalEntry :one
+INSERT INTO journal_entries (
+  transfer_id,
+  transfer_group_id,
+  description
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: CreatePostings :many
+INSERT INTO postings (journal_entry_id, entry_id, account_id, currency, amount)
+SELECT sqlc.arg(journal_entry_id)::bigint, entries.id, entries.account_id, accounts.currency, entries.amount
+FROM entries
+JOIN accounts ON accounts.id = entries.account_id
+WHERE entries.id = ANY(sqlc.arg(entry_ids)::bigint[])
+ORDER BY entries.id
+RETURNING *;
+
+-- name: GetJournalEntry :one
+SELECT * FROM journal_entries
+WHERE id = $1 LIMIT 1;
+
+-- name: GetJournalEntryByTransfer :one
+SELECT * FROM journal_entries
+WHERE transfer_id = $1 LIMIT 1;
+
+-- name: ListPostings :many
+SELECT * FROM postings
+WHERE journal_entry_id = $1
+ORDER BY id;
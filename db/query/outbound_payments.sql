@@ -0,0 +1,67 @@
+-- name: CreateOutboundPayment :one
+INSERT INTO outbound_payments (
+  transfer_id,
+  from_account_id,
+  beneficiary_id,
+  beneficiary_name,
+  routing_number,
+  account_number,
+  account_type,
+  amount
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetOutboundPayment :one
+SELECT * FROM outbound_payments
+WHERE id = $1 LIMIT 1;
+
+-- name: GetOutboundPaymentByTransfer :one
+SELECT * FROM outbound_payments
+WHERE transfer_id = $1 LIMIT 1;
+
+-- name: GetOutboundPaymentByTraceForUpdate :one
+SELECT * FROM outbound_payments
+WHERE trace_number = sqlc.arg(trace_number)::varchar
+  AND amount = sqlc.arg(amount)::bigint
+  AND upper(account_number) = upper(sqlc.arg(account_number)::varchar)
+ORDER BY exported_at DESC
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPendingOutboundPaymentsForUpdate :many
+SELECT * FROM outbound_payments
+WHERE status = 'pending'
+ORDER BY id
+LIMIT $1
+FOR NO KEY UPDATE SKIP LOCKED;
+
+-- name: MarkOutboundPaymentExported :one
+UPDATE outbound_payments
+SET status = 'exported', ach_file_id = sqlc.arg(ach_file_id)::bigint, trace_number = sqlc.arg(trace_number)::varchar, trace_date = sqlc.arg(trace_date)::date, exported_at = now()
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: MarkOutboundPaymentReturned :one
+UPDATE outbound_payments
+SET status = 'returned', return_code = sqlc.arg(return_code)::varchar, return_transfer_id = sqlc.arg(return_transfer_id)::bigint, returned_at = now()
+WHERE id = sqlc.arg(id) AND status = 'exported'
+RETURNING *;
+
+-- name: CreateACHFile :one
+INSERT INTO ach_files (
+  file_name,
+  file_id_modifier,
+  entry_count,
+  total_amount
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: CountACHFilesSince :one
+SELECT COUNT(*) FROM ach_files
+WHERE created_at >= sqlc.arg(since)::timestamptz;
+
+-- name: SumACHFileEntriesSince :one
+SELECT COALESCE(SUM(entry_count), 0)::bigint FROM ach_files
+WHERE created_at >= sqlc.arg(since)::timestamptz;
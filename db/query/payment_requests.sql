@@ -0,0 +1,43 @@
+-- name: CreatePaymentRequest :one
+INSERT INTO payment_requests (
+  requester_account_id,
+  payer,
+  amount,
+  currency,
+  note,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetPaymentRequest :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPaymentRequestForUpdate :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListIncomingPaymentRequests :many
+SELECT * FROM payment_requests
+WHERE payer = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListOutgoingPaymentRequests :many
+SELECT * FROM payment_requests
+WHERE requester_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner)::varchar)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: DecidePaymentRequest :one
+UPDATE payment_requests
+SET
+  status = sqlc.arg(status),
+  transfer_id = sqlc.narg(transfer_id),
+  decided_at = now()
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
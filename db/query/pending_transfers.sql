@@ -0,0 +1,74 @@
+-- name: CreatePendingTransfer :one
+INSERT INTO pending_transfers (
+  created_by,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  expires_at,
+  memo,
+  reference,
+  category,
+  risk_decision_id,
+  kind,
+  fx_quote_id,
+  payment_request_id,
+  beneficiary_id,
+  transfer_batch_id,
+  scheduled_transfer_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+) RETURNING *;
+
+-- name: GetPendingTransfer :one
+SELECT * FROM pending_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPendingTransferForUpdate :one
+SELECT * FROM pending_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPendingTransfers :many
+SELECT * FROM pending_transfers
+WHERE status = $1
+ORDER BY created_at, id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListPendingTransfersByCreator :many
+SELECT * FROM pending_transfers
+WHERE created_by = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: DecidePendingTransfer :one
+UPDATE pending_transfers
+SET
+  status = sqlc.arg(status),
+  transfer_id = sqlc.narg(transfer_id),
+  decided_at = now()
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: ExpirePendingTransfers :many
+UPDATE pending_transfers
+SET status = 'expired', decided_at = now()
+WHERE status = 'pending' AND expires_at <= now()
+RETURNING *;
+
+-- name: CreatePendingTransferApproval :one
+INSERT INTO pending_transfer_approvals (
+  pending_transfer_id,
+  username,
+  decision,
+  comment
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListPendingTransferApprovals :many
+SELECT * FROM pending_transfer_approvals
+WHERE pending_transfer_id = $1
+ORDER BY id;
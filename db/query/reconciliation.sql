@@ -0,0 +1,56 @@
+-- name: CountAccounts :one
+SELECT COUNT(*) FROM accounts;
+
+-- name: ListAccountDrift :many
+SELECT accounts.id, accounts.owner, accounts.currency, accounts.balance, COALESCE(totals.entries_total, 0)::bigint AS entries_total
+FROM accounts
+LEFT JOIN (
+  SELECT account_id, SUM(amount) AS entries_total FROM entries GROUP BY account_id
+) totals ON totals.account_id = accounts.id
+WHERE accounts.balance <> COALESCE(totals.entries_total, 0)
+ORDER BY accounts.id;
+
+-- name: ListCurrencyTotals :many
+SELECT accounts.currency, SUM(accounts.balance)::bigint AS balance_total, COALESCE(SUM(totals.entries_total), 0)::bigint AS entries_total
+FROM accounts
+LEFT JOIN (
+  SELECT account_id, SUM(amount) AS entries_total FROM entries GROUP BY account_id
+) totals ON totals.account_id = accounts.id
+GROUP BY accounts.currency
+ORDER BY accounts.currency;
+
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (
+  repair,
+  accounts_checked,
+  discrepancies
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: CreateReconciliationDiscrepancy :one
+INSERT INTO reconciliation_discrepancies (
+  run_id,
+  account_id,
+  currency,
+  balance,
+  entries_total,
+  difference,
+  journal_entry_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetReconciliationRun :one
+SELECT * FROM reconciliation_runs
+WHERE id = $1 LIMIT 1;
+
+-- name: GetLatestReconciliationRun :one
+SELECT * FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListReconciliationDiscrepancies :many
+SELECT * FROM reconciliation_discrepancies
+WHERE run_id = $1
+ORDER BY account_id NULLS LAST, currency;
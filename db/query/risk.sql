@@ -0,0 +1,59 @@
+-- name: CreateRiskRule :one
+INSERT INTO risk_rules (
+  name,
+  kind,
+  action,
+  currency,
+  max_count,
+  window_minutes,
+  min_amount,
+  multiplier
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListRiskRules :many
+SELECT * FROM risk_rules
+WHERE enabled AND (currency IS NULL OR currency = sqlc.arg(currency)::varchar)
+ORDER BY id;
+
+-- name: DeleteRiskRule :exec
+DELETE FROM risk_rules
+WHERE id = $1;
+
+-- name: CreateRiskDecision :one
+INSERT INTO risk_decisions (
+  username,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  decision,
+  fired_rules
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ListRiskDecisions :many
+SELECT * FROM risk_decisions
+WHERE decision = ANY(sqlc.arg(decisions)::varchar[])
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: CountTransfersSince :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = sqlc.arg(from_account_id)::bigint
+  AND created_at >= sqlc.arg(since)::timestamptz;
+
+-- name: CountTransfersTo :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = sqlc.arg(from_account_id)::bigint
+  AND to_account_id = sqlc.arg(to_account_id)::bigint
+  AND status <> 'failed';
+
+-- name: GetTransferAverage :one
+SELECT COUNT(*) AS transfer_count, COALESCE(AVG(amount), 0)::bigint AS average_amount
+FROM transfers
+WHERE from_account_id = sqlc.arg(from_account_id)::bigint
+  AND status <> 'failed';
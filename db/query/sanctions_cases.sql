@@ -0,0 +1,39 @@
+-- name: CreateSanctionsCase :one
+INSERT INTO sanctions_cases (
+  operation,
+  initiated_by,
+  screened_name,
+  screened_username,
+  account_id,
+  list_entry_id,
+  list_entry_name,
+  score,
+  action
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetSanctionsCase :one
+SELECT * FROM sanctions_cases
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSanctionsCases :many
+SELECT * FROM sanctions_cases
+WHERE status = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ClearSanctionsCase :one
+UPDATE sanctions_cases
+SET status = 'cleared', resolved_by = sqlc.arg(resolved_by)::varchar, resolution_note = sqlc.arg(resolution_note), resolved_at = now()
+WHERE id = sqlc.arg(id) AND status = 'open'
+RETURNING *;
+
+-- name: CountClearedSanctionsMatches :one
+SELECT COUNT(*) FROM sanctions_cases
+WHERE screened_name = $1 AND list_entry_id = $2 AND status = 'cleared';
+
+-- name: CountOpenSanctionsCasesForTransfer :one
+SELECT COUNT(*) FROM sanctions_cases
+WHERE status = 'open' AND account_id IN (sqlc.arg(from_account_id)::bigint, sqlc.arg(to_account_id)::bigint);
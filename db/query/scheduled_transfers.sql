@@ -0,0 +1,90 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  username,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  execute_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE username = $1
+ORDER BY execute_at DESC, id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers SET status = 'cancelled'
+WHERE id = $1 AND status = 'scheduled'
+RETURNING *;
+
+-- name: ClaimDueScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE status = 'scheduled' AND execute_at <= now()
+ORDER BY execute_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateScheduledTransferRun :one
+UPDATE scheduled_transfers
+SET
+  status = sqlc.arg(status),
+  attempts = attempts + 1,
+  execute_at = sqlc.arg(execute_at),
+  transfer_id = sqlc.narg(transfer_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DecideHeldScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  status = sqlc.arg(status),
+  transfer_id = sqlc.narg(transfer_id)
+WHERE id = sqlc.arg(id) AND status = 'held'
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+  scheduled_transfer_id,
+  outcome,
+  reason,
+  transfer_id
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id;
+
+-- name: CreateStandingOrderOccurrence :one
+INSERT INTO scheduled_transfers (
+  username,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  execute_at,
+  standing_order_id,
+  occurrence_date
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (standing_order_id, occurrence_date) DO NOTHING
+RETURNING *;
+
+-- name: ListStandingOrderOccurrences :many
+SELECT * FROM scheduled_transfers
+WHERE standing_order_id = $1
+ORDER BY occurrence_date DESC
+LIMIT $2
+OFFSET $3;
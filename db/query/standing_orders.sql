@@ -0,0 +1,70 @@
+-- name: CreateStandingOrder :one
+INSERT INTO standing_orders (
+  username,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  frequency,
+  weekday,
+  day_of_month,
+  start_date,
+  end_date,
+  max_occurrences,
+  next_nominal_date,
+  next_run_date
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING *;
+
+-- name: GetStandingOrder :one
+SELECT * FROM standing_orders
+WHERE id = $1 LIMIT 1;
+
+-- name: GetStandingOrderForUpdate :one
+SELECT * FROM standing_orders
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ListStandingOrders :many
+SELECT * FROM standing_orders
+WHERE username = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ClaimDueStandingOrder :one
+SELECT * FROM standing_orders
+WHERE status = 'active' AND next_run_date <= sqlc.arg(today)::date
+ORDER BY next_run_date
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateStandingOrder :one
+UPDATE standing_orders
+SET
+  amount = sqlc.arg(amount),
+  frequency = sqlc.arg(frequency),
+  weekday = sqlc.narg(weekday),
+  day_of_month = sqlc.narg(day_of_month),
+  end_date = sqlc.narg(end_date),
+  max_occurrences = sqlc.narg(max_occurrences),
+  occurrences = sqlc.arg(occurrences),
+  next_nominal_date = sqlc.arg(next_nominal_date),
+  next_run_date = sqlc.arg(next_run_date),
+  status = sqlc.arg(status),
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: PauseStandingOrder :one
+UPDATE standing_orders
+SET status = 'paused', updated_at = now()
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: CancelStandingOrder :one
+UPDATE standing_orders
+SET status = 'cancelled', updated_at = now()
+WHERE id = $1 AND status IN ('active', 'paused')
+RETURNING *;
@@ -0,0 +1,60 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (
+  username,
+  from_account_id,
+  currency,
+  mode,
+  item_count,
+  total_amount,
+  message_id,
+  payment_information_id,
+  status
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: CreateTransferBatchItem :one
+INSERT INTO transfer_batch_items (
+  batch_id,
+  line,
+  to_account_id,
+  amount,
+  reference,
+  end_to_end_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetTransferBatch :one
+SELECT * FROM transfer_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: ListTransferBatchItems :many
+SELECT * FROM transfer_batch_items
+WHERE batch_id = $1
+ORDER BY line;
+
+-- name: StartTransferBatch :one
+UPDATE transfer_batches SET status = 'processing'
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: DecideHeldTransferBatch :one
+UPDATE transfer_batches SET status = $2
+WHERE id = $1 AND status = 'held'
+RETURNING *;
+
+-- name: FinishTransferBatch :one
+UPDATE transfer_batches SET status = $2, completed_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CompleteTransferBatchItem :one
+UPDATE transfer_batch_items SET status = 'completed', transfer_id = sqlc.arg(transfer_id)
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: FailTransferBatchItem :one
+UPDATE transfer_batch_items SET status = 'failed', reason = $2, failure_code = $3
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,35 @@
+-- name: CreateTransferConfirmation :one
+INSERT INTO transfer_confirmations (
+  username,
+  from_account_id,
+  to_account_id,
+  alias,
+  amount,
+  currency,
+  memo,
+  reference,
+  category,
+  expires_at,
+  risk_decision_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: GetTransferConfirmation :one
+SELECT * FROM transfer_confirmations
+WHERE id = $1 LIMIT 1;
+
+-- name: GetTransferConfirmationForUpdate :one
+SELECT * FROM transfer_confirmations
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: SetTransferConfirmationTransfer :one
+UPDATE transfer_confirmations SET transfer_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: SetTransferConfirmationPendingTransfer :one
+UPDATE transfer_confirmations SET pending_transfer_id = $2
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,26 @@
+-- name: CreateTransferGroup :one
+INSERT INTO transfer_groups (
+  currency,
+  total_amount,
+  description
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetTransferGroup :one
+SELECT * FROM transfer_groups
+WHERE id = $1 LIMIT 1;
+
+-- name: CreateTransferGroupEntry :one
+INSERT INTO entries (
+  account_id,
+  amount,
+  transfer_group_id
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: ListTransferGroupEntries :many
+SELECT * FROM entries
+WHERE transfer_group_id = $1
+ORDER BY id;
@@ -0,0 +1,20 @@
+-- name: CreateTransferLimit :one
+INSERT INTO transfer_limits (
+  account_id,
+  tier,
+  currency,
+  max_single_amount,
+  max_daily_amount,
+  max_daily_count
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetTransferLimit :one
+SELECT * FROM transfer_limits
+WHERE currency = sqlc.arg(currency) AND (
+    account_id = sqlc.arg(account_id)::bigint OR
+    (account_id IS NULL AND tier = sqlc.arg(tier)::varchar)
+)
+ORDER BY account_id NULLS LAST
+LIMIT 1;
@@ -2,9 +2,14 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  fee,
+  memo,
+  reference,
+  category,
+  status
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetTransfer :one
@@ -18,4 +23,70 @@ WHERE
     to_account_id = $2
 ORDER BY id
 LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ClaimQueuedTransfer :one
+SELECT * FROM transfers
+WHERE status = 'pending'
+ORDER BY id
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET
+  status = sqlc.arg(status),
+  failure_reason = sqlc.arg(failure_reason),
+  status_changed_at = now()
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)::varchar
+RETURNING *;
+
+-- name: SetTransferFee :one
+UPDATE transfers
+SET fee = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateReversalTransfer :one
+INSERT INTO transfers (
+  from_account_id,
+  to_account_id,
+  amount,
+  reversal_of_transfer_id
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS reversed_amount
+FROM transfers
+WHERE reversal_of_transfer_id = sqlc.arg(transfer_id)::bigint;
+
+-- name: SearchTransfers :many
+SELECT * FROM transfers
+WHERE
+    (from_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner)::varchar) OR
+     to_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner)::varchar)) AND
+    (sqlc.narg(query)::text IS NULL OR
+     to_tsvector('simple', memo) @@ plainto_tsquery('simple', sqlc.narg(query)::text)) AND
+    (sqlc.narg(counterparty_account_id)::bigint IS NULL OR
+     from_account_id = sqlc.narg(counterparty_account_id)::bigint OR
+     to_account_id = sqlc.narg(counterparty_account_id)::bigint) AND
+    (sqlc.narg(category)::varchar IS NULL OR category = sqlc.narg(category)::varchar) AND
+    (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from)::timestamptz) AND
+    (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to)::timestamptz) AND
+    (sqlc.narg(min_amount)::bigint IS NULL OR amount >= sqlc.narg(min_amount)::bigint) AND
+    (sqlc.narg(max_amount)::bigint IS NULL OR amount <= sqlc.narg(max_amount)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListTransfersByIDs :many
+SELECT * FROM transfers
+WHERE id = ANY(sqlc.arg(ids)::bigint[])
+ORDER BY id;
@@ -1,5 +1,18 @@
 -- name: CreateUser :one
-INSERT INTO users(username, hashed_password, full_name, email) VALUES ($1, $2, $3, $4) RETURNING *;
+INSERT INTO users(username, hashed_password, full_name, email, phone) VALUES ($1, $2, $3, $4, $5) RETURNING *;
 
 -- name: GetUsers :one
 SELECT * FROM users WHERE username = $1 LIMIT 1;
+
+-- name: GetUserByVerifiedEmail :one
+SELECT * FROM users
+WHERE email = $1 AND email_verified_at IS NOT NULL
+LIMIT 1;
+
+-- name: GetUserByPhone :one
+SELECT * FROM users WHERE phone = $1 LIMIT 1;
+
+-- name: VerifyUserEmail :one
+UPDATE users SET email_verified_at = now()
+WHERE username = $1
+RETURNING *;
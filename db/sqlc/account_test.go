@@ -12,9 +12,10 @@ import (
 func createRandomAccount(t *testing.T) Account {
 	user := createRandomUser(t)
 	arg := CreateAccountParams{
-		Owner:    user.Username,
-		Balance:  utils.RandomMoney(),
-		Currency: utils.RandomCurrency(),
+		Owner:         user.Username,
+		Balance:       utils.RandomMoney(),
+		Currency:      utils.RandomCurrency(),
+		AccountNumber: utils.RandomAccountNumber(),
 	}
 	account, err := testQueries.CreateAccount(context.Background(), arg)
 	require.NoError(t, err)
@@ -22,6 +23,7 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.AccountNumber, account.AccountNumber)
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
 	return account
@@ -45,6 +47,25 @@ func TestGetAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt.Time, account2.CreatedAt.Time, time.Second)
 }
 
+func TestGetAccountByNumber(t *testing.T) {
+	account1 := createRandomAccount(t)
+
+	account2, err := testQueries.GetAccountByNumber(context.Background(), account1.AccountNumber)
+	require.NoError(t, err)
+	require.NotEmpty(t, account2)
+
+	require.Equal(t, account1.ID, account2.ID)
+	require.Equal(t, account1.AccountNumber, account2.AccountNumber)
+}
+
+func TestNextAccountNumberSerial(t *testing.T) {
+	serial1, err := testQueries.NextAccountNumberSerial(context.Background())
+	require.NoError(t, err)
+	serial2, err := testQueries.NextAccountNumberSerial(context.Background())
+	require.NoError(t, err)
+	require.Greater(t, serial2, serial1)
+}
+
 func TestUpdateAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
 
@@ -10,7 +10,7 @@ import (
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
-UPDATE accounts SET balance = balance + $1 WHERE id =$2 RETURNING id, owner, balance, currency, created_at
+UPDATE accounts SET balance = balance + $1 WHERE id =$2 RETURNING id, owner, balance, currency, created_at, account_number, frozen, last_entry_seq, last_entry_hash
 `
 
 type AddAccountBalanceParams struct {
@@ -19,7 +19,7 @@ type AddAccountBalanceParams struct {
 }
 
 func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, addAccountBalance, arg.Ammount, arg.ID)
+	row := q.db.QueryRow(ctx, addAccountBalance, arg.Ammount, arg.ID)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -27,22 +27,32 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.Frozen,
+		&i.LastEntrySeq,
+		&i.LastEntryHash,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO accounts(owner, balance, currency) VALUES ($1, $2, $3) RETURNING id, owner, balance, currency, created_at
+INSERT INTO accounts(owner, balance, currency, account_number) VALUES ($1, $2, $3, $4) RETURNING id, owner, balance, currency, created_at, account_number, frozen, last_entry_seq, last_entry_hash
 `
 
 type CreateAccountParams struct {
-	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
-	Currency string `json:"currency"`
+	Owner         string `json:"owner"`
+	Balance       int64  `json:"balance"`
+	Currency      string `json:"currency"`
+	AccountNumber string `json:"account_number"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRow(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.AccountNumber,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -50,6 +60,10 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.Frozen,
+		&i.LastEntrySeq,
+		&i.LastEntryHash,
 	)
 	return i, err
 }
@@ -59,16 +73,63 @@ DELETE FROM accounts WHERE id = $1
 `
 
 func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteAccount, id)
+	_, err := q.db.Exec(ctx, deleteAccount, id)
 	return err
 }
 
+const getAccountByNumber = `-- name: GetAccountByNumber :one
+SELECT id, owner, balance, currency, created_at, account_number, frozen, last_entry_seq, last_entry_hash FROM accounts WHERE account_number = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountByNumber(ctx context.Context, accountNumber string) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByNumber, accountNumber)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.Frozen,
+		&i.LastEntrySeq,
+		&i.LastEntryHash,
+	)
+	return i, err
+}
+
+const getAccountByOwnerAndCurrency = `-- name: GetAccountByOwnerAndCurrency :one
+SELECT id, owner, balance, currency, created_at, account_number, frozen, last_entry_seq, last_entry_hash FROM accounts WHERE owner = $1 AND currency = $2 LIMIT 1
+`
+
+type GetAccountByOwnerAndCurrencyParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByOwnerAndCurrency, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.Frozen,
+		&i.LastEntrySeq,
+		&i.LastEntryHash,
+	)
+	return i, err
+}
+
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE
+SELECT id, owner, balance, currency, created_at, account_number, frozen, last_entry_seq, last_entry_hash FROM accounts WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE
 `
 
 func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
-	row := q.db.QueryRowContext(ctx, getAccountForUpdate, id)
+	row := q.db.QueryRow(ctx, getAccountForUpdate, id)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -76,16 +137,20 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.Frozen,
+		&i.LastEntrySeq,
+		&i.LastEntryHash,
 	)
 	return i, err
 }
 
 const getAccounts = `-- name: GetAccounts :one
-SELECT id, owner, balance, currency, created_at FROM accounts WHERE id = $1 LIMIT 1
+SELECT id, owner, balance, currency, created_at, account_number, frozen, last_entry_seq, last_entry_hash FROM accounts WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetAccounts(ctx context.Context, id int64) (Account, error) {
-	row := q.db.QueryRowContext(ctx, getAccounts, id)
+	row := q.db.QueryRow(ctx, getAccounts, id)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -93,12 +158,16 @@ func (q *Queries) GetAccounts(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.Frozen,
+		&i.LastEntrySeq,
+		&i.LastEntryHash,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts ORDER BY id DESC LIMIT $1 OFFSET $2
+SELECT id, owner, balance, currency, created_at, account_number, frozen, last_entry_seq, last_entry_hash FROM accounts ORDER BY id DESC LIMIT $1 OFFSET $2
 `
 
 type ListAccountsParams struct {
@@ -107,7 +176,7 @@ type ListAccountsParams struct {
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listAccounts, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -121,22 +190,96 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.AccountNumber,
+			&i.Frozen,
+			&i.LastEntrySeq,
+			&i.LastEntryHash,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChainHeads = `-- name: ListChainHeads :many
+SELECT id, last_entry_seq, last_entry_hash FROM accounts
+WHERE last_entry_seq > 0
+ORDER BY id
+`
+
+type ListChainHeadsRow struct {
+	ID            int64  `json:"id"`
+	LastEntrySeq  int64  `json:"last_entry_seq"`
+	LastEntryHash []byte `json:"last_entry_hash"`
+}
+
+func (q *Queries) ListChainHeads(ctx context.Context) ([]ListChainHeadsRow, error) {
+	rows, err := q.db.Query(ctx, listChainHeads)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+	items := []ListChainHeadsRow{}
+	for rows.Next() {
+		var i ListChainHeadsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LastEntrySeq,
+			&i.LastEntryHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
+const nextAccountNumberSerial = `-- name: NextAccountNumberSerial :one
+SELECT nextval('account_number_seq')::bigint
+`
+
+func (q *Queries) NextAccountNumberSerial(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, nextAccountNumberSerial)
+	var nextval int64
+	err := row.Scan(&nextval)
+	return nextval, err
+}
+
+const setAccountFrozen = `-- name: SetAccountFrozen :one
+UPDATE accounts SET frozen = $2 WHERE id = $1 RETURNING id, owner, balance, currency, created_at, account_number, frozen, last_entry_seq, last_entry_hash
+`
+
+type SetAccountFrozenParams struct {
+	ID     int64 `json:"id"`
+	Frozen bool  `json:"frozen"`
+}
+
+func (q *Queries) SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error) {
+	row := q.db.QueryRow(ctx, setAccountFrozen, arg.ID, arg.Frozen)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.Frozen,
+		&i.LastEntrySeq,
+		&i.LastEntryHash,
+	)
+	return i, err
+}
+
 const updateAccount = `-- name: UpdateAccount :one
-UPDATE accounts SET balance = $2 WHERE id = $1 RETURNING id, owner, balance, currency, created_at
+UPDATE accounts SET balance = $2 WHERE id = $1 RETURNING id, owner, balance, currency, created_at, account_number, frozen, last_entry_seq, last_entry_hash
 `
 
 type UpdateAccountParams struct {
@@ -145,7 +288,7 @@ type UpdateAccountParams struct {
 }
 
 func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, updateAccount, arg.ID, arg.Balance)
+	row := q.db.QueryRow(ctx, updateAccount, arg.ID, arg.Balance)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -153,6 +296,10 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.Frozen,
+		&i.LastEntrySeq,
+		&i.LastEntryHash,
 	)
 	return i, err
 }
@@ -8,53 +8,223 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3
+) RETURNING id, account_id, amount, created_at, transfer_group_id, fee_transfer_id, transfer_id, chain_seq, hash
 `
 
 type CreateEntryParams struct {
-	AccountID sql.NullInt64 `json:"account_id"`
-	Amount    int64         `json:"amount"`
+	AccountID  sql.NullInt64 `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferGroupID,
+		&i.FeeTransferID,
+		&i.TransferID,
+		&i.ChainSeq,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const createFeeEntry = `-- name: CreateFeeEntry :one
+INSERT INTO entries (
+  account_id,
+  amount,
+  fee_transfer_id
+) VALUES (
+  $1, $2, $3
+) RETURNING id, account_id, amount, created_at, transfer_group_id, fee_transfer_id, transfer_id, chain_seq, hash
+`
+
+type CreateFeeEntryParams struct {
+	AccountID     sql.NullInt64 `json:"account_id"`
+	Amount        int64         `json:"amount"`
+	FeeTransferID sql.NullInt64 `json:"fee_transfer_id"`
+}
+
+func (q *Queries) CreateFeeEntry(ctx context.Context, arg CreateFeeEntryParams) (Entry, error) {
+	row := q.db.QueryRow(ctx, createFeeEntry, arg.AccountID, arg.Amount, arg.FeeTransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferGroupID,
+		&i.FeeTransferID,
+		&i.TransferID,
+		&i.ChainSeq,
+		&i.Hash,
 	)
 	return i, err
 }
 
+const getChainEntry = `-- name: GetChainEntry :one
+SELECT id, account_id, amount, created_at, transfer_group_id, fee_transfer_id, transfer_id, chain_seq, hash FROM entries
+WHERE account_id = $1::bigint AND chain_seq = $2::bigint
+LIMIT 1
+`
+
+type GetChainEntryParams struct {
+	AccountID int64 `json:"account_id"`
+	ChainSeq  int64 `json:"chain_seq"`
+}
+
+func (q *Queries) GetChainEntry(ctx context.Context, arg GetChainEntryParams) (Entry, error) {
+	row := q.db.QueryRow(ctx, getChainEntry, arg.AccountID, arg.ChainSeq)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferGroupID,
+		&i.FeeTransferID,
+		&i.TransferID,
+		&i.ChainSeq,
+		&i.Hash,
+	)
+	return i, err
+}
+
+const getDailyDebitUsage = `-- name: GetDailyDebitUsage :one
+SELECT COALESCE(SUM(-entries.amount), 0)::bigint AS total_amount, COUNT(*) AS debit_count
+FROM entries
+LEFT JOIN transfers ON transfers.id = entries.transfer_id
+WHERE entries.account_id = $1::bigint
+  AND entries.amount < 0
+  AND entries.fee_transfer_id IS NULL
+  AND entries.created_at >= $2::timestamptz
+  AND (
+    entries.transfer_group_id IS NOT NULL
+    OR (transfers.from_account_id = entries.account_id AND transfers.reversal_of_transfer_id IS NULL)
+  )
+`
+
+type GetDailyDebitUsageParams struct {
+	AccountID int64     `json:"account_id"`
+	Since     time.Time `json:"since"`
+}
+
+type GetDailyDebitUsageRow struct {
+	TotalAmount int64 `json:"total_amount"`
+	DebitCount  int64 `json:"debit_count"`
+}
+
+func (q *Queries) GetDailyDebitUsage(ctx context.Context, arg GetDailyDebitUsageParams) (GetDailyDebitUsageRow, error) {
+	row := q.db.QueryRow(ctx, getDailyDebitUsage, arg.AccountID, arg.Since)
+	var i GetDailyDebitUsageRow
+	err := row.Scan(
+		&i.TotalAmount,
+		&i.DebitCount,
+	)
+	return i, err
+}
+
+const getEntriesTotalSince = `-- name: GetEntriesTotalSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint FROM entries
+WHERE account_id = $1::bigint
+  AND created_at >= $2::timestamptz
+`
+
+type GetEntriesTotalSinceParams struct {
+	AccountID int64     `json:"account_id"`
+	Since     time.Time `json:"since"`
+}
+
+func (q *Queries) GetEntriesTotalSince(ctx context.Context, arg GetEntriesTotalSinceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getEntriesTotalSince, arg.AccountID, arg.Since)
+	var coalesce int64
+	err := row.Scan(&coalesce)
+	return coalesce, err
+}
+
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_group_id, fee_transfer_id, transfer_id, chain_seq, hash FROM entries
 WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, getEntry, id)
+	row := q.db.QueryRow(ctx, getEntry, id)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferGroupID,
+		&i.FeeTransferID,
+		&i.TransferID,
+		&i.ChainSeq,
+		&i.Hash,
 	)
 	return i, err
 }
 
+const listChainEntries = `-- name: ListChainEntries :many
+SELECT id, account_id, amount, created_at, transfer_group_id, fee_transfer_id, transfer_id, chain_seq, hash FROM entries
+WHERE account_id IS NOT NULL
+  AND (account_id, chain_seq) > ($1::bigint, $2::bigint)
+ORDER BY account_id, chain_seq
+LIMIT $3::int
+`
+
+type ListChainEntriesParams struct {
+	AfterAccountID int64 `json:"after_account_id"`
+	AfterChainSeq  int64 `json:"after_chain_seq"`
+	PageSize       int32 `json:"page_size"`
+}
+
+func (q *Queries) ListChainEntries(ctx context.Context, arg ListChainEntriesParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listChainEntries, arg.AfterAccountID, arg.AfterChainSeq, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferGroupID,
+			&i.FeeTransferID,
+			&i.TransferID,
+			&i.ChainSeq,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_group_id, fee_transfer_id, transfer_id, chain_seq, hash FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -68,7 +238,7 @@ type ListEntriesParams struct {
 }
 
 func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -81,14 +251,60 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferGroupID,
+			&i.FeeTransferID,
+			&i.TransferID,
+			&i.ChainSeq,
+			&i.Hash,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	return items, nil
+}
+
+const listEntriesBetween = `-- name: ListEntriesBetween :many
+SELECT id, account_id, amount, created_at, transfer_group_id, fee_transfer_id, transfer_id, chain_seq, hash FROM entries
+WHERE account_id = $1::bigint
+  AND created_at >= $2::timestamptz
+  AND created_at < $3::timestamptz
+ORDER BY id
+`
+
+type ListEntriesBetweenParams struct {
+	AccountID   int64     `json:"account_id"`
+	CreatedFrom time.Time `json:"created_from"`
+	CreatedTo   time.Time `json:"created_to"`
+}
+
+func (q *Queries) ListEntriesBetween(ctx context.Context, arg ListEntriesBetweenParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesBetween, arg.AccountID, arg.CreatedFrom, arg.CreatedTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferGroupID,
+			&i.FeeTransferID,
+			&i.TransferID,
+			&i.ChainSeq,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestEntryChain(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createAccountInCurrency(t, utils.USD, 1000)
+	account2 := createAccountInCurrency(t, utils.USD, 1000)
+
+	var entries []Entry
+	for i := 0; i < 3; i++ {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+		entries = append(entries, result.FromEntry)
+	}
+
+	for i, entry := range entries {
+		require.Equal(t, int64(i+1), entry.ChainSeq.Int64)
+		require.Len(t, entry.Hash, sha256.Size)
+		got, err := testQueries.GetChainEntry(context.Background(), GetChainEntryParams{
+			AccountID: account1.ID,
+			ChainSeq:  entry.ChainSeq.Int64,
+		})
+		require.NoError(t, err)
+		require.Equal(t, entry, got)
+	}
+	require.NotEqual(t, entries[0].Hash, entries[1].Hash)
+
+	account, err := testQueries.GetAccounts(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), account.LastEntrySeq)
+	require.Equal(t, entries[2].Hash, account.LastEntryHash)
+
+	// the chain of every account starts over at 1
+	first, err := testQueries.GetChainEntry(context.Background(), GetChainEntryParams{AccountID: account2.ID, ChainSeq: 1})
+	require.NoError(t, err)
+	require.Equal(t, account2.ID, first.AccountID.Int64)
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// Errors the store reports, whatever the driver said. Match them with
+// errors.Is; the driver error stays reachable through errors.As.
+var (
+	ErrRecordNotFound      = errors.New("record not found")
+	ErrUniqueViolation     = errors.New("record already exists")
+	ErrForeignKeyViolation = errors.New("referenced record doesn't exist")
+	ErrInsufficientFunds   = errors.New("insufficient funds")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrAccountFrozen       = errors.New("account is frozen")
+	ErrUnbalancedJournal   = errors.New("journal entry postings don't sum to zero")
+)
+
+// Postgres error codes TranslateError knows about.
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+	checkViolationCode      = "23514"
+)
+
+// postingsBalancedConstraint is the deferred check that the postings of a
+// journal entry sum to zero in every currency.
+const postingsBalancedConstraint = "postings_balanced"
+
+// dbError ties a driver error to one of the errors above.
+type dbError struct {
+	kind error
+	err  error
+}
+
+func (e *dbError) Error() string {
+	if e.err == sql.ErrNoRows || e.err == pgx.ErrNoRows {
+		return e.kind.Error()
+	}
+	return e.err.Error()
+}
+
+func (e *dbError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// TranslateError wraps sql.ErrNoRows, which pgx.ErrNoRows matches too, the
+// Postgres constraint violations callers act on and the conflicts executeTx
+// retries so they match the store's errors. Other errors are returned as
+// they are.
+func TranslateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var translated *dbError
+	if errors.As(err, &translated) {
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return &dbError{kind: ErrRecordNotFound, err: err}
+	}
+	if pgErr, ok := asPgError(err); ok {
+		switch pgErr.Code {
+		case uniqueViolationCode:
+			return &dbError{kind: ErrUniqueViolation, err: err}
+		case foreignKeyViolationCode:
+			return &dbError{kind: ErrForeignKeyViolation, err: err}
+		case checkViolationCode:
+			if pgErr.Constraint == postingsBalancedConstraint {
+				return &dbError{kind: ErrUnbalancedJournal, err: err}
+			}
+		case serializationFailureCode, deadlockDetectedCode:
+			return &dbError{kind: ErrTxConflict, err: err}
+		}
+	}
+	return err
+}
+
+// pgError is what the store looks at in an error Postgres reported.
+type pgError struct {
+	Code       string
+	Constraint string
+}
+
+// asPgError finds the Postgres error in err. The store talks to Postgres
+// through pgx, but errors built with lib/pq, like the ones the mocks return,
+// are understood too.
+func asPgError(err error) (pgError, bool) {
+	var pgxErr *pgconn.PgError
+	if errors.As(err, &pgxErr) {
+		return pgError{Code: pgxErr.Code, Constraint: pgxErr.ConstraintName}, true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pgError{Code: string(pqErr.Code), Constraint: pqErr.Constraint}, true
+	}
+	return pgError{}, false
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateError(t *testing.T) {
+	require.NoError(t, TranslateError(nil))
+
+	notFound := TranslateError(fmt.Errorf("get account: %w", sql.ErrNoRows))
+	require.ErrorIs(t, notFound, ErrRecordNotFound)
+	require.ErrorIs(t, notFound, sql.ErrNoRows)
+	require.Equal(t, notFound, TranslateError(notFound))
+	require.EqualError(t, TranslateError(sql.ErrNoRows), ErrRecordNotFound.Error())
+	require.EqualError(t, TranslateError(pgx.ErrNoRows), ErrRecordNotFound.Error())
+	require.ErrorIs(t, TranslateError(pgx.ErrNoRows), ErrRecordNotFound)
+
+	unique := TranslateError(&pq.Error{Code: uniqueViolationCode})
+	require.ErrorIs(t, unique, ErrUniqueViolation)
+	var pqErr *pq.Error
+	require.True(t, errors.As(unique, &pqErr))
+
+	require.ErrorIs(t, TranslateError(&pq.Error{Code: foreignKeyViolationCode}), ErrForeignKeyViolation)
+	require.ErrorIs(t, TranslateError(&pq.Error{Code: serializationFailureCode}), ErrTxConflict)
+	require.ErrorIs(t, TranslateError(&pq.Error{Code: deadlockDetectedCode}), ErrTxConflict)
+	require.ErrorIs(t, TranslateError(&pq.Error{Code: checkViolationCode, Constraint: postingsBalancedConstraint}), ErrUnbalancedJournal)
+
+	check := &pq.Error{Code: checkViolationCode, Constraint: "accounts_balance_check"}
+	require.Equal(t, check, TranslateError(check))
+
+	pgUnique := TranslateError(fmt.Errorf("create account: %w", &pgconn.PgError{Code: uniqueViolationCode}))
+	require.ErrorIs(t, pgUnique, ErrUniqueViolation)
+	var pgErr *pgconn.PgError
+	require.True(t, errors.As(pgUnique, &pgErr))
+	require.ErrorIs(t, TranslateError(&pgconn.PgError{Code: serializationFailureCode}), ErrTxConflict)
+	require.ErrorIs(t, TranslateError(&pgconn.PgError{Code: checkViolationCode, ConstraintName: postingsBalancedConstraint}), ErrUnbalancedJournal)
+
+	other := errors.New("connection reset")
+	require.Equal(t, other, TranslateError(other))
+}
+
+func TestGetAccountNotFound(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	err := store.executeTx(context.Background(), TxOptions{}, func(q *Queries) error {
+		_, err := q.GetAccounts(context.Background(), -1)
+		return err
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestTransferTxAccountFrozen(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	frozen, err := testQueries.SetAccountFrozen(context.Background(), SetAccountFrozenParams{
+		ID:     account2.ID,
+		Frozen: true,
+	})
+	require.NoError(t, err)
+	require.True(t, frozen.Frozen)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	got, err := testQueries.GetAccounts(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, got.Balance)
+}
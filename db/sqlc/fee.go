@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// FeeIncomeOwner owns the bank's fee income accounts, one per currency.
+const FeeIncomeOwner = "bankfee"
+
+// Calculate returns the fee the rule charges on amount: the flat amount plus
+// the percentage, rounded half up, clamped to the rule's min and max.
+func (rule FeeRule) Calculate(amount int64) int64 {
+	percentage := new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(rule.PercentageBps)))
+	percentage.Add(percentage, big.NewInt(5_000))
+	percentage.Quo(percentage, big.NewInt(10_000))
+
+	fee := new(big.Int).Add(percentage, big.NewInt(rule.FlatAmount))
+	if rule.MaxAmount.Valid && fee.Cmp(big.NewInt(rule.MaxAmount.Int64)) > 0 {
+		return rule.MaxAmount.Int64
+	}
+	if fee.Cmp(big.NewInt(rule.MinAmount)) < 0 {
+		return rule.MinAmount
+	}
+	return fee.Int64()
+}
+
+// TransferFee is the fee charged on top of a transfer, in the currency of
+// the source account.
+type TransferFee struct {
+	Amount        int64  `json:"fee"`
+	Currency      string `json:"currency"`
+	CrossCurrency bool   `json:"cross_currency"`
+	// RuleID is the fee rule that applied, if any.
+	RuleID sql.NullInt64 `json:"rule_id"`
+}
+
+// QuoteTransferFee works out the fee for sending amount from one account to
+// another. Transfers between accounts of the same owner are free, and
+// transfers without a fee rule for their source currency and kind cost
+// nothing either.
+func QuoteTransferFee(ctx context.Context, q Querier, from Account, to Account, amount int64) (TransferFee, error) {
+	fee := TransferFee{
+		Currency:      from.Currency,
+		CrossCurrency: from.Currency != to.Currency,
+	}
+	if from.Owner == to.Owner {
+		return fee, nil
+	}
+	rule, err := q.GetFeeRule(ctx, GetFeeRuleParams{
+		Currency:      fee.Currency,
+		CrossCurrency: fee.CrossCurrency,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fee, nil
+	}
+	if err != nil {
+		return fee, err
+	}
+	fee.Amount = rule.Calculate(amount)
+	fee.RuleID = sql.NullInt64{Int64: rule.ID, Valid: true}
+	return fee, nil
+}
+
+// feeIncomeAccount returns the fee income account for currency.
+func feeIncomeAccount(ctx context.Context, q *Queries, currency string) (Account, error) {
+	account, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    FeeIncomeOwner,
+		Currency: currency,
+	})
+	if err != nil {
+		return account, fmt.Errorf("fee income account %s: %w", currency, err)
+	}
+	return account, nil
+}
+
+// transferFee quotes the fee of a transfer between two accounts and, when
+// there is one, returns the fee income account it goes to. The accounts are
+// read without locks: owner and currency never change.
+func transferFee(ctx context.Context, q *Queries, fromAccountID int64, toAccountID int64, amount int64) (TransferFee, Account, error) {
+	var feeAccount Account
+	from, err := q.GetAccounts(ctx, fromAccountID)
+	if err != nil {
+		return TransferFee{}, feeAccount, err
+	}
+	to, err := q.GetAccounts(ctx, toAccountID)
+	if err != nil {
+		return TransferFee{}, feeAccount, err
+	}
+	fee, err := QuoteTransferFee(ctx, q, from, to, amount)
+	if err != nil || fee.Amount == 0 {
+		return fee, feeAccount, err
+	}
+	feeAccount, err = feeIncomeAccount(ctx, q, fee.Currency)
+	return fee, feeAccount, err
+}
+
+// postFee debits the fee of a transfer from its source account and credits
+// it to the fee income account, adds both to the journal entry of the
+// transfer, and sets the fee entries and the source account on result. The
+// caller must already hold the locks of the source account and of the fee
+// income account.
+func postFee(ctx context.Context, q *Queries, result *TransferTxResult, feeAccountID int64) error {
+	transfer := result.Transfer
+	feeTransferID := sql.NullInt64{Int64: transfer.ID, Valid: true}
+
+	feeEntry, err := q.CreateFeeEntry(ctx, CreateFeeEntryParams{
+		AccountID:     transfer.FromAccountID,
+		Amount:        -transfer.Fee,
+		FeeTransferID: feeTransferID,
+	})
+	if err != nil {
+		return err
+	}
+	incomeEntry, err := q.CreateFeeEntry(ctx, CreateFeeEntryParams{
+		AccountID:     sql.NullInt64{Int64: feeAccountID, Valid: true},
+		Amount:        transfer.Fee,
+		FeeTransferID: feeTransferID,
+	})
+	if err != nil {
+		return err
+	}
+	result.FeeEntry = &feeEntry
+	result.FeeIncomeEntry = &incomeEntry
+	if err := addPostings(ctx, q, result.JournalEntry.ID, feeEntry, incomeEntry); err != nil {
+		return err
+	}
+
+	updated, err := addBalances(ctx, q, map[int64]int64{
+		transfer.FromAccountID.Int64: -transfer.Fee,
+		feeAccountID:                 transfer.Fee,
+	})
+	if err != nil {
+		return err
+	}
+	result.FromAccount = updated[transfer.FromAccountID.Int64]
+	return nil
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestFeeRuleCalculate(t *testing.T) {
+	flat := FeeRule{FlatAmount: 50}
+	require.Equal(t, int64(50), flat.Calculate(1))
+	require.Equal(t, int64(50), flat.Calculate(1_000_000))
+
+	percentage := FeeRule{
+		PercentageBps: 150, // 1.5%
+		MinAmount:     10,
+		MaxAmount:     sql.NullInt64{Int64: 500, Valid: true},
+	}
+	require.Equal(t, int64(15), percentage.Calculate(1_000))
+	require.Equal(t, int64(10), percentage.Calculate(100))
+	require.Equal(t, int64(500), percentage.Calculate(1_000_000))
+	// rounds half up: 1.5% of 1_100 is 16.5
+	require.Equal(t, int64(17), percentage.Calculate(1_100))
+
+	both := FeeRule{FlatAmount: 25, PercentageBps: 100}
+	require.Equal(t, int64(35), both.Calculate(1_000))
+	// doesn't overflow on large amounts
+	require.Equal(t, int64(92_233_720_368_547_783), both.Calculate(9_223_372_036_854_775_807))
+}
+
+func createTestFeeRule(t *testing.T, arg CreateFeeRuleParams) FeeRule {
+	rule, err := testQueries.CreateFeeRule(context.Background(), arg)
+	require.NoError(t, err)
+	// fee rules apply to every transfer in their currency, so don't leave
+	// them behind for the other tests
+	t.Cleanup(func() {
+		require.NoError(t, testQueries.DeleteFeeRule(context.Background(), rule.ID))
+	})
+	return rule
+}
+
+func TestTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+	createTestFeeRule(t, CreateFeeRuleParams{Currency: utils.USD, FlatAmount: 5, PercentageBps: 100})
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	feeAccount, err := store.GetAccountByOwnerAndCurrency(context.Background(), GetAccountByOwnerAndCurrencyParams{
+		Owner:    FeeIncomeOwner,
+		Currency: utils.USD,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        500,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), result.Transfer.Fee)
+	require.Equal(t, int64(490), result.FromAccount.Balance)
+	require.Equal(t, int64(500), result.ToAccount.Balance)
+
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, from.ID, result.FeeEntry.AccountID.Int64)
+	require.Equal(t, int64(-10), result.FeeEntry.Amount)
+	require.Equal(t, result.Transfer.ID, result.FeeEntry.FeeTransferID.Int64)
+	require.NotNil(t, result.FeeIncomeEntry)
+	require.Equal(t, feeAccount.ID, result.FeeIncomeEntry.AccountID.Int64)
+	require.Equal(t, int64(10), result.FeeIncomeEntry.Amount)
+
+	updatedFeeAccount, err := store.GetAccounts(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, updatedFeeAccount.Balance-feeAccount.Balance, int64(10))
+}
+
+func TestTransferTxSameOwnerIsFree(t *testing.T) {
+	store := NewStore(testDB)
+	createTestFeeRule(t, CreateFeeRuleParams{Currency: utils.USD, FlatAmount: 5})
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:         from.Owner,
+		Balance:       0,
+		Currency:      utils.USD,
+		AccountNumber: utils.RandomAccountNumber(),
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        500,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Transfer.Fee)
+	require.Nil(t, result.FeeEntry)
+	require.Equal(t, int64(500), result.FromAccount.Balance)
+}
+
+func TestQuoteTransferFeeCrossCurrency(t *testing.T) {
+	rule := createTestFeeRule(t, CreateFeeRuleParams{Currency: utils.USD, CrossCurrency: true, PercentageBps: 200})
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.EUR, 0)
+
+	fee, err := QuoteTransferFee(context.Background(), testQueries, from, to, 1000)
+	require.NoError(t, err)
+	require.True(t, fee.CrossCurrency)
+	require.Equal(t, int64(20), fee.Amount)
+	require.Equal(t, utils.USD, fee.Currency)
+	require.Equal(t, rule.ID, fee.RuleID.Int64)
+}
@@ -0,0 +1,266 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// FXRateScale is the fixed-point scale of fx_rates.rate and fx_quotes.rate:
+// a rate of 2_500_000_000_000 between USD and VND means 1 USD = 25000 VND.
+const FXRateScale = 100_000_000
+
+// FXPositionOwner owns the bank's FX position accounts, one per currency.
+const FXPositionOwner = "bankfx"
+
+var (
+	ErrFxQuoteExpired  = errors.New("fx quote has expired")
+	ErrFxQuoteUsed     = errors.New("fx quote has already been used")
+	ErrFxQuoteMismatch = errors.New("fx quote doesn't match the transfer")
+)
+
+// ConvertAmount converts amount at a scaled rate, rounding down.
+func ConvertAmount(amount int64, rate int64) int64 {
+	converted := new(big.Int).Mul(big.NewInt(amount), big.NewInt(rate))
+	return converted.Quo(converted, big.NewInt(FXRateScale)).Int64()
+}
+
+// InvertFxRate returns the scaled rate of the opposite direction.
+func InvertFxRate(rate int64) int64 {
+	inverted := new(big.Int).Mul(big.NewInt(FXRateScale), big.NewInt(FXRateScale))
+	return inverted.Quo(inverted, big.NewInt(rate)).Int64()
+}
+
+type FxTransferTxParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// Amount is in the currency of the source account.
+	Amount    int64 `json:"amount"`
+	FxQuoteID int64 `json:"fx_quote_id"`
+	TransferDetails
+	// Username must own the quote.
+	Username       string                `json:"-"`
+	IdempotencyKey *IdempotencyKeyParams `json:"-"`
+}
+
+type FxTransferTxResult struct {
+	TransferTxResult
+	Quote FxQuote `json:"quote"`
+	// ConvertedAmount is what the destination account received.
+	ConvertedAmount   int64 `json:"converted_amount"`
+	FromPositionEntry Entry `json:"from_position_entry"`
+	ToPositionEntry   Entry `json:"to_position_entry"`
+}
+
+// LatestFxRate returns the rate in force from one currency to another,
+// falling back to the inverse of the rate of the opposite direction.
+func LatestFxRate(ctx context.Context, q Querier, from string, to string) (FxRate, error) {
+	rate, err := q.GetLatestFxRate(ctx, GetLatestFxRateParams{
+		BaseCurrency:  from,
+		QuoteCurrency: to,
+	})
+	if !errors.Is(err, sql.ErrNoRows) {
+		return rate, err
+	}
+	rate, err = q.GetLatestFxRate(ctx, GetLatestFxRateParams{
+		BaseCurrency:  to,
+		QuoteCurrency: from,
+	})
+	if err != nil {
+		return rate, err
+	}
+	rate.Rate = InvertFxRate(rate.Rate)
+	return rate, nil
+}
+
+// FxTransferTx moves money between accounts in different currencies at the
+// rate of a quote. The source leg is credited to the bank's FX position
+// account in the source currency and the destination leg is debited from the
+// position account in the destination currency, so every currency still nets
+// to zero. The fee, from the cross-currency rule of the source currency, is
+// charged on top in the source currency.
+func (store *SQLStore) FxTransferTx(ctx context.Context, arg FxTransferTxParams) (FxTransferTxResult, error) {
+	var result FxTransferTxResult
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		var err error
+		result, err = fxTransferTx(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+		if arg.IdempotencyKey != nil {
+			return saveIdempotencyKey(ctx, q, arg.IdempotencyKey, result)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// fxTransferTx is FxTransferTx inside a transaction the caller runs.
+func fxTransferTx(ctx context.Context, q *Queries, arg FxTransferTxParams) (FxTransferTxResult, error) {
+	var result FxTransferTxResult
+	var err error
+	result.Quote, err = q.GetFxQuoteForUpdate(ctx, arg.FxQuoteID)
+	if err != nil {
+		return result, err
+	}
+	if result.Quote.Username != arg.Username {
+		return result, ErrFxQuoteMismatch
+	}
+	if result.Quote.TransferID.Valid {
+		return result, ErrFxQuoteUsed
+	}
+	if time.Now().After(result.Quote.ExpiresAt) {
+		return result, ErrFxQuoteExpired
+	}
+
+	fromPosition, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    FXPositionOwner,
+		Currency: result.Quote.FromCurrency,
+	})
+	if err != nil {
+		return result, fmt.Errorf("fx position account %s: %w", result.Quote.FromCurrency, err)
+	}
+	toPosition, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    FXPositionOwner,
+		Currency: result.Quote.ToCurrency,
+	})
+	if err != nil {
+		return result, fmt.Errorf("fx position account %s: %w", result.Quote.ToCurrency, err)
+	}
+
+	fee, feeAccount, err := transferFee(ctx, q, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	if err != nil {
+		return result, err
+	}
+	ids := []int64{arg.FromAccountID, arg.ToAccountID, fromPosition.ID, toPosition.ID}
+	if fee.Amount > 0 {
+		ids = append(ids, feeAccount.ID)
+	}
+	accounts, err := lockAccounts(ctx, q, ids...)
+	if err != nil {
+		return result, err
+	}
+	sender := accounts[arg.FromAccountID]
+	if sender.Currency != result.Quote.FromCurrency || accounts[arg.ToAccountID].Currency != result.Quote.ToCurrency {
+		return result, ErrFxQuoteMismatch
+	}
+	if err := checkNotFrozen(sender, accounts[arg.ToAccountID]); err != nil {
+		return result, err
+	}
+	if sender.Balance < arg.Amount+fee.Amount {
+		return result, ErrInsufficientFunds
+	}
+	if err := checkTransferLimits(ctx, q, sender, arg.Amount); err != nil {
+		return result, err
+	}
+
+	result.ConvertedAmount = ConvertAmount(arg.Amount, result.Quote.Rate)
+	if result.ConvertedAmount <= 0 {
+		return result, fmt.Errorf("amount %d %s is too small to convert", arg.Amount, sender.Currency)
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, arg.transferParams(arg.FromAccountID, arg.ToAccountID, arg.Amount, fee.Amount))
+	if err != nil {
+		return result, err
+	}
+
+	legs := []struct {
+		accountID int64
+		amount    int64
+		entry     *Entry
+	}{
+		{arg.FromAccountID, -arg.Amount, &result.FromEntry},
+		{fromPosition.ID, arg.Amount, &result.FromPositionEntry},
+		{toPosition.ID, -result.ConvertedAmount, &result.ToPositionEntry},
+		{arg.ToAccountID, result.ConvertedAmount, &result.ToEntry},
+	}
+	deltas := make(map[int64]int64, len(legs))
+	for _, leg := range legs {
+		*leg.entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  sql.NullInt64{Int64: leg.accountID, Valid: true},
+			Amount:     leg.amount,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return result, err
+		}
+		deltas[leg.accountID] += leg.amount
+	}
+	result.JournalEntry, err = postJournalEntry(ctx, q, CreateJournalEntryParams{
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	}, result.FromEntry, result.FromPositionEntry, result.ToPositionEntry, result.ToEntry)
+	if err != nil {
+		return result, err
+	}
+
+	updated, err := addBalances(ctx, q, deltas)
+	if err != nil {
+		return result, err
+	}
+	result.FromAccount = updated[arg.FromAccountID]
+	result.ToAccount = updated[arg.ToAccountID]
+	if fee.Amount > 0 {
+		if err := postFee(ctx, q, &result.TransferTxResult, feeAccount.ID); err != nil {
+			return result, err
+		}
+	}
+
+	result.Quote, err = q.SetFxQuoteTransfer(ctx, SetFxQuoteTransferParams{
+		ID:         result.Quote.ID,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+	return result, err
+}
+
+// lockAccounts locks the given accounts for update in ascending id order, so
+// transactions touching overlapping sets of accounts can't deadlock.
+func lockAccounts(ctx context.Context, q *Queries, accountIDs ...int64) (map[int64]Account, error) {
+	accounts := make(map[int64]Account, len(accountIDs))
+	for _, id := range sortedIDs(accountIDs) {
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		accounts[id] = account
+	}
+	return accounts, nil
+}
+
+// addBalances applies the balance changes in ascending account id order.
+func addBalances(ctx context.Context, q *Queries, deltas map[int64]int64) (map[int64]Account, error) {
+	ids := make([]int64, 0, len(deltas))
+	for id := range deltas {
+		ids = append(ids, id)
+	}
+	accounts := make(map[int64]Account, len(deltas))
+	for _, id := range sortedIDs(ids) {
+		account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:      id,
+			Ammount: deltas[id],
+		})
+		if err != nil {
+			return nil, err
+		}
+		accounts[id] = account
+	}
+	return accounts, nil
+}
+
+// sortedIDs returns the distinct ids in ascending order.
+func sortedIDs(ids []int64) []int64 {
+	sorted := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			sorted = append(sorted, id)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
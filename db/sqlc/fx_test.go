@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestConvertAmount(t *testing.T) {
+	require.Equal(t, int64(92), ConvertAmount(100, 92_000_000))
+	require.Equal(t, int64(2_500_000), ConvertAmount(100, 2_500_000_000_000))
+	// rounds down
+	require.Equal(t, int64(0), ConvertAmount(1, 92_000_000))
+	// doesn't overflow on large amounts at large rates
+	require.Equal(t, int64(250_000_000_000_000), ConvertAmount(10_000_000_000, 2_500_000_000_000))
+}
+
+func TestInvertFxRate(t *testing.T) {
+	require.Equal(t, int64(4_000), InvertFxRate(2_500_000_000_000))
+	require.Equal(t, int64(50_000_000), InvertFxRate(200_000_000))
+}
+
+func createAccountInCurrency(t *testing.T, currency string, balance int64) Account {
+	user := createRandomUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:         user.Username,
+		Balance:       balance,
+		Currency:      currency,
+		AccountNumber: utils.RandomAccountNumber(),
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func createRandomFxQuote(t *testing.T, username string, rate int64, expiresAt time.Time) FxQuote {
+	fxRate, err := testQueries.CreateFxRate(context.Background(), CreateFxRateParams{
+		BaseCurrency:  utils.USD,
+		QuoteCurrency: utils.EUR,
+		Rate:          rate,
+		ValidFrom:     time.Now(),
+	})
+	require.NoError(t, err)
+
+	quote, err := testQueries.CreateFxQuote(context.Background(), CreateFxQuoteParams{
+		Username:     username,
+		FxRateID:     fxRate.ID,
+		FromCurrency: utils.USD,
+		ToCurrency:   utils.EUR,
+		Rate:         fxRate.Rate,
+		ExpiresAt:    expiresAt,
+	})
+	require.NoError(t, err)
+	require.False(t, quote.TransferID.Valid)
+	return quote
+}
+
+func TestFxTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.EUR, 0)
+	quote := createRandomFxQuote(t, from.Owner, 92_000_000, time.Now().Add(time.Minute))
+
+	fromPosition, err := testQueries.GetAccountByOwnerAndCurrency(context.Background(), GetAccountByOwnerAndCurrencyParams{
+		Owner:    FXPositionOwner,
+		Currency: utils.USD,
+	})
+	require.NoError(t, err)
+	toPosition, err := testQueries.GetAccountByOwnerAndCurrency(context.Background(), GetAccountByOwnerAndCurrencyParams{
+		Owner:    FXPositionOwner,
+		Currency: utils.EUR,
+	})
+	require.NoError(t, err)
+
+	arg := FxTransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+		FxQuoteID:     quote.ID,
+		Username:      from.Owner,
+	}
+	result, err := store.FxTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(92), result.ConvertedAmount)
+	require.Equal(t, int64(100), result.Transfer.Amount)
+	require.Equal(t, int64(900), result.FromAccount.Balance)
+	require.Equal(t, int64(92), result.ToAccount.Balance)
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(92), result.ToEntry.Amount)
+	require.Equal(t, fromPosition.ID, result.FromPositionEntry.AccountID.Int64)
+	require.Equal(t, int64(100), result.FromPositionEntry.Amount)
+	require.Equal(t, toPosition.ID, result.ToPositionEntry.AccountID.Int64)
+	require.Equal(t, int64(-92), result.ToPositionEntry.Amount)
+	require.Equal(t, result.Transfer.ID, result.Quote.TransferID.Int64)
+
+	// a quote can only be used once
+	_, err = store.FxTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrFxQuoteUsed)
+}
+
+func TestFxTransferTxExpiredQuote(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.EUR, 0)
+	quote := createRandomFxQuote(t, from.Owner, 92_000_000, time.Now().Add(-time.Second))
+
+	_, err := store.FxTransferTx(context.Background(), FxTransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+		FxQuoteID:     quote.ID,
+		Username:      from.Owner,
+	})
+	require.ErrorIs(t, err, ErrFxQuoteExpired)
+
+	account, err := testQueries.GetAccounts(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, account.Balance)
+}
+
+func TestFxTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 50)
+	to := createAccountInCurrency(t, utils.EUR, 0)
+	quote := createRandomFxQuote(t, from.Owner, 92_000_000, time.Now().Add(time.Minute))
+
+	_, err := store.FxTransferTx(context.Background(), FxTransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+		FxQuoteID:     quote.ID,
+		Username:      from.Owner,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	account, err := testQueries.GetAccounts(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, account.Balance)
+
+	// the quote was not used up
+	quote, err = testQueries.GetFxQuote(context.Background(), quote.ID)
+	require.NoError(t, err)
+	require.False(t, quote.TransferID.Valid)
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrIdempotencyKeyInUse is returned when another request already stored
+// its response under the same idempotency key.
+var ErrIdempotencyKeyInUse = errors.New("idempotency key already in use")
+
+// IdempotencyKeyParams ties a transaction to the Idempotency-Key of the
+// request that asked for it. The response is stored in the same database
+// transaction, so it is only kept if the transaction commits.
+type IdempotencyKeyParams struct {
+	Username       string
+	Key            string
+	RequestHash    string
+	ResponseStatus int32
+	ExpiresAt      time.Time
+}
+
+func saveIdempotencyKey(ctx context.Context, q *Queries, key *IdempotencyKeyParams, response interface{}) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:       key.Username,
+		IdempotencyKey: key.Key,
+		RequestHash:    key.RequestHash,
+		ResponseStatus: key.ResponseStatus,
+		ResponseBody:   body,
+		ExpiresAt:      key.ExpiresAt,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// the upsert only overwrites expired keys
+		return ErrIdempotencyKeyInUse
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func createRandomIdempotencyKey(t *testing.T, user User, expiresAt time.Time) IdempotencyKey {
+	arg := CreateIdempotencyKeyParams{
+		Username:       user.Username,
+		IdempotencyKey: utils.RandomString(16),
+		RequestHash:    utils.RandomString(64),
+		ResponseStatus: 200,
+		ResponseBody:   []byte(`{"ok":true}`),
+		ExpiresAt:      expiresAt,
+	}
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, key.Username)
+	require.Equal(t, arg.IdempotencyKey, key.IdempotencyKey)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+	require.Equal(t, arg.ResponseStatus, key.ResponseStatus)
+	require.JSONEq(t, string(arg.ResponseBody), string(key.ResponseBody))
+	require.WithinDuration(t, arg.ExpiresAt, key.ExpiresAt, time.Second)
+	return key
+}
+
+func TestGetIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	key1 := createRandomIdempotencyKey(t, user, time.Now().Add(time.Minute))
+
+	key2, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       user.Username,
+		IdempotencyKey: key1.IdempotencyKey,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key1.ID, key2.ID)
+	require.Equal(t, key1.RequestHash, key2.RequestHash)
+}
+
+func TestCreateIdempotencyKeyConflict(t *testing.T) {
+	user := createRandomUser(t)
+	key := createRandomIdempotencyKey(t, user, time.Now().Add(time.Minute))
+
+	_, err := testQueries.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Username:       user.Username,
+		IdempotencyKey: key.IdempotencyKey,
+		RequestHash:    "other",
+		ResponseStatus: 200,
+		ResponseBody:   []byte(`{}`),
+		ExpiresAt:      time.Now().Add(time.Minute),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestExpiredIdempotencyKey(t *testing.T) {
+	user := createRandomUser(t)
+	key1 := createRandomIdempotencyKey(t, user, time.Now().Add(-time.Minute))
+
+	_, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       user.Username,
+		IdempotencyKey: key1.IdempotencyKey,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	key2, err := testQueries.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Username:       user.Username,
+		IdempotencyKey: key1.IdempotencyKey,
+		RequestHash:    "other",
+		ResponseStatus: 200,
+		ResponseBody:   []byte(`{}`),
+		ExpiresAt:      time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, key1.ID, key2.ID)
+	require.Equal(t, "other", key2.RequestHash)
+
+	deleted, err := testQueries.DeleteExpiredIdempotencyKeys(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(0))
+}
+
+func TestTransferTxIdempotencyKey(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createAccountInCurrency(t, utils.USD, 1000)
+	account2 := createAccountInCurrency(t, utils.USD, 1000)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		IdempotencyKey: &IdempotencyKeyParams{
+			Username:       account1.Owner,
+			Key:            utils.RandomString(16),
+			RequestHash:    utils.RandomString(64),
+			ResponseStatus: 200,
+			ExpiresAt:      time.Now().Add(time.Minute),
+		},
+	}
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyInUse)
+
+	key, err := store.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       account1.Owner,
+		IdempotencyKey: arg.IdempotencyKey.Key,
+	})
+	require.NoError(t, err)
+	require.Contains(t, string(key.ResponseBody), `"transfer"`)
+
+	updatedAccount1, err := store.GetAccounts(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.FromAccount.Balance, updatedAccount1.Balance)
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// postJournalEntry writes a journal entry with the entries as its postings.
+// The postings of a journal entry must sum to zero in every currency by the
+// time the transaction commits, or the commit fails with
+// ErrUnbalancedJournal.
+func postJournalEntry(ctx context.Context, q *Queries, arg CreateJournalEntryParams, entries ...Entry) (JournalEntry, error) {
+	journalEntry, err := q.CreateJournalEntry(ctx, arg)
+	if err != nil {
+		return journalEntry, err
+	}
+	return journalEntry, addPostings(ctx, q, journalEntry.ID, entries...)
+}
+
+// addPostings adds the entries to the postings of a journal entry.
+func addPostings(ctx context.Context, q *Queries, journalEntryID int64, entries ...Entry) error {
+	ids := make([]int64, len(entries))
+	for i, entry := range entries {
+		if !entry.AccountID.Valid {
+			return fmt.Errorf("entry %d has no account to post to", entry.ID)
+		}
+		ids[i] = entry.ID
+	}
+	postings, err := q.CreatePostings(ctx, CreatePostingsParams{
+		JournalEntryID: journalEntryID,
+		EntryIds:       ids,
+	})
+	if err != nil {
+		return err
+	}
+	if len(postings) != len(entries) {
+		return fmt.Errorf("journal entry %d: posted %d of %d entries", journalEntryID, len(postings), len(entries))
+	}
+	return nil
+}
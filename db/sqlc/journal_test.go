@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// requireBalancedJournalEntry checks that the journal entry has one posting
+// per entry, and that they sum to zero in every currency.
+func requireBalancedJournalEntry(t *testing.T, journalEntryID int64, entries ...Entry) []Posting {
+	postings, err := testQueries.ListPostings(context.Background(), journalEntryID)
+	require.NoError(t, err)
+	require.Len(t, postings, len(entries))
+
+	byEntry := make(map[int64]Posting, len(postings))
+	totals := make(map[string]int64)
+	for _, posting := range postings {
+		byEntry[posting.EntryID] = posting
+		totals[posting.Currency] += posting.Amount
+	}
+	for _, entry := range entries {
+		posting, ok := byEntry[entry.ID]
+		require.True(t, ok, "entry %d has no posting", entry.ID)
+		require.Equal(t, entry.AccountID.Int64, posting.AccountID)
+		require.Equal(t, entry.Amount, posting.Amount)
+	}
+	for currency, total := range totals {
+		require.Zero(t, total, currency)
+	}
+	return postings
+}
+
+func TestTransferTxJournalEntry(t *testing.T) {
+	store := NewStore(testDB)
+	createTestFeeRule(t, CreateFeeRuleParams{Currency: utils.USD, FlatAmount: 5})
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, result.JournalEntry.TransferID.Int64)
+	requireBalancedJournalEntry(t, result.JournalEntry.ID, result.FromEntry, result.ToEntry, *result.FeeEntry, *result.FeeIncomeEntry)
+
+	journalEntry, err := store.GetJournalEntryByTransfer(context.Background(), sql.NullInt64{Int64: result.Transfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Equal(t, result.JournalEntry, journalEntry)
+}
+
+func TestFxTransferTxJournalEntry(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.EUR, 0)
+	quote := createRandomFxQuote(t, from.Owner, 92_000_000, time.Now().Add(time.Minute))
+
+	result, err := store.FxTransferTx(context.Background(), FxTransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+		FxQuoteID:     quote.ID,
+		Username:      from.Owner,
+	})
+	require.NoError(t, err)
+
+	postings := requireBalancedJournalEntry(t, result.JournalEntry.ID,
+		result.FromEntry, result.FromPositionEntry, result.ToPositionEntry, result.ToEntry)
+	currencies := make(map[string]bool)
+	for _, posting := range postings {
+		currencies[posting.Currency] = true
+	}
+	require.Equal(t, map[string]bool{utils.USD: true, utils.EUR: true}, currencies)
+}
+
+func TestMultiTransferTxJournalEntry(t *testing.T) {
+	store := NewStore(testDB)
+	buyer := createAccountInCurrency(t, utils.USD, 1000)
+	seller := createAccountInCurrency(t, utils.USD, 0)
+	platform := createAccountInCurrency(t, utils.USD, 0)
+
+	result, err := store.MultiTransferTx(context.Background(), MultiTransferTxParams{
+		Debits:      []TransferLeg{{AccountID: buyer.ID, Amount: 500}},
+		Credits:     []TransferLeg{{AccountID: seller.ID, Amount: 450}, {AccountID: platform.ID, Amount: 50}},
+		Description: "order 43",
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.Group.ID, result.JournalEntry.TransferGroupID.Int64)
+	require.Equal(t, "order 43", result.JournalEntry.Description)
+	requireBalancedJournalEntry(t, result.JournalEntry.ID, append(result.DebitEntries, result.CreditEntries...)...)
+}
+
+func TestUnbalancedJournalEntry(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account1 := createAccountInCurrency(t, utils.USD, 0)
+	account2 := createAccountInCurrency(t, utils.EUR, 0)
+
+	var journalEntryID int64
+	post := func(amounts ...int64) error {
+		return store.executeTx(context.Background(), TxOptions{}, func(q *Queries) error {
+			journalEntry, err := q.CreateJournalEntry(context.Background(), CreateJournalEntryParams{Description: "test"})
+			if err != nil {
+				return err
+			}
+			journalEntryID = journalEntry.ID
+			// postings are checked at commit, so they can be added one at a time
+			for i, amount := range amounts {
+				account := account1
+				if i >= 2 {
+					account = account2
+				}
+				entry, err := q.CreateEntry(context.Background(), CreateEntryParams{
+					AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+					Amount:    amount,
+				})
+				if err != nil {
+					return err
+				}
+				if err := addPostings(context.Background(), q, journalEntry.ID, entry); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	require.NoError(t, post(10, -10))
+
+	require.ErrorIs(t, post(10, -9), ErrUnbalancedJournal)
+	_, err := testQueries.GetJournalEntry(context.Background(), journalEntryID)
+	require.ErrorIs(t, TranslateError(err), ErrRecordNotFound)
+
+	// each currency has to balance on its own
+	require.ErrorIs(t, post(10, -10, 5), ErrUnbalancedJournal)
+}
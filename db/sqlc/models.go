@@ -6,6 +6,7 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -15,14 +16,337 @@ type Account struct {
 	Balance   int64        `json:"balance"`
 	Currency  string       `json:"currency"`
 	CreatedAt sql.NullTime `json:"created_at"`
+	// bank code, branch code, serial and mod 97-10 check digits
+	AccountNumber string `json:"account_number"`
+	// frozen accounts can neither send nor receive transfers
+	Frozen bool `json:"frozen"`
+	// chain_seq of the latest entry of the account
+	LastEntrySeq int64 `json:"last_entry_seq"`
+	// hash of the latest entry of the account, the head of its chain
+	LastEntryHash []byte `json:"last_entry_hash"`
+}
+
+type AchFile struct {
+	ID             int64     `json:"id"`
+	FileName       string    `json:"file_name"`
+	FileIDModifier string    `json:"file_id_modifier"`
+	EntryCount     int32     `json:"entry_count"`
+	TotalAmount    int64     `json:"total_amount"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type Beneficiary struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	// account holder at the receiving bank, as sent in the ACH entry
+	Name          string    `json:"name"`
+	RoutingNumber string    `json:"routing_number"`
+	AccountNumber string    `json:"account_number"`
+	AccountType   string    `json:"account_type"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type Entry struct {
 	ID        int64         `json:"id"`
 	AccountID sql.NullInt64 `json:"account_id"`
 	// can be negative for positive
-	Amount    int64        `json:"amount"`
-	CreatedAt sql.NullTime `json:"created_at"`
+	Amount          int64         `json:"amount"`
+	CreatedAt       sql.NullTime  `json:"created_at"`
+	TransferGroupID sql.NullInt64 `json:"transfer_group_id"`
+	// set on the two entries that post the fee of a transfer
+	FeeTransferID sql.NullInt64 `json:"fee_transfer_id"`
+	// transfer the entry posts; null on entries written before it was recorded that could not be tied to their transfer
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// position of the entry in the hash chain of its account, from 1
+	ChainSeq sql.NullInt64 `json:"chain_seq"`
+	// SHA-256 of the hash of the previous entry of the account and the contents of this one; see ledger.EntryHash
+	Hash []byte `json:"hash"`
+}
+
+type FeeRule struct {
+	ID int64 `json:"id"`
+	// currency of the source account; fees are charged in it
+	Currency      string `json:"currency"`
+	CrossCurrency bool   `json:"cross_currency"`
+	FlatAmount    int64  `json:"flat_amount"`
+	// percentage of the amount in basis points, added to flat_amount
+	PercentageBps int32         `json:"percentage_bps"`
+	MinAmount     int64         `json:"min_amount"`
+	MaxAmount     sql.NullInt64 `json:"max_amount"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type FxQuote struct {
+	ID           int64  `json:"id"`
+	Username     string `json:"username"`
+	FxRateID     int64  `json:"fx_rate_id"`
+	FromCurrency string `json:"from_currency"`
+	ToCurrency   string `json:"to_currency"`
+	// to currency units per from currency unit, scaled by 10^8
+	Rate      int64     `json:"rate"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// set once the quote has been used
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type FxRate struct {
+	ID            int64  `json:"id"`
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	// quote currency units per base currency unit, scaled by 10^8
+	Rate      int64     `json:"rate"`
+	ValidFrom time.Time `json:"valid_from"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Holiday struct {
+	Date time.Time `json:"date"`
+	Name string    `json:"name"`
+}
+
+type IdempotencyKey struct {
+	ID             int64  `json:"id"`
+	Username       string `json:"username"`
+	IdempotencyKey string `json:"idempotency_key"`
+	// sha256 of the request the key was first used with
+	RequestHash    string          `json:"request_hash"`
+	ResponseStatus int32           `json:"response_status"`
+	ResponseBody   json.RawMessage `json:"response_body"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+}
+
+type JournalEntry struct {
+	ID int64 `json:"id"`
+	// transfer the journal entry posts, with its fee
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// transfer group the journal entry posts
+	TransferGroupID sql.NullInt64 `json:"transfer_group_id"`
+	Description     string        `json:"description"`
+	CreatedAt       time.Time     `json:"created_at"`
+}
+
+type OutboundPayment struct {
+	ID int64 `json:"id"`
+	// debit of the customer into the clearing suspense account
+	TransferID    int64 `json:"transfer_id"`
+	FromAccountID int64 `json:"from_account_id"`
+	BeneficiaryID int64 `json:"beneficiary_id"`
+	// beneficiary details are copied so later edits never change a payment
+	BeneficiaryName string        `json:"beneficiary_name"`
+	RoutingNumber   string        `json:"routing_number"`
+	AccountNumber   string        `json:"account_number"`
+	AccountType     string        `json:"account_type"`
+	Amount          int64         `json:"amount"`
+	Status          string        `json:"status"`
+	AchFileID       sql.NullInt64 `json:"ach_file_id"`
+	// ACH trace number, set when the payment is exported
+	TraceNumber sql.NullString `json:"trace_number"`
+	ReturnCode  sql.NullString `json:"return_code"`
+	// reversal that credited the customer back when the receiving bank returned the payment
+	ReturnTransferID sql.NullInt64 `json:"return_transfer_id"`
+	ExportedAt       sql.NullTime  `json:"exported_at"`
+	ReturnedAt       sql.NullTime  `json:"returned_at"`
+	CreatedAt        time.Time     `json:"created_at"`
+	// day (UTC) of the ACH file; trace numbers are unique within a day
+	TraceDate sql.NullTime `json:"trace_date"`
+}
+
+type PaymentRequest struct {
+	ID int64 `json:"id"`
+	// account the money is paid into
+	RequesterAccountID int64     `json:"requester_account_id"`
+	Payer              string    `json:"payer"`
+	Amount             int64     `json:"amount"`
+	Currency           string    `json:"currency"`
+	Note               string    `json:"note"`
+	Status             string    `json:"status"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	// set once the request is paid
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	DecidedAt  sql.NullTime  `json:"decided_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type PendingTransfer struct {
+	ID int64 `json:"id"`
+	// maker of the request, who can never approve it
+	CreatedBy     string `json:"created_by"`
+	FromAccountID int64  `json:"from_account_id"`
+	// null for a transfer batch, whose items go to many accounts
+	ToAccountID sql.NullInt64  `json:"to_account_id"`
+	Amount      int64          `json:"amount"`
+	Currency    string         `json:"currency"`
+	Status      string         `json:"status"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+	TransferID  sql.NullInt64  `json:"transfer_id"`
+	DecidedAt   sql.NullTime   `json:"decided_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Memo        string         `json:"memo"`
+	Reference   sql.NullString `json:"reference"`
+	Category    string         `json:"category"`
+	// set when the transfer was held for review by the risk engine
+	RiskDecisionID sql.NullInt64 `json:"risk_decision_id"`
+	// what an approval runs: a transfer, a cross-currency transfer at the rate of the time of approval, the payment of a payment request, an outbound transfer or a whole transfer batch
+	Kind string `json:"kind"`
+	// quote the maker was shown; approval converts at the latest rate
+	FxQuoteID        sql.NullInt64 `json:"fx_quote_id"`
+	PaymentRequestID sql.NullInt64 `json:"payment_request_id"`
+	BeneficiaryID    sql.NullInt64 `json:"beneficiary_id"`
+	TransferBatchID  sql.NullInt64 `json:"transfer_batch_id"`
+	// set when the scheduled transfer worker held the transfer; the decision is recorded on it
+	ScheduledTransferID sql.NullInt64 `json:"scheduled_transfer_id"`
+}
+
+type PendingTransferApproval struct {
+	ID                int64     `json:"id"`
+	PendingTransferID int64     `json:"pending_transfer_id"`
+	Username          string    `json:"username"`
+	Decision          string    `json:"decision"`
+	Comment           string    `json:"comment"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type Posting struct {
+	ID             int64 `json:"id"`
+	JournalEntryID int64 `json:"journal_entry_id"`
+	// entry of the account the posting is booked as
+	EntryID   int64 `json:"entry_id"`
+	AccountID int64 `json:"account_id"`
+	// currency of the account; the postings of a journal entry sum to zero in every currency
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+}
+
+type ReconciliationDiscrepancy struct {
+	ID    int64 `json:"id"`
+	RunID int64 `json:"run_id"`
+	// null on the grand total of the currency
+	AccountID sql.NullInt64 `json:"account_id"`
+	Currency  string        `json:"currency"`
+	// balance of the account, or of all the accounts in the currency
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+	// balance minus entries_total
+	Difference int64 `json:"difference"`
+	// adjustment posted by a repair run to bring the entries in line with the balance
+	JournalEntryID sql.NullInt64 `json:"journal_entry_id"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type ReconciliationRun struct {
+	ID              int64 `json:"id"`
+	Repair          bool  `json:"repair"`
+	AccountsChecked int64 `json:"accounts_checked"`
+	// accounts whose balance differs from the sum of their entries
+	Discrepancies int64     `json:"discrepancies"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type RiskDecision struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Decision      string `json:"decision"`
+	// names of the rules that fired, in the order they were checked
+	FiredRules []string  `json:"fired_rules"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type RiskRule struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Action string `json:"action"`
+	// the rule only applies to transfers in this currency; NULL for all
+	Currency sql.NullString `json:"currency"`
+	// velocity: transfers allowed in the window; above_average: history needed before the rule applies
+	MaxCount int32 `json:"max_count"`
+	// velocity: length of the window; after_password_change: how long after the change the rule applies
+	WindowMinutes int32 `json:"window_minutes"`
+	// the rule only fires for transfers of at least this amount
+	MinAmount int64 `json:"min_amount"`
+	// above_average: fires when the amount exceeds the average by this factor
+	Multiplier int32     `json:"multiplier"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type SanctionsCase struct {
+	ID        int64  `json:"id"`
+	Operation string `json:"operation"`
+	// user who made the request; not a foreign key because a blocked user is never created
+	InitiatedBy  string `json:"initiated_by"`
+	ScreenedName string `json:"screened_name"`
+	// user whose full name matched the list
+	ScreenedUsername string `json:"screened_username"`
+	// counterparty account of a transfer
+	AccountID     sql.NullInt64 `json:"account_id"`
+	ListEntryID   string        `json:"list_entry_id"`
+	ListEntryName string        `json:"list_entry_name"`
+	Score         float64       `json:"score"`
+	// flag lets the operation go on or holds it for review; block refuses it
+	Action string `json:"action"`
+	// cleared cases are false positives; the same name no longer matches the same entry
+	Status         string         `json:"status"`
+	ResolvedBy     sql.NullString `json:"resolved_by"`
+	ResolutionNote string         `json:"resolution_note"`
+	ResolvedAt     sql.NullTime   `json:"resolved_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// next time the worker should try the transfer
+	ExecuteAt       time.Time     `json:"execute_at"`
+	Status          string        `json:"status"`
+	Attempts        int32         `json:"attempts"`
+	TransferID      sql.NullInt64 `json:"transfer_id"`
+	CreatedAt       time.Time     `json:"created_at"`
+	StandingOrderID sql.NullInt64 `json:"standing_order_id"`
+	// nominal date of the standing order occurrence
+	OccurrenceDate sql.NullTime `json:"occurrence_date"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64         `json:"id"`
+	ScheduledTransferID int64         `json:"scheduled_transfer_id"`
+	Outcome             string        `json:"outcome"`
+	Reason              string        `json:"reason"`
+	TransferID          sql.NullInt64 `json:"transfer_id"`
+	CreatedAt           time.Time     `json:"created_at"`
+}
+
+type StandingOrder struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Frequency     string `json:"frequency"`
+	// 0 is Sunday
+	Weekday        sql.NullInt32 `json:"weekday"`
+	DayOfMonth     sql.NullInt32 `json:"day_of_month"`
+	StartDate      time.Time     `json:"start_date"`
+	EndDate        sql.NullTime  `json:"end_date"`
+	MaxOccurrences sql.NullInt32 `json:"max_occurrences"`
+	Occurrences    int32         `json:"occurrences"`
+	// next date the rule produces, before moving it onto a business day
+	NextNominalDate time.Time `json:"next_nominal_date"`
+	NextRunDate     time.Time `json:"next_run_date"`
+	Status          string    `json:"status"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 type Transfer struct {
@@ -32,6 +356,96 @@ type Transfer struct {
 	// Mut be positive
 	Amount    int64        `json:"amount"`
 	CreatedAt sql.NullTime `json:"created_at"`
+	// transfer this one (partially) reverses
+	ReversalOfTransferID sql.NullInt64 `json:"reversal_of_transfer_id"`
+	Fee                  int64         `json:"fee"`
+	// free text from the sender, searchable
+	Memo string `json:"memo"`
+	// reference supplied by the client, unique per source account
+	Reference sql.NullString `json:"reference"`
+	Category  string         `json:"category"`
+	// pending and processing transfers have no entries yet
+	Status          string    `json:"status"`
+	FailureReason   string    `json:"failure_reason"`
+	StatusChangedAt time.Time `json:"status_changed_at"`
+}
+
+type TransferBatch struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	Currency      string `json:"currency"`
+	// atomic runs every item in one transaction, per_item runs each on its own
+	Mode        string       `json:"mode"`
+	Status      string       `json:"status"`
+	ItemCount   int32        `json:"item_count"`
+	TotalAmount int64        `json:"total_amount"`
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt sql.NullTime `json:"completed_at"`
+	// MsgId of the pain.001 message the batch was imported from
+	MessageID sql.NullString `json:"message_id"`
+	// PmtInfId of the payment information block the batch was imported from
+	PaymentInformationID sql.NullString `json:"payment_information_id"`
+}
+
+type TransferBatchItem struct {
+	ID      int64 `json:"id"`
+	BatchID int64 `json:"batch_id"`
+	// position of the item in the uploaded file, starting at 1
+	Line        int32         `json:"line"`
+	ToAccountID int64         `json:"to_account_id"`
+	Amount      int64         `json:"amount"`
+	Reference   string        `json:"reference"`
+	Status      string        `json:"status"`
+	Reason      string        `json:"reason"`
+	TransferID  sql.NullInt64 `json:"transfer_id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	EndToEndID  string        `json:"end_to_end_id"`
+	// why the item failed, for reports; reason holds the error text
+	FailureCode string `json:"failure_code"`
+}
+
+type TransferConfirmation struct {
+	ID            int64          `json:"id"`
+	Username      string         `json:"username"`
+	FromAccountID int64          `json:"from_account_id"`
+	ToAccountID   int64          `json:"to_account_id"`
+	Alias         string         `json:"alias"`
+	Amount        int64          `json:"amount"`
+	Currency      string         `json:"currency"`
+	Memo          string         `json:"memo"`
+	Reference     sql.NullString `json:"reference"`
+	Category      string         `json:"category"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+	// set once confirmed; a confirmation can only be used once
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	// set when confirming held the transfer for approval; a confirmation can only be used once
+	PendingTransferID sql.NullInt64 `json:"pending_transfer_id"`
+	// set when the risk engine asked for a review; confirming holds the transfer for approval
+	RiskDecisionID sql.NullInt64 `json:"risk_decision_id"`
+}
+
+type TransferGroup struct {
+	ID       int64  `json:"id"`
+	Currency string `json:"currency"`
+	// sum of the debit legs, which equals the sum of the credit legs
+	TotalAmount int64     `json:"total_amount"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type TransferLimit struct {
+	ID int64 `json:"id"`
+	// set for a per account limit, overrides the tier limit
+	AccountID sql.NullInt64 `json:"account_id"`
+	// set for a limit applying to every user of the tier
+	Tier            sql.NullString `json:"tier"`
+	Currency        string         `json:"currency"`
+	MaxSingleAmount sql.NullInt64  `json:"max_single_amount"`
+	MaxDailyAmount  sql.NullInt64  `json:"max_daily_amount"`
+	MaxDailyCount   sql.NullInt64  `json:"max_daily_count"`
+	CreatedAt       time.Time      `json:"created_at"`
 }
 
 type User struct {
@@ -41,4 +455,10 @@ type User struct {
 	Email             string       `json:"email"`
 	PasswordChangedAt time.Time    `json:"password_changed_at"`
 	CreatedAt         sql.NullTime `json:"created_at"`
+	Tier              string       `json:"tier"`
+	Role              string       `json:"role"`
+	// E.164 phone number, usable as a transfer alias
+	Phone sql.NullString `json:"phone"`
+	// only verified emails can be used as a transfer alias
+	EmailVerifiedAt sql.NullTime `json:"email_verified_at"`
 }
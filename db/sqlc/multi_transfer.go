@@ -0,0 +1,186 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrNoTransferLegs        = errors.New("a transfer group needs at least one debit and one credit leg")
+	ErrUnbalancedTransfer    = errors.New("debit and credit legs don't add up to the same amount")
+	ErrTransferLegBothSides  = errors.New("an account can't be debited and credited in the same transfer group")
+	ErrTransferGroupCurrency = fmt.Errorf("all accounts of a transfer group must have the same currency: %w", ErrCurrencyMismatch)
+)
+
+// TransferLeg is one side of a multi-party transfer: the account and the
+// positive amount it is debited or credited.
+type TransferLeg struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+type MultiTransferTxParams struct {
+	Debits      []TransferLeg `json:"debits"`
+	Credits     []TransferLeg `json:"credits"`
+	Description string        `json:"description"`
+}
+
+type MultiTransferTxResult struct {
+	Group TransferGroup `json:"group"`
+	// DebitEntries and CreditEntries follow the order of the legs in the params.
+	DebitEntries  []Entry   `json:"debit_entries"`
+	CreditEntries []Entry   `json:"credit_entries"`
+	Accounts      []Account `json:"accounts"`
+	// JournalEntry holds the entries of both sides as its postings.
+	JournalEntry JournalEntry `json:"journal_entry"`
+}
+
+// MultiTransferTx moves money from N debit legs to M credit legs in one
+// transaction and records them under a single transfer group. The totals of
+// both sides must match and every account must be in the same currency.
+// All accounts are locked in ascending id order before anything is written,
+// and every debited account has to stay within its limits and keep a
+// non-negative balance.
+func (store *SQLStore) MultiTransferTx(ctx context.Context, arg MultiTransferTxParams) (MultiTransferTxResult, error) {
+	var result MultiTransferTxResult
+	total, err := validateTransferLegs(arg.Debits, arg.Credits)
+	if err != nil {
+		return result, err
+	}
+
+	err = store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		// start over when the transaction is retried
+		result = MultiTransferTxResult{}
+		debited := make(map[int64]int64, len(arg.Debits))
+		ids := make([]int64, 0, len(arg.Debits)+len(arg.Credits))
+		for _, leg := range arg.Debits {
+			debited[leg.AccountID] += leg.Amount
+			ids = append(ids, leg.AccountID)
+		}
+		for _, leg := range arg.Credits {
+			ids = append(ids, leg.AccountID)
+		}
+
+		accounts, err := lockAccounts(ctx, q, ids...)
+		if err != nil {
+			return err
+		}
+		currency := accounts[ids[0]].Currency
+		for _, account := range accounts {
+			if account.Currency != currency {
+				return ErrTransferGroupCurrency
+			}
+			if err := checkNotFrozen(account); err != nil {
+				return err
+			}
+		}
+		for _, id := range sortedIDs(ids) {
+			if amount, ok := debited[id]; ok {
+				if err := checkTransferLimits(ctx, q, accounts[id], amount); err != nil {
+					return err
+				}
+			}
+		}
+
+		result.Group, err = q.CreateTransferGroup(ctx, CreateTransferGroupParams{
+			Currency:    currency,
+			TotalAmount: total,
+			Description: arg.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		deltas := make(map[int64]int64, len(accounts))
+		createEntry := func(leg TransferLeg, amount int64) (Entry, error) {
+			deltas[leg.AccountID] += amount
+			return q.CreateTransferGroupEntry(ctx, CreateTransferGroupEntryParams{
+				AccountID:       sql.NullInt64{Int64: leg.AccountID, Valid: true},
+				Amount:          amount,
+				TransferGroupID: sql.NullInt64{Int64: result.Group.ID, Valid: true},
+			})
+		}
+		for _, leg := range arg.Debits {
+			entry, err := createEntry(leg, -leg.Amount)
+			if err != nil {
+				return err
+			}
+			result.DebitEntries = append(result.DebitEntries, entry)
+		}
+		for _, leg := range arg.Credits {
+			entry, err := createEntry(leg, leg.Amount)
+			if err != nil {
+				return err
+			}
+			result.CreditEntries = append(result.CreditEntries, entry)
+		}
+		result.JournalEntry, err = postJournalEntry(ctx, q, CreateJournalEntryParams{
+			TransferGroupID: sql.NullInt64{Int64: result.Group.ID, Valid: true},
+			Description:     arg.Description,
+		}, append(result.DebitEntries, result.CreditEntries...)...)
+		if err != nil {
+			return err
+		}
+
+		updated, err := addBalances(ctx, q, deltas)
+		if err != nil {
+			return err
+		}
+		for _, id := range sortedIDs(ids) {
+			account := updated[id]
+			if _, ok := debited[id]; ok && account.Balance < 0 {
+				return fmt.Errorf("account %d: %w", id, ErrInsufficientFunds)
+			}
+			result.Accounts = append(result.Accounts, account)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// validateTransferLegs checks the legs of a multi-party transfer before any
+// account is touched and returns the amount moved.
+func validateTransferLegs(debits []TransferLeg, credits []TransferLeg) (int64, error) {
+	if len(debits) == 0 || len(credits) == 0 {
+		return 0, ErrNoTransferLegs
+	}
+	sum := func(legs []TransferLeg) (int64, error) {
+		var total int64
+		for _, leg := range legs {
+			if leg.Amount <= 0 {
+				return 0, fmt.Errorf("account %d: leg amount must be positive", leg.AccountID)
+			}
+			if total > math.MaxInt64-leg.Amount {
+				return 0, fmt.Errorf("account %d: total amount overflows", leg.AccountID)
+			}
+			total += leg.Amount
+		}
+		return total, nil
+	}
+	debitTotal, err := sum(debits)
+	if err != nil {
+		return 0, err
+	}
+	creditTotal, err := sum(credits)
+	if err != nil {
+		return 0, err
+	}
+	if debitTotal != creditTotal {
+		return 0, ErrUnbalancedTransfer
+	}
+
+	debited := make(map[int64]bool, len(debits))
+	for _, leg := range debits {
+		debited[leg.AccountID] = true
+	}
+	for _, leg := range credits {
+		if debited[leg.AccountID] {
+			return 0, ErrTransferLegBothSides
+		}
+	}
+	return debitTotal, nil
+}
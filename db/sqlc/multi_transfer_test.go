@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestValidateTransferLegs(t *testing.T) {
+	total, err := validateTransferLegs(
+		[]TransferLeg{{AccountID: 1, Amount: 70}, {AccountID: 2, Amount: 30}},
+		[]TransferLeg{{AccountID: 3, Amount: 90}, {AccountID: 4, Amount: 10}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), total)
+
+	_, err = validateTransferLegs(nil, []TransferLeg{{AccountID: 3, Amount: 10}})
+	require.ErrorIs(t, err, ErrNoTransferLegs)
+
+	_, err = validateTransferLegs(
+		[]TransferLeg{{AccountID: 1, Amount: 100}},
+		[]TransferLeg{{AccountID: 3, Amount: 99}},
+	)
+	require.ErrorIs(t, err, ErrUnbalancedTransfer)
+
+	_, err = validateTransferLegs(
+		[]TransferLeg{{AccountID: 1, Amount: 100}},
+		[]TransferLeg{{AccountID: 1, Amount: 100}},
+	)
+	require.ErrorIs(t, err, ErrTransferLegBothSides)
+
+	_, err = validateTransferLegs(
+		[]TransferLeg{{AccountID: 1, Amount: 0}},
+		[]TransferLeg{{AccountID: 3, Amount: 0}},
+	)
+	require.Error(t, err)
+}
+
+func TestMultiTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	buyer1 := createAccountInCurrency(t, utils.USD, 1000)
+	buyer2 := createAccountInCurrency(t, utils.USD, 1000)
+	seller := createAccountInCurrency(t, utils.USD, 0)
+	platform := createAccountInCurrency(t, utils.USD, 0)
+
+	result, err := store.MultiTransferTx(context.Background(), MultiTransferTxParams{
+		Debits:      []TransferLeg{{AccountID: buyer1.ID, Amount: 300}, {AccountID: buyer2.ID, Amount: 200}},
+		Credits:     []TransferLeg{{AccountID: seller.ID, Amount: 450}, {AccountID: platform.ID, Amount: 50}},
+		Description: "order 42",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(500), result.Group.TotalAmount)
+	require.Equal(t, utils.USD, result.Group.Currency)
+	require.Len(t, result.DebitEntries, 2)
+	require.Len(t, result.CreditEntries, 2)
+	require.Equal(t, int64(-300), result.DebitEntries[0].Amount)
+	require.Equal(t, int64(450), result.CreditEntries[0].Amount)
+
+	entries, err := store.ListTransferGroupEntries(context.Background(), sql.NullInt64{Int64: result.Group.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	var sum int64
+	for _, entry := range entries {
+		sum += entry.Amount
+	}
+	require.Zero(t, sum)
+
+	balances := map[int64]int64{buyer1.ID: 700, buyer2.ID: 800, seller.ID: 450, platform.ID: 50}
+	require.Len(t, result.Accounts, len(balances))
+	for id, balance := range balances {
+		account, err := store.GetAccounts(context.Background(), id)
+		require.NoError(t, err)
+		require.Equal(t, balance, account.Balance)
+	}
+}
+
+func TestMultiTransferTxRollsBack(t *testing.T) {
+	store := NewStore(testDB)
+	buyer := createAccountInCurrency(t, utils.USD, 100)
+	seller := createAccountInCurrency(t, utils.USD, 0)
+	other := createAccountInCurrency(t, utils.EUR, 0)
+
+	_, err := store.MultiTransferTx(context.Background(), MultiTransferTxParams{
+		Debits:  []TransferLeg{{AccountID: buyer.ID, Amount: 50}},
+		Credits: []TransferLeg{{AccountID: seller.ID, Amount: 25}, {AccountID: other.ID, Amount: 25}},
+	})
+	require.ErrorIs(t, err, ErrTransferGroupCurrency)
+
+	_, err = store.MultiTransferTx(context.Background(), MultiTransferTxParams{
+		Debits:  []TransferLeg{{AccountID: buyer.ID, Amount: 150}},
+		Credits: []TransferLeg{{AccountID: seller.ID, Amount: 150}},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	account, err := store.GetAccounts(context.Background(), buyer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), account.Balance)
+}
+
+func TestMultiTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := make([]Account, 4)
+	for i := range accounts {
+		accounts[i] = createAccountInCurrency(t, utils.USD, 1000)
+	}
+
+	// Each group debits and credits the same accounts in a different order;
+	// locking in id order keeps them from deadlocking.
+	n := 10
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		a, b, c, d := accounts[i%4], accounts[(i+1)%4], accounts[(i+2)%4], accounts[(i+3)%4]
+		go func() {
+			_, err := store.MultiTransferTx(context.Background(), MultiTransferTxParams{
+				Debits:  []TransferLeg{{AccountID: a.ID, Amount: 10}, {AccountID: b.ID, Amount: 10}},
+				Credits: []TransferLeg{{AccountID: c.ID, Amount: 15}, {AccountID: d.ID, Amount: 5}},
+			})
+			errs <- err
+		}()
+	}
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	var total int64
+	for _, account := range accounts {
+		updated, err := store.GetAccounts(context.Background(), account.ID)
+		require.NoError(t, err)
+		total += updated.Balance
+	}
+	require.Equal(t, int64(4000), total)
+}
@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// ClearingOwner owns the clearing suspense account outbound payments are
+// debited into until they settle at the receiving bank.
+const ClearingOwner = "bankclearing"
+
+// Statuses of an outbound payment.
+const (
+	OutboundPaymentPending  = "pending"
+	OutboundPaymentExported = "exported"
+	OutboundPaymentReturned = "returned"
+)
+
+// Types of a beneficiary account.
+const (
+	AccountTypeChecking = "checking"
+	AccountTypeSavings  = "savings"
+)
+
+// achFileIDModifiers tell apart the ACH files sent on the same day.
+const achFileIDModifiers = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+var (
+	ErrOutboundPaymentCurrency    = errors.New("outbound payments can only be made in USD")
+	ErrOutboundPaymentReversal    = errors.New("outbound payments can only be returned by the receiving bank")
+	ErrOutboundPaymentNotExported = errors.New("outbound payment hasn't been exported yet")
+	ErrOutboundPaymentReturned    = errors.New("outbound payment has already been returned")
+	ErrNoOutboundPayments         = errors.New("no outbound payments to export")
+	ErrTooManyACHFiles            = errors.New("no ACH file id modifier left for today")
+	ErrTooManyACHEntries          = errors.New("no ACH trace number left for today")
+)
+
+// maxTraceSequence is the largest sequence number a trace number can hold.
+const maxTraceSequence = 9_999_999
+
+type CreateOutboundTransferTxParams struct {
+	// Username must own both the source account and the beneficiary.
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	BeneficiaryID int64  `json:"beneficiary_id"`
+	Amount        int64  `json:"amount"`
+	TransferDetails
+}
+
+type OutboundTransferTxResult struct {
+	TransferTxResult
+	Payment OutboundPayment `json:"payment"`
+}
+
+// CreateOutboundTransferTx debits a customer into the clearing suspense
+// account and records the payment to the beneficiary, which the next ACH
+// export sends to the receiving bank.
+func (store *SQLStore) CreateOutboundTransferTx(ctx context.Context, arg CreateOutboundTransferTxParams) (OutboundTransferTxResult, error) {
+	var result OutboundTransferTxResult
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		var err error
+		result, err = createOutboundTransfer(ctx, q, arg)
+		return err
+	})
+	return result, err
+}
+
+// createOutboundTransfer is CreateOutboundTransferTx inside a transaction the
+// caller runs.
+func createOutboundTransfer(ctx context.Context, q *Queries, arg CreateOutboundTransferTxParams) (OutboundTransferTxResult, error) {
+	var result OutboundTransferTxResult
+	beneficiary, err := q.GetBeneficiary(ctx, arg.BeneficiaryID)
+	if err != nil {
+		return result, err
+	}
+	if beneficiary.Owner != arg.Username {
+		// don't tell other users which ids exist
+		return result, ErrRecordNotFound
+	}
+	fromAccount, err := q.GetAccounts(ctx, arg.FromAccountID)
+	if err != nil {
+		return result, err
+	}
+	if fromAccount.Currency != utils.USD {
+		return result, ErrOutboundPaymentCurrency
+	}
+	clearing, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    ClearingOwner,
+		Currency: utils.USD,
+	})
+	if err != nil {
+		return result, fmt.Errorf("clearing account: %w", err)
+	}
+
+	result.TransferTxResult, err = transferTx(ctx, q, TransferTxParams{
+		FromAccountID:   fromAccount.ID,
+		ToAccountID:     clearing.ID,
+		Amount:          arg.Amount,
+		TransferDetails: arg.TransferDetails,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Payment, err = q.CreateOutboundPayment(ctx, CreateOutboundPaymentParams{
+		TransferID:      result.Transfer.ID,
+		FromAccountID:   fromAccount.ID,
+		BeneficiaryID:   beneficiary.ID,
+		BeneficiaryName: beneficiary.Name,
+		RoutingNumber:   beneficiary.RoutingNumber,
+		AccountNumber:   beneficiary.AccountNumber,
+		AccountType:     beneficiary.AccountType,
+		Amount:          arg.Amount,
+	})
+	return result, err
+}
+
+type ExportOutboundPaymentsTxParams struct {
+	// Limit caps the number of payments in one file.
+	Limit int32
+	// TracePrefix is the first eight digits of the originating bank's
+	// routing number, which begin every trace number.
+	TracePrefix string
+	// FileName names the file from its file id modifier.
+	FileName func(modifier string) string
+	// Write writes the file; the export is rolled back if it fails.
+	Write func(file AchFile, payments []OutboundPayment) error
+}
+
+type ExportOutboundPaymentsTxResult struct {
+	File     AchFile           `json:"file"`
+	Payments []OutboundPayment `json:"payments"`
+}
+
+// ExportOutboundPaymentsTx claims the pending outbound payments, gives each
+// a trace number and hands them to arg.Write. They are only marked exported
+// if the file was written, so a failed export leaves them for the next one.
+// It returns ErrNoOutboundPayments when nothing is pending.
+func (store *SQLStore) ExportOutboundPaymentsTx(ctx context.Context, arg ExportOutboundPaymentsTxParams) (ExportOutboundPaymentsTxResult, error) {
+	var result ExportOutboundPaymentsTxResult
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		payments, err := q.ListPendingOutboundPaymentsForUpdate(ctx, arg.Limit)
+		if err != nil {
+			return err
+		}
+		if len(payments) == 0 {
+			return ErrNoOutboundPayments
+		}
+
+		today := startOfDay(time.Now())
+		sentToday, err := q.CountACHFilesSince(ctx, today)
+		if err != nil {
+			return err
+		}
+		if sentToday >= int64(len(achFileIDModifiers)) {
+			return ErrTooManyACHFiles
+		}
+		modifier := string(achFileIDModifiers[sentToday])
+		// trace numbers carry on from the files already sent today
+		tracedToday, err := q.SumACHFileEntriesSince(ctx, today)
+		if err != nil {
+			return err
+		}
+		if tracedToday+int64(len(payments)) > maxTraceSequence {
+			return ErrTooManyACHEntries
+		}
+
+		var total int64
+		for _, payment := range payments {
+			total += payment.Amount
+		}
+		result.File, err = q.CreateACHFile(ctx, CreateACHFileParams{
+			FileName:       arg.FileName(modifier),
+			FileIDModifier: modifier,
+			EntryCount:     int32(len(payments)),
+			TotalAmount:    total,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Payments = make([]OutboundPayment, 0, len(payments))
+		for i, payment := range payments {
+			payment, err = q.MarkOutboundPaymentExported(ctx, MarkOutboundPaymentExportedParams{
+				ID:          payment.ID,
+				AchFileID:   result.File.ID,
+				TraceNumber: TraceNumber(arg.TracePrefix, tracedToday+int64(i)+1),
+				TraceDate:   today,
+			})
+			if err != nil {
+				return err
+			}
+			result.Payments = append(result.Payments, payment)
+		}
+		return arg.Write(result.File, result.Payments)
+	})
+	return result, err
+}
+
+// TraceNumber returns the ACH trace number of the entry with sequence
+// number sequence among the entries sent today: the originating bank's
+// prefix followed by the sequence in seven digits.
+func TraceNumber(tracePrefix string, sequence int64) string {
+	return fmt.Sprintf("%s%07d", tracePrefix, sequence)
+}
+
+type ReturnOutboundPaymentTxParams struct {
+	// TraceNumber, Amount and AccountNumber are those of the returned
+	// entry; trace numbers restart every day, so the latest export that
+	// matches all three is the one returned.
+	TraceNumber   string `json:"trace_number"`
+	Amount        int64  `json:"amount"`
+	AccountNumber string `json:"account_number"`
+	// ReturnCode is the reason the receiving bank gave, like R03.
+	ReturnCode string `json:"return_code"`
+}
+
+type ReturnOutboundPaymentTxResult struct {
+	Payment  OutboundPayment         `json:"payment"`
+	Reversal ReverseTransferTxResult `json:"reversal"`
+}
+
+// ReturnOutboundPaymentTx credits the customer back for an outbound payment
+// the receiving bank returned, by reversing its transfer out of the clearing
+// suspense account.
+func (store *SQLStore) ReturnOutboundPaymentTx(ctx context.Context, arg ReturnOutboundPaymentTxParams) (ReturnOutboundPaymentTxResult, error) {
+	var result ReturnOutboundPaymentTxResult
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		payment, err := q.GetOutboundPaymentByTraceForUpdate(ctx, GetOutboundPaymentByTraceForUpdateParams{
+			TraceNumber:   arg.TraceNumber,
+			Amount:        arg.Amount,
+			AccountNumber: arg.AccountNumber,
+		})
+		if err != nil {
+			return err
+		}
+		switch payment.Status {
+		case OutboundPaymentExported:
+		case OutboundPaymentReturned:
+			return ErrOutboundPaymentReturned
+		default:
+			return ErrOutboundPaymentNotExported
+		}
+
+		result.Reversal, err = reverseTransfer(ctx, q, ReverseTransferTxParams{TransferID: payment.TransferID})
+		if err != nil {
+			return err
+		}
+		result.Payment, err = q.MarkOutboundPaymentReturned(ctx, MarkOutboundPaymentReturnedParams{
+			ID:               payment.ID,
+			ReturnCode:       arg.ReturnCode,
+			ReturnTransferID: result.Reversal.Transfer.ID,
+		})
+		return err
+	})
+	return result, err
+}
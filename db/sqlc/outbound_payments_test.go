@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func createTestBeneficiary(t *testing.T, owner string) Beneficiary {
+	arg := CreateBeneficiaryParams{
+		Owner:         owner,
+		Name:          utils.RandomOwner(),
+		RoutingNumber: "011000015",
+		AccountNumber: fmt.Sprint(utils.RandomInt(10000000, 99999999)),
+		AccountType:   AccountTypeChecking,
+	}
+	beneficiary, err := testQueries.CreateBeneficiary(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Name, beneficiary.Name)
+	require.Equal(t, arg.AccountNumber, beneficiary.AccountNumber)
+	return beneficiary
+}
+
+func createTestOutboundTransfer(t *testing.T, amount int64) (Account, OutboundTransferTxResult) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	beneficiary := createTestBeneficiary(t, from.Owner)
+
+	result, err := store.CreateOutboundTransferTx(context.Background(), CreateOutboundTransferTxParams{
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		BeneficiaryID: beneficiary.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	return from, result
+}
+
+func TestCreateOutboundTransferTx(t *testing.T) {
+	from, result := createTestOutboundTransfer(t, 300)
+	require.Equal(t, int64(700), result.FromAccount.Balance)
+	require.Equal(t, ClearingOwner, result.ToAccount.Owner)
+	require.Equal(t, result.Transfer.ID, result.Payment.TransferID)
+	require.Equal(t, OutboundPaymentPending, result.Payment.Status)
+	require.Equal(t, "011000015", result.Payment.RoutingNumber)
+	require.False(t, result.Payment.TraceNumber.Valid)
+
+	store := NewStore(testDB)
+	// only the owner can pay a beneficiary
+	other := createRandomAccount(t)
+	_, err := store.CreateOutboundTransferTx(context.Background(), CreateOutboundTransferTxParams{
+		Username:      other.Owner,
+		FromAccountID: from.ID,
+		BeneficiaryID: result.Payment.BeneficiaryID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	eur := createAccountInCurrency(t, utils.EUR, 1000)
+	beneficiary := createTestBeneficiary(t, eur.Owner)
+	_, err = store.CreateOutboundTransferTx(context.Background(), CreateOutboundTransferTxParams{
+		Username:      eur.Owner,
+		FromAccountID: eur.ID,
+		BeneficiaryID: beneficiary.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrOutboundPaymentCurrency)
+
+	// the debit can't be reversed like an internal transfer
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: result.Transfer.ID})
+	require.ErrorIs(t, err, ErrOutboundPaymentReversal)
+}
+
+func exportTestPayments(t *testing.T, write func(AchFile, []OutboundPayment) error) (ExportOutboundPaymentsTxResult, error) {
+	store := NewStore(testDB)
+	result, err := store.ExportOutboundPaymentsTx(context.Background(), ExportOutboundPaymentsTxParams{
+		Limit:       1000,
+		TracePrefix: "12345678",
+		FileName: func(modifier string) string {
+			return fmt.Sprintf("test-%s-%s.txt", utils.RandomString(10), modifier)
+		},
+		Write: write,
+	})
+	if errors.Is(err, ErrTooManyACHFiles) {
+		t.Skip("all of today's ACH file id modifiers are used")
+	}
+	return result, err
+}
+
+func TestExportOutboundPaymentsTx(t *testing.T) {
+	_, created := createTestOutboundTransfer(t, 300)
+
+	// a failed write leaves the payment pending
+	_, err := exportTestPayments(t, func(AchFile, []OutboundPayment) error {
+		return errors.New("disk full")
+	})
+	require.Error(t, err)
+	payment, err := testQueries.GetOutboundPayment(context.Background(), created.Payment.ID)
+	require.NoError(t, err)
+	require.Equal(t, OutboundPaymentPending, payment.Status)
+
+	var written []OutboundPayment
+	result, err := exportTestPayments(t, func(file AchFile, payments []OutboundPayment) error {
+		written = payments
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.Payments, written)
+	require.Equal(t, int32(len(written)), result.File.EntryCount)
+
+	payment, err = testQueries.GetOutboundPayment(context.Background(), created.Payment.ID)
+	require.NoError(t, err)
+	require.Equal(t, OutboundPaymentExported, payment.Status)
+	require.Equal(t, result.File.ID, payment.AchFileID.Int64)
+	require.Equal(t, startOfDay(time.Now()), payment.TraceDate.Time.UTC())
+	require.Contains(t, written, payment)
+
+	// trace numbers follow each other within the file
+	for i := 1; i < len(written); i++ {
+		require.Greater(t, written[i].TraceNumber.String, written[i-1].TraceNumber.String)
+		require.True(t, strings.HasPrefix(written[i].TraceNumber.String, "12345678"))
+	}
+}
+
+func TestReturnOutboundPaymentTx(t *testing.T) {
+	from, created := createTestOutboundTransfer(t, 300)
+	store := NewStore(testDB)
+	arg := ReturnOutboundPaymentTxParams{
+		TraceNumber:   TraceNumber("12345678", maxTraceSequence),
+		Amount:        created.Payment.Amount,
+		AccountNumber: created.Payment.AccountNumber,
+		ReturnCode:    "R03",
+	}
+
+	_, err := store.ReturnOutboundPaymentTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = exportTestPayments(t, func(AchFile, []OutboundPayment) error { return nil })
+	require.NoError(t, err)
+	exported, err := testQueries.GetOutboundPayment(context.Background(), created.Payment.ID)
+	require.NoError(t, err)
+	arg.TraceNumber = exported.TraceNumber.String
+
+	// the amount and account must match too
+	_, err = store.ReturnOutboundPaymentTx(context.Background(), ReturnOutboundPaymentTxParams{
+		TraceNumber:   arg.TraceNumber,
+		Amount:        arg.Amount + 1,
+		AccountNumber: arg.AccountNumber,
+		ReturnCode:    "R03",
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	result, err := store.ReturnOutboundPaymentTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, OutboundPaymentReturned, result.Payment.Status)
+	require.Equal(t, "R03", result.Payment.ReturnCode.String)
+	require.Equal(t, result.Reversal.Transfer.ID, result.Payment.ReturnTransferID.Int64)
+	require.Equal(t, created.Transfer.ID, result.Reversal.Transfer.ReversalOfTransferID.Int64)
+
+	account, err := testQueries.GetAccounts(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), account.Balance)
+
+	_, err = store.ReturnOutboundPaymentTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrOutboundPaymentReturned)
+}
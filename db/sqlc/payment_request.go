@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Statuses of a payment request.
+const (
+	PaymentRequestPending  = "pending"
+	PaymentRequestPaid     = "paid"
+	PaymentRequestDeclined = "declined"
+)
+
+var (
+	ErrPaymentRequestClosed   = errors.New("payment request has already been paid or declined")
+	ErrPaymentRequestExpired  = errors.New("payment request has expired")
+	ErrPaymentRequestHeld     = errors.New("payment of the payment request is already waiting for approval")
+	ErrNotPaymentRequestPayer = errors.New("only the payer of a payment request can pay it, from their own account")
+)
+
+type PayPaymentRequestTxParams struct {
+	ID int64 `json:"id"`
+	// Payer must be the user the request is addressed to.
+	Payer         string `json:"payer"`
+	FromAccountID int64  `json:"from_account_id"`
+}
+
+type PayPaymentRequestTxResult struct {
+	PaymentRequest PaymentRequest   `json:"payment_request"`
+	Transfer       TransferTxResult `json:"transfer"`
+}
+
+// PayPaymentRequestTx pays a payment request from one of the payer's
+// accounts with transferTx and links the transfer to the request, all in one
+// transaction. The request is locked first, so of several concurrent
+// attempts to pay it only one goes through.
+func (store *SQLStore) PayPaymentRequestTx(ctx context.Context, arg PayPaymentRequestTxParams) (PayPaymentRequestTxResult, error) {
+	var result PayPaymentRequestTxResult
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		request, err := q.GetPaymentRequestForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if request.Payer != arg.Payer {
+			return ErrNotPaymentRequestPayer
+		}
+		if request.Status != PaymentRequestPending {
+			return ErrPaymentRequestClosed
+		}
+		if !time.Now().Before(request.ExpiresAt) {
+			return ErrPaymentRequestExpired
+		}
+
+		result, err = payPaymentRequest(ctx, q, request, arg.FromAccountID)
+		return err
+	})
+	return result, err
+}
+
+// payPaymentRequest pays a locked payment request from one of the payer's
+// accounts and links the transfer to it.
+func payPaymentRequest(ctx context.Context, q *Queries, request PaymentRequest, fromAccountID int64) (PayPaymentRequestTxResult, error) {
+	var result PayPaymentRequestTxResult
+	// owner and currency never change, so the account needn't be locked here
+	from, err := q.GetAccounts(ctx, fromAccountID)
+	if err != nil {
+		return result, err
+	}
+	if from.Owner != request.Payer {
+		return result, ErrNotPaymentRequestPayer
+	}
+	if from.Currency != request.Currency {
+		return result, ErrCurrencyMismatch
+	}
+
+	result.Transfer, err = transferTx(ctx, q, TransferTxParams{
+		FromAccountID:   fromAccountID,
+		ToAccountID:     request.RequesterAccountID,
+		Amount:          request.Amount,
+		TransferDetails: TransferDetails{Memo: request.Note},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.PaymentRequest, err = q.DecidePaymentRequest(ctx, DecidePaymentRequestParams{
+		ID:         request.ID,
+		Status:     PaymentRequestPaid,
+		TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+	})
+	return result, err
+}
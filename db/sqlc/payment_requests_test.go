@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func createTestPaymentRequest(t *testing.T, requester Account, payer string, expiresAt time.Time) PaymentRequest {
+	request, err := testQueries.CreatePaymentRequest(context.Background(), CreatePaymentRequestParams{
+		RequesterAccountID: requester.ID,
+		Payer:              payer,
+		Amount:             100,
+		Currency:           requester.Currency,
+		Note:               "lunch",
+		ExpiresAt:          expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestPending, request.Status)
+	require.False(t, request.TransferID.Valid)
+	return request
+}
+
+func TestPayPaymentRequestTx(t *testing.T) {
+	store := NewStore(testDB)
+	requester := createAccountInCurrency(t, utils.USD, 0)
+	payer := createAccountInCurrency(t, utils.USD, 1000)
+	request := createTestPaymentRequest(t, requester, payer.Owner, time.Now().Add(time.Hour))
+
+	result, err := store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		ID:            request.ID,
+		Payer:         payer.Owner,
+		FromAccountID: payer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestPaid, result.PaymentRequest.Status)
+	require.Equal(t, result.Transfer.Transfer.ID, result.PaymentRequest.TransferID.Int64)
+	require.Equal(t, "lunch", result.Transfer.Transfer.Memo)
+	require.Equal(t, int64(900), result.Transfer.FromAccount.Balance)
+	require.Equal(t, int64(100), result.Transfer.ToAccount.Balance)
+
+	_, err = store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		ID:            request.ID,
+		Payer:         payer.Owner,
+		FromAccountID: payer.ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestClosed)
+}
+
+func TestPayPaymentRequestTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	requester := createAccountInCurrency(t, utils.USD, 0)
+	payer := createAccountInCurrency(t, utils.USD, 1000)
+	request := createTestPaymentRequest(t, requester, payer.Owner, time.Now().Add(time.Hour))
+
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+				ID:            request.ID,
+				Payer:         payer.Owner,
+				FromAccountID: payer.ID,
+			})
+			errs <- err
+		}()
+	}
+
+	paid := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			paid++
+			continue
+		}
+		require.ErrorIs(t, err, ErrPaymentRequestClosed)
+	}
+	require.Equal(t, 1, paid)
+
+	account, err := testQueries.GetAccounts(context.Background(), payer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(900), account.Balance)
+}
+
+func TestPayPaymentRequestTxRefused(t *testing.T) {
+	store := NewStore(testDB)
+	requester := createAccountInCurrency(t, utils.USD, 0)
+	payer := createAccountInCurrency(t, utils.USD, 1000)
+	stranger := createAccountInCurrency(t, utils.USD, 1000)
+	eurAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:         payer.Owner,
+		Currency:      utils.EUR,
+		Balance:       1000,
+		AccountNumber: utils.RandomAccountNumber(),
+	})
+	require.NoError(t, err)
+
+	request := createTestPaymentRequest(t, requester, payer.Owner, time.Now().Add(time.Hour))
+	_, err = store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		ID:            request.ID,
+		Payer:         stranger.Owner,
+		FromAccountID: stranger.ID,
+	})
+	require.ErrorIs(t, err, ErrNotPaymentRequestPayer)
+
+	_, err = store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		ID:            request.ID,
+		Payer:         payer.Owner,
+		FromAccountID: stranger.ID,
+	})
+	require.ErrorIs(t, err, ErrNotPaymentRequestPayer)
+
+	_, err = store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		ID:            request.ID,
+		Payer:         payer.Owner,
+		FromAccountID: eurAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	expired := createTestPaymentRequest(t, requester, payer.Owner, time.Now().Add(-time.Second))
+	_, err = store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		ID:            expired.ID,
+		Payer:         payer.Owner,
+		FromAccountID: payer.ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestExpired)
+}
+
+func TestDeclinePaymentRequest(t *testing.T) {
+	requester := createAccountInCurrency(t, utils.USD, 0)
+	payer := createRandomUser(t)
+	request := createTestPaymentRequest(t, requester, payer.Username, time.Now().Add(time.Hour))
+
+	declined, err := testQueries.DecidePaymentRequest(context.Background(), DecidePaymentRequestParams{
+		ID:     request.ID,
+		Status: PaymentRequestDeclined,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestDeclined, declined.Status)
+	require.True(t, declined.DecidedAt.Valid)
+
+	_, err = testQueries.DecidePaymentRequest(context.Background(), DecidePaymentRequestParams{
+		ID:     request.ID,
+		Status: PaymentRequestDeclined,
+	})
+	require.Error(t, err)
+
+	incoming, err := testQueries.ListIncomingPaymentRequests(context.Background(), ListIncomingPaymentRequestsParams{
+		Payer: payer.Username,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+
+	outgoing, err := testQueries.ListOutgoingPaymentRequests(context.Background(), ListOutgoingPaymentRequestsParams{
+		Owner: requester.Owner,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, outgoing, 1)
+	require.Equal(t, request.ID, outgoing[0].ID)
+}
@@ -0,0 +1,312 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// Statuses of a pending transfer.
+const (
+	PendingTransferPending  = "pending"
+	PendingTransferApproved = "approved"
+	PendingTransferRejected = "rejected"
+	PendingTransferExpired  = "expired"
+)
+
+// Kinds of pending transfer, which say what approving one runs.
+const (
+	PendingTransferKindTransfer         = "transfer"
+	PendingTransferKindFxTransfer       = "fx_transfer"
+	PendingTransferKindPaymentRequest   = "payment_request"
+	PendingTransferKindOutboundTransfer = "outbound_transfer"
+	PendingTransferKindTransferBatch    = "transfer_batch"
+)
+
+// Decisions a checker can record on a pending transfer.
+const (
+	DecisionApproved = "approved"
+	DecisionRejected = "rejected"
+)
+
+var (
+	ErrSelfApproval           = errors.New("a pending transfer can't be decided by the user who created it")
+	ErrPendingTransferDecided = errors.New("pending transfer has already been decided")
+	ErrPendingTransferExpired = errors.New("pending transfer has expired")
+)
+
+// HoldParams holds a transfer until another user approves it instead of
+// running it.
+type HoldParams struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	// RiskDecisionID is set when the risk engine asked for the review.
+	RiskDecisionID sql.NullInt64 `json:"risk_decision_id"`
+}
+
+type CreatePendingTransferTxParams struct {
+	CreatePendingTransferParams
+	// IdempotencyKey, when set, stores the pending transfer under the key in the same transaction.
+	IdempotencyKey *IdempotencyKeyParams `json:"-"`
+}
+
+// CreatePendingTransferTx records a transfer that has to be approved before
+// it runs.
+func (store *SQLStore) CreatePendingTransferTx(ctx context.Context, arg CreatePendingTransferTxParams) (PendingTransfer, error) {
+	var pending PendingTransfer
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		var err error
+		pending, err = holdTransfer(ctx, q, arg.CreatePendingTransferParams)
+		if err != nil {
+			return err
+		}
+		if arg.IdempotencyKey != nil {
+			return saveIdempotencyKey(ctx, q, arg.IdempotencyKey, pending)
+		}
+		return nil
+	})
+
+	return pending, err
+}
+
+// holdTransfer records a pending transfer, defaulting its kind and category.
+func holdTransfer(ctx context.Context, q *Queries, arg CreatePendingTransferParams) (PendingTransfer, error) {
+	if arg.Kind == "" {
+		arg.Kind = PendingTransferKindTransfer
+	}
+	if arg.Category == "" {
+		arg.Category = utils.CategoryGeneral
+	}
+	pending, err := q.CreatePendingTransfer(ctx, arg)
+	if arg.PaymentRequestID.Valid && errors.Is(TranslateError(err), ErrUniqueViolation) {
+		return pending, ErrPaymentRequestHeld
+	}
+	return pending, err
+}
+
+type DecidePendingTransferTxParams struct {
+	ID int64 `json:"id"`
+	// Username is the checker; it must not be the maker of the request.
+	Username string `json:"username"`
+	Decision string `json:"decision"`
+	Comment  string `json:"comment"`
+}
+
+type DecidePendingTransferTxResult struct {
+	PendingTransfer PendingTransfer         `json:"pending_transfer"`
+	Approval        PendingTransferApproval `json:"approval"`
+	// Transfer is only set when the request was approved, and isn't for a
+	// transfer batch, whose items each have their own.
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+	// FxQuote is the quote an approved cross-currency transfer converted at.
+	FxQuote         *FxQuote             `json:"fx_quote,omitempty"`
+	PaymentRequest  *PaymentRequest      `json:"payment_request,omitempty"`
+	OutboundPayment *OutboundPayment     `json:"outbound_payment,omitempty"`
+	Batch           *TransferBatchResult `json:"batch,omitempty"`
+}
+
+// DecidePendingTransferTx approves or rejects a pending transfer and records
+// the decision in its approval trail. An approval runs what the pending
+// transfer holds in the same transaction, so the request is only marked
+// approved if the money actually moved. A transfer batch is the exception:
+// its items run in transactions of their own, right after the approval is
+// committed. The pending transfer is locked first so two checkers can't both
+// decide it.
+func (store *SQLStore) DecidePendingTransferTx(ctx context.Context, arg DecidePendingTransferTxParams) (DecidePendingTransferTxResult, error) {
+	var result DecidePendingTransferTxResult
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		pending, err := q.GetPendingTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if pending.CreatedBy == arg.Username {
+			return ErrSelfApproval
+		}
+		if pending.Status != PendingTransferPending {
+			return ErrPendingTransferDecided
+		}
+		if !time.Now().Before(pending.ExpiresAt) {
+			return ErrPendingTransferExpired
+		}
+
+		decide := DecidePendingTransferParams{ID: pending.ID, Status: PendingTransferRejected}
+		if arg.Decision == DecisionApproved {
+			decide.Status = PendingTransferApproved
+			decide.TransferID, err = runPendingTransfer(ctx, q, pending, &result)
+		} else {
+			err = dropPendingTransfer(ctx, q, pending, PendingTransferRejected)
+		}
+		if err != nil {
+			return err
+		}
+
+		result.PendingTransfer, err = q.DecidePendingTransfer(ctx, decide)
+		if err != nil {
+			return err
+		}
+		result.Approval, err = q.CreatePendingTransferApproval(ctx, CreatePendingTransferApprovalParams{
+			PendingTransferID: pending.ID,
+			Username:          arg.Username,
+			Decision:          arg.Decision,
+			Comment:           arg.Comment,
+		})
+		return err
+	})
+	if err != nil || !result.PendingTransfer.TransferBatchID.Valid || result.PendingTransfer.Status != PendingTransferApproved {
+		return result, err
+	}
+
+	batch, err := store.ExecuteTransferBatch(ctx, result.PendingTransfer.TransferBatchID.Int64)
+	result.Batch = &batch
+	return result, err
+}
+
+// runPendingTransfer runs what an approved pending transfer holds and returns
+// the id of the transfer that moved the money. A transfer batch only becomes
+// ready to run and has no transfer of its own.
+func runPendingTransfer(ctx context.Context, q *Queries, pending PendingTransfer, result *DecidePendingTransferTxResult) (sql.NullInt64, error) {
+	details := TransferDetails{
+		Memo:      pending.Memo,
+		Reference: pending.Reference.String,
+		Category:  pending.Category,
+	}
+	switch pending.Kind {
+	case PendingTransferKindFxTransfer:
+		quote, err := requoteFx(ctx, q, pending)
+		if err != nil {
+			return sql.NullInt64{}, err
+		}
+		transfer, err := fxTransferTx(ctx, q, FxTransferTxParams{
+			FromAccountID:   pending.FromAccountID,
+			ToAccountID:     pending.ToAccountID.Int64,
+			Amount:          pending.Amount,
+			FxQuoteID:       quote.ID,
+			TransferDetails: details,
+			Username:        pending.CreatedBy,
+		})
+		if err != nil {
+			return sql.NullInt64{}, err
+		}
+		result.Transfer = &transfer.TransferTxResult
+		result.FxQuote = &transfer.Quote
+
+	case PendingTransferKindPaymentRequest:
+		request, err := q.GetPaymentRequestForUpdate(ctx, pending.PaymentRequestID.Int64)
+		if err != nil {
+			return sql.NullInt64{}, err
+		}
+		// the payer asked in time, so the request may have expired since
+		if request.Status != PaymentRequestPending {
+			return sql.NullInt64{}, ErrPaymentRequestClosed
+		}
+		paid, err := payPaymentRequest(ctx, q, request, pending.FromAccountID)
+		if err != nil {
+			return sql.NullInt64{}, err
+		}
+		result.Transfer = &paid.Transfer
+		result.PaymentRequest = &paid.PaymentRequest
+
+	case PendingTransferKindOutboundTransfer:
+		outbound, err := createOutboundTransfer(ctx, q, CreateOutboundTransferTxParams{
+			Username:        pending.CreatedBy,
+			FromAccountID:   pending.FromAccountID,
+			BeneficiaryID:   pending.BeneficiaryID.Int64,
+			Amount:          pending.Amount,
+			TransferDetails: details,
+		})
+		if err != nil {
+			return sql.NullInt64{}, err
+		}
+		result.Transfer = &outbound.TransferTxResult
+		result.OutboundPayment = &outbound.Payment
+
+	case PendingTransferKindTransferBatch:
+		_, err := q.DecideHeldTransferBatch(ctx, DecideHeldTransferBatchParams{
+			ID:     pending.TransferBatchID.Int64,
+			Status: TransferBatchPending,
+		})
+		return sql.NullInt64{}, err
+
+	default:
+		transfer, err := transferTx(ctx, q, TransferTxParams{
+			FromAccountID:   pending.FromAccountID,
+			ToAccountID:     pending.ToAccountID.Int64,
+			Amount:          pending.Amount,
+			TransferDetails: details,
+		})
+		if err != nil {
+			return sql.NullInt64{}, err
+		}
+		result.Transfer = &transfer
+	}
+
+	transferID := sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true}
+	if pending.ScheduledTransferID.Valid {
+		err := closeHeldScheduledTransfer(ctx, q, pending.ScheduledTransferID.Int64, transferID, "")
+		if err != nil {
+			return transferID, err
+		}
+	}
+	return transferID, nil
+}
+
+// requoteFx quotes the rate of the time of approval for a held
+// cross-currency transfer. The quote the maker was shown expired long ago.
+func requoteFx(ctx context.Context, q *Queries, pending PendingTransfer) (FxQuote, error) {
+	shown, err := q.GetFxQuote(ctx, pending.FxQuoteID.Int64)
+	if err != nil {
+		return FxQuote{}, err
+	}
+	rate, err := LatestFxRate(ctx, q, shown.FromCurrency, shown.ToCurrency)
+	if err != nil {
+		return FxQuote{}, err
+	}
+	return q.CreateFxQuote(ctx, CreateFxQuoteParams{
+		Username:     pending.CreatedBy,
+		FxRateID:     rate.ID,
+		FromCurrency: shown.FromCurrency,
+		ToCurrency:   shown.ToCurrency,
+		Rate:         rate.Rate,
+		ExpiresAt:    time.Now().Add(time.Minute),
+	})
+}
+
+// dropPendingTransfer closes what a rejected or expired pending transfer
+// held, so it doesn't wait for an approval that won't come.
+func dropPendingTransfer(ctx context.Context, q *Queries, pending PendingTransfer, outcome string) error {
+	if pending.TransferBatchID.Valid {
+		_, err := q.DecideHeldTransferBatch(ctx, DecideHeldTransferBatchParams{
+			ID:     pending.TransferBatchID.Int64,
+			Status: TransferBatchRejected,
+		})
+		return err
+	}
+	if pending.ScheduledTransferID.Valid {
+		reason := fmt.Sprintf("pending transfer %d was %s", pending.ID, outcome)
+		return closeHeldScheduledTransfer(ctx, q, pending.ScheduledTransferID.Int64, sql.NullInt64{}, reason)
+	}
+	return nil
+}
+
+// ExpirePendingTransfersTx marks the pending transfers nobody decided on
+// before their expiry as expired and closes what they held.
+func (store *SQLStore) ExpirePendingTransfersTx(ctx context.Context) ([]PendingTransfer, error) {
+	var expired []PendingTransfer
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		var err error
+		expired, err = q.ExpirePendingTransfers(ctx)
+		if err != nil {
+			return err
+		}
+		for _, pending := range expired {
+			if err := dropPendingTransfer(ctx, q, pending, PendingTransferExpired); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return expired, err
+}
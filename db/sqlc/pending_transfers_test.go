@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func createRandomPendingTransfer(t *testing.T, from Account, to Account, expiresAt time.Time) PendingTransfer {
+	pending, err := NewStore(testDB).CreatePendingTransferTx(context.Background(), CreatePendingTransferTxParams{
+		CreatePendingTransferParams: CreatePendingTransferParams{
+			CreatedBy:     from.Owner,
+			FromAccountID: from.ID,
+			ToAccountID:   sql.NullInt64{Int64: to.ID, Valid: true},
+			Amount:        100,
+			Currency:      from.Currency,
+			ExpiresAt:     expiresAt,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferPending, pending.Status)
+	require.False(t, pending.TransferID.Valid)
+	return pending
+}
+
+func TestDecidePendingTransferTxApprove(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	checker := createRandomUser(t)
+	pending := createRandomPendingTransfer(t, from, to, time.Now().Add(time.Hour))
+
+	result, err := store.DecidePendingTransferTx(context.Background(), DecidePendingTransferTxParams{
+		ID:       pending.ID,
+		Username: checker.Username,
+		Decision: DecisionApproved,
+		Comment:  "ok",
+	})
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferApproved, result.PendingTransfer.Status)
+	require.True(t, result.PendingTransfer.DecidedAt.Valid)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, result.Transfer.Transfer.ID, result.PendingTransfer.TransferID.Int64)
+	require.Equal(t, int64(900), result.Transfer.FromAccount.Balance)
+	require.Equal(t, int64(100), result.Transfer.ToAccount.Balance)
+
+	approvals, err := store.ListPendingTransferApprovals(context.Background(), pending.ID)
+	require.NoError(t, err)
+	require.Len(t, approvals, 1)
+	require.Equal(t, checker.Username, approvals[0].Username)
+	require.Equal(t, "ok", approvals[0].Comment)
+
+	_, err = store.DecidePendingTransferTx(context.Background(), DecidePendingTransferTxParams{
+		ID:       pending.ID,
+		Username: checker.Username,
+		Decision: DecisionRejected,
+	})
+	require.ErrorIs(t, err, ErrPendingTransferDecided)
+}
+
+func TestDecidePendingTransferTxReject(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	checker := createRandomUser(t)
+	pending := createRandomPendingTransfer(t, from, to, time.Now().Add(time.Hour))
+
+	result, err := store.DecidePendingTransferTx(context.Background(), DecidePendingTransferTxParams{
+		ID:       pending.ID,
+		Username: checker.Username,
+		Decision: DecisionRejected,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferRejected, result.PendingTransfer.Status)
+	require.Nil(t, result.Transfer)
+	require.False(t, result.PendingTransfer.TransferID.Valid)
+
+	account, err := store.GetAccounts(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, account.Balance)
+}
+
+func TestDecidePendingTransferTxSelfApproval(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	pending := createRandomPendingTransfer(t, from, to, time.Now().Add(time.Hour))
+
+	_, err := store.DecidePendingTransferTx(context.Background(), DecidePendingTransferTxParams{
+		ID:       pending.ID,
+		Username: from.Owner,
+		Decision: DecisionApproved,
+	})
+	require.ErrorIs(t, err, ErrSelfApproval)
+
+	approvals, err := store.ListPendingTransferApprovals(context.Background(), pending.ID)
+	require.NoError(t, err)
+	require.Empty(t, approvals)
+}
+
+func TestExpirePendingTransfers(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	checker := createRandomUser(t)
+	pending := createRandomPendingTransfer(t, from, to, time.Now().Add(-time.Minute))
+
+	_, err := store.DecidePendingTransferTx(context.Background(), DecidePendingTransferTxParams{
+		ID:       pending.ID,
+		Username: checker.Username,
+		Decision: DecisionApproved,
+	})
+	require.ErrorIs(t, err, ErrPendingTransferExpired)
+
+	expired, err := store.ExpirePendingTransfersTx(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, expired)
+
+	pending, err = store.GetPendingTransfer(context.Background(), pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferExpired, pending.Status)
+
+	_, err = store.DecidePendingTransferTx(context.Background(), DecidePendingTransferTxParams{ID: 0})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDecidePendingTransferTxApprovePaymentRequest(t *testing.T) {
+	store := NewStore(testDB)
+	requester := createAccountInCurrency(t, utils.USD, 0)
+	payer := createAccountInCurrency(t, utils.USD, 1000)
+	checker := createRandomUser(t)
+	request := createTestPaymentRequest(t, requester, payer.Owner, time.Now().Add(time.Hour))
+
+	arg := CreatePendingTransferTxParams{
+		CreatePendingTransferParams: CreatePendingTransferParams{
+			CreatedBy:        payer.Owner,
+			FromAccountID:    payer.ID,
+			ToAccountID:      sql.NullInt64{Int64: requester.ID, Valid: true},
+			Amount:           request.Amount,
+			Currency:         request.Currency,
+			ExpiresAt:        time.Now().Add(time.Hour),
+			Kind:             PendingTransferKindPaymentRequest,
+			PaymentRequestID: sql.NullInt64{Int64: request.ID, Valid: true},
+		},
+	}
+	pending, err := store.CreatePendingTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the payment can only wait for one approval at a time
+	_, err = store.CreatePendingTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrPaymentRequestHeld)
+
+	result, err := store.DecidePendingTransferTx(context.Background(), DecidePendingTransferTxParams{
+		ID:       pending.ID,
+		Username: checker.Username,
+		Decision: DecisionApproved,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.PaymentRequest)
+	require.Equal(t, PaymentRequestPaid, result.PaymentRequest.Status)
+	require.Equal(t, result.PendingTransfer.TransferID, result.PaymentRequest.TransferID)
+	require.Equal(t, int64(900), result.Transfer.FromAccount.Balance)
+}
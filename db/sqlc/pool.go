@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"expvar"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// NewPool opens a connection pool to the database of config. Pool settings
+// left at zero keep pgxpool's defaults, or whatever the pool_ parameters of
+// DB_SOURCE say. The pool connects lazily and its stats are published with
+// expvar under "db_pool".
+func NewPool(ctx context.Context, config utils.Config) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(config.DBSource)
+	if err != nil {
+		return nil, err
+	}
+	if config.DBMaxConns > 0 {
+		poolConfig.MaxConns = config.DBMaxConns
+	}
+	if config.DBMinConns > 0 {
+		poolConfig.MinConns = config.DBMinConns
+	}
+	if config.DBMaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = config.DBMaxConnLifetime
+	}
+	if config.DBMaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = config.DBMaxConnIdleTime
+	}
+	if config.DBHealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = config.DBHealthCheckPeriod
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+	metricsPool.Store(pool)
+	return pool, nil
+}
+
+// PoolStats is a snapshot of the connections of a pool.
+type PoolStats struct {
+	MaxConns      int32 `json:"max_conns"`
+	TotalConns    int32 `json:"total_conns"`
+	AcquiredConns int32 `json:"acquired_conns"`
+	IdleConns     int32 `json:"idle_conns"`
+	// ConstructingConns are being opened.
+	ConstructingConns int32 `json:"constructing_conns"`
+	AcquireCount      int64 `json:"acquire_count"`
+	// EmptyAcquireCount counts the acquires that had to wait for a
+	// connection, and AcquireDurationMs how long all acquires took.
+	EmptyAcquireCount       int64 `json:"empty_acquire_count"`
+	CanceledAcquireCount    int64 `json:"canceled_acquire_count"`
+	AcquireDurationMs       int64 `json:"acquire_duration_ms"`
+	NewConnsCount           int64 `json:"new_conns_count"`
+	MaxLifetimeDestroyCount int64 `json:"max_lifetime_destroy_count"`
+	MaxIdleDestroyCount     int64 `json:"max_idle_destroy_count"`
+}
+
+// NewPoolStats reads the stats of pool.
+func NewPoolStats(pool *pgxpool.Pool) PoolStats {
+	stat := pool.Stat()
+	return PoolStats{
+		MaxConns:                stat.MaxConns(),
+		TotalConns:              stat.TotalConns(),
+		AcquiredConns:           stat.AcquiredConns(),
+		IdleConns:               stat.IdleConns(),
+		ConstructingConns:       stat.ConstructingConns(),
+		AcquireCount:            stat.AcquireCount(),
+		EmptyAcquireCount:       stat.EmptyAcquireCount(),
+		CanceledAcquireCount:    stat.CanceledAcquireCount(),
+		AcquireDurationMs:       stat.AcquireDuration().Milliseconds(),
+		NewConnsCount:           stat.NewConnsCount(),
+		MaxLifetimeDestroyCount: stat.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     stat.MaxIdleDestroyCount(),
+	}
+}
+
+// metricsPool is the pool "db_pool" shows, the one NewPool opened last.
+var metricsPool atomic.Pointer[pgxpool.Pool]
+
+func init() {
+	expvar.Publish("db_pool", expvar.Func(func() any {
+		pool := metricsPool.Load()
+		if pool == nil {
+			return nil
+		}
+		return NewPoolStats(pool)
+	}))
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestNewPool(t *testing.T) {
+	pool, err := NewPool(context.Background(), utils.Config{
+		DBSource:            dbSource,
+		DBMaxConns:          7,
+		DBMinConns:          0,
+		DBMaxConnLifetime:   10 * time.Minute,
+		DBMaxConnIdleTime:   time.Minute,
+		DBHealthCheckPeriod: 15 * time.Second,
+	})
+	require.NoError(t, err)
+	defer pool.Close()
+
+	config := pool.Config()
+	require.Equal(t, int32(7), config.MaxConns)
+	require.Equal(t, 10*time.Minute, config.MaxConnLifetime)
+	require.Equal(t, time.Minute, config.MaxConnIdleTime)
+	require.Equal(t, 15*time.Second, config.HealthCheckPeriod)
+
+	var stats PoolStats
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("db_pool").String()), &stats))
+	require.Equal(t, int32(7), stats.MaxConns)
+	require.Zero(t, stats.AcquiredConns)
+
+	// settings left at zero keep the ones of the connection string
+	pool, err = NewPool(context.Background(), utils.Config{DBSource: dbSource + "&pool_max_conns=3"})
+	require.NoError(t, err)
+	defer pool.Close()
+	require.Equal(t, int32(3), pool.Config().MaxConns)
+
+	_, err = NewPool(context.Background(), utils.Config{DBSource: "postgresql://root@localhost:5432/db?pool_max_conns=many"})
+	require.Error(t, err)
+}
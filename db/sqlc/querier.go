@@ -6,24 +6,156 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	CancelScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	CancelStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	ClaimDueScheduledTransfer(ctx context.Context) (ScheduledTransfer, error)
+	ClaimDueStandingOrder(ctx context.Context, today time.Time) (StandingOrder, error)
+	ClaimQueuedTransfer(ctx context.Context) (Transfer, error)
+	ClearSanctionsCase(ctx context.Context, arg ClearSanctionsCaseParams) (SanctionsCase, error)
+	CompleteTransferBatchItem(ctx context.Context, arg CompleteTransferBatchItemParams) (TransferBatchItem, error)
+	CountACHFilesSince(ctx context.Context, since time.Time) (int64, error)
+	CountAccounts(ctx context.Context) (int64, error)
+	CountClearedSanctionsMatches(ctx context.Context, arg CountClearedSanctionsMatchesParams) (int64, error)
+	CountOpenSanctionsCasesForTransfer(ctx context.Context, arg CountOpenSanctionsCasesForTransferParams) (int64, error)
+	CountTransfersSince(ctx context.Context, arg CountTransfersSinceParams) (int64, error)
+	CountTransfersTo(ctx context.Context, arg CountTransfersToParams) (int64, error)
+	CreateACHFile(ctx context.Context, arg CreateACHFileParams) (AchFile, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateBeneficiary(ctx context.Context, arg CreateBeneficiaryParams) (Beneficiary, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFeeEntry(ctx context.Context, arg CreateFeeEntryParams) (Entry, error)
+	CreateFeeRule(ctx context.Context, arg CreateFeeRuleParams) (FeeRule, error)
+	CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error)
+	CreateFxRate(ctx context.Context, arg CreateFxRateParams) (FxRate, error)
+	CreateHoliday(ctx context.Context, arg CreateHolidayParams) (Holiday, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateJournalEntry(ctx context.Context, arg CreateJournalEntryParams) (JournalEntry, error)
+	CreateOutboundPayment(ctx context.Context, arg CreateOutboundPaymentParams) (OutboundPayment, error)
+	CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (PendingTransfer, error)
+	CreatePendingTransferApproval(ctx context.Context, arg CreatePendingTransferApprovalParams) (PendingTransferApproval, error)
+	CreatePostings(ctx context.Context, arg CreatePostingsParams) ([]Posting, error)
+	CreateReconciliationDiscrepancy(ctx context.Context, arg CreateReconciliationDiscrepancyParams) (ReconciliationDiscrepancy, error)
+	CreateReconciliationRun(ctx context.Context, arg CreateReconciliationRunParams) (ReconciliationRun, error)
+	CreateReversalTransfer(ctx context.Context, arg CreateReversalTransferParams) (Transfer, error)
+	CreateRiskDecision(ctx context.Context, arg CreateRiskDecisionParams) (RiskDecision, error)
+	CreateRiskRule(ctx context.Context, arg CreateRiskRuleParams) (RiskRule, error)
+	CreateSanctionsCase(ctx context.Context, arg CreateSanctionsCaseParams) (SanctionsCase, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateStandingOrder(ctx context.Context, arg CreateStandingOrderParams) (StandingOrder, error)
+	CreateStandingOrderOccurrence(ctx context.Context, arg CreateStandingOrderOccurrenceParams) (ScheduledTransfer, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error)
+	CreateTransferBatchItem(ctx context.Context, arg CreateTransferBatchItemParams) (TransferBatchItem, error)
+	CreateTransferConfirmation(ctx context.Context, arg CreateTransferConfirmationParams) (TransferConfirmation, error)
+	CreateTransferGroup(ctx context.Context, arg CreateTransferGroupParams) (TransferGroup, error)
+	CreateTransferGroupEntry(ctx context.Context, arg CreateTransferGroupEntryParams) (Entry, error)
+	CreateTransferLimit(ctx context.Context, arg CreateTransferLimitParams) (TransferLimit, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DecideHeldScheduledTransfer(ctx context.Context, arg DecideHeldScheduledTransferParams) (ScheduledTransfer, error)
+	DecideHeldTransferBatch(ctx context.Context, arg DecideHeldTransferBatchParams) (TransferBatch, error)
+	DecidePaymentRequest(ctx context.Context, arg DecidePaymentRequestParams) (PaymentRequest, error)
+	DecidePendingTransfer(ctx context.Context, arg DecidePendingTransferParams) (PendingTransfer, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteFeeRule(ctx context.Context, id int64) error
+	DeleteRiskRule(ctx context.Context, id int64) error
+	ExpirePendingTransfers(ctx context.Context) ([]PendingTransfer, error)
+	FailTransferBatchItem(ctx context.Context, arg FailTransferBatchItemParams) (TransferBatchItem, error)
+	FinishTransferBatch(ctx context.Context, arg FinishTransferBatchParams) (TransferBatch, error)
+	GetAccountByNumber(ctx context.Context, accountNumber string) (Account, error)
+	GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
 	GetAccounts(ctx context.Context, id int64) (Account, error)
+	GetBeneficiary(ctx context.Context, id int64) (Beneficiary, error)
+	GetChainEntry(ctx context.Context, arg GetChainEntryParams) (Entry, error)
+	GetDailyDebitUsage(ctx context.Context, arg GetDailyDebitUsageParams) (GetDailyDebitUsageRow, error)
+	GetEntriesTotalSince(ctx context.Context, arg GetEntriesTotalSinceParams) (int64, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFeeRule(ctx context.Context, arg GetFeeRuleParams) (FeeRule, error)
+	GetFxQuote(ctx context.Context, id int64) (FxQuote, error)
+	GetFxQuoteForUpdate(ctx context.Context, id int64) (FxQuote, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetJournalEntry(ctx context.Context, id int64) (JournalEntry, error)
+	GetJournalEntryByTransfer(ctx context.Context, transferID sql.NullInt64) (JournalEntry, error)
+	GetLatestFxRate(ctx context.Context, arg GetLatestFxRateParams) (FxRate, error)
+	GetLatestReconciliationRun(ctx context.Context) (ReconciliationRun, error)
+	GetOutboundPayment(ctx context.Context, id int64) (OutboundPayment, error)
+	GetOutboundPaymentByTraceForUpdate(ctx context.Context, arg GetOutboundPaymentByTraceForUpdateParams) (OutboundPayment, error)
+	GetOutboundPaymentByTransfer(ctx context.Context, transferID int64) (OutboundPayment, error)
+	GetPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPendingTransfer(ctx context.Context, id int64) (PendingTransfer, error)
+	GetPendingTransferForUpdate(ctx context.Context, id int64) (PendingTransfer, error)
+	GetReconciliationRun(ctx context.Context, id int64) (ReconciliationRun, error)
+	GetReversedAmount(ctx context.Context, transferID int64) (int64, error)
+	GetSanctionsCase(ctx context.Context, id int64) (SanctionsCase, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	GetStandingOrderForUpdate(ctx context.Context, id int64) (StandingOrder, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferAverage(ctx context.Context, fromAccountID int64) (GetTransferAverageRow, error)
+	GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	GetTransferConfirmation(ctx context.Context, id int64) (TransferConfirmation, error)
+	GetTransferConfirmationForUpdate(ctx context.Context, id int64) (TransferConfirmation, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferGroup(ctx context.Context, id int64) (TransferGroup, error)
+	GetTransferLimit(ctx context.Context, arg GetTransferLimitParams) (TransferLimit, error)
+	GetUserByPhone(ctx context.Context, phone sql.NullString) (User, error)
+	GetUserByVerifiedEmail(ctx context.Context, email string) (User, error)
 	GetUsers(ctx context.Context, username string) (User, error)
+	ListAccountDrift(ctx context.Context) ([]ListAccountDriftRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListBeneficiaries(ctx context.Context, arg ListBeneficiariesParams) ([]Beneficiary, error)
+	ListChainEntries(ctx context.Context, arg ListChainEntriesParams) ([]Entry, error)
+	ListChainHeads(ctx context.Context) ([]ListChainHeadsRow, error)
+	ListCurrencyTotals(ctx context.Context) ([]ListCurrencyTotalsRow, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesBetween(ctx context.Context, arg ListEntriesBetweenParams) ([]Entry, error)
+	ListHolidays(ctx context.Context, arg ListHolidaysParams) ([]Holiday, error)
+	ListIncomingPaymentRequests(ctx context.Context, arg ListIncomingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListOutgoingPaymentRequests(ctx context.Context, arg ListOutgoingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListPendingOutboundPaymentsForUpdate(ctx context.Context, limit int32) ([]OutboundPayment, error)
+	ListPendingTransferApprovals(ctx context.Context, pendingTransferID int64) ([]PendingTransferApproval, error)
+	ListPendingTransfers(ctx context.Context, arg ListPendingTransfersParams) ([]PendingTransfer, error)
+	ListPendingTransfersByCreator(ctx context.Context, arg ListPendingTransfersByCreatorParams) ([]PendingTransfer, error)
+	ListPostings(ctx context.Context, journalEntryID int64) ([]Posting, error)
+	ListReconciliationDiscrepancies(ctx context.Context, runID int64) ([]ReconciliationDiscrepancy, error)
+	ListRiskDecisions(ctx context.Context, arg ListRiskDecisionsParams) ([]RiskDecision, error)
+	ListRiskRules(ctx context.Context, currency string) ([]RiskRule, error)
+	ListSanctionsCases(ctx context.Context, arg ListSanctionsCasesParams) ([]SanctionsCase, error)
+	ListScheduledTransferRuns(ctx context.Context, scheduledTransferID int64) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListStandingOrderOccurrences(ctx context.Context, arg ListStandingOrderOccurrencesParams) ([]ScheduledTransfer, error)
+	ListStandingOrders(ctx context.Context, arg ListStandingOrdersParams) ([]StandingOrder, error)
+	ListTransferBatchItems(ctx context.Context, batchID int64) ([]TransferBatchItem, error)
+	ListTransferGroupEntries(ctx context.Context, transferGroupID sql.NullInt64) ([]Entry, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListTransfersByIDs(ctx context.Context, ids []int64) ([]Transfer, error)
+	MarkOutboundPaymentExported(ctx context.Context, arg MarkOutboundPaymentExportedParams) (OutboundPayment, error)
+	MarkOutboundPaymentReturned(ctx context.Context, arg MarkOutboundPaymentReturnedParams) (OutboundPayment, error)
+	NextAccountNumberSerial(ctx context.Context) (int64, error)
+	PauseStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error)
+	SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error)
+	SetFxQuoteTransfer(ctx context.Context, arg SetFxQuoteTransferParams) (FxQuote, error)
+	SetTransferConfirmationPendingTransfer(ctx context.Context, arg SetTransferConfirmationPendingTransferParams) (TransferConfirmation, error)
+	SetTransferConfirmationTransfer(ctx context.Context, arg SetTransferConfirmationTransferParams) (TransferConfirmation, error)
+	SetTransferFee(ctx context.Context, arg SetTransferFeeParams) (Transfer, error)
+	StartTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	SumACHFileEntriesSince(ctx context.Context, since time.Time) (int64, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateScheduledTransferRun(ctx context.Context, arg UpdateScheduledTransferRunParams) (ScheduledTransfer, error)
+	UpdateStandingOrder(ctx context.Context, arg UpdateStandingOrderParams) (StandingOrder, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
+	VerifyUserEmail(ctx context.Context, username string) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// LedgerAdjustmentOwner owns the accounts, one per currency, that take the
+// other side of the adjustments a reconciliation repair posts.
+const LedgerAdjustmentOwner = "bankadjustment"
+
+type ReconcileParams struct {
+	// Repair brings the entries of every account that drifted in line with
+	// its balance, with an adjustment taken from the ledger adjustment
+	// account of its currency. The balances themselves are left alone.
+	Repair bool `json:"repair"`
+}
+
+type ReconciliationResult struct {
+	Run ReconciliationRun `json:"run"`
+	// Discrepancies lists the accounts that drifted, then the currencies
+	// whose grand totals differ, as they were before any repair.
+	Discrepancies []ReconciliationDiscrepancy `json:"discrepancies"`
+}
+
+// Reconcile compares the balance of every account with the sum of its
+// entries, and the balances of all the accounts in each currency with the
+// sum of their entries, and records the run with what differs. It reads a
+// single snapshot, so a transfer committing meanwhile is seen either with
+// both its entries and its balance changes or not at all.
+func (store *SQLStore) Reconcile(ctx context.Context, arg ReconcileParams) (ReconciliationResult, error) {
+	var result ReconciliationResult
+	err := store.executeTx(ctx, TxOptions{Isolation: pgx.RepeatableRead}, func(q *Queries) error {
+		// start over when the transaction is retried
+		result = ReconciliationResult{Discrepancies: []ReconciliationDiscrepancy{}}
+		checked, err := q.CountAccounts(ctx)
+		if err != nil {
+			return err
+		}
+		drift, err := q.ListAccountDrift(ctx)
+		if err != nil {
+			return err
+		}
+		totals, err := q.ListCurrencyTotals(ctx)
+		if err != nil {
+			return err
+		}
+
+		result.Run, err = q.CreateReconciliationRun(ctx, CreateReconciliationRunParams{
+			Repair:          arg.Repair,
+			AccountsChecked: checked,
+			Discrepancies:   int64(len(drift)),
+		})
+		if err != nil {
+			return err
+		}
+		for _, account := range drift {
+			var journalEntryID sql.NullInt64
+			// a drifted adjustment account has nothing to be adjusted against
+			if arg.Repair && account.Owner != LedgerAdjustmentOwner {
+				journalEntry, err := postAdjustment(ctx, q, result.Run.ID, account)
+				if err != nil {
+					return err
+				}
+				journalEntryID = sql.NullInt64{Int64: journalEntry.ID, Valid: true}
+			}
+			discrepancy, err := q.CreateReconciliationDiscrepancy(ctx, CreateReconciliationDiscrepancyParams{
+				RunID:          result.Run.ID,
+				AccountID:      sql.NullInt64{Int64: account.ID, Valid: true},
+				Currency:       account.Currency,
+				Balance:        account.Balance,
+				EntriesTotal:   account.EntriesTotal,
+				Difference:     account.Balance - account.EntriesTotal,
+				JournalEntryID: journalEntryID,
+			})
+			if err != nil {
+				return err
+			}
+			result.Discrepancies = append(result.Discrepancies, discrepancy)
+		}
+		for _, total := range totals {
+			if total.BalanceTotal == total.EntriesTotal {
+				continue
+			}
+			discrepancy, err := q.CreateReconciliationDiscrepancy(ctx, CreateReconciliationDiscrepancyParams{
+				RunID:        result.Run.ID,
+				Currency:     total.Currency,
+				Balance:      total.BalanceTotal,
+				EntriesTotal: total.EntriesTotal,
+				Difference:   total.BalanceTotal - total.EntriesTotal,
+			})
+			if err != nil {
+				return err
+			}
+			result.Discrepancies = append(result.Discrepancies, discrepancy)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// String says in a line what the discrepancy is about.
+func (d ReconciliationDiscrepancy) String() string {
+	subject := "total " + d.Currency
+	if d.AccountID.Valid {
+		subject = fmt.Sprintf("account %d", d.AccountID.Int64)
+	}
+	line := fmt.Sprintf("%s: balance %d, entries %d, difference %d %s", subject, d.Balance, d.EntriesTotal, d.Difference, d.Currency)
+	if d.JournalEntryID.Valid {
+		line += fmt.Sprintf(", adjusted by journal entry %d", d.JournalEntryID.Int64)
+	}
+	return line
+}
+
+// postAdjustment posts the difference between the balance of an account and
+// the sum of its entries as an entry of the account, against the ledger
+// adjustment account of its currency. The adjustment account's balance moves
+// with its entry, so it stays reconciled itself.
+func postAdjustment(ctx context.Context, q *Queries, runID int64, drift ListAccountDriftRow) (JournalEntry, error) {
+	adjustmentAccount, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    LedgerAdjustmentOwner,
+		Currency: drift.Currency,
+	})
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("ledger adjustment account %s: %w", drift.Currency, err)
+	}
+
+	difference := drift.Balance - drift.EntriesTotal
+	entry, err := q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: sql.NullInt64{Int64: drift.ID, Valid: true},
+		Amount:    difference,
+	})
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	offset, err := q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: sql.NullInt64{Int64: adjustmentAccount.ID, Valid: true},
+		Amount:    -difference,
+	})
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	if _, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{ID: adjustmentAccount.ID, Ammount: -difference}); err != nil {
+		return JournalEntry{}, err
+	}
+	return postJournalEntry(ctx, q, CreateJournalEntryParams{
+		Description: fmt.Sprintf("reconciliation run %d: adjustment of account %d", runID, drift.ID),
+	}, entry, offset)
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// findDiscrepancy returns the discrepancy of the account in result.
+func findDiscrepancy(result ReconciliationResult, accountID int64) (ReconciliationDiscrepancy, bool) {
+	for _, discrepancy := range result.Discrepancies {
+		if discrepancy.AccountID.Int64 == accountID {
+			return discrepancy, true
+		}
+	}
+	return ReconciliationDiscrepancy{}, false
+}
+
+func TestReconcile(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 0)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	// accounts are opened with a balance but no entries, so reconcile them
+	// first
+	_, err := store.Reconcile(context.Background(), ReconcileParams{Repair: true})
+	require.NoError(t, err)
+
+	_, err = store.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: from.ID, Ammount: 100})
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10})
+	require.NoError(t, err)
+
+	result, err := store.Reconcile(context.Background(), ReconcileParams{})
+	require.NoError(t, err)
+	require.False(t, result.Run.Repair)
+	require.Positive(t, result.Run.AccountsChecked)
+	discrepancy, ok := findDiscrepancy(result, from.ID)
+	require.True(t, ok)
+	require.Equal(t, int64(90), discrepancy.Balance)
+	require.Equal(t, int64(-10), discrepancy.EntriesTotal)
+	require.Equal(t, int64(100), discrepancy.Difference)
+	require.False(t, discrepancy.JournalEntryID.Valid)
+	_, ok = findDiscrepancy(result, to.ID)
+	require.False(t, ok)
+
+	var total *ReconciliationDiscrepancy
+	for i := range result.Discrepancies {
+		if !result.Discrepancies[i].AccountID.Valid && result.Discrepancies[i].Currency == utils.USD {
+			total = &result.Discrepancies[i]
+		}
+	}
+	require.NotNil(t, total)
+	require.Equal(t, int64(100), total.Difference)
+
+	stored, err := store.ListReconciliationDiscrepancies(context.Background(), result.Run.ID)
+	require.NoError(t, err)
+	require.Len(t, stored, len(result.Discrepancies))
+	latest, err := store.GetLatestReconciliationRun(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, result.Run, latest)
+
+	repaired, err := store.Reconcile(context.Background(), ReconcileParams{Repair: true})
+	require.NoError(t, err)
+	discrepancy, ok = findDiscrepancy(repaired, from.ID)
+	require.True(t, ok)
+	require.True(t, discrepancy.JournalEntryID.Valid)
+	postings, err := store.ListPostings(context.Background(), discrepancy.JournalEntryID.Int64)
+	require.NoError(t, err)
+	require.Len(t, postings, 2)
+
+	account, err := store.GetAccounts(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(90), account.Balance)
+
+	clean, err := store.Reconcile(context.Background(), ReconcileParams{})
+	require.NoError(t, err)
+	require.Zero(t, clean.Run.Discrepancies)
+	require.Empty(t, clean.Discrepancies)
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrReversalOfReversal       = errors.New("a reversal can't itself be reversed")
+	ErrCrossCurrencyReversal    = errors.New("cross-currency transfers can't be reversed")
+	ErrTransferFullyReversed    = errors.New("transfer has already been fully reversed")
+	errReversalExceedsRemaining = errors.New("reversal exceeds the amount left to reverse")
+)
+
+// ReversalExceedsError is returned when a reversal asks for more than what
+// is left of the original transfer.
+type ReversalExceedsError struct {
+	Requested int64 `json:"requested"`
+	Remaining int64 `json:"remaining"`
+}
+
+func (e *ReversalExceedsError) Error() string {
+	return fmt.Sprintf("%v: requested %d, remaining %d", errReversalExceedsRemaining, e.Requested, e.Remaining)
+}
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount to send back; zero reverses whatever is left of the transfer.
+	Amount int64 `json:"amount"`
+}
+
+type ReverseTransferTxResult struct {
+	TransferTxResult
+	ReversalOf Transfer `json:"reversal_of"`
+	// Remaining is what can still be reversed after this reversal.
+	Remaining int64 `json:"remaining"`
+}
+
+// ReverseTransferTx sends all or part of a transfer back with a new transfer
+// linked to it through reversal_of_transfer_id. The original transfer and
+// its entries are left as they are, except that the original is marked
+// reversed once nothing is left of it. The original is locked first, so
+// concurrent reversals of it are serialized and can't add up to more than
+// its amount.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		// the money of an outbound payment has left the bank; only a
+		// return from the receiving bank brings it back
+		_, err := q.GetOutboundPaymentByTransfer(ctx, arg.TransferID)
+		if err == nil {
+			return ErrOutboundPaymentReversal
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		result, err = reverseTransfer(ctx, q, arg)
+		return err
+	})
+	return result, err
+}
+
+// reverseTransfer does the work of ReverseTransferTx inside the caller's
+// transaction.
+func reverseTransfer(ctx context.Context, q *Queries, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+	original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+	if err != nil {
+		return result, err
+	}
+	if original.ReversalOfTransferID.Valid {
+		return result, ErrReversalOfReversal
+	}
+	switch original.Status {
+	case TransferCompleted:
+	case TransferReversed:
+		return result, ErrTransferFullyReversed
+	default:
+		return result, ErrTransferNotCompleted
+	}
+	result.ReversalOf = original
+
+	reversed, err := q.GetReversedAmount(ctx, original.ID)
+	if err != nil {
+		return result, err
+	}
+	remaining := original.Amount - reversed
+	amount := arg.Amount
+	if amount == 0 {
+		amount = remaining
+	}
+	if remaining <= 0 {
+		return result, ErrTransferFullyReversed
+	}
+	if amount > remaining {
+		return result, &ReversalExceedsError{Requested: amount, Remaining: remaining}
+	}
+
+	// the money goes back from the original recipient to the sender
+	recipient, sender, err := lockAccountPair(ctx, q, original.ToAccountID.Int64, original.FromAccountID.Int64)
+	if err != nil {
+		return result, err
+	}
+	if recipient.Currency != sender.Currency {
+		return result, ErrCrossCurrencyReversal
+	}
+	if recipient.Balance < amount {
+		return result, ErrInsufficientFunds
+	}
+
+	transfer, err := q.CreateReversalTransfer(ctx, CreateReversalTransferParams{
+		FromAccountID:        original.ToAccountID,
+		ToAccountID:          original.FromAccountID,
+		Amount:               amount,
+		ReversalOfTransferID: sql.NullInt64{Int64: original.ID, Valid: true},
+	})
+	if err != nil {
+		return result, err
+	}
+	result.TransferTxResult, err = postTransfer(ctx, q, transfer)
+	if err != nil {
+		return result, err
+	}
+	result.Remaining = remaining - amount
+	if result.Remaining == 0 {
+		result.ReversalOf, err = transitionTransfer(ctx, q, original, TransferReversed, "")
+	}
+	return result, err
+}
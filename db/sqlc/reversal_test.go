@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	partial, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     30,
+	})
+	require.NoError(t, err)
+	require.Equal(t, original.Transfer.ID, partial.Transfer.ReversalOfTransferID.Int64)
+	require.Equal(t, to.ID, partial.Transfer.FromAccountID.Int64)
+	require.Equal(t, from.ID, partial.Transfer.ToAccountID.Int64)
+	require.Equal(t, int64(30), partial.Transfer.Amount)
+	require.Equal(t, int64(70), partial.Remaining)
+	require.Equal(t, int64(930), partial.ToAccount.Balance)
+	require.Equal(t, int64(70), partial.FromAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     71,
+	})
+	var exceedsErr *ReversalExceedsError
+	require.ErrorAs(t, err, &exceedsErr)
+	require.Equal(t, int64(70), exceedsErr.Remaining)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: partial.Transfer.ID})
+	require.ErrorIs(t, err, ErrReversalOfReversal)
+
+	// the original's entries are untouched
+	entry, err := testQueries.GetEntry(context.Background(), original.FromEntry.ID)
+	require.NoError(t, err)
+	require.Equal(t, original.FromEntry, entry)
+}
+
+func TestReverseTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+				TransferID: original.Transfer.ID,
+				Amount:     30,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		if err := <-errs; err == nil {
+			succeeded++
+		}
+	}
+	require.Equal(t, 3, succeeded)
+
+	reversed, err := testQueries.GetReversedAmount(context.Background(), original.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(90), reversed)
+}
+
+func TestReverseTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	other := createAccountInCurrency(t, utils.USD, 0)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	// the recipient spends most of it before the reversal
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: to.ID,
+		ToAccountID:   other.ID,
+		Amount:        80,
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	account, err := testQueries.GetAccounts(context.Background(), to.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(20), account.Balance)
+}
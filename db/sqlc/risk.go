@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Decisions of the risk engine, from the mildest to the strictest.
+const (
+	RiskAllow  = "allow"
+	RiskReview = "review"
+	RiskBlock  = "block"
+)
+
+// Kinds of risk rules.
+const (
+	RiskRuleVelocity            = "velocity"
+	RiskRuleNewRecipient        = "new_recipient"
+	RiskRuleAboveAverage        = "above_average"
+	RiskRuleAfterPasswordChange = "after_password_change"
+)
+
+var ErrTransferBlocked = errors.New("transfer was blocked by the risk engine")
+
+var riskSeverity = map[string]int{
+	RiskAllow:  0,
+	RiskReview: 1,
+	RiskBlock:  2,
+}
+
+type ScreenTransferParams struct {
+	FromAccount Account
+	ToAccountID int64
+	Amount      int64
+}
+
+// ScreenTransfer runs a transfer through the enabled risk rules for its
+// currency and records the decision with the rules that fired. The decision
+// is the strictest action of those rules, or allow when none fired. It is
+// recorded outside any transfer transaction, or in one that commits when the
+// transfer is blocked, so blocked transfers are logged too.
+func ScreenTransfer(ctx context.Context, q Querier, arg ScreenTransferParams) (RiskDecision, error) {
+	rules, err := q.ListRiskRules(ctx, arg.FromAccount.Currency)
+	if err != nil {
+		return RiskDecision{}, err
+	}
+
+	decision := RiskAllow
+	fired := []string{}
+	for _, rule := range rules {
+		if arg.Amount < rule.MinAmount {
+			continue
+		}
+		ok, err := rule.fires(ctx, q, arg)
+		if err != nil {
+			return RiskDecision{}, fmt.Errorf("risk rule %s: %w", rule.Name, err)
+		}
+		if !ok {
+			continue
+		}
+		fired = append(fired, rule.Name)
+		if riskSeverity[rule.Action] > riskSeverity[decision] {
+			decision = rule.Action
+		}
+	}
+
+	return q.CreateRiskDecision(ctx, CreateRiskDecisionParams{
+		Username:      arg.FromAccount.Owner,
+		FromAccountID: arg.FromAccount.ID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Currency:      arg.FromAccount.Currency,
+		Decision:      decision,
+		FiredRules:    fired,
+	})
+}
+
+// fires reports whether the rule matches the transfer. The caller has
+// already checked the rule's minimum amount.
+func (rule RiskRule) fires(ctx context.Context, q Querier, arg ScreenTransferParams) (bool, error) {
+	switch rule.Kind {
+	case RiskRuleVelocity:
+		// the transfer being screened counts towards the window too
+		count, err := q.CountTransfersSince(ctx, CountTransfersSinceParams{
+			FromAccountID: arg.FromAccount.ID,
+			Since:         time.Now().Add(-rule.window()),
+		})
+		return count+1 > int64(rule.MaxCount), err
+	case RiskRuleNewRecipient:
+		count, err := q.CountTransfersTo(ctx, CountTransfersToParams{
+			FromAccountID: arg.FromAccount.ID,
+			ToAccountID:   arg.ToAccountID,
+		})
+		return count == 0, err
+	case RiskRuleAboveAverage:
+		// without enough history the average says nothing
+		average, err := q.GetTransferAverage(ctx, arg.FromAccount.ID)
+		if err != nil || average.TransferCount == 0 || average.TransferCount < int64(rule.MaxCount) {
+			return false, err
+		}
+		return arg.Amount > average.AverageAmount*int64(rule.Multiplier), nil
+	case RiskRuleAfterPasswordChange:
+		owner, err := q.GetUsers(ctx, arg.FromAccount.Owner)
+		if err != nil {
+			return false, err
+		}
+		changedAt := owner.PasswordChangedAt
+		if !changedAt.After(owner.CreatedAt.Time) || time.Since(changedAt) > rule.window() {
+			// the password was never changed, or not recently
+			return false, nil
+		}
+		count, err := q.CountTransfersSince(ctx, CountTransfersSinceParams{
+			FromAccountID: arg.FromAccount.ID,
+			Since:         changedAt,
+		})
+		return count == 0, err
+	}
+	return false, fmt.Errorf("unknown risk rule kind %q", rule.Kind)
+}
+
+func (rule RiskRule) window() time.Duration {
+	return time.Duration(rule.WindowMinutes) * time.Minute
+}
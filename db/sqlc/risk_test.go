@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func createTestRiskRule(t *testing.T, arg CreateRiskRuleParams) RiskRule {
+	arg.Name = arg.Kind + "_" + utils.RandomString(8)
+	rule, err := testQueries.CreateRiskRule(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, rule.Enabled)
+	t.Cleanup(func() {
+		require.NoError(t, testQueries.DeleteRiskRule(context.Background(), rule.ID))
+	})
+	return rule
+}
+
+func TestScreenTransferNewRecipient(t *testing.T) {
+	// the rule only applies to this made-up currency, so it can't affect
+	// other tests
+	currency := "X" + utils.RandomString(2)
+	rule := createTestRiskRule(t, CreateRiskRuleParams{
+		Kind:      RiskRuleNewRecipient,
+		Action:    RiskReview,
+		Currency:  sql.NullString{String: currency, Valid: true},
+		MinAmount: 100,
+	})
+	from := createAccountInCurrency(t, currency, 1000)
+	to := createAccountInCurrency(t, currency, 0)
+
+	screen := func(amount int64) RiskDecision {
+		decision, err := ScreenTransfer(context.Background(), testQueries, ScreenTransferParams{
+			FromAccount: from,
+			ToAccountID: to.ID,
+			Amount:      amount,
+		})
+		require.NoError(t, err)
+		require.NotZero(t, decision.ID)
+		require.Equal(t, from.Owner, decision.Username)
+		require.Equal(t, amount, decision.Amount)
+		return decision
+	}
+
+	decision := screen(150)
+	require.Equal(t, RiskReview, decision.Decision)
+	require.Equal(t, []string{rule.Name}, decision.FiredRules)
+
+	// below the rule's minimum amount
+	decision = screen(50)
+	require.Equal(t, RiskAllow, decision.Decision)
+	require.Empty(t, decision.FiredRules)
+
+	// the recipient isn't new anymore
+	createRandomTransfer(t, from, to)
+	decision = screen(150)
+	require.Equal(t, RiskAllow, decision.Decision)
+}
+
+func TestScreenTransferVelocity(t *testing.T) {
+	currency := "X" + utils.RandomString(2)
+	review := createTestRiskRule(t, CreateRiskRuleParams{
+		Kind:          RiskRuleVelocity,
+		Action:        RiskReview,
+		Currency:      sql.NullString{String: currency, Valid: true},
+		MaxCount:      1,
+		WindowMinutes: 10,
+	})
+	block := createTestRiskRule(t, CreateRiskRuleParams{
+		Kind:          RiskRuleVelocity,
+		Action:        RiskBlock,
+		Currency:      sql.NullString{String: currency, Valid: true},
+		MaxCount:      2,
+		WindowMinutes: 10,
+	})
+	from := createAccountInCurrency(t, currency, 1000)
+	to := createAccountInCurrency(t, currency, 0)
+
+	arg := ScreenTransferParams{FromAccount: from, ToAccountID: to.ID, Amount: 10}
+	decision, err := ScreenTransfer(context.Background(), testQueries, arg)
+	require.NoError(t, err)
+	require.Equal(t, RiskAllow, decision.Decision)
+
+	createRandomTransfer(t, from, to)
+	decision, err = ScreenTransfer(context.Background(), testQueries, arg)
+	require.NoError(t, err)
+	require.Equal(t, RiskReview, decision.Decision)
+	require.Equal(t, []string{review.Name}, decision.FiredRules)
+
+	createRandomTransfer(t, from, to)
+	decision, err = ScreenTransfer(context.Background(), testQueries, arg)
+	require.NoError(t, err)
+	require.Equal(t, RiskBlock, decision.Decision)
+	require.Equal(t, []string{review.Name, block.Name}, decision.FiredRules)
+
+	decisions, err := testQueries.ListRiskDecisions(context.Background(), ListRiskDecisionsParams{
+		Decisions: []string{RiskBlock},
+		Limit:     1,
+	})
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	require.Equal(t, decision.ID, decisions[0].ID)
+}
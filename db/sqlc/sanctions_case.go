@@ -0,0 +1,19 @@
+package db
+
+// Operations that are screened against the sanctions list.
+const (
+	SanctionsOperationCreateUser = "create_user"
+	SanctionsOperationTransfer   = "transfer"
+)
+
+// Actions taken on a sanctions match.
+const (
+	SanctionsFlag  = "flag"
+	SanctionsBlock = "block"
+)
+
+// Statuses of a sanctions case.
+const (
+	SanctionsCaseOpen    = "open"
+	SanctionsCaseCleared = "cleared"
+)
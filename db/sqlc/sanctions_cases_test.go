@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func createTestSanctionsCase(t *testing.T, screenedName string, entryID string) SanctionsCase {
+	username := utils.RandomOwner()
+	sanctionsCase, err := testQueries.CreateSanctionsCase(context.Background(), CreateSanctionsCaseParams{
+		Operation:        SanctionsOperationCreateUser,
+		InitiatedBy:      username,
+		ScreenedName:     screenedName,
+		ScreenedUsername: username,
+		ListEntryID:      entryID,
+		ListEntryName:    "Ivan Petrov",
+		Score:            0.92,
+		Action:           SanctionsFlag,
+	})
+	require.NoError(t, err)
+	require.Equal(t, SanctionsCaseOpen, sanctionsCase.Status)
+	require.False(t, sanctionsCase.ResolvedAt.Valid)
+	return sanctionsCase
+}
+
+func TestClearSanctionsCase(t *testing.T) {
+	staff := createRandomUser(t)
+	screenedName := utils.RandomOwner()
+	entryID := "SDN-" + utils.RandomString(6)
+	sanctionsCase := createTestSanctionsCase(t, screenedName, entryID)
+
+	arg := CountClearedSanctionsMatchesParams{ScreenedName: screenedName, ListEntryID: entryID}
+	cleared, err := testQueries.CountClearedSanctionsMatches(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, cleared)
+
+	sanctionsCase, err = testQueries.ClearSanctionsCase(context.Background(), ClearSanctionsCaseParams{
+		ID:             sanctionsCase.ID,
+		ResolvedBy:     staff.Username,
+		ResolutionNote: "different person",
+	})
+	require.NoError(t, err)
+	require.Equal(t, SanctionsCaseCleared, sanctionsCase.Status)
+	require.Equal(t, staff.Username, sanctionsCase.ResolvedBy.String)
+	require.True(t, sanctionsCase.ResolvedAt.Valid)
+
+	cleared, err = testQueries.CountClearedSanctionsMatches(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cleared)
+
+	// only open cases can be cleared
+	_, err = testQueries.ClearSanctionsCase(context.Background(), ClearSanctionsCaseParams{
+		ID:             sanctionsCase.ID,
+		ResolvedBy:     staff.Username,
+		ResolutionNote: "again",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestListSanctionsCases(t *testing.T) {
+	sanctionsCase := createTestSanctionsCase(t, utils.RandomOwner(), "SDN-"+utils.RandomString(6))
+
+	cases, err := testQueries.ListSanctionsCases(context.Background(), ListSanctionsCasesParams{
+		Status: SanctionsCaseOpen,
+		Limit:  5,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, cases)
+	require.Equal(t, sanctionsCase.ID, cases[0].ID)
+}
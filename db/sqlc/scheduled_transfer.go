@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Statuses of a scheduled transfer.
+const (
+	ScheduledTransferScheduled = "scheduled"
+	// ScheduledTransferHeld waits for a pending transfer to be decided.
+	ScheduledTransferHeld      = "held"
+	ScheduledTransferExecuted  = "executed"
+	ScheduledTransferFailed    = "failed"
+	ScheduledTransferCancelled = "cancelled"
+)
+
+// Outcomes recorded for each attempt to run a scheduled transfer.
+const (
+	ScheduledRunExecuted = "executed"
+	ScheduledRunHeld     = "held"
+	ScheduledRunFailed   = "failed"
+	ScheduledRunRetried  = "retried"
+)
+
+type RunScheduledTransfersParams struct {
+	// Limit is the most transfers to run in one call.
+	Limit int
+	// MaxAttempts is how many times a transfer is tried before it fails for good.
+	MaxAttempts int32
+	// RetryDelay is how long to wait before trying a failed transfer again.
+	RetryDelay time.Duration
+	// NeedsApproval reports whether a transfer is above the approval
+	// threshold of its currency; such transfers are held as a pending
+	// transfer expiring after PendingTransferTTL instead of running.
+	NeedsApproval      func(currency string, amount int64) bool
+	PendingTransferTTL time.Duration
+}
+
+// RunDueScheduledTransfers runs scheduled transfers whose time has come, one
+// transaction each, and returns what happened to them. Rows are claimed with
+// FOR UPDATE SKIP LOCKED, so several workers can run it at the same time
+// without running a transfer twice. Each transfer goes through the risk
+// engine first: a block fails it for good, and a review holds it like a
+// transfer above the approval threshold. A transfer is also held while a
+// sanctions case flagged on either account is open.
+func (store *SQLStore) RunDueScheduledTransfers(ctx context.Context, arg RunScheduledTransfersParams) ([]ScheduledTransferRun, error) {
+	var runs []ScheduledTransferRun
+	for len(runs) < arg.Limit {
+		run, err := store.runNextScheduledTransfer(ctx, arg)
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return runs, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+func (store *SQLStore) runNextScheduledTransfer(ctx context.Context, arg RunScheduledTransfersParams) (ScheduledTransferRun, error) {
+	var run ScheduledTransferRun
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		scheduled, err := q.ClaimDueScheduledTransfer(ctx)
+		if err != nil {
+			return err
+		}
+		// screened again now: the rules and the history of the account may
+		// have changed since the transfer was scheduled
+		fromAccount, err := q.GetAccounts(ctx, scheduled.FromAccountID)
+		if err != nil {
+			return err
+		}
+		decision, err := ScreenTransfer(ctx, q, ScreenTransferParams{
+			FromAccount: fromAccount,
+			ToAccountID: scheduled.ToAccountID,
+			Amount:      scheduled.Amount,
+		})
+		if err != nil {
+			return err
+		}
+		openCases, err := q.CountOpenSanctionsCasesForTransfer(ctx, CountOpenSanctionsCasesForTransferParams{
+			FromAccountID: scheduled.FromAccountID,
+			ToAccountID:   scheduled.ToAccountID,
+		})
+		if err != nil {
+			return err
+		}
+		blocked := decision.Decision == RiskBlock
+		needsApproval := arg.NeedsApproval != nil && arg.NeedsApproval(scheduled.Currency, scheduled.Amount)
+		if !blocked && (needsApproval || decision.Decision == RiskReview || openCases > 0) {
+			riskDecisionID := sql.NullInt64{Int64: decision.ID, Valid: decision.Decision == RiskReview}
+			run, err = holdScheduledTransfer(ctx, q, scheduled, arg.PendingTransferTTL, riskDecisionID)
+			return err
+		}
+
+		var result TransferTxResult
+		var transferErr error
+		if blocked {
+			transferErr = fmt.Errorf("%w: %s", ErrTransferBlocked, strings.Join(decision.FiredRules, ", "))
+		} else {
+			// The transfer gets its own savepoint so a failure can still be
+			// recorded against the claimed row.
+			transferErr = withSavepoint(ctx, q, "scheduled_transfer", func() error {
+				var err error
+				result, err = transferTx(ctx, q, TransferTxParams{
+					FromAccountID: scheduled.FromAccountID,
+					ToAccountID:   scheduled.ToAccountID,
+					Amount:        scheduled.Amount,
+				})
+				return err
+			})
+		}
+
+		update := UpdateScheduledTransferRunParams{
+			ID:        scheduled.ID,
+			Status:    ScheduledTransferExecuted,
+			ExecuteAt: scheduled.ExecuteAt,
+		}
+		runArg := CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduled.ID,
+			Outcome:             ScheduledRunExecuted,
+		}
+		switch {
+		case transferErr == nil:
+			update.TransferID = sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+			runArg.TransferID = update.TransferID
+		case blocked, scheduled.Attempts+1 >= arg.MaxAttempts:
+			// a blocked transfer isn't tried again
+			update.Status = ScheduledTransferFailed
+			runArg.Outcome = ScheduledRunFailed
+			runArg.Reason = transferErr.Error()
+		default:
+			update.Status = ScheduledTransferScheduled
+			update.ExecuteAt = time.Now().Add(arg.RetryDelay)
+			runArg.Outcome = ScheduledRunRetried
+			runArg.Reason = transferErr.Error()
+		}
+
+		if _, err := q.UpdateScheduledTransferRun(ctx, update); err != nil {
+			return err
+		}
+		run, err = q.CreateScheduledTransferRun(ctx, runArg)
+		return err
+	})
+	return run, err
+}
+
+// holdScheduledTransfer holds a due scheduled transfer as a pending transfer
+// instead of running it. The decision on the pending transfer executes or
+// fails it.
+func holdScheduledTransfer(ctx context.Context, q *Queries, scheduled ScheduledTransfer, ttl time.Duration, riskDecisionID sql.NullInt64) (ScheduledTransferRun, error) {
+	pending, err := holdTransfer(ctx, q, CreatePendingTransferParams{
+		CreatedBy:           scheduled.Username,
+		FromAccountID:       scheduled.FromAccountID,
+		ToAccountID:         sql.NullInt64{Int64: scheduled.ToAccountID, Valid: true},
+		Amount:              scheduled.Amount,
+		Currency:            scheduled.Currency,
+		ExpiresAt:           time.Now().Add(ttl),
+		RiskDecisionID:      riskDecisionID,
+		ScheduledTransferID: sql.NullInt64{Int64: scheduled.ID, Valid: true},
+	})
+	if err != nil {
+		return ScheduledTransferRun{}, err
+	}
+	_, err = q.UpdateScheduledTransferRun(ctx, UpdateScheduledTransferRunParams{
+		ID:        scheduled.ID,
+		Status:    ScheduledTransferHeld,
+		ExecuteAt: scheduled.ExecuteAt,
+	})
+	if err != nil {
+		return ScheduledTransferRun{}, err
+	}
+	return q.CreateScheduledTransferRun(ctx, CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduled.ID,
+		Outcome:             ScheduledRunHeld,
+		Reason:              fmt.Sprintf("waiting for the approval of pending transfer %d", pending.ID),
+	})
+}
+
+// closeHeldScheduledTransfer records the decision on a held scheduled
+// transfer: executed by the transfer an approval made, or failed for reason.
+func closeHeldScheduledTransfer(ctx context.Context, q *Queries, id int64, transferID sql.NullInt64, reason string) error {
+	update := DecideHeldScheduledTransferParams{
+		ID:         id,
+		Status:     ScheduledTransferExecuted,
+		TransferID: transferID,
+	}
+	runArg := CreateScheduledTransferRunParams{
+		ScheduledTransferID: id,
+		Outcome:             ScheduledRunExecuted,
+		TransferID:          transferID,
+	}
+	if !transferID.Valid {
+		update.Status = ScheduledTransferFailed
+		runArg.Outcome = ScheduledRunFailed
+		runArg.Reason = reason
+	}
+	if _, err := q.DecideHeldScheduledTransfer(ctx, update); err != nil {
+		return err
+	}
+	_, err := q.CreateScheduledTransferRun(ctx, runArg)
+	return err
+}
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func createRandomScheduledTransfer(t *testing.T, from, to Account, amount int64, executeAt time.Time) ScheduledTransfer {
+	arg := CreateScheduledTransferParams{
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Currency:      from.Currency,
+		ExecuteAt:     executeAt,
+	}
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.FromAccountID, scheduled.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduled.ToAccountID)
+	require.Equal(t, arg.Amount, scheduled.Amount)
+	require.Equal(t, ScheduledTransferScheduled, scheduled.Status)
+	require.Zero(t, scheduled.Attempts)
+	require.False(t, scheduled.TransferID.Valid)
+	return scheduled
+}
+
+func TestCancelScheduledTransfer(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccount(t)
+	scheduled := createRandomScheduledTransfer(t, from, to, 10, time.Now().Add(time.Hour))
+
+	cancelled, err := testQueries.CancelScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferCancelled, cancelled.Status)
+
+	// only scheduled transfers can be cancelled
+	_, err = testQueries.CancelScheduledTransfer(context.Background(), scheduled.ID)
+	require.Error(t, err)
+}
+
+func TestRunDueScheduledTransfers(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	due := createRandomScheduledTransfer(t, from, to, 10, time.Now().Add(-time.Second))
+	tooLarge := createRandomScheduledTransfer(t, from, to, from.Balance+1_000_000, time.Now().Add(-time.Second))
+	later := createRandomScheduledTransfer(t, from, to, 10, time.Now().Add(time.Hour))
+
+	arg := RunScheduledTransfersParams{
+		Limit:       1000,
+		MaxAttempts: 2,
+		RetryDelay:  -time.Second,
+	}
+	_, err := store.RunDueScheduledTransfers(context.Background(), arg)
+	require.NoError(t, err)
+
+	executed, err := testQueries.GetScheduledTransfer(context.Background(), due.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferExecuted, executed.Status)
+	require.True(t, executed.TransferID.Valid)
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), due.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, ScheduledRunExecuted, runs[0].Outcome)
+	require.Equal(t, executed.TransferID, runs[0].TransferID)
+
+	// the transfer that can't be covered is retried once, then fails
+	retried, err := testQueries.GetScheduledTransfer(context.Background(), tooLarge.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferFailed, retried.Status)
+	require.Equal(t, int32(2), retried.Attempts)
+
+	runs, err = testQueries.ListScheduledTransferRuns(context.Background(), tooLarge.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.Equal(t, ScheduledRunRetried, runs[0].Outcome)
+	require.Equal(t, ScheduledRunFailed, runs[1].Outcome)
+	require.Equal(t, ErrInsufficientFunds.Error(), runs[1].Reason)
+
+	notDue, err := testQueries.GetScheduledTransfer(context.Background(), later.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferScheduled, notDue.Status)
+
+	account, err := testQueries.GetAccounts(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance-10, account.Balance)
+}
+
+func TestRunDueScheduledTransfersHold(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	checker := createRandomUser(t)
+	large := createRandomScheduledTransfer(t, from, to, 500, time.Now().Add(-time.Second))
+
+	arg := RunScheduledTransfersParams{
+		Limit:       1000,
+		MaxAttempts: 2,
+		RetryDelay:  -time.Second,
+		NeedsApproval: func(currency string, amount int64) bool {
+			return amount > 100
+		},
+		PendingTransferTTL: time.Hour,
+	}
+	_, err := store.RunDueScheduledTransfers(context.Background(), arg)
+	require.NoError(t, err)
+
+	held, err := testQueries.GetScheduledTransfer(context.Background(), large.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferHeld, held.Status)
+	require.False(t, held.TransferID.Valid)
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), large.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, ScheduledRunHeld, runs[0].Outcome)
+
+	// a held transfer isn't due again
+	_, err = store.RunDueScheduledTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	runs, err = testQueries.ListScheduledTransferRuns(context.Background(), large.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+
+	pendings, err := testQueries.ListPendingTransfersByCreator(context.Background(), ListPendingTransfersByCreatorParams{
+		CreatedBy: from.Owner,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, pendings, 1)
+	require.Equal(t, large.ID, pendings[0].ScheduledTransferID.Int64)
+
+	result, err := store.DecidePendingTransferTx(context.Background(), DecidePendingTransferTxParams{
+		ID:       pendings[0].ID,
+		Username: checker.Username,
+		Decision: DecisionApproved,
+	})
+	require.NoError(t, err)
+
+	executed, err := testQueries.GetScheduledTransfer(context.Background(), large.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferExecuted, executed.Status)
+	require.Equal(t, result.PendingTransfer.TransferID, executed.TransferID)
+}
+
+func TestRunDueScheduledTransfersOpenSanctionsCase(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	scheduled := createRandomScheduledTransfer(t, from, to, 10, time.Now().Add(-time.Second))
+
+	_, err := testQueries.CreateSanctionsCase(context.Background(), CreateSanctionsCaseParams{
+		Operation:        SanctionsOperationTransfer,
+		InitiatedBy:      from.Owner,
+		ScreenedName:     utils.RandomOwner(),
+		ScreenedUsername: to.Owner,
+		AccountID:        sql.NullInt64{Int64: to.ID, Valid: true},
+		ListEntryID:      "SDN-" + utils.RandomString(6),
+		ListEntryName:    "Ivan Petrov",
+		Score:            0.92,
+		Action:           SanctionsFlag,
+	})
+	require.NoError(t, err)
+
+	_, err = store.RunDueScheduledTransfers(context.Background(), RunScheduledTransfersParams{
+		Limit:              1000,
+		MaxAttempts:        2,
+		RetryDelay:         -time.Second,
+		PendingTransferTTL: time.Hour,
+	})
+	require.NoError(t, err)
+
+	held, err := testQueries.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferHeld, held.Status)
+	require.False(t, held.TransferID.Valid)
+}
+
+func TestRunDueScheduledTransfersRisk(t *testing.T) {
+	store := NewStore(testDB)
+	screened := func(action string) (ScheduledTransfer, RiskRule) {
+		// the rule only applies to this made-up currency, so it can't affect
+		// other tests
+		currency := "X" + utils.RandomString(2)
+		rule := createTestRiskRule(t, CreateRiskRuleParams{
+			Kind:     RiskRuleNewRecipient,
+			Action:   action,
+			Currency: sql.NullString{String: currency, Valid: true},
+		})
+		from := createAccountInCurrency(t, currency, 1000)
+		to := createAccountInCurrency(t, currency, 0)
+		return createRandomScheduledTransfer(t, from, to, 10, time.Now().Add(-time.Second)), rule
+	}
+	blocked, blockRule := screened(RiskBlock)
+	reviewed, _ := screened(RiskReview)
+
+	_, err := store.RunDueScheduledTransfers(context.Background(), RunScheduledTransfersParams{
+		Limit:              1000,
+		MaxAttempts:        3,
+		RetryDelay:         time.Hour,
+		PendingTransferTTL: time.Hour,
+	})
+	require.NoError(t, err)
+
+	// a block fails the transfer without retrying it
+	failed, err := testQueries.GetScheduledTransfer(context.Background(), blocked.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferFailed, failed.Status)
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), blocked.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, ScheduledRunFailed, runs[0].Outcome)
+	require.Contains(t, runs[0].Reason, ErrTransferBlocked.Error())
+	require.Contains(t, runs[0].Reason, blockRule.Name)
+
+	held, err := testQueries.GetScheduledTransfer(context.Background(), reviewed.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferHeld, held.Status)
+	pendings, err := testQueries.ListPendingTransfersByCreator(context.Background(), ListPendingTransfersByCreatorParams{
+		CreatedBy: reviewed.Username,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, pendings, 1)
+	require.True(t, pendings[0].RiskDecisionID.Valid)
+}
@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"tutorial.sqlc.dev/app/schedule"
+)
+
+// Statuses of a standing order.
+const (
+	StandingOrderActive    = "active"
+	StandingOrderPaused    = "paused"
+	StandingOrderCompleted = "completed"
+	StandingOrderCancelled = "cancelled"
+)
+
+var ErrStandingOrderClosed = errors.New("standing order is completed or cancelled")
+
+// Rule returns the schedule rule of the order.
+func (order StandingOrder) Rule() schedule.Rule {
+	return schedule.Rule{
+		Frequency:  schedule.Frequency(order.Frequency),
+		Weekday:    time.Weekday(order.Weekday.Int32),
+		DayOfMonth: int(order.DayOfMonth.Int32),
+	}
+}
+
+// ScheduleFrom sets the next occurrence of the order to the first one on or
+// after day (and not before its start date), and completes the order once it
+// has nothing left to run.
+func (order *StandingOrder) ScheduleFrom(day time.Time, calendar schedule.Calendar) {
+	if day.Before(order.StartDate) {
+		day = order.StartDate
+	}
+	rule := order.Rule()
+	order.NextNominalDate = rule.FirstOnOrAfter(day)
+	order.NextRunDate = rule.RunDate(order.NextNominalDate, calendar)
+
+	reachedMax := order.MaxOccurrences.Valid && order.Occurrences >= order.MaxOccurrences.Int32
+	pastEnd := order.EndDate.Valid && order.NextNominalDate.After(schedule.Date(order.EndDate.Time))
+	if reachedMax || pastEnd {
+		order.Status = StandingOrderCompleted
+	}
+}
+
+// calendarDays is how far ahead LoadCalendar loads holidays; enough for the
+// longest gap between two occurrences plus any run of holidays after it.
+const calendarDays = 400
+
+// LoadCalendar builds a business day calendar from the holidays table,
+// covering the dates a standing order scheduled from day can fall on.
+func LoadCalendar(ctx context.Context, q Querier, day time.Time) (schedule.Calendar, error) {
+	from := schedule.Date(day).AddDate(0, 0, -7)
+	holidays, err := q.ListHolidays(ctx, ListHolidaysParams{
+		FromDate: from,
+		ToDate:   from.AddDate(0, 0, calendarDays),
+	})
+	if err != nil {
+		return schedule.Calendar{}, err
+	}
+	dates := make([]time.Time, len(holidays))
+	for i, holiday := range holidays {
+		dates[i] = holiday.Date
+	}
+	return schedule.NewCalendar(dates...), nil
+}
+
+// MaterializeStandingOrders turns every standing order occurrence that is due
+// by today into a scheduled transfer, which the scheduled transfer worker
+// then runs. Orders are claimed with FOR UPDATE SKIP LOCKED and occurrences
+// are unique per order and date, so concurrent callers can't create one twice.
+func (store *SQLStore) MaterializeStandingOrders(ctx context.Context, today time.Time) ([]ScheduledTransfer, error) {
+	var occurrences []ScheduledTransfer
+	for {
+		occurrence, err := store.materializeNextStandingOrder(ctx, today)
+		if errors.Is(err, sql.ErrNoRows) {
+			return occurrences, nil
+		}
+		if err != nil {
+			return occurrences, err
+		}
+		if occurrence.ID != 0 {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+}
+
+func (store *SQLStore) materializeNextStandingOrder(ctx context.Context, today time.Time) (ScheduledTransfer, error) {
+	var occurrence ScheduledTransfer
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		order, err := q.ClaimDueStandingOrder(ctx, schedule.Date(today))
+		if err != nil {
+			return err
+		}
+		calendar, err := LoadCalendar(ctx, q, order.NextNominalDate)
+		if err != nil {
+			return err
+		}
+
+		occurrence, err = q.CreateStandingOrderOccurrence(ctx, CreateStandingOrderOccurrenceParams{
+			Username:        order.Username,
+			FromAccountID:   order.FromAccountID,
+			ToAccountID:     order.ToAccountID,
+			Amount:          order.Amount,
+			Currency:        order.Currency,
+			ExecuteAt:       order.NextRunDate,
+			StandingOrderID: sql.NullInt64{Int64: order.ID, Valid: true},
+			OccurrenceDate:  sql.NullTime{Time: order.NextNominalDate, Valid: true},
+		})
+		switch {
+		case err == nil:
+			order.Occurrences++
+		case errors.Is(err, sql.ErrNoRows):
+			// created before the order was edited; just move on
+		default:
+			return err
+		}
+
+		order.ScheduleFrom(order.Rule().After(order.NextNominalDate), calendar)
+		_, err = q.UpdateStandingOrder(ctx, updateStandingOrderParams(order))
+		return err
+	})
+	return occurrence, err
+}
+
+// StandingOrderChanges replaces the amount and schedule of a standing order.
+type StandingOrderChanges struct {
+	Amount         int64         `json:"amount"`
+	Frequency      string        `json:"frequency"`
+	Weekday        sql.NullInt32 `json:"weekday"`
+	DayOfMonth     sql.NullInt32 `json:"day_of_month"`
+	EndDate        sql.NullTime  `json:"end_date"`
+	MaxOccurrences sql.NullInt32 `json:"max_occurrences"`
+}
+
+type UpdateStandingOrderTxParams struct {
+	ID int64 `json:"id"`
+	// Changes, when set, is applied before the order is rescheduled.
+	Changes *StandingOrderChanges `json:"changes"`
+	// Resume reactivates a paused order.
+	Resume bool `json:"resume"`
+	// Today is the first day the rescheduled order can run on; occurrences
+	// missed while the order was paused are skipped.
+	Today time.Time `json:"today"`
+}
+
+// UpdateStandingOrderTx edits or resumes a standing order and recomputes its
+// next run. The order keeps its id, so the occurrences it already produced
+// stay linked to it.
+func (store *SQLStore) UpdateStandingOrderTx(ctx context.Context, arg UpdateStandingOrderTxParams) (StandingOrder, error) {
+	var order StandingOrder
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		var err error
+		order, err = q.GetStandingOrderForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if order.Status == StandingOrderCompleted || order.Status == StandingOrderCancelled {
+			return ErrStandingOrderClosed
+		}
+		if changes := arg.Changes; changes != nil {
+			order.Amount = changes.Amount
+			order.Frequency = changes.Frequency
+			order.Weekday = changes.Weekday
+			order.DayOfMonth = changes.DayOfMonth
+			order.EndDate = changes.EndDate
+			order.MaxOccurrences = changes.MaxOccurrences
+		}
+		if arg.Resume {
+			order.Status = StandingOrderActive
+		}
+
+		calendar, err := LoadCalendar(ctx, q, arg.Today)
+		if err != nil {
+			return err
+		}
+		order.ScheduleFrom(arg.Today, calendar)
+		order, err = q.UpdateStandingOrder(ctx, updateStandingOrderParams(order))
+		return err
+	})
+	return order, err
+}
+
+func updateStandingOrderParams(order StandingOrder) UpdateStandingOrderParams {
+	return UpdateStandingOrderParams{
+		ID:              order.ID,
+		Amount:          order.Amount,
+		Frequency:       order.Frequency,
+		Weekday:         order.Weekday,
+		DayOfMonth:      order.DayOfMonth,
+		EndDate:         order.EndDate,
+		MaxOccurrences:  order.MaxOccurrences,
+		Occurrences:     order.Occurrences,
+		NextNominalDate: order.NextNominalDate,
+		NextRunDate:     order.NextRunDate,
+		Status:          order.Status,
+	}
+}
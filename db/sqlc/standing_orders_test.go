@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/schedule"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func createRandomStandingOrder(t *testing.T, from, to Account, startDate time.Time, maxOccurrences int32) StandingOrder {
+	order := StandingOrder{
+		Frequency:      string(schedule.Monthly),
+		DayOfMonth:     sql.NullInt32{Int32: int32(startDate.Day()), Valid: true},
+		StartDate:      startDate,
+		MaxOccurrences: sql.NullInt32{Int32: maxOccurrences, Valid: true},
+	}
+	order.ScheduleFrom(startDate, schedule.NewCalendar())
+
+	created, err := testQueries.CreateStandingOrder(context.Background(), CreateStandingOrderParams{
+		Username:        from.Owner,
+		FromAccountID:   from.ID,
+		ToAccountID:     to.ID,
+		Amount:          10,
+		Currency:        from.Currency,
+		Frequency:       order.Frequency,
+		DayOfMonth:      order.DayOfMonth,
+		StartDate:       order.StartDate,
+		MaxOccurrences:  order.MaxOccurrences,
+		NextNominalDate: order.NextNominalDate,
+		NextRunDate:     order.NextRunDate,
+	})
+	require.NoError(t, err)
+	require.Equal(t, StandingOrderActive, created.Status)
+	require.Zero(t, created.Occurrences)
+	return created
+}
+
+func TestMaterializeStandingOrders(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	// two months ago, so two occurrences are due and the order then completes
+	startDate := schedule.Date(time.Now()).AddDate(0, -2, 0)
+	order := createRandomStandingOrder(t, from, to, startDate, 2)
+
+	_, err := store.MaterializeStandingOrders(context.Background(), time.Now())
+	require.NoError(t, err)
+
+	occurrences, err := testQueries.ListStandingOrderOccurrences(context.Background(), ListStandingOrderOccurrencesParams{
+		StandingOrderID: sql.NullInt64{Int64: order.ID, Valid: true},
+		Limit:           10,
+	})
+	require.NoError(t, err)
+	require.Len(t, occurrences, 2)
+	for _, occurrence := range occurrences {
+		require.Equal(t, ScheduledTransferScheduled, occurrence.Status)
+		require.Equal(t, order.Amount, occurrence.Amount)
+		require.True(t, occurrence.OccurrenceDate.Valid)
+	}
+
+	order, err = testQueries.GetStandingOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), order.Occurrences)
+	require.Equal(t, StandingOrderCompleted, order.Status)
+
+	// running it again doesn't create anything new
+	_, err = store.MaterializeStandingOrders(context.Background(), time.Now())
+	require.NoError(t, err)
+	occurrences, err = testQueries.ListStandingOrderOccurrences(context.Background(), ListStandingOrderOccurrencesParams{
+		StandingOrderID: sql.NullInt64{Int64: order.ID, Valid: true},
+		Limit:           10,
+	})
+	require.NoError(t, err)
+	require.Len(t, occurrences, 2)
+
+	_, err = store.UpdateStandingOrderTx(context.Background(), UpdateStandingOrderTxParams{
+		ID:     order.ID,
+		Resume: true,
+		Today:  time.Now(),
+	})
+	require.ErrorIs(t, err, ErrStandingOrderClosed)
+}
+
+func TestPauseAndResumeStandingOrder(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	startDate := schedule.Date(time.Now()).AddDate(0, 0, 1)
+	order := createRandomStandingOrder(t, from, to, startDate, 12)
+
+	paused, err := testQueries.PauseStandingOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, StandingOrderPaused, paused.Status)
+
+	// paused orders are never materialized
+	_, err = store.MaterializeStandingOrders(context.Background(), startDate.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	paused, err = testQueries.GetStandingOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Zero(t, paused.Occurrences)
+
+	resumed, err := store.UpdateStandingOrderTx(context.Background(), UpdateStandingOrderTxParams{
+		ID: order.ID,
+		Changes: &StandingOrderChanges{
+			Amount:         25,
+			Frequency:      string(schedule.LastBusinessDay),
+			MaxOccurrences: order.MaxOccurrences,
+		},
+		Resume: true,
+		Today:  startDate,
+	})
+	require.NoError(t, err)
+	require.Equal(t, StandingOrderActive, resumed.Status)
+	require.Equal(t, int64(25), resumed.Amount)
+	require.Equal(t, string(schedule.LastBusinessDay), resumed.Frequency)
+	require.Equal(t, order.ID, resumed.ID)
+	require.False(t, resumed.NextRunDate.Before(startDate.AddDate(0, 0, -3)))
+}
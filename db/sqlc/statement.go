@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type GetAccountStatementParams struct {
+	AccountID int64 `json:"account_id"`
+	// The statement covers the entries created from From up to, but not
+	// including, To.
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// StatementEntry is an entry of a statement with the transfer it posts.
+type StatementEntry struct {
+	Entry Entry `json:"entry"`
+	// Transfer is nil for entries that don't post a transfer, and for
+	// entries written before entries recorded their transfer.
+	Transfer *Transfer `json:"transfer,omitempty"`
+	// Fee is set on the entry that charges the fee of Transfer.
+	Fee bool `json:"fee"`
+}
+
+type AccountStatement struct {
+	Account        Account          `json:"account"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	OpeningBalance int64            `json:"opening_balance"`
+	ClosingBalance int64            `json:"closing_balance"`
+	Entries        []StatementEntry `json:"entries"`
+}
+
+// GetAccountStatement returns the entries of an account over a period with
+// its balances at the start and the end of it, worked back from the current
+// balance.
+func GetAccountStatement(ctx context.Context, q Querier, arg GetAccountStatementParams) (AccountStatement, error) {
+	statement := AccountStatement{From: arg.From, To: arg.To}
+	var err error
+	statement.Account, err = q.GetAccounts(ctx, arg.AccountID)
+	if err != nil {
+		return statement, err
+	}
+	entries, err := q.ListEntriesBetween(ctx, ListEntriesBetweenParams{
+		AccountID:   arg.AccountID,
+		CreatedFrom: arg.From,
+		CreatedTo:   arg.To,
+	})
+	if err != nil {
+		return statement, err
+	}
+	since, err := q.GetEntriesTotalSince(ctx, GetEntriesTotalSinceParams{
+		AccountID: arg.AccountID,
+		Since:     arg.To,
+	})
+	if err != nil {
+		return statement, err
+	}
+
+	statement.ClosingBalance = statement.Account.Balance - since
+	statement.OpeningBalance = statement.ClosingBalance
+	var transferIDs []int64
+	for _, entry := range entries {
+		statement.OpeningBalance -= entry.Amount
+		if id, ok := entryTransferID(entry); ok {
+			transferIDs = append(transferIDs, id)
+		}
+	}
+
+	transfers := make(map[int64]*Transfer, len(transferIDs))
+	if len(transferIDs) > 0 {
+		list, err := q.ListTransfersByIDs(ctx, transferIDs)
+		if err != nil {
+			return statement, err
+		}
+		for i := range list {
+			transfers[list[i].ID] = &list[i]
+		}
+	}
+	statement.Entries = make([]StatementEntry, len(entries))
+	for i, entry := range entries {
+		id, _ := entryTransferID(entry)
+		statement.Entries[i] = StatementEntry{
+			Entry:    entry,
+			Transfer: transfers[id],
+			Fee:      entry.FeeTransferID.Valid,
+		}
+	}
+	return statement, nil
+}
+
+// entryTransferID returns the transfer the entry posts, or charges the fee of.
+func entryTransferID(entry Entry) (int64, bool) {
+	if entry.FeeTransferID.Valid {
+		return entry.FeeTransferID.Int64, true
+	}
+	return entry.TransferID.Int64, entry.TransferID.Valid
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestGetAccountStatement(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	start := time.Now().Add(-time.Minute)
+
+	reference := utils.RandomString(10)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:   from.ID,
+		ToAccountID:     to.ID,
+		Amount:          100,
+		TransferDetails: TransferDetails{Memo: "rent", Reference: reference},
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, result.FromEntry.TransferID.Int64)
+	require.Equal(t, result.Transfer.ID, result.ToEntry.TransferID.Int64)
+
+	statement, err := GetAccountStatement(context.Background(), store, GetAccountStatementParams{
+		AccountID: from.ID,
+		From:      start,
+		To:        time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, from.ID, statement.Account.ID)
+	require.Equal(t, int64(1000), statement.OpeningBalance)
+	require.Equal(t, result.FromAccount.Balance, statement.ClosingBalance)
+	require.NotEmpty(t, statement.Entries)
+	require.Equal(t, result.FromEntry.ID, statement.Entries[0].Entry.ID)
+	require.NotNil(t, statement.Entries[0].Transfer)
+	require.Equal(t, reference, statement.Entries[0].Transfer.Reference.String)
+	require.False(t, statement.Entries[0].Fee)
+	for _, entry := range statement.Entries[1:] {
+		// only the fee of the transfer follows it
+		require.True(t, entry.Fee)
+	}
+
+	// the period before the transfer ends where the statement above starts
+	before, err := GetAccountStatement(context.Background(), store, GetAccountStatementParams{
+		AccountID: from.ID,
+		From:      start.Add(-time.Hour),
+		To:        start,
+	})
+	require.NoError(t, err)
+	require.Empty(t, before.Entries)
+	require.Equal(t, int64(1000), before.OpeningBalance)
+	require.Equal(t, statement.OpeningBalance, before.ClosingBalance)
+
+	_, err = GetAccountStatement(context.Background(), store, GetAccountStatementParams{AccountID: -1, From: start, To: time.Now()})
+	require.ErrorIs(t, TranslateError(err), ErrRecordNotFound)
+}
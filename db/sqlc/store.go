@@ -4,45 +4,93 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"tutorial.sqlc.dev/app/utils"
 )
 
 type Store interface {
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	QueueTransferTx(ctx context.Context, arg TransferTxParams) (Transfer, error)
+	ProcessQueuedTransfers(ctx context.Context, limit int) ([]Transfer, error)
+	PayPaymentRequestTx(ctx context.Context, arg PayPaymentRequestTxParams) (PayPaymentRequestTxResult, error)
+	ConfirmTransferTx(ctx context.Context, arg ConfirmTransferTxParams) (ConfirmTransferTxResult, error)
+	FxTransferTx(ctx context.Context, arg FxTransferTxParams) (FxTransferTxResult, error)
+	MultiTransferTx(ctx context.Context, arg MultiTransferTxParams) (MultiTransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	CreateTransferBatchTx(ctx context.Context, arg CreateTransferBatchTxParams) (TransferBatchResult, error)
+	ExecuteTransferBatch(ctx context.Context, batchID int64) (TransferBatchResult, error)
+	CreatePendingTransferTx(ctx context.Context, arg CreatePendingTransferTxParams) (PendingTransfer, error)
+	DecidePendingTransferTx(ctx context.Context, arg DecidePendingTransferTxParams) (DecidePendingTransferTxResult, error)
+	ExpirePendingTransfersTx(ctx context.Context) ([]PendingTransfer, error)
+	RunDueScheduledTransfers(ctx context.Context, arg RunScheduledTransfersParams) ([]ScheduledTransferRun, error)
+	MaterializeStandingOrders(ctx context.Context, today time.Time) ([]ScheduledTransfer, error)
+	UpdateStandingOrderTx(ctx context.Context, arg UpdateStandingOrderTxParams) (StandingOrder, error)
+	CreateOutboundTransferTx(ctx context.Context, arg CreateOutboundTransferTxParams) (OutboundTransferTxResult, error)
+	ExportOutboundPaymentsTx(ctx context.Context, arg ExportOutboundPaymentsTxParams) (ExportOutboundPaymentsTxResult, error)
+	ReturnOutboundPaymentTx(ctx context.Context, arg ReturnOutboundPaymentTxParams) (ReturnOutboundPaymentTxResult, error)
+	Reconcile(ctx context.Context, arg ReconcileParams) (ReconciliationResult, error)
 	Querier
 }
 
 type SQLStore struct {
 	*Queries
-	db *sql.DB
+	db      *pgxpool.Pool
+	options StoreOptions
+}
+
+func NewStore(db *pgxpool.Pool) Store {
+	return NewStoreWithOptions(db, StoreOptions{})
 }
 
-func NewStore(db *sql.DB) Store {
+func NewStoreWithOptions(db *pgxpool.Pool, options StoreOptions) Store {
+	if options.MaxTxRetries == 0 {
+		options.MaxTxRetries = DefaultTxMaxRetries
+	}
 	return &SQLStore{
 		Queries: New(db),
 		db:      db,
+		options: options,
 	}
 }
-func (store *SQLStore) executeTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
 
-	q := New(tx)
-	if err := fn(q); err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
-		}
-		return err
-	}
+// TransferDetails is what the sender says about a transfer.
+type TransferDetails struct {
+	Memo string `json:"memo"`
+	// Reference is chosen by the client and must be unique among the
+	// transfers from the source account. Empty means none.
+	Reference string `json:"reference"`
+	// Category defaults to general.
+	Category string `json:"category"`
+}
 
-	return tx.Commit()
+// transferParams returns the CreateTransferParams of a transfer with the
+// details d.
+func (d TransferDetails) transferParams(fromAccountID int64, toAccountID int64, amount int64, fee int64) CreateTransferParams {
+	category := d.Category
+	if category == "" {
+		category = utils.CategoryGeneral
+	}
+	return CreateTransferParams{
+		FromAccountID: sql.NullInt64{Int64: fromAccountID, Valid: true},
+		ToAccountID:   sql.NullInt64{Int64: toAccountID, Valid: true},
+		Amount:        amount,
+		Fee:           fee,
+		Memo:          d.Memo,
+		Reference:     sql.NullString{String: d.Reference, Valid: d.Reference != ""},
+		Category:      category,
+		Status:        TransferCompleted,
+	}
 }
 
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	TransferDetails
+	// IdempotencyKey, when set, stores the result under the key in the same transaction.
+	IdempotencyKey *IdempotencyKeyParams `json:"-"`
 }
 
 type TransferTxResult struct {
@@ -51,56 +99,169 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// FeeEntry debits the fee from the source account and FeeIncomeEntry
+	// credits it to the bank; both are nil when the transfer is free.
+	FeeEntry       *Entry `json:"fee_entry,omitempty"`
+	FeeIncomeEntry *Entry `json:"fee_income_entry,omitempty"`
+	// JournalEntry holds all the entries above as its postings.
+	JournalEntry JournalEntry `json:"journal_entry"`
 }
 
 var txKey = struct{}{}
 
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
-	err := store.executeTx(ctx, func(q *Queries) error {
+	err := store.executeTx(ctx, TxOptions{Isolation: store.options.TransferIsolation}, func(q *Queries) error {
 		var err error
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: sql.NullInt64{Int64: arg.FromAccountID, Valid: true},
-			ToAccountID:   sql.NullInt64{Int64: arg.ToAccountID, Valid: true},
-			Amount:        arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
+		result, err = transferTx(ctx, q, arg)
+		return err
+	})
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: sql.NullInt64{Int64: arg.FromAccountID, Valid: true},
-			Amount:    -arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: sql.NullInt64{Int64: arg.ToAccountID, Valid: true},
-			Amount:    arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
+	return result, err
+}
 
-		// Update account balances with proper ordering to avoid deadlocks
-		var fromAccount, toAccount Account
-		if arg.FromAccountID < arg.ToAccountID {
-			fromAccount, toAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			toAccount, fromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
-		}
-		if err != nil {
-			return err
-		}
-		result.FromAccount = fromAccount
-		result.ToAccount = toAccount
-		return nil
+// transferTx does the work of TransferTx inside the caller's transaction.
+func transferTx(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	result, err := settleTransfer(ctx, q, arg, func(fee int64) (Transfer, error) {
+		return q.CreateTransfer(ctx, arg.transferParams(arg.FromAccountID, arg.ToAccountID, arg.Amount, fee))
 	})
+	if err != nil {
+		return result, err
+	}
 
+	if arg.IdempotencyKey != nil {
+		err = saveIdempotencyKey(ctx, q, arg.IdempotencyKey, result)
+	}
 	return result, err
 }
 
+// settleTransfer locks the accounts of a transfer, checks that it may go
+// ahead and posts it with its fee. writeTransfer stores the completed
+// transfer once the fee is known.
+func settleTransfer(ctx context.Context, q *Queries, arg TransferTxParams, writeTransfer func(fee int64) (Transfer, error)) (TransferTxResult, error) {
+	var result TransferTxResult
+	fee, feeAccount, err := transferFee(ctx, q, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	if err != nil {
+		return result, err
+	}
+
+	// Lock every account up front, in id order like addMoney, so the
+	// limit check below sees every transfer committed before ours.
+	ids := []int64{arg.FromAccountID, arg.ToAccountID}
+	if fee.Amount > 0 {
+		ids = append(ids, feeAccount.ID)
+	}
+	accounts, err := lockAccounts(ctx, q, ids...)
+	if err != nil {
+		return result, err
+	}
+	if err := checkNotFrozen(accounts[arg.FromAccountID], accounts[arg.ToAccountID]); err != nil {
+		return result, err
+	}
+	if accounts[arg.FromAccountID].Balance < arg.Amount+fee.Amount {
+		return result, ErrInsufficientFunds
+	}
+	if err := checkTransferLimits(ctx, q, accounts[arg.FromAccountID], arg.Amount); err != nil {
+		return result, err
+	}
+
+	transfer, err := writeTransfer(fee.Amount)
+	if err != nil {
+		return result, err
+	}
+	result, err = postTransfer(ctx, q, transfer)
+	if err != nil {
+		return result, err
+	}
+	if fee.Amount > 0 {
+		err = postFee(ctx, q, &result, feeAccount.ID)
+	}
+	return result, err
+}
+
+// postTransfer writes the entries of a transfer, posts them to the journal
+// entry of the transfer and moves the money between the two accounts. The
+// caller must already hold the locks of both accounts.
+func postTransfer(ctx context.Context, q *Queries, transfer Transfer) (TransferTxResult, error) {
+	result := TransferTxResult{Transfer: transfer}
+	fromAccountID := transfer.FromAccountID.Int64
+	toAccountID := transfer.ToAccountID.Int64
+
+	var err error
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.FromAccountID,
+		Amount:     -transfer.Amount,
+		TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+	})
+	if err != nil {
+		return result, err
+	}
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.ToAccountID,
+		Amount:     transfer.Amount,
+		TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+	})
+	if err != nil {
+		return result, err
+	}
+	result.JournalEntry, err = postJournalEntry(ctx, q, CreateJournalEntryParams{
+		TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+	}, result.FromEntry, result.ToEntry)
+	if err != nil {
+		return result, err
+	}
+
+	// Update account balances with proper ordering to avoid deadlocks
+	if fromAccountID < toAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, fromAccountID, -transfer.Amount, toAccountID, transfer.Amount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, toAccountID, transfer.Amount, fromAccountID, -transfer.Amount)
+	}
+	return result, err
+}
+
+// withSavepoint runs fn inside a savepoint of the current transaction and
+// rolls back to it when fn fails, so the transaction can carry on.
+func withSavepoint(ctx context.Context, q *Queries, name string, fn func() error) error {
+	if _, err := q.db.Exec(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		if _, rbErr := q.db.Exec(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("savepoint err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+	_, err := q.db.Exec(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// checkNotFrozen returns ErrAccountFrozen, naming the account, if any of the
+// accounts is frozen.
+func checkNotFrozen(accounts ...Account) error {
+	for _, account := range accounts {
+		if account.Frozen {
+			return fmt.Errorf("account %d: %w", account.ID, ErrAccountFrozen)
+		}
+	}
+	return nil
+}
+
+// lockAccountPair locks two accounts for update, lower id first, and returns
+// them in the order they were passed in.
+func lockAccountPair(ctx context.Context, q *Queries, account1ID int64, account2ID int64) (account1 Account, account2 Account, err error) {
+	if account1ID > account2ID {
+		account2, account1, err = lockAccountPair(ctx, q, account2ID, account1ID)
+		return
+	}
+	account1, err = q.GetAccountForUpdate(ctx, account1ID)
+	if err != nil {
+		return
+	}
+	account2, err = q.GetAccountForUpdate(ctx, account2ID)
+	return
+}
+
 func addMoney(ctx context.Context, q *Queries, account1ID int64, amount1 int64, account2ID int64, amount2 int64) (account1 Account, account2 Account, err error) {
 	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
 		ID:      account1ID,
@@ -2,16 +2,18 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
 )
 
 func TestTranferTx(t *testing.T) {
 	store := NewStore(testDB)
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createAccountInCurrency(t, utils.USD, 1000)
+	account2 := createAccountInCurrency(t, utils.USD, 1000)
 	fmt.Printf(">> before: account1 balance: %d, account2 balance: %d\n", account1.Balance, account2.Balance)
 	n := 10
 	amount := int64(10)
@@ -102,8 +104,8 @@ func TestTranferTx(t *testing.T) {
 
 func TestTranferTxDeadLock(t *testing.T) {
 	store := NewStore(testDB)
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createAccountInCurrency(t, utils.USD, 1000)
+	account2 := createAccountInCurrency(t, utils.USD, 1000)
 	fmt.Printf(">> before: account1 balance: %d, account2 balance: %d\n", account1.Balance, account2.Balance)
 	n := 10
 	amount := int64(10)
@@ -143,3 +145,67 @@ func TestTranferTxDeadLock(t *testing.T) {
 	require.NoError(t, err)
 	fmt.Printf(">> after: account1 balance: %d, account2 balance: %d\n", updatedAccount1.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createAccountInCurrency(t, utils.USD, 50)
+	account2 := createAccountInCurrency(t, utils.USD, 0)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        51,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	got, err := testQueries.GetAccounts(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, got.Balance)
+
+	// the whole balance can be sent
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.FromAccount.Balance)
+}
+
+func TestTransferTxDetails(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createAccountInCurrency(t, utils.USD, 1000)
+	account2 := createAccountInCurrency(t, utils.USD, 1000)
+	reference := "REF-" + utils.RandomString(8)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		TransferDetails: TransferDetails{
+			Memo:      "dinner",
+			Reference: reference,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "dinner", result.Transfer.Memo)
+	require.Equal(t, sql.NullString{String: reference, Valid: true}, result.Transfer.Reference)
+	require.Equal(t, utils.CategoryGeneral, result.Transfer.Category)
+
+	// a reference can only be used once per source account
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:   account1.ID,
+		ToAccountID:     account2.ID,
+		Amount:          10,
+		TransferDetails: TransferDetails{Reference: reference},
+	})
+	require.ErrorIs(t, err, ErrUniqueViolation)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:   account2.ID,
+		ToAccountID:     account1.ID,
+		Amount:          10,
+		TransferDetails: TransferDetails{Reference: reference},
+	})
+	require.NoError(t, err)
+}
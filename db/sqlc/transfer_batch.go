@@ -0,0 +1,286 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Ways to run a transfer batch.
+const (
+	TransferBatchAtomic  = "atomic"
+	TransferBatchPerItem = "per_item"
+)
+
+// Statuses of a transfer batch.
+const (
+	// TransferBatchHeld waits for another user to approve it; the approval
+	// makes it pending, a rejection makes it rejected.
+	TransferBatchHeld               = "held"
+	TransferBatchPending            = "pending"
+	TransferBatchProcessing         = "processing"
+	TransferBatchCompleted          = "completed"
+	TransferBatchPartiallyCompleted = "partially_completed"
+	TransferBatchFailed             = "failed"
+	TransferBatchRejected           = "rejected"
+)
+
+// Statuses of a transfer batch item.
+const (
+	TransferBatchItemPending   = "pending"
+	TransferBatchItemCompleted = "completed"
+	TransferBatchItemFailed    = "failed"
+)
+
+// Failure codes of a transfer batch item, which tell reports why it failed
+// without parsing the error text.
+const (
+	BatchItemInsufficientFunds = "insufficient_funds"
+	BatchItemAccountFrozen     = "account_frozen"
+	BatchItemCurrencyMismatch  = "currency_mismatch"
+	BatchItemAccountNotFound   = "account_not_found"
+	BatchItemOtherFailure      = "other"
+)
+
+var ErrTransferBatchStarted = errors.New("transfer batch has already been started")
+
+type TransferBatchItemParams struct {
+	Line        int32  `json:"line"`
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Reference   string `json:"reference"`
+	// EndToEndID is the id the client gave the item in a pain.001 message.
+	EndToEndID string `json:"end_to_end_id"`
+}
+
+type CreateTransferBatchTxParams struct {
+	Username      string                    `json:"username"`
+	FromAccountID int64                     `json:"from_account_id"`
+	Currency      string                    `json:"currency"`
+	Mode          string                    `json:"mode"`
+	Items         []TransferBatchItemParams `json:"items"`
+	// MessageID and PaymentInformationID identify the pain.001 payment
+	// information block the batch was imported from; a user can't import
+	// the same one twice.
+	MessageID            string `json:"message_id"`
+	PaymentInformationID string `json:"payment_information_id"`
+	// Hold, when set, holds the batch as a whole until another user
+	// approves it.
+	Hold *HoldParams `json:"hold,omitempty"`
+}
+
+type TransferBatchResult struct {
+	Batch TransferBatch       `json:"batch"`
+	Items []TransferBatchItem `json:"items"`
+	// PendingTransfer is the approval a held batch waits for.
+	PendingTransfer *PendingTransfer `json:"pending_transfer,omitempty"`
+}
+
+// CreateTransferBatchTx stores a batch and all of its items as pending, or
+// the batch as held with a pending transfer for it when arg.Hold is set.
+func (store *SQLStore) CreateTransferBatchTx(ctx context.Context, arg CreateTransferBatchTxParams) (TransferBatchResult, error) {
+	var result TransferBatchResult
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		var total int64
+		for _, item := range arg.Items {
+			total += item.Amount
+		}
+
+		status := TransferBatchPending
+		if arg.Hold != nil {
+			status = TransferBatchHeld
+		}
+		var err error
+		result.Batch, err = q.CreateTransferBatch(ctx, CreateTransferBatchParams{
+			Username:      arg.Username,
+			FromAccountID: arg.FromAccountID,
+			Currency:      arg.Currency,
+			Mode:          arg.Mode,
+			ItemCount:     int32(len(arg.Items)),
+			TotalAmount:   total,
+			MessageID:     sql.NullString{String: arg.MessageID, Valid: arg.MessageID != ""},
+			PaymentInformationID: sql.NullString{
+				String: arg.PaymentInformationID,
+				Valid:  arg.MessageID != "",
+			},
+			Status: status,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Items = make([]TransferBatchItem, len(arg.Items))
+		for i, item := range arg.Items {
+			result.Items[i], err = q.CreateTransferBatchItem(ctx, CreateTransferBatchItemParams{
+				BatchID:     result.Batch.ID,
+				Line:        item.Line,
+				ToAccountID: item.ToAccountID,
+				Amount:      item.Amount,
+				Reference:   item.Reference,
+				EndToEndID:  item.EndToEndID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if arg.Hold == nil {
+			return nil
+		}
+		pending, err := holdTransfer(ctx, q, CreatePendingTransferParams{
+			CreatedBy:       arg.Username,
+			FromAccountID:   arg.FromAccountID,
+			Amount:          total,
+			Currency:        arg.Currency,
+			ExpiresAt:       arg.Hold.ExpiresAt,
+			RiskDecisionID:  arg.Hold.RiskDecisionID,
+			Kind:            PendingTransferKindTransferBatch,
+			TransferBatchID: sql.NullInt64{Int64: result.Batch.ID, Valid: true},
+		})
+		result.PendingTransfer = &pending
+		return err
+	})
+	return result, err
+}
+
+// ExecuteTransferBatch runs a pending batch. Atomic batches move all of the
+// money in one transaction or none of it; per item batches run every item in
+// its own transaction and record the outcome of each. A batch that can't be
+// run to the end is marked failed.
+func (store *SQLStore) ExecuteTransferBatch(ctx context.Context, batchID int64) (TransferBatchResult, error) {
+	var result TransferBatchResult
+	batch, err := store.StartTransferBatch(ctx, batchID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return result, ErrTransferBatchStarted
+		}
+		return result, err
+	}
+	status, err := store.executeTransferBatch(ctx, batch)
+	if err != nil {
+		// don't leave the batch stuck in processing; the items keep the
+		// status they got before the error
+		if _, finishErr := store.FinishTransferBatch(context.WithoutCancel(ctx), FinishTransferBatchParams{
+			ID:     batch.ID,
+			Status: TransferBatchFailed,
+		}); finishErr != nil {
+			return result, fmt.Errorf("batch err: %w, finish err: %v", err, finishErr)
+		}
+		return result, err
+	}
+
+	result.Batch, err = store.FinishTransferBatch(ctx, FinishTransferBatchParams{
+		ID:     batch.ID,
+		Status: status,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Items, err = store.ListTransferBatchItems(ctx, batch.ID)
+	return result, err
+}
+
+// executeTransferBatch runs the items of a started batch and returns the
+// status the batch finished with.
+func (store *SQLStore) executeTransferBatch(ctx context.Context, batch TransferBatch) (string, error) {
+	items, err := store.ListTransferBatchItems(ctx, batch.ID)
+	if err != nil {
+		return "", err
+	}
+	if batch.Mode == TransferBatchAtomic {
+		return store.executeBatchAtomically(ctx, batch, items)
+	}
+	return store.executeBatchPerItem(ctx, batch, items)
+}
+
+func (store *SQLStore) executeBatchAtomically(ctx context.Context, batch TransferBatch, items []TransferBatchItem) (string, error) {
+	var failed TransferBatchItem
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		for _, item := range items {
+			if err := transferBatchItem(ctx, q, batch, item); err != nil {
+				failed = item
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		return TransferBatchCompleted, nil
+	}
+	// nothing was moved; record the item that stopped the batch
+	if _, err := store.FailTransferBatchItem(ctx, failTransferBatchItemParams(failed, err)); err != nil {
+		return "", err
+	}
+	return TransferBatchFailed, nil
+}
+
+func (store *SQLStore) executeBatchPerItem(ctx context.Context, batch TransferBatch, items []TransferBatchItem) (string, error) {
+	var completed, failed int
+	for _, item := range items {
+		err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+			return transferBatchItem(ctx, q, batch, item)
+		})
+		if err == nil {
+			completed++
+			continue
+		}
+		failed++
+		if _, err := store.FailTransferBatchItem(ctx, failTransferBatchItemParams(item, err)); err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return TransferBatchCompleted, nil
+	case completed == 0:
+		return TransferBatchFailed, nil
+	}
+	return TransferBatchPartiallyCompleted, nil
+}
+
+// failTransferBatchItemParams records err as the reason item failed.
+func failTransferBatchItemParams(item TransferBatchItem, err error) FailTransferBatchItemParams {
+	return FailTransferBatchItemParams{
+		ID:          item.ID,
+		Reason:      err.Error(),
+		FailureCode: batchItemFailureCode(err),
+	}
+}
+
+// batchItemFailureCode returns the failure code of an error a batch item
+// failed with.
+func batchItemFailureCode(err error) string {
+	err = TranslateError(err)
+	switch {
+	case errors.Is(err, ErrInsufficientFunds):
+		return BatchItemInsufficientFunds
+	case errors.Is(err, ErrAccountFrozen):
+		return BatchItemAccountFrozen
+	case errors.Is(err, ErrCurrencyMismatch):
+		return BatchItemCurrencyMismatch
+	case errors.Is(err, ErrRecordNotFound), errors.Is(err, ErrForeignKeyViolation):
+		return BatchItemAccountNotFound
+	}
+	return BatchItemOtherFailure
+}
+
+// transferBatchItem moves the money of one item and marks it completed.
+func transferBatchItem(ctx context.Context, q *Queries, batch TransferBatch, item TransferBatchItem) error {
+	result, err := transferTx(ctx, q, TransferTxParams{
+		FromAccountID: batch.FromAccountID,
+		ToAccountID:   item.ToAccountID,
+		Amount:        item.Amount,
+		// the item's reference is what the recipient sees
+		TransferDetails: TransferDetails{Memo: item.Reference},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = q.CompleteTransferBatchItem(ctx, CompleteTransferBatchItemParams{
+		ID:         item.ID,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+	return err
+}
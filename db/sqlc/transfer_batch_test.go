@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func createRandomTransferBatch(t *testing.T, store Store, from Account, mode string, amounts ...int64) TransferBatchResult {
+	arg := CreateTransferBatchTxParams{
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		Currency:      from.Currency,
+		Mode:          mode,
+	}
+	for i, amount := range amounts {
+		to := createAccountInCurrency(t, from.Currency, 0)
+		arg.Items = append(arg.Items, TransferBatchItemParams{
+			Line:        int32(i + 1),
+			ToAccountID: to.ID,
+			Amount:      amount,
+			Reference:   utils.RandomString(8),
+		})
+	}
+	result, err := store.CreateTransferBatchTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchPending, result.Batch.Status)
+	require.Equal(t, int32(len(amounts)), result.Batch.ItemCount)
+	require.Len(t, result.Items, len(amounts))
+	return result
+}
+
+func TestExecuteTransferBatchAtomic(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 100)
+
+	ok := createRandomTransferBatch(t, store, from, TransferBatchAtomic, 30, 20)
+	result, err := store.ExecuteTransferBatch(context.Background(), ok.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchCompleted, result.Batch.Status)
+	require.True(t, result.Batch.CompletedAt.Valid)
+	for _, item := range result.Items {
+		require.Equal(t, TransferBatchItemCompleted, item.Status)
+		require.True(t, item.TransferID.Valid)
+	}
+
+	// the second item can't be covered, so nothing moves
+	failing := createRandomTransferBatch(t, store, from, TransferBatchAtomic, 10, 60)
+	result, err = store.ExecuteTransferBatch(context.Background(), failing.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchFailed, result.Batch.Status)
+	require.Equal(t, TransferBatchItemPending, result.Items[0].Status)
+	require.Equal(t, TransferBatchItemFailed, result.Items[1].Status)
+	require.Equal(t, ErrInsufficientFunds.Error(), result.Items[1].Reason)
+	require.Equal(t, BatchItemInsufficientFunds, result.Items[1].FailureCode)
+
+	account, err := testQueries.GetAccounts(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), account.Balance)
+
+	_, err = store.ExecuteTransferBatch(context.Background(), failing.Batch.ID)
+	require.ErrorIs(t, err, ErrTransferBatchStarted)
+}
+
+func TestExecuteTransferBatchPerItem(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 100)
+
+	batch := createRandomTransferBatch(t, store, from, TransferBatchPerItem, 60, 60, 40)
+	result, err := store.ExecuteTransferBatch(context.Background(), batch.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchPartiallyCompleted, result.Batch.Status)
+	require.Equal(t, TransferBatchItemCompleted, result.Items[0].Status)
+	require.Equal(t, TransferBatchItemFailed, result.Items[1].Status)
+	require.Equal(t, TransferBatchItemCompleted, result.Items[2].Status)
+
+	account, err := testQueries.GetAccounts(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.Balance)
+}
+
+// failingQueryDB fails every run of the named query.
+type failingQueryDB struct {
+	DBTX
+	query string
+}
+
+var errQueryFailed = errors.New("query failed")
+
+func (db failingQueryDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	if strings.HasPrefix(sql, "-- name: "+db.query+" ") {
+		return nil, errQueryFailed
+	}
+	return db.DBTX.Query(ctx, sql, args...)
+}
+
+func TestExecuteTransferBatchError(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 100)
+	batch := createRandomTransferBatch(t, store, from, TransferBatchPerItem, 30)
+
+	failing := NewStore(testDB).(*SQLStore)
+	failing.Queries = New(failingQueryDB{DBTX: testDB, query: "ListTransferBatchItems"})
+	_, err := failing.ExecuteTransferBatch(context.Background(), batch.Batch.ID)
+	require.ErrorIs(t, err, errQueryFailed)
+
+	// the batch isn't left in processing
+	got, err := testQueries.GetTransferBatch(context.Background(), batch.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchFailed, got.Status)
+
+	account, err := testQueries.GetAccounts(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, account.Balance)
+}
+
+func TestCreateTransferBatchFromPain001(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 100)
+	to := createAccountInCurrency(t, utils.USD, 0)
+
+	arg := CreateTransferBatchTxParams{
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		Currency:      from.Currency,
+		Mode:          TransferBatchPerItem,
+		Items: []TransferBatchItemParams{
+			{Line: 1, ToAccountID: to.ID, Amount: 10, Reference: "Invoice 77", EndToEndID: "INV-77"},
+		},
+		MessageID:            utils.RandomString(12),
+		PaymentInformationID: "SUPPLIERS",
+	}
+	result, err := store.CreateTransferBatchTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.MessageID, result.Batch.MessageID.String)
+	require.Equal(t, arg.PaymentInformationID, result.Batch.PaymentInformationID.String)
+	require.Equal(t, "INV-77", result.Items[0].EndToEndID)
+
+	// a payment information block can only be imported once
+	_, err = store.CreateTransferBatchTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrUniqueViolation)
+
+	arg.PaymentInformationID = "PAYROLL"
+	_, err = store.CreateTransferBatchTx(context.Background(), arg)
+	require.NoError(t, err)
+}
+
+func TestBatchItemFailureCode(t *testing.T) {
+	require.Equal(t, BatchItemInsufficientFunds, batchItemFailureCode(ErrInsufficientFunds))
+	require.Equal(t, BatchItemAccountFrozen, batchItemFailureCode(fmt.Errorf("account 7: %w", ErrAccountFrozen)))
+	require.Equal(t, BatchItemCurrencyMismatch, batchItemFailureCode(ErrCurrencyMismatch))
+	require.Equal(t, BatchItemAccountNotFound, batchItemFailureCode(sql.ErrNoRows))
+	require.Equal(t, BatchItemOtherFailure, batchItemFailureCode(errors.New("disk full")))
+}
+
+func TestCreateTransferBatchTxHold(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 100)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	checker := createRandomUser(t)
+
+	hold := func() TransferBatchResult {
+		result, err := store.CreateTransferBatchTx(context.Background(), CreateTransferBatchTxParams{
+			Username:      from.Owner,
+			FromAccountID: from.ID,
+			Currency:      from.Currency,
+			Mode:          TransferBatchPerItem,
+			Items: []TransferBatchItemParams{
+				{Line: 1, ToAccountID: to.ID, Amount: 10},
+				{Line: 2, ToAccountID: to.ID, Amount: 20},
+			},
+			Hold: &HoldParams{ExpiresAt: time.Now().Add(time.Hour)},
+		})
+		require.NoError(t, err)
+		require.Equal(t, TransferBatchHeld, result.Batch.Status)
+		require.NotNil(t, result.PendingTransfer)
+		require.Equal(t, PendingTransferKindTransferBatch, result.PendingTransfer.Kind)
+		require.Equal(t, int64(30), result.PendingTransfer.Amount)
+		require.False(t, result.PendingTransfer.ToAccountID.Valid)
+		return result
+	}
+
+	rejected := hold()
+	_, err := store.DecidePendingTransferTx(context.Background(), DecidePendingTransferTxParams{
+		ID:       rejected.PendingTransfer.ID,
+		Username: checker.Username,
+		Decision: DecisionRejected,
+	})
+	require.NoError(t, err)
+	batch, err := store.GetTransferBatch(context.Background(), rejected.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchRejected, batch.Status)
+
+	approved := hold()
+	result, err := store.DecidePendingTransferTx(context.Background(), DecidePendingTransferTxParams{
+		ID:       approved.PendingTransfer.ID,
+		Username: checker.Username,
+		Decision: DecisionApproved,
+	})
+	require.NoError(t, err)
+	require.Nil(t, result.Transfer)
+	require.NotNil(t, result.Batch)
+	require.Equal(t, TransferBatchCompleted, result.Batch.Batch.Status)
+
+	account, err := store.GetAccounts(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(70), account.Balance)
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrTransferConfirmationUsed    = errors.New("transfer has already been confirmed")
+	ErrTransferConfirmationExpired = errors.New("transfer confirmation has expired")
+)
+
+type ConfirmTransferTxParams struct {
+	ID int64 `json:"id"`
+	// Username must be the user who asked for the confirmation.
+	Username string `json:"username"`
+	// Hold, when set, holds the transfer until another user approves it
+	// instead of running it.
+	Hold *HoldParams `json:"hold,omitempty"`
+}
+
+type ConfirmTransferTxResult struct {
+	TransferTxResult
+	Confirmation TransferConfirmation `json:"confirmation"`
+	// PendingTransfer is set instead of the transfer when it was held.
+	PendingTransfer *PendingTransfer `json:"pending_transfer,omitempty"`
+}
+
+// ConfirmTransferTx runs, or holds, the transfer a sender asked for by alias
+// once they have seen who it resolved to. The confirmation is locked first
+// and linked to the transfer or the pending transfer in the same
+// transaction, so it can only be used once.
+func (store *SQLStore) ConfirmTransferTx(ctx context.Context, arg ConfirmTransferTxParams) (ConfirmTransferTxResult, error) {
+	var result ConfirmTransferTxResult
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		confirmation, err := q.GetTransferConfirmationForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if confirmation.Username != arg.Username {
+			// don't tell other users which ids exist
+			return ErrRecordNotFound
+		}
+		if confirmation.TransferID.Valid || confirmation.PendingTransferID.Valid {
+			return ErrTransferConfirmationUsed
+		}
+		if !time.Now().Before(confirmation.ExpiresAt) {
+			return ErrTransferConfirmationExpired
+		}
+
+		if arg.Hold != nil {
+			pending, err := holdTransfer(ctx, q, CreatePendingTransferParams{
+				CreatedBy:      confirmation.Username,
+				FromAccountID:  confirmation.FromAccountID,
+				ToAccountID:    sql.NullInt64{Int64: confirmation.ToAccountID, Valid: true},
+				Amount:         confirmation.Amount,
+				Currency:       confirmation.Currency,
+				ExpiresAt:      arg.Hold.ExpiresAt,
+				Memo:           confirmation.Memo,
+				Reference:      confirmation.Reference,
+				Category:       confirmation.Category,
+				RiskDecisionID: arg.Hold.RiskDecisionID,
+			})
+			if err != nil {
+				return err
+			}
+			result.PendingTransfer = &pending
+			result.Confirmation, err = q.SetTransferConfirmationPendingTransfer(ctx, SetTransferConfirmationPendingTransferParams{
+				ID:                confirmation.ID,
+				PendingTransferID: sql.NullInt64{Int64: pending.ID, Valid: true},
+			})
+			return err
+		}
+
+		result.TransferTxResult, err = transferTx(ctx, q, TransferTxParams{
+			FromAccountID: confirmation.FromAccountID,
+			ToAccountID:   confirmation.ToAccountID,
+			Amount:        confirmation.Amount,
+			TransferDetails: TransferDetails{
+				Memo:      confirmation.Memo,
+				Reference: confirmation.Reference.String,
+				Category:  confirmation.Category,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Confirmation, err = q.SetTransferConfirmationTransfer(ctx, SetTransferConfirmationTransferParams{
+			ID:         confirmation.ID,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+	return result, err
+}
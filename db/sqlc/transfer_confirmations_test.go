@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func createTestTransferConfirmation(t *testing.T, from Account, to Account, expiresAt time.Time) TransferConfirmation {
+	confirmation, err := testQueries.CreateTransferConfirmation(context.Background(), CreateTransferConfirmationParams{
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Alias:         to.Owner,
+		Amount:        100,
+		Currency:      from.Currency,
+		Memo:          "books",
+		Category:      utils.CategoryShopping,
+		ExpiresAt:     expiresAt,
+	})
+	require.NoError(t, err)
+	require.False(t, confirmation.TransferID.Valid)
+	return confirmation
+}
+
+func TestConfirmTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	confirmation := createTestTransferConfirmation(t, from, to, time.Now().Add(time.Minute))
+
+	// only the sender can confirm
+	_, err := store.ConfirmTransferTx(context.Background(), ConfirmTransferTxParams{
+		ID:       confirmation.ID,
+		Username: to.Owner,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	result, err := store.ConfirmTransferTx(context.Background(), ConfirmTransferTxParams{
+		ID:       confirmation.ID,
+		Username: from.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, result.Confirmation.TransferID.Int64)
+	require.Equal(t, to.ID, result.Transfer.ToAccountID.Int64)
+	require.Equal(t, "books", result.Transfer.Memo)
+	require.Equal(t, utils.CategoryShopping, result.Transfer.Category)
+	require.Equal(t, int64(900), result.FromAccount.Balance)
+
+	_, err = store.ConfirmTransferTx(context.Background(), ConfirmTransferTxParams{
+		ID:       confirmation.ID,
+		Username: from.Owner,
+	})
+	require.ErrorIs(t, err, ErrTransferConfirmationUsed)
+}
+
+func TestConfirmTransferTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	confirmation := createTestTransferConfirmation(t, from, to, time.Now().Add(-time.Second))
+
+	_, err := store.ConfirmTransferTx(context.Background(), ConfirmTransferTxParams{
+		ID:       confirmation.ID,
+		Username: from.Owner,
+	})
+	require.ErrorIs(t, err, ErrTransferConfirmationExpired)
+}
+
+func TestConfirmTransferTxHold(t *testing.T) {
+	store := NewStore(testDB)
+	from := createAccountInCurrency(t, utils.USD, 1000)
+	to := createAccountInCurrency(t, utils.USD, 0)
+	confirmation := createTestTransferConfirmation(t, from, to, time.Now().Add(time.Minute))
+
+	arg := ConfirmTransferTxParams{
+		ID:       confirmation.ID,
+		Username: from.Owner,
+		Hold:     &HoldParams{ExpiresAt: time.Now().Add(time.Hour)},
+	}
+	result, err := store.ConfirmTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotNil(t, result.PendingTransfer)
+	require.Equal(t, result.PendingTransfer.ID, result.Confirmation.PendingTransferID.Int64)
+	require.Equal(t, to.ID, result.PendingTransfer.ToAccountID.Int64)
+	require.Equal(t, "books", result.PendingTransfer.Memo)
+
+	// holding uses the confirmation up
+	_, err = store.ConfirmTransferTx(context.Background(), ConfirmTransferTxParams{
+		ID:       confirmation.ID,
+		Username: from.Owner,
+	})
+	require.ErrorIs(t, err, ErrTransferConfirmationUsed)
+
+	account, err := store.GetAccounts(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, account.Balance)
+}
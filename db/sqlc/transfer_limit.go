@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Names of the limits a transfer can hit, as reported in LimitExceededError.
+const (
+	LimitMaxSingleAmount = "max_single_amount"
+	LimitMaxDailyAmount  = "max_daily_amount"
+	LimitMaxDailyCount   = "max_daily_count"
+)
+
+// LimitExceededError is returned by TransferTx when a transfer would break
+// one of the limits configured for the source account.
+type LimitExceededError struct {
+	Limit     string `json:"limit"`
+	Currency  string `json:"currency"`
+	Max       int64  `json:"max"`
+	Available int64  `json:"available"`
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("transfer limit %s exceeded: max %d %s, available %d", e.Limit, e.Max, e.Currency, e.Available)
+}
+
+// DailyUsage is what an account already sent out since the start of the day.
+type DailyUsage struct {
+	Amount int64
+	Count  int64
+}
+
+// startOfDay returns the beginning of the UTC day t falls in; daily limits reset then.
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// checkTransferLimits looks up the limit that applies to the account (its own
+// limit first, then the one of its owner's tier) and checks amount against it
+// and against what the account sent today; reversals of transfers it received
+// and the FX and fee legs don't count. The caller must hold the account lock
+// so concurrent transfers can't both see the same usage.
+func checkTransferLimits(ctx context.Context, q *Queries, account Account, amount int64) error {
+	owner, err := q.GetUsers(ctx, account.Owner)
+	if err != nil {
+		return err
+	}
+	limit, err := q.GetTransferLimit(ctx, GetTransferLimitParams{
+		Currency:  account.Currency,
+		AccountID: account.ID,
+		Tier:      owner.Tier,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	usage, err := q.GetDailyDebitUsage(ctx, GetDailyDebitUsageParams{
+		AccountID: account.ID,
+		Since:     startOfDay(time.Now()),
+	})
+	if err != nil {
+		return err
+	}
+	return limit.Check(amount, DailyUsage{Amount: usage.TotalAmount, Count: usage.DebitCount})
+}
+
+// Check returns a *LimitExceededError if sending amount on top of usage
+// would break the limit.
+func (limit TransferLimit) Check(amount int64, usage DailyUsage) error {
+	if limit.MaxSingleAmount.Valid && amount > limit.MaxSingleAmount.Int64 {
+		return &LimitExceededError{
+			Limit:     LimitMaxSingleAmount,
+			Currency:  limit.Currency,
+			Max:       limit.MaxSingleAmount.Int64,
+			Available: limit.MaxSingleAmount.Int64,
+		}
+	}
+	if limit.MaxDailyCount.Valid && usage.Count+1 > limit.MaxDailyCount.Int64 {
+		return &LimitExceededError{
+			Limit:     LimitMaxDailyCount,
+			Currency:  limit.Currency,
+			Max:       limit.MaxDailyCount.Int64,
+			Available: max(limit.MaxDailyCount.Int64-usage.Count, 0),
+		}
+	}
+	if limit.MaxDailyAmount.Valid && usage.Amount+amount > limit.MaxDailyAmount.Int64 {
+		return &LimitExceededError{
+			Limit:     LimitMaxDailyAmount,
+			Currency:  limit.Currency,
+			Max:       limit.MaxDailyAmount.Int64,
+			Available: max(limit.MaxDailyAmount.Int64-usage.Amount, 0),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestTransferLimitCheck(t *testing.T) {
+	limit := TransferLimit{
+		Currency:        "USD",
+		MaxSingleAmount: sql.NullInt64{Int64: 100, Valid: true},
+		MaxDailyAmount:  sql.NullInt64{Int64: 250, Valid: true},
+		MaxDailyCount:   sql.NullInt64{Int64: 3, Valid: true},
+	}
+
+	require.NoError(t, limit.Check(100, DailyUsage{}))
+	require.NoError(t, limit.Check(50, DailyUsage{Amount: 200, Count: 2}))
+
+	testCases := []struct {
+		name      string
+		amount    int64
+		usage     DailyUsage
+		limit     string
+		available int64
+	}{
+		{"SingleAmount", 101, DailyUsage{}, LimitMaxSingleAmount, 100},
+		{"DailyCount", 10, DailyUsage{Amount: 20, Count: 3}, LimitMaxDailyCount, 0},
+		{"DailyAmount", 60, DailyUsage{Amount: 200, Count: 2}, LimitMaxDailyAmount, 50},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := limit.Check(tc.amount, tc.usage)
+			var limitErr *LimitExceededError
+			require.ErrorAs(t, err, &limitErr)
+			require.Equal(t, tc.limit, limitErr.Limit)
+			require.Equal(t, tc.available, limitErr.Available)
+			require.Equal(t, "USD", limitErr.Currency)
+		})
+	}
+
+	require.NoError(t, TransferLimit{Currency: "USD"}.Check(1_000_000, DailyUsage{Count: 1000}))
+}
+
+func TestTransferTxDailyLimit(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createAccountInCurrency(t, utils.USD, 1000)
+	account2 := createAccountInCurrency(t, utils.USD, 1000)
+
+	_, err := testQueries.CreateTransferLimit(context.Background(), CreateTransferLimitParams{
+		AccountID:      sql.NullInt64{Int64: account1.ID, Valid: true},
+		Currency:       account1.Currency,
+		MaxDailyAmount: sql.NullInt64{Int64: 15, Valid: true},
+	})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	}
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), arg)
+	var limitErr *LimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitMaxDailyAmount, limitErr.Limit)
+	require.Equal(t, int64(5), limitErr.Available)
+
+	updatedAccount1, err := store.GetAccounts(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-10, updatedAccount1.Balance)
+}
+
+func TestTransferTxDailyLimitIgnoresReversals(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createAccountInCurrency(t, utils.USD, 100)
+	account2 := createAccountInCurrency(t, utils.USD, 1000)
+
+	_, err := testQueries.CreateTransferLimit(context.Background(), CreateTransferLimitParams{
+		AccountID:      sql.NullInt64{Int64: account1.ID, Valid: true},
+		Currency:       account1.Currency,
+		MaxDailyAmount: sql.NullInt64{Int64: 15, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// account1 receives a transfer and sends it back as a reversal
+	received, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        15,
+	})
+	require.NoError(t, err)
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: received.Transfer.ID})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        15,
+	})
+	require.NoError(t, err)
+
+	// the reversal didn't use up the limit of account1
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        15,
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Statuses of a transfer. Synchronous transfers are created completed;
+// queued ones start pending and are settled by ProcessQueuedTransfers.
+const (
+	TransferPending    = "pending"
+	TransferProcessing = "processing"
+	TransferCompleted  = "completed"
+	TransferFailed     = "failed"
+	TransferReversed   = "reversed"
+)
+
+// transferTransitions lists the statuses a transfer in each status can move
+// to. Failed and reversed transfers are final.
+var transferTransitions = map[string][]string{
+	TransferPending:    {TransferProcessing, TransferFailed},
+	TransferProcessing: {TransferCompleted, TransferFailed},
+	TransferCompleted:  {TransferReversed},
+}
+
+var (
+	ErrInvalidTransferTransition = errors.New("invalid transfer status transition")
+	ErrTransferNotCompleted      = errors.New("only completed transfers can be reversed")
+)
+
+// CanTransitionTransfer reports whether a transfer may move from one status
+// to another.
+func CanTransitionTransfer(from string, to string) bool {
+	for _, status := range transferTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionTransfer moves transfer to status. The update only applies while
+// the row still has the status transfer was read with, so a concurrent change
+// can't be overwritten.
+func transitionTransfer(ctx context.Context, q *Queries, transfer Transfer, status string, reason string) (Transfer, error) {
+	if !CanTransitionTransfer(transfer.Status, status) {
+		return transfer, fmt.Errorf("%w: %s to %s", ErrInvalidTransferTransition, transfer.Status, status)
+	}
+	updated, err := q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+		ID:            transfer.ID,
+		FromStatus:    transfer.Status,
+		Status:        status,
+		FailureReason: reason,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return transfer, fmt.Errorf("%w: transfer %d is no longer %s", ErrInvalidTransferTransition, transfer.ID, transfer.Status)
+	}
+	return updated, err
+}
+
+// QueueTransferTx records a pending transfer for ProcessQueuedTransfers to
+// settle later. Nothing is checked or posted until then.
+func (store *SQLStore) QueueTransferTx(ctx context.Context, arg TransferTxParams) (Transfer, error) {
+	var transfer Transfer
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		params := arg.transferParams(arg.FromAccountID, arg.ToAccountID, arg.Amount, 0)
+		params.Status = TransferPending
+		var err error
+		transfer, err = q.CreateTransfer(ctx, params)
+		if err != nil {
+			return err
+		}
+		if arg.IdempotencyKey != nil {
+			return saveIdempotencyKey(ctx, q, arg.IdempotencyKey, transfer)
+		}
+		return nil
+	})
+	return transfer, err
+}
+
+// ProcessQueuedTransfers settles up to limit pending transfers, oldest first,
+// one transaction each, and returns them in their final status. Transfers
+// that can't go ahead are marked failed with the reason. Rows are claimed
+// with FOR UPDATE SKIP LOCKED, so several workers can run it at once.
+func (store *SQLStore) ProcessQueuedTransfers(ctx context.Context, limit int) ([]Transfer, error) {
+	var processed []Transfer
+	for len(processed) < limit {
+		transfer, err := store.processNextQueuedTransfer(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return processed, err
+		}
+		processed = append(processed, transfer)
+	}
+	return processed, nil
+}
+
+func (store *SQLStore) processNextQueuedTransfer(ctx context.Context) (Transfer, error) {
+	var processed Transfer
+	err := store.executeTx(ctx, TxOptions{}, func(q *Queries) error {
+		queued, err := q.ClaimQueuedTransfer(ctx)
+		if err != nil {
+			return err
+		}
+		processing, err := transitionTransfer(ctx, q, queued, TransferProcessing, "")
+		if err != nil {
+			return err
+		}
+
+		// Settling gets its own savepoint so a failure can still be
+		// recorded against the transfer.
+		var result TransferTxResult
+		settleErr := withSavepoint(ctx, q, "queued_transfer", func() error {
+			var err error
+			result, err = settleTransfer(ctx, q, TransferTxParams{
+				FromAccountID: processing.FromAccountID.Int64,
+				ToAccountID:   processing.ToAccountID.Int64,
+				Amount:        processing.Amount,
+			}, func(fee int64) (Transfer, error) {
+				transfer, err := q.SetTransferFee(ctx, SetTransferFeeParams{ID: processing.ID, Fee: fee})
+				if err != nil {
+					return transfer, err
+				}
+				return transitionTransfer(ctx, q, transfer, TransferCompleted, "")
+			})
+			return err
+		})
+		if settleErr != nil {
+			processed, err = transitionTransfer(ctx, q, processing, TransferFailed, settleErr.Error())
+			return err
+		}
+		processed = result.Transfer
+		return nil
+	})
+	return processed, err
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestCanTransitionTransfer(t *testing.T) {
+	require.True(t, CanTransitionTransfer(TransferPending, TransferProcessing))
+	require.True(t, CanTransitionTransfer(TransferPending, TransferFailed))
+	require.True(t, CanTransitionTransfer(TransferProcessing, TransferCompleted))
+	require.True(t, CanTransitionTransfer(TransferProcessing, TransferFailed))
+	require.True(t, CanTransitionTransfer(TransferCompleted, TransferReversed))
+
+	require.False(t, CanTransitionTransfer(TransferPending, TransferCompleted))
+	require.False(t, CanTransitionTransfer(TransferCompleted, TransferFailed))
+	require.False(t, CanTransitionTransfer(TransferFailed, TransferProcessing))
+	require.False(t, CanTransitionTransfer(TransferReversed, TransferCompleted))
+}
+
+// processQueuedTransfer runs the queue until transfer has been settled and
+// returns it in its final status.
+func processQueuedTransfer(t *testing.T, store *SQLStore, transfer Transfer) Transfer {
+	_, err := store.ProcessQueuedTransfers(context.Background(), 1000)
+	require.NoError(t, err)
+	settled, err := testQueries.GetTransfer(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	return settled
+}
+
+func TestQueueTransferTx(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	queued, err := store.QueueTransferTx(context.Background(), TransferTxParams{
+		FromAccountID:   account1.ID,
+		ToAccountID:     account2.ID,
+		Amount:          10,
+		TransferDetails: TransferDetails{Memo: "queued"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferPending, queued.Status)
+	require.Equal(t, "queued", queued.Memo)
+
+	// nothing moves until the transfer is settled
+	got, err := testQueries.GetAccounts(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, got.Balance)
+
+	settled := processQueuedTransfer(t, store, queued)
+	require.Equal(t, TransferCompleted, settled.Status)
+	require.Empty(t, settled.FailureReason)
+
+	got, err = testQueries.GetAccounts(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-10, got.Balance)
+	got, err = testQueries.GetAccounts(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+10, got.Balance)
+
+	// a settled transfer isn't picked up again
+	require.Equal(t, settled, processQueuedTransfer(t, store, settled))
+}
+
+func TestQueueTransferTxFails(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	queued, err := store.QueueTransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.NoError(t, err)
+
+	settled := processQueuedTransfer(t, store, queued)
+	require.Equal(t, TransferFailed, settled.Status)
+	require.Equal(t, ErrInsufficientFunds.Error(), settled.FailureReason)
+
+	got, err := testQueries.GetAccounts(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, got.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: queued.ID})
+	require.ErrorIs(t, err, ErrTransferNotCompleted)
+}
+
+func TestReverseTransferTxMarksReversed(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createAccountInCurrency(t, utils.USD, 1000)
+	account2 := createAccountInCurrency(t, utils.USD, 0)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferCompleted, result.Transfer.Status)
+
+	reversal, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: result.Transfer.ID,
+		Amount:     4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferCompleted, reversal.ReversalOf.Status)
+
+	reversal, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: result.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, TransferReversed, reversal.ReversalOf.Status)
+	require.Zero(t, reversal.Remaining)
+}
@@ -10,24 +10,116 @@ import (
 	"database/sql"
 )
 
+const claimQueuedTransfer = `-- name: ClaimQueuedTransfer :one
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of_transfer_id, fee, memo, reference, category, status, failure_reason, status_changed_at FROM transfers
+WHERE status = 'pending'
+ORDER BY id
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ClaimQueuedTransfer(ctx context.Context) (Transfer, error) {
+	row := q.db.QueryRow(ctx, claimQueuedTransfer)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOfTransferID,
+		&i.Fee,
+		&i.Memo,
+		&i.Reference,
+		&i.Category,
+		&i.Status,
+		&i.FailureReason,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const createReversalTransfer = `-- name: CreateReversalTransfer :one
+INSERT INTO transfers (
+  from_account_id,
+  to_account_id,
+  amount,
+  reversal_of_transfer_id
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of_transfer_id, fee, memo, reference, category, status, failure_reason, status_changed_at
+`
+
+type CreateReversalTransferParams struct {
+	FromAccountID        sql.NullInt64 `json:"from_account_id"`
+	ToAccountID          sql.NullInt64 `json:"to_account_id"`
+	Amount               int64         `json:"amount"`
+	ReversalOfTransferID sql.NullInt64 `json:"reversal_of_transfer_id"`
+}
+
+func (q *Queries) CreateReversalTransfer(ctx context.Context, arg CreateReversalTransferParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, createReversalTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ReversalOfTransferID,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOfTransferID,
+		&i.Fee,
+		&i.Memo,
+		&i.Reference,
+		&i.Category,
+		&i.Status,
+		&i.FailureReason,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  fee,
+  memo,
+  reference,
+  category,
+  status
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of_transfer_id, fee, memo, reference, category, status, failure_reason, status_changed_at
 `
 
 type CreateTransferParams struct {
-	FromAccountID sql.NullInt64 `json:"from_account_id"`
-	ToAccountID   sql.NullInt64 `json:"to_account_id"`
-	Amount        int64         `json:"amount"`
+	FromAccountID sql.NullInt64  `json:"from_account_id"`
+	ToAccountID   sql.NullInt64  `json:"to_account_id"`
+	Amount        int64          `json:"amount"`
+	Fee           int64          `json:"fee"`
+	Memo          string         `json:"memo"`
+	Reference     sql.NullString `json:"reference"`
+	Category      string         `json:"category"`
+	Status        string         `json:"status"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Fee,
+		arg.Memo,
+		arg.Reference,
+		arg.Category,
+		arg.Status,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -35,17 +127,65 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOfTransferID,
+		&i.Fee,
+		&i.Memo,
+		&i.Reference,
+		&i.Category,
+		&i.Status,
+		&i.FailureReason,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
 
+const getReversedAmount = `-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS reversed_amount
+FROM transfers
+WHERE reversal_of_transfer_id = $1::bigint
+`
+
+func (q *Queries) GetReversedAmount(ctx context.Context, transferID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, getReversedAmount, transferID)
+	var reversedAmount int64
+	err := row.Scan(&reversedAmount)
+	return reversedAmount, err
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of_transfer_id, fee, memo, reference, category, status, failure_reason, status_changed_at FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, getTransfer, id)
+	row := q.db.QueryRow(ctx, getTransfer, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOfTransferID,
+		&i.Fee,
+		&i.Memo,
+		&i.Reference,
+		&i.Category,
+		&i.Status,
+		&i.FailureReason,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of_transfer_id, fee, memo, reference, category, status, failure_reason, status_changed_at FROM transfers
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRow(ctx, getTransferForUpdate, id)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -53,12 +193,20 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOfTransferID,
+		&i.Fee,
+		&i.Memo,
+		&i.Reference,
+		&i.Category,
+		&i.Status,
+		&i.FailureReason,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of_transfer_id, fee, memo, reference, category, status, failure_reason, status_changed_at FROM transfers
 WHERE 
     from_account_id = $1 OR
     to_account_id = $2
@@ -75,7 +223,7 @@ type ListTransfersParams struct {
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
-	rows, err := q.db.QueryContext(ctx, listTransfers,
+	rows, err := q.db.Query(ctx, listTransfers,
 		arg.FromAccountID,
 		arg.ToAccountID,
 		arg.Limit,
@@ -94,16 +242,215 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ReversalOfTransferID,
+			&i.Fee,
+			&i.Memo,
+			&i.Reference,
+			&i.Category,
+			&i.Status,
+			&i.FailureReason,
+			&i.StatusChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersByIDs = `-- name: ListTransfersByIDs :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of_transfer_id, fee, memo, reference, category, status, failure_reason, status_changed_at FROM transfers
+WHERE id = ANY($1::bigint[])
+ORDER BY id
+`
+
+func (q *Queries) ListTransfersByIDs(ctx context.Context, ids []int64) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfersByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversalOfTransferID,
+			&i.Fee,
+			&i.Memo,
+			&i.Reference,
+			&i.Category,
+			&i.Status,
+			&i.FailureReason,
+			&i.StatusChangedAt,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	return items, nil
+}
+
+const searchTransfers = `-- name: SearchTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of_transfer_id, fee, memo, reference, category, status, failure_reason, status_changed_at FROM transfers
+WHERE
+    (from_account_id IN (SELECT id FROM accounts WHERE owner = $1::varchar) OR
+     to_account_id IN (SELECT id FROM accounts WHERE owner = $1::varchar)) AND
+    ($2::text IS NULL OR
+     to_tsvector('simple', memo) @@ plainto_tsquery('simple', $2::text)) AND
+    ($3::bigint IS NULL OR
+     from_account_id = $3::bigint OR
+     to_account_id = $3::bigint) AND
+    ($4::varchar IS NULL OR category = $4::varchar) AND
+    ($5::timestamptz IS NULL OR created_at >= $5::timestamptz) AND
+    ($6::timestamptz IS NULL OR created_at < $6::timestamptz) AND
+    ($7::bigint IS NULL OR amount >= $7::bigint) AND
+    ($8::bigint IS NULL OR amount <= $8::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $9
+OFFSET $10
+`
+
+type SearchTransfersParams struct {
+	Owner                 string         `json:"owner"`
+	Query                 sql.NullString `json:"query"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterparty_account_id"`
+	Category              sql.NullString `json:"category"`
+	CreatedFrom           sql.NullTime   `json:"created_from"`
+	CreatedTo             sql.NullTime   `json:"created_to"`
+	MinAmount             sql.NullInt64  `json:"min_amount"`
+	MaxAmount             sql.NullInt64  `json:"max_amount"`
+	Limit                 int32          `json:"limit"`
+	Offset                int32          `json:"offset"`
+}
+
+func (q *Queries) SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, searchTransfers,
+		arg.Owner,
+		arg.Query,
+		arg.CounterpartyAccountID,
+		arg.Category,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversalOfTransferID,
+			&i.Fee,
+			&i.Memo,
+			&i.Reference,
+			&i.Category,
+			&i.Status,
+			&i.FailureReason,
+			&i.StatusChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
+
+const setTransferFee = `-- name: SetTransferFee :one
+UPDATE transfers
+SET fee = $2
+WHERE id = $1
+RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of_transfer_id, fee, memo, reference, category, status, failure_reason, status_changed_at
+`
+
+type SetTransferFeeParams struct {
+	ID  int64 `json:"id"`
+	Fee int64 `json:"fee"`
+}
+
+func (q *Queries) SetTransferFee(ctx context.Context, arg SetTransferFeeParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, setTransferFee, arg.ID, arg.Fee)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOfTransferID,
+		&i.Fee,
+		&i.Memo,
+		&i.Reference,
+		&i.Category,
+		&i.Status,
+		&i.FailureReason,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET
+  status = $1,
+  failure_reason = $2,
+  status_changed_at = now()
+WHERE id = $3 AND status = $4::varchar
+RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of_transfer_id, fee, memo, reference, category, status, failure_reason, status_changed_at
+`
+
+type UpdateTransferStatusParams struct {
+	Status        string `json:"status"`
+	FailureReason string `json:"failure_reason"`
+	ID            int64  `json:"id"`
+	FromStatus    string `json:"from_status"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, updateTransferStatus,
+		arg.Status,
+		arg.FailureReason,
+		arg.ID,
+		arg.FromStatus,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOfTransferID,
+		&i.Fee,
+		&i.Memo,
+		&i.Reference,
+		&i.Category,
+		&i.Status,
+		&i.FailureReason,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
@@ -15,6 +15,7 @@ func createRandomTransfer(t *testing.T, from, to Account) Transfer {
 		FromAccountID: sql.NullInt64{Int64: from.ID, Valid: true},
 		ToAccountID:   sql.NullInt64{Int64: to.ID, Valid: true},
 		Amount:        utils.RandomMoney(),
+		Category:      utils.CategoryGeneral,
 	}
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
 	require.NoError(t, err)
@@ -66,3 +67,60 @@ func TestListTransfers(t *testing.T) {
 		require.NotEmpty(t, transfer)
 	}
 }
+
+func TestSearchTransfers(t *testing.T) {
+	owner := createRandomAccount(t)
+	other := createRandomAccount(t)
+	stranger := createRandomAccount(t)
+	memo := "rent " + utils.RandomString(12)
+
+	details := []TransferDetails{
+		{Memo: memo + " march", Category: utils.CategoryRent},
+		{Memo: memo + " april", Category: utils.CategoryRent},
+		{Memo: "groceries", Category: utils.CategoryShopping},
+	}
+	var transfers []Transfer
+	for i, d := range details {
+		transfer, err := testQueries.CreateTransfer(context.Background(), d.transferParams(owner.ID, other.ID, int64(100*(i+1)), 0))
+		require.NoError(t, err)
+		transfers = append(transfers, transfer)
+	}
+	// not visible to owner
+	_, err := testQueries.CreateTransfer(context.Background(), TransferDetails{Memo: memo}.transferParams(stranger.ID, other.ID, 10, 0))
+	require.NoError(t, err)
+
+	found, err := testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		Owner:  owner.Owner,
+		Query:  sql.NullString{String: memo, Valid: true},
+		Limit:  10,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, transfers[1].ID, found[0].ID)
+	require.Equal(t, transfers[0].ID, found[1].ID)
+
+	found, err = testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		Owner:                 owner.Owner,
+		CounterpartyAccountID: sql.NullInt64{Int64: other.ID, Valid: true},
+		Category:              sql.NullString{String: utils.CategoryRent, Valid: true},
+		MinAmount:             sql.NullInt64{Int64: 150, Valid: true},
+		MaxAmount:             sql.NullInt64{Int64: 300, Valid: true},
+		CreatedFrom:           sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+		Limit:                 10,
+		Offset:                0,
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, transfers[1].ID, found[0].ID)
+
+	found, err = testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		Owner:     other.Owner,
+		Query:     sql.NullString{String: "groceries", Valid: true},
+		CreatedTo: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+		Limit:     10,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Empty(t, found)
+}
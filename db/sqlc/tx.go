@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrTxConflict is reported when a transaction still failed with a
+// serialization failure or a deadlock once its retries were used up.
+var ErrTxConflict = errors.New("transaction conflicted with concurrent transactions, try again")
+
+// Postgres error codes of the failures a transaction can be run again after.
+const (
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+)
+
+// DefaultTxMaxRetries is how many times a transaction is run again after a
+// serialization failure or a deadlock, unless the store or the transaction
+// says otherwise.
+const DefaultTxMaxRetries = 3
+
+// Backoff between the runs of a transaction: a random wait of up to
+// txRetryBaseDelay doubled on every retry, and never more than txRetryMaxDelay.
+const (
+	txRetryBaseDelay = 10 * time.Millisecond
+	txRetryMaxDelay  = time.Second
+)
+
+// txMetrics counts the transactions that were run again, published with
+// expvar under "db_tx".
+var txMetrics = expvar.NewMap("db_tx")
+
+// Keys of txMetrics.
+const (
+	metricSerializationFailures = "serialization_failures"
+	metricDeadlocks             = "deadlocks"
+	metricRetries               = "retries"
+	metricRetriesExhausted      = "retries_exhausted"
+)
+
+// TxOptions say how executeTx runs a transaction.
+type TxOptions struct {
+	// Isolation defaults to the database's, read committed.
+	Isolation pgx.TxIsoLevel
+	ReadOnly  bool
+	// MaxRetries is how many times the transaction is run again after a
+	// serialization failure or a deadlock. Zero uses the store's setting;
+	// a negative value never retries.
+	MaxRetries int
+}
+
+// StoreOptions tune how the store runs its transactions.
+type StoreOptions struct {
+	// TransferIsolation is the isolation level of TransferTx. Everything it
+	// does is safe to run again, so serializable is fine.
+	TransferIsolation pgx.TxIsoLevel
+	// MaxTxRetries defaults to DefaultTxMaxRetries.
+	MaxTxRetries int
+}
+
+// ParseIsolationLevel parses an isolation level as Postgres spells it, like
+// "serializable". Empty means the default level.
+func ParseIsolationLevel(level string) (pgx.TxIsoLevel, error) {
+	switch level {
+	case "", "default":
+		return "", nil
+	case "read committed":
+		return pgx.ReadCommitted, nil
+	case "repeatable read":
+		return pgx.RepeatableRead, nil
+	case "serializable":
+		return pgx.Serializable, nil
+	}
+	return "", fmt.Errorf("unsupported isolation level %q", level)
+}
+
+// executeTx runs fn in a transaction and commits it unless fn fails. When
+// the transaction fails with a serialization failure or a deadlock, the
+// whole of it runs again after a short random wait, so fn must not have
+// effects outside the transaction it can't repeat. Errors come back through
+// TranslateError.
+func (store *SQLStore) executeTx(ctx context.Context, opts TxOptions, fn func(*Queries) error) error {
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = store.options.MaxTxRetries
+	}
+	txOptions := pgx.TxOptions{IsoLevel: opts.Isolation}
+	if opts.ReadOnly {
+		txOptions.AccessMode = pgx.ReadOnly
+	}
+	return retryTx(ctx, maxRetries, func() error {
+		return store.runTx(ctx, txOptions, fn)
+	})
+}
+
+// runTx runs fn in one transaction.
+func (store *SQLStore) runTx(ctx context.Context, opts pgx.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	if err := fn(q); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("tx err: %w, rb err: %v", TranslateError(err), rbErr)
+		}
+		return TranslateError(err)
+	}
+
+	return TranslateError(tx.Commit(ctx))
+}
+
+// retryTx calls run until it succeeds, fails with an error that isn't worth
+// retrying, or has been retried maxRetries times. It stops waiting when ctx
+// is done.
+func retryTx(ctx context.Context, maxRetries int, run func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := run()
+		code, retryable := retryableCode(err)
+		if !retryable {
+			return err
+		}
+		if code == deadlockDetectedCode {
+			txMetrics.Add(metricDeadlocks, 1)
+		} else {
+			txMetrics.Add(metricSerializationFailures, 1)
+		}
+		if attempt >= maxRetries {
+			if maxRetries > 0 {
+				txMetrics.Add(metricRetriesExhausted, 1)
+			}
+			return err
+		}
+
+		timer := time.NewTimer(txRetryDelay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		txMetrics.Add(metricRetries, 1)
+	}
+}
+
+// retryableCode returns the code of err when it is a serialization failure
+// or a deadlock.
+func retryableCode(err error) (string, bool) {
+	pgErr, ok := asPgError(err)
+	if !ok {
+		return "", false
+	}
+	switch pgErr.Code {
+	case serializationFailureCode, deadlockDetectedCode:
+		return pgErr.Code, true
+	}
+	return "", false
+}
+
+// txRetryDelay returns how long to wait before retry number attempt+1, with
+// full jitter so that the transactions that conflicted don't meet again.
+func txRetryDelay(attempt int) time.Duration {
+	ceiling := txRetryMaxDelay
+	if attempt < 10 {
+		ceiling = min(txRetryBaseDelay<<attempt, txRetryMaxDelay)
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)))
+}
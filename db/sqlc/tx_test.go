@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func txMetric(key string) int64 {
+	if v, ok := txMetrics.Get(key).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestRetryTx(t *testing.T) {
+	serializationFailure := TranslateError(&pgconn.PgError{Code: serializationFailureCode})
+	deadlock := fmt.Errorf("tx err: %w, rb err: none", &pgconn.PgError{Code: deadlockDetectedCode})
+
+	testCases := []struct {
+		name       string
+		maxRetries int
+		errs       []error
+		runs       int
+		err        error
+	}{
+		{"Success", 3, []error{nil}, 1, nil},
+		{"OtherError", 3, []error{ErrInsufficientFunds}, 1, ErrInsufficientFunds},
+		{"RetriedSerializationFailure", 3, []error{serializationFailure, serializationFailure, nil}, 3, nil},
+		{"RetriedDeadlock", 3, []error{deadlock, nil}, 2, nil},
+		{"RetriesExhausted", 2, []error{serializationFailure, deadlock, serializationFailure}, 3, ErrTxConflict},
+		{"NoRetries", -1, []error{deadlock}, 1, ErrTxConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			retries := txMetric(metricRetries)
+			runs := 0
+			err := retryTx(context.Background(), tc.maxRetries, func() error {
+				err := tc.errs[runs]
+				runs++
+				return err
+			})
+			require.Equal(t, tc.runs, runs)
+			require.Equal(t, int64(tc.runs-1), txMetric(metricRetries)-retries)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, TranslateError(err), tc.err)
+		})
+	}
+}
+
+func TestRetryTxCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	runs := 0
+	err := retryTx(ctx, 10, func() error {
+		runs++
+		return &pgconn.PgError{Code: serializationFailureCode}
+	})
+	require.Equal(t, 1, runs)
+	require.ErrorIs(t, TranslateError(err), ErrTxConflict)
+}
+
+func TestTxRetryDelay(t *testing.T) {
+	for attempt := 0; attempt < 100; attempt++ {
+		delay := txRetryDelay(attempt)
+		require.GreaterOrEqual(t, delay, time.Duration(0))
+		require.Less(t, delay, txRetryMaxDelay)
+		if attempt < 3 {
+			require.Less(t, delay, txRetryBaseDelay<<attempt)
+		}
+	}
+}
+
+func TestParseIsolationLevel(t *testing.T) {
+	level, err := ParseIsolationLevel("serializable")
+	require.NoError(t, err)
+	require.Equal(t, pgx.Serializable, level)
+
+	level, err = ParseIsolationLevel("")
+	require.NoError(t, err)
+	require.Equal(t, pgx.TxIsoLevel(""), level)
+
+	_, err = ParseIsolationLevel("snapshot")
+	require.Error(t, err)
+}
+
+// TestTransferTxSerializable runs transfers both ways between two accounts
+// at serializable; the conflicts between them are retried away.
+func TestTransferTxSerializable(t *testing.T) {
+	store := NewStoreWithOptions(testDB, StoreOptions{TransferIsolation: pgx.Serializable, MaxTxRetries: 20})
+	account1 := createAccountInCurrency(t, utils.USD, 1000)
+	account2 := createAccountInCurrency(t, utils.USD, 1000)
+
+	n := 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		from, to := account1, account2
+		if i%2 == 1 {
+			from, to = account2, account1
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: from.ID,
+				ToAccountID:   to.ID,
+				Amount:        10,
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	updated1, err := testQueries.GetAccounts(context.Background(), account1.ID)
+	require.NoError(t, err)
+	updated2, err := testQueries.GetAccounts(context.Background(), account2.ID)
+	require.NoError(t, err)
+	// fees, if any apply, leave the bank; the accounts never gain money
+	require.LessOrEqual(t, updated1.Balance+updated2.Balance, int64(2000))
+}
+
+func TestExecuteTxReadOnly(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account := createRandomAccount(t)
+
+	err := store.executeTx(context.Background(), TxOptions{ReadOnly: true}, func(q *Queries) error {
+		_, err := q.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account.ID, Ammount: 1})
+		return err
+	})
+	require.Error(t, err)
+	require.False(t, errors.Is(err, ErrTxConflict))
+}
@@ -0,0 +1,7 @@
+package db
+
+// Roles a user can have.
+const (
+	RoleCustomer = "customer"
+	RoleStaff    = "staff"
+)
@@ -2,6 +2,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"testing"
 	"time"
 
@@ -46,3 +48,35 @@ func TestGetUser(t *testing.T) {
 	require.WithinDuration(t, user1.CreatedAt.Time, user2.CreatedAt.Time, time.Second)
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 }
+
+func TestGetUserByAlias(t *testing.T) {
+	user := createRandomUser(t)
+
+	// unverified emails aren't aliases
+	_, err := testQueries.GetUserByVerifiedEmail(context.Background(), user.Email)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	verified, err := testQueries.VerifyUserEmail(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.True(t, verified.EmailVerifiedAt.Valid)
+
+	found, err := testQueries.GetUserByVerifiedEmail(context.Background(), user.Email)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, found.Username)
+
+	phone := sql.NullString{String: fmt.Sprintf("+849%08d", utils.RandomInt(0, 99999999)), Valid: true}
+	hashedPassword, err := utils.HashedPassword(utils.RandomString(6))
+	require.NoError(t, err)
+	withPhone, err := testQueries.CreateUser(context.Background(), CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+		Phone:          phone,
+	})
+	require.NoError(t, err)
+
+	found, err = testQueries.GetUserByPhone(context.Background(), phone)
+	require.NoError(t, err)
+	require.Equal(t, withPhone.Username, found.Username)
+}
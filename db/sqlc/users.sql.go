@@ -7,25 +7,28 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users(username, hashed_password, full_name, email) VALUES ($1, $2, $3, $4) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at
+INSERT INTO users(username, hashed_password, full_name, email, phone) VALUES ($1, $2, $3, $4, $5) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, tier, role, phone, email_verified_at
 `
 
 type CreateUserParams struct {
-	Username       string `json:"username"`
-	HashedPassword string `json:"hashed_password"`
-	FullName       string `json:"full_name"`
-	Email          string `json:"email"`
+	Username       string         `json:"username"`
+	HashedPassword string         `json:"hashed_password"`
+	FullName       string         `json:"full_name"`
+	Email          string         `json:"email"`
+	Phone          sql.NullString `json:"phone"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser,
+	row := q.db.QueryRow(ctx, createUser,
 		arg.Username,
 		arg.HashedPassword,
 		arg.FullName,
 		arg.Email,
+		arg.Phone,
 	)
 	var i User
 	err := row.Scan(
@@ -35,16 +38,90 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Tier,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByPhone = `-- name: GetUserByPhone :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, tier, role, phone, email_verified_at FROM users WHERE phone = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByPhone(ctx context.Context, phone sql.NullString) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByPhone, phone)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Tier,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByVerifiedEmail = `-- name: GetUserByVerifiedEmail :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, tier, role, phone, email_verified_at FROM users
+WHERE email = $1 AND email_verified_at IS NOT NULL
+LIMIT 1
+`
+
+func (q *Queries) GetUserByVerifiedEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByVerifiedEmail, email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Tier,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
 const getUsers = `-- name: GetUsers :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at FROM users WHERE username = $1 LIMIT 1
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, tier, role, phone, email_verified_at FROM users WHERE username = $1 LIMIT 1
 `
 
 func (q *Queries) GetUsers(ctx context.Context, username string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUsers, username)
+	row := q.db.QueryRow(ctx, getUsers, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Tier,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users SET email_verified_at = now()
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, tier, role, phone, email_verified_at
+`
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, verifyUserEmail, username)
 	var i User
 	err := row.Scan(
 		&i.Username,
@@ -53,6 +130,10 @@ func (q *Queries) GetUsers(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Tier,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,109 @@
+package iso20022
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// Amount is an amount with its currency, like <InstdAmt Ccy="EUR">12.50</InstdAmt>.
+type Amount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// NewAmount returns the amount of minor units of currency, like cents.
+func NewAmount(minorUnits int64, currency string) Amount {
+	return Amount{Currency: currency, Value: FormatAmount(minorUnits, currency)}
+}
+
+// maxAmountDigits is the most digits an ISO 20022 amount may have.
+const maxAmountDigits = 18
+
+var errInvalidAmount = errors.New("is not a decimal amount")
+
+// ParseAmount converts a decimal amount like 12.50 to minor units of
+// currency. It fails when the amount has more decimals than the currency.
+func ParseAmount(value string, currency string) (int64, error) {
+	value = strings.TrimSpace(value)
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" || !isDigits(whole) || !isDigits(fraction) {
+		return 0, errInvalidAmount
+	}
+	if len(whole)+len(fraction) > maxAmountDigits {
+		return 0, fmt.Errorf("has more than %d digits", maxAmountDigits)
+	}
+	decimals := utils.MinorUnits(currency)
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > decimals {
+		return 0, fmt.Errorf("has more than %d decimals for %s", decimals, currency)
+	}
+	fraction += strings.Repeat("0", decimals-len(fraction))
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, errInvalidAmount
+	}
+	return amount, nil
+}
+
+// FormatAmount formats minor units of currency as a decimal amount.
+func FormatAmount(minorUnits int64, currency string) string {
+	decimals := utils.MinorUnits(currency)
+	sign := ""
+	if minorUnits < 0 {
+		sign = "-"
+		minorUnits = -minorUnits
+	}
+	s := fmt.Sprintf("%0*d", decimals+1, minorUnits)
+	if decimals == 0 {
+		return sign + s
+	}
+	return sign + s[:len(s)-decimals] + "." + s[len(s)-decimals:]
+}
+
+// sumAmounts adds up decimal amounts without rounding, for the control sums
+// that add amounts of any currency.
+func sumAmounts(values []string) (string, error) {
+	const scale = 5
+	var total int64
+	for _, value := range values {
+		whole, fraction, _ := strings.Cut(strings.TrimSpace(value), ".")
+		if whole == "" || !isDigits(whole) || !isDigits(fraction) || len(fraction) > scale {
+			return "", errInvalidAmount
+		}
+		n, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", scale-len(fraction)), 10, 64)
+		if err != nil {
+			return "", errInvalidAmount
+		}
+		total += n
+	}
+	return normalizeDecimal(fmt.Sprintf("%d.%05d", total/100_000, total%100_000)), nil
+}
+
+// normalizeDecimal drops the insignificant zeros of a decimal, so that 10.50
+// and 10.5 compare equal.
+func normalizeDecimal(value string) string {
+	value = strings.TrimSpace(value)
+	whole, fraction, _ := strings.Cut(value, ".")
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" {
+		whole = "0"
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if fraction == "" {
+		return whole
+	}
+	return whole + "." + fraction
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package iso20022
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmount(t *testing.T) {
+	testCases := []struct {
+		value    string
+		currency string
+		amount   int64
+		valid    bool
+	}{
+		{"12.50", "USD", 1250, true},
+		{"12.5", "EUR", 1250, true},
+		{"12", "USD", 1200, true},
+		{"0.01", "USD", 1, true},
+		{"1.200", "USD", 120, true},
+		{"15000", "VND", 15000, true},
+		{"15000.0", "VND", 15000, true},
+		{"1.001", "USD", 0, false},
+		{"15000.5", "VND", 0, false},
+		{"-1", "USD", 0, false},
+		{"1,50", "USD", 0, false},
+		{".5", "USD", 0, false},
+		{"1234567890123456789", "VND", 0, false},
+	}
+
+	for _, tc := range testCases {
+		amount, err := ParseAmount(tc.value, tc.currency)
+		if !tc.valid {
+			require.Error(t, err, tc.value)
+			continue
+		}
+		require.NoError(t, err, tc.value)
+		require.Equal(t, tc.amount, amount, tc.value)
+		require.Equal(t, tc.amount, mustParse(t, FormatAmount(amount, tc.currency), tc.currency))
+	}
+}
+
+func mustParse(t *testing.T, value string, currency string) int64 {
+	amount, err := ParseAmount(value, currency)
+	require.NoError(t, err)
+	return amount
+}
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "0.05", FormatAmount(5, "USD"))
+	require.Equal(t, "1000.25", FormatAmount(100025, "EUR"))
+	require.Equal(t, "-3.00", FormatAmount(-300, "USD"))
+	require.Equal(t, "15000", FormatAmount(15000, "VND"))
+}
+
+func TestSumAmounts(t *testing.T) {
+	sum, err := sumAmounts([]string{"1000.25", "500", "0.5"})
+	require.NoError(t, err)
+	require.Equal(t, "1500.75", sum)
+	require.Equal(t, normalizeDecimal("1500.750"), sum)
+
+	_, err = sumAmounts([]string{"1", "x"})
+	require.Error(t, err)
+}
@@ -0,0 +1,243 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+// Credit and debit indicators.
+const (
+	Credit = "CRDT"
+	Debit  = "DBIT"
+)
+
+// Balance types of a statement.
+const (
+	BalanceOpeningBooked = "OPBD"
+	BalanceClosingBooked = "CLBD"
+)
+
+// EntryStatusBooked is the status of every entry of a statement.
+const EntryStatusBooked = "BOOK"
+
+// notProvided stands for an end to end id the client didn't give.
+const notProvided = "NOTPROVIDED"
+
+// Proprietary bank transaction codes of the entries, issued by the bank.
+const (
+	bankTransactionIssuer = "DIGI"
+	bankTransferCode      = "TRANSFER"
+	bankReversalCode      = "REVERSAL"
+	bankFeeCode           = "FEE"
+	bankOtherCode         = "OTHER"
+)
+
+// Camt053 is a bank to customer statement.
+type Camt053 struct {
+	XMLName   xml.Name
+	Statement BankToCustomerStatement `xml:"BkToCstmrStmt"`
+}
+
+type BankToCustomerStatement struct {
+	GroupHeader StatementGroupHeader `xml:"GrpHdr"`
+	Statements  []Statement          `xml:"Stmt"`
+}
+
+type StatementGroupHeader struct {
+	MessageID        string `xml:"MsgId"`
+	CreationDateTime string `xml:"CreDtTm"`
+}
+
+type Statement struct {
+	ID               string           `xml:"Id"`
+	CreationDateTime string           `xml:"CreDtTm"`
+	FromDateTime     string           `xml:"FrToDt>FrDtTm"`
+	ToDateTime       string           `xml:"FrToDt>ToDtTm"`
+	Account          StatementAccount `xml:"Acct"`
+	Balances         []Balance        `xml:"Bal"`
+	Summary          Summary          `xml:"TxsSummry"`
+	Entries          []Entry          `xml:"Ntry,omitempty"`
+}
+
+type StatementAccount struct {
+	Number   string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy"`
+	Owner    string `xml:"Ownr>Nm,omitempty"`
+}
+
+type Balance struct {
+	Type        string `xml:"Tp>CdOrPrtry>Cd"`
+	Amount      Amount `xml:"Amt"`
+	CreditDebit string `xml:"CdtDbtInd"`
+	Date        string `xml:"Dt>Dt"`
+}
+
+type Summary struct {
+	Total   SummaryTotal `xml:"TtlNtries"`
+	Credits SummarySum   `xml:"TtlCdtNtries"`
+	Debits  SummarySum   `xml:"TtlDbtNtries"`
+}
+
+type SummaryTotal struct {
+	NumberOfEntries string `xml:"NbOfNtries"`
+	Sum             string `xml:"Sum"`
+	NetAmount       string `xml:"TtlNetNtryAmt"`
+	CreditDebit     string `xml:"CdtDbtInd"`
+}
+
+type SummarySum struct {
+	NumberOfEntries string `xml:"NbOfNtries"`
+	Sum             string `xml:"Sum"`
+}
+
+type Entry struct {
+	Amount      Amount              `xml:"Amt"`
+	CreditDebit string              `xml:"CdtDbtInd"`
+	Status      string              `xml:"Sts"`
+	BookingDate string              `xml:"BookgDt>DtTm"`
+	ValueDate   string              `xml:"ValDt>DtTm"`
+	ServicerRef string              `xml:"AcctSvcrRef"`
+	BankCode    BankTransactionCode `xml:"BkTxCd"`
+	Details     *TransactionDetails `xml:"NtryDtls>TxDtls,omitempty"`
+}
+
+type BankTransactionCode struct {
+	Code   string `xml:"Prtry>Cd"`
+	Issuer string `xml:"Prtry>Issr"`
+}
+
+type TransactionDetails struct {
+	ServicerRef string      `xml:"Refs>AcctSvcrRef"`
+	EndToEndID  string      `xml:"Refs>EndToEndId"`
+	Remittance  *Remittance `xml:"RmtInf,omitempty"`
+}
+
+// ParseCamt053 reads a camt.053 message.
+func ParseCamt053(r io.Reader) (Camt053, error) {
+	var msg Camt053
+	if err := xml.NewDecoder(r).Decode(&msg); err != nil {
+		return msg, fmt.Errorf("cannot read camt.053 message: %w", err)
+	}
+	if msg.XMLName.Local != "Document" || msg.XMLName.Space != Camt053Namespace {
+		return msg, fmt.Errorf("unsupported message %q, expected %s", msg.XMLName.Space, Camt053Namespace)
+	}
+	return msg, nil
+}
+
+// Marshal writes the message as an XML document.
+func (msg Camt053) Marshal(w io.Writer) error {
+	msg.XMLName = xml.Name{Local: "Document", Space: Camt053Namespace}
+	return marshal(w, msg)
+}
+
+// NewCamt053 returns the message with the statement of an account. The
+// message and the statement are identified by messageID.
+func NewCamt053(messageID string, createdAt time.Time, statement db.AccountStatement) Camt053 {
+	account := statement.Account
+	currency := account.Currency
+	stmt := Statement{
+		ID:               messageID,
+		CreationDateTime: dateTime(createdAt),
+		FromDateTime:     dateTime(statement.From),
+		ToDateTime:       dateTime(statement.To),
+		Account: StatementAccount{
+			Number:   account.AccountNumber,
+			Currency: currency,
+			Owner:    account.Owner,
+		},
+		Balances: []Balance{
+			newBalance(BalanceOpeningBooked, statement.OpeningBalance, currency, statement.From),
+			// the closing balance is the one at the end of the last day of the period
+			newBalance(BalanceClosingBooked, statement.ClosingBalance, currency, statement.To.Add(-time.Nanosecond)),
+		},
+	}
+
+	var credits, debits, creditCount, debitCount int64
+	for _, entry := range statement.Entries {
+		stmt.Entries = append(stmt.Entries, newEntry(entry, currency))
+		if entry.Entry.Amount >= 0 {
+			credits += entry.Entry.Amount
+			creditCount++
+		} else {
+			debits -= entry.Entry.Amount
+			debitCount++
+		}
+	}
+	net, indicator := credits-debits, Credit
+	if net < 0 {
+		net, indicator = -net, Debit
+	}
+	stmt.Summary = Summary{
+		Total: SummaryTotal{
+			NumberOfEntries: fmt.Sprint(creditCount + debitCount),
+			Sum:             FormatAmount(credits+debits, currency),
+			NetAmount:       FormatAmount(net, currency),
+			CreditDebit:     indicator,
+		},
+		Credits: SummarySum{NumberOfEntries: fmt.Sprint(creditCount), Sum: FormatAmount(credits, currency)},
+		Debits:  SummarySum{NumberOfEntries: fmt.Sprint(debitCount), Sum: FormatAmount(debits, currency)},
+	}
+
+	return Camt053{Statement: BankToCustomerStatement{
+		GroupHeader: StatementGroupHeader{MessageID: messageID, CreationDateTime: dateTime(createdAt)},
+		Statements:  []Statement{stmt},
+	}}
+}
+
+func newBalance(balanceType string, amount int64, currency string, date time.Time) Balance {
+	indicator := Credit
+	if amount < 0 {
+		amount, indicator = -amount, Debit
+	}
+	return Balance{
+		Type:        balanceType,
+		Amount:      NewAmount(amount, currency),
+		CreditDebit: indicator,
+		Date:        date.UTC().Format("2006-01-02"),
+	}
+}
+
+func newEntry(statementEntry db.StatementEntry, currency string) Entry {
+	entry := statementEntry.Entry
+	amount, indicator := entry.Amount, Credit
+	if amount < 0 {
+		amount, indicator = -amount, Debit
+	}
+	ref := fmt.Sprint(entry.ID)
+	result := Entry{
+		Amount:      NewAmount(amount, currency),
+		CreditDebit: indicator,
+		Status:      EntryStatusBooked,
+		BookingDate: dateTime(entry.CreatedAt.Time),
+		ValueDate:   dateTime(entry.CreatedAt.Time),
+		ServicerRef: ref,
+		BankCode:    BankTransactionCode{Code: bankOtherCode, Issuer: bankTransactionIssuer},
+	}
+
+	transfer := statementEntry.Transfer
+	if transfer == nil {
+		return result
+	}
+	switch {
+	case statementEntry.Fee:
+		result.BankCode.Code = bankFeeCode
+	case transfer.ReversalOfTransferID.Valid:
+		result.BankCode.Code = bankReversalCode
+	default:
+		result.BankCode.Code = bankTransferCode
+	}
+	details := &TransactionDetails{
+		ServicerRef: fmt.Sprint(transfer.ID),
+		EndToEndID:  notProvided,
+		Remittance:  newRemittance(transfer.Memo),
+	}
+	if transfer.Reference.Valid {
+		details.EndToEndID = transfer.Reference.String
+	}
+	result.Details = details
+	return result
+}
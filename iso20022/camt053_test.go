@@ -0,0 +1,91 @@
+package iso20022
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+func at(hour int, minute int) sql.NullTime {
+	return sql.NullTime{Time: time.Date(2024, 3, 1, hour, minute, 0, 0, time.UTC), Valid: true}
+}
+
+// testStatement is a day of the payroll account: the payroll of
+// testdata/pain001.xml with the fee of one of its transfers, a refund and an
+// entry written before entries recorded their transfer.
+func testStatement() db.AccountStatement {
+	account := db.Account{ID: 1, Owner: "acme", Currency: "USD", AccountNumber: "DIGI0001000000000137", Balance: 1_000_000}
+	salary := db.Transfer{
+		ID:        41,
+		Amount:    100025,
+		Memo:      "Salary March",
+		Reference: sql.NullString{String: "E2E-1", Valid: true},
+	}
+	bonus := db.Transfer{ID: 42, Amount: 50000}
+	refund := db.Transfer{ID: 43, Amount: 2000, ReversalOfTransferID: sql.NullInt64{Int64: 12, Valid: true}}
+	return db.AccountStatement{
+		Account:        account,
+		From:           time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: 200_000,
+		ClosingBalance: 52_375,
+		Entries: []db.StatementEntry{
+			{Entry: db.Entry{ID: 101, Amount: -100025, CreatedAt: at(9, 31)}, Transfer: &salary},
+			{Entry: db.Entry{ID: 102, Amount: -50000, CreatedAt: at(9, 31)}, Transfer: &bonus},
+			{Entry: db.Entry{ID: 103, Amount: -100, CreatedAt: at(9, 31)}, Transfer: &bonus, Fee: true},
+			{Entry: db.Entry{ID: 104, Amount: 2000, CreatedAt: at(14, 5)}, Transfer: &refund},
+			{Entry: db.Entry{ID: 105, Amount: 500, CreatedAt: at(23, 59)}},
+		},
+	}
+}
+
+func TestNewCamt053(t *testing.T) {
+	msg := NewCamt053("STMT-1-20240301", reportTime, testStatement())
+	require.Len(t, msg.Statement.Statements, 1)
+	stmt := msg.Statement.Statements[0]
+	require.Equal(t, "DIGI0001000000000137", stmt.Account.Number)
+	require.Equal(t, "2000.00", stmt.Balances[0].Amount.Value)
+	require.Equal(t, "523.75", stmt.Balances[1].Amount.Value)
+	require.Equal(t, "2024-03-01", stmt.Balances[1].Date)
+	require.Equal(t, "5", stmt.Summary.Total.NumberOfEntries)
+	require.Equal(t, "1476.25", stmt.Summary.Total.NetAmount)
+	require.Equal(t, Debit, stmt.Summary.Total.CreditDebit)
+	require.Len(t, stmt.Entries, 5)
+	require.Equal(t, "E2E-1", stmt.Entries[0].Details.EndToEndID)
+	require.Equal(t, notProvided, stmt.Entries[1].Details.EndToEndID)
+	require.Equal(t, bankFeeCode, stmt.Entries[2].BankCode.Code)
+	require.Equal(t, bankReversalCode, stmt.Entries[3].BankCode.Code)
+	require.Equal(t, Credit, stmt.Entries[3].CreditDebit)
+	require.Nil(t, stmt.Entries[4].Details)
+
+	requireGolden(t, "testdata/camt053.xml", msg.Marshal)
+}
+
+func TestCamt053RoundTrip(t *testing.T) {
+	msg := NewCamt053("STMT-1-20240301", reportTime, testStatement())
+	var buf bytes.Buffer
+	require.NoError(t, msg.Marshal(&buf))
+	again, err := ParseCamt053(&buf)
+	require.NoError(t, err)
+	msg.XMLName = again.XMLName
+	require.Equal(t, msg, again)
+}
+
+func TestNewCamt053Empty(t *testing.T) {
+	statement := testStatement()
+	statement.Entries = nil
+	statement.OpeningBalance = -500
+	statement.ClosingBalance = -500
+
+	msg := NewCamt053("STMT-1-20240301", reportTime, statement)
+	stmt := msg.Statement.Statements[0]
+	require.Empty(t, stmt.Entries)
+	require.Equal(t, Debit, stmt.Balances[0].CreditDebit)
+	require.Equal(t, "5.00", stmt.Balances[0].Amount.Value)
+	require.Equal(t, "0", stmt.Summary.Total.NumberOfEntries)
+	require.Equal(t, "0.00", stmt.Summary.Total.Sum)
+}
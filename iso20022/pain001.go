@@ -0,0 +1,365 @@
+// Package iso20022 reads and writes the ISO 20022 XML messages corporate
+// clients exchange with the bank: pain.001 credit transfer initiations,
+// pain.002 payment status reports and camt.053 account statements.
+package iso20022
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// Namespaces of the supported message versions.
+const (
+	Pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+	Pain002Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.002.001.03"
+	Camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+)
+
+// ErrUnreadableMessage is returned by ParsePain001 for a body that isn't a
+// pain.001 message.
+var ErrUnreadableMessage = errors.New("cannot read pain.001 message")
+
+// Lengths of the text types of the schemas.
+const (
+	max35Text  = 35
+	max70Text  = 70
+	max140Text = 140
+	max34Text  = 34
+)
+
+// PaymentMethodTransfer is the only payment method of a pain.001 message.
+const PaymentMethodTransfer = "TRF"
+
+// Pain001 is a customer credit transfer initiation.
+type Pain001 struct {
+	XMLName  xml.Name
+	Initiate CustomerCreditTransferInitiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type CustomerCreditTransferInitiation struct {
+	GroupHeader        GroupHeader          `xml:"GrpHdr"`
+	PaymentInformation []PaymentInformation `xml:"PmtInf"`
+}
+
+type GroupHeader struct {
+	MessageID            string `xml:"MsgId"`
+	CreationDateTime     string `xml:"CreDtTm"`
+	NumberOfTransactions string `xml:"NbOfTxs"`
+	ControlSum           string `xml:"CtrlSum,omitempty"`
+	InitiatingParty      *Party `xml:"InitgPty,omitempty"`
+}
+
+type Party struct {
+	Name string `xml:"Nm,omitempty"`
+}
+
+type PaymentInformation struct {
+	ID                   string                      `xml:"PmtInfId"`
+	PaymentMethod        string                      `xml:"PmtMtd"`
+	BatchBooking         string                      `xml:"BtchBookg,omitempty"`
+	NumberOfTransactions string                      `xml:"NbOfTxs,omitempty"`
+	ControlSum           string                      `xml:"CtrlSum,omitempty"`
+	RequestedExecution   string                      `xml:"ReqdExctnDt"`
+	Debtor               Party                       `xml:"Dbtr"`
+	DebtorAccount        CashAccount                 `xml:"DbtrAcct"`
+	Transactions         []CreditTransferTransaction `xml:"CdtTrfTxInf"`
+}
+
+// CashAccount is an account identified by its account number; IBAN is read
+// only to refuse it, as accounts of this bank have none.
+type CashAccount struct {
+	IBAN     string `xml:"Id>IBAN,omitempty"`
+	Number   string `xml:"Id>Othr>Id,omitempty"`
+	Currency string `xml:"Ccy,omitempty"`
+}
+
+type CreditTransferTransaction struct {
+	InstructionID    string      `xml:"PmtId>InstrId,omitempty"`
+	EndToEndID       string      `xml:"PmtId>EndToEndId"`
+	InstructedAmount Amount      `xml:"Amt>InstdAmt"`
+	Creditor         Party       `xml:"Cdtr"`
+	CreditorAccount  CashAccount `xml:"CdtrAcct"`
+	Remittance       *Remittance `xml:"RmtInf,omitempty"`
+}
+
+// Remittance is the unstructured remittance information of a transfer, the
+// text the creditor sees.
+type Remittance struct {
+	Unstructured string `xml:"Ustrd"`
+}
+
+// newRemittance returns the remittance information with text cut to its
+// length, or nil when there is no text.
+func newRemittance(text string) *Remittance {
+	if text == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(text) > max140Text {
+		text = string([]rune(text)[:max140Text])
+	}
+	return &Remittance{Unstructured: text}
+}
+
+// ParsePain001 reads a pain.001 message. It only checks that the XML is a
+// pain.001 document; Validate checks its content.
+func ParsePain001(r io.Reader) (Pain001, error) {
+	var msg Pain001
+	if err := xml.NewDecoder(r).Decode(&msg); err != nil {
+		return msg, fmt.Errorf("%w: %w", ErrUnreadableMessage, err)
+	}
+	if msg.XMLName.Local != "Document" || msg.XMLName.Space != Pain001Namespace {
+		return msg, fmt.Errorf("%w: unsupported message %q, expected %s", ErrUnreadableMessage, msg.XMLName.Space, Pain001Namespace)
+	}
+	return msg, nil
+}
+
+// Marshal writes the message as an XML document.
+func (msg Pain001) Marshal(w io.Writer) error {
+	msg.XMLName = xml.Name{Local: "Document", Space: Pain001Namespace}
+	return marshal(w, msg)
+}
+
+// ValidationError is a rule a field of a message breaks.
+type ValidationError struct {
+	// Path is the path of the field, like PmtInf[1]/CdtTrfTxInf[2]/PmtId/EndToEndId.
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// ValidationErrors is the list of rules a message breaks.
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Path + ": " + err.Error
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Payment is a validated payment information block: transfers from one
+// account in one currency.
+type Payment struct {
+	ID            string
+	DebtorAccount string
+	Currency      string
+	// BatchBooking asks for the transfers to be booked all together or not at all.
+	BatchBooking  bool
+	ExecutionDate time.Time
+	Transactions  []Transaction
+}
+
+// Transaction is a validated credit transfer.
+type Transaction struct {
+	InstructionID   string
+	EndToEndID      string
+	Amount          int64
+	CreditorAccount string
+	CreditorName    string
+	Remittance      string
+}
+
+// Validate checks the message against the rules of the schema that matter
+// to us, and the ones of the bank, and returns its payments. The error is
+// ValidationErrors when the message breaks any rule.
+func (msg Pain001) Validate() ([]Payment, error) {
+	v := validator{}
+	header := msg.Initiate.GroupHeader
+	v.text("GrpHdr/MsgId", header.MessageID, max35Text, true)
+	if _, err := parseDateTime(header.CreationDateTime); err != nil {
+		v.fail("GrpHdr/CreDtTm", "is not an ISO date and time")
+	}
+	if len(msg.Initiate.PaymentInformation) == 0 {
+		v.fail("PmtInf", "is missing")
+	}
+
+	var payments []Payment
+	var amounts []string
+	seenPayments := make(map[string]bool)
+	for i, info := range msg.Initiate.PaymentInformation {
+		path := fmt.Sprintf("PmtInf[%d]", i+1)
+		v.text(path+"/PmtInfId", info.ID, max35Text, true)
+		if seenPayments[info.ID] {
+			v.fail(path+"/PmtInfId", "is used by another payment information block")
+		}
+		seenPayments[info.ID] = true
+		if info.PaymentMethod != PaymentMethodTransfer {
+			v.fail(path+"/PmtMtd", "must be "+PaymentMethodTransfer)
+		}
+		payment := Payment{ID: info.ID}
+		switch info.BatchBooking {
+		case "", "false", "0":
+		case "true", "1":
+			payment.BatchBooking = true
+		default:
+			v.fail(path+"/BtchBookg", "is not a boolean")
+		}
+		executionDate, err := time.Parse("2006-01-02", info.RequestedExecution)
+		if err != nil {
+			v.fail(path+"/ReqdExctnDt", "is not an ISO date")
+		}
+		payment.ExecutionDate = executionDate
+		payment.DebtorAccount = v.account(path+"/DbtrAcct", info.DebtorAccount)
+		if len(info.Transactions) == 0 {
+			v.fail(path+"/CdtTrfTxInf", "is missing")
+		}
+
+		var paymentAmounts []string
+		seenEndToEnd := make(map[string]bool)
+		for j, tx := range info.Transactions {
+			txPath := fmt.Sprintf("%s/CdtTrfTxInf[%d]", path, j+1)
+			v.text(txPath+"/PmtId/InstrId", tx.InstructionID, max35Text, false)
+			v.text(txPath+"/PmtId/EndToEndId", tx.EndToEndID, max35Text, true)
+			if seenEndToEnd[tx.EndToEndID] {
+				v.fail(txPath+"/PmtId/EndToEndId", "is used by another transaction of the payment")
+			}
+			seenEndToEnd[tx.EndToEndID] = true
+			v.text(txPath+"/Cdtr/Nm", tx.Creditor.Name, max70Text, false)
+			var remittance string
+			if tx.Remittance != nil {
+				remittance = tx.Remittance.Unstructured
+				v.text(txPath+"/RmtInf/Ustrd", remittance, max140Text, true)
+			}
+
+			transaction := Transaction{
+				InstructionID:   tx.InstructionID,
+				EndToEndID:      tx.EndToEndID,
+				CreditorAccount: v.account(txPath+"/CdtrAcct", tx.CreditorAccount),
+				CreditorName:    tx.Creditor.Name,
+				Remittance:      remittance,
+			}
+			currency := tx.InstructedAmount.Currency
+			amountPath := txPath + "/Amt/InstdAmt"
+			switch {
+			case !utils.IsValidCurrency(currency):
+				v.fail(amountPath, fmt.Sprintf("currency %q is not supported", currency))
+			case payment.Currency != "" && currency != payment.Currency:
+				v.fail(amountPath, fmt.Sprintf("currency %s differs from the payment's %s", currency, payment.Currency))
+			default:
+				payment.Currency = currency
+				amount, err := ParseAmount(tx.InstructedAmount.Value, currency)
+				switch {
+				case err != nil:
+					v.fail(amountPath, err.Error())
+				case amount <= 0:
+					v.fail(amountPath, "must be positive")
+				}
+				transaction.Amount = amount
+			}
+			paymentAmounts = append(paymentAmounts, tx.InstructedAmount.Value)
+			payment.Transactions = append(payment.Transactions, transaction)
+		}
+		if info.DebtorAccount.Currency != "" && payment.Currency != "" && info.DebtorAccount.Currency != payment.Currency {
+			v.fail(path+"/DbtrAcct/Ccy", fmt.Sprintf("is %s, the transactions are in %s", info.DebtorAccount.Currency, payment.Currency))
+		}
+		v.count(path+"/NbOfTxs", info.NumberOfTransactions, len(info.Transactions), false)
+		v.sum(path+"/CtrlSum", info.ControlSum, paymentAmounts)
+		amounts = append(amounts, paymentAmounts...)
+		payments = append(payments, payment)
+	}
+	v.count("GrpHdr/NbOfTxs", header.NumberOfTransactions, len(amounts), true)
+	v.sum("GrpHdr/CtrlSum", header.ControlSum, amounts)
+
+	if len(v.errors) > 0 {
+		return nil, v.errors
+	}
+	return payments, nil
+}
+
+// validator collects the validation errors of a message.
+type validator struct {
+	errors ValidationErrors
+}
+
+func (v *validator) fail(path string, message string) {
+	v.errors = append(v.errors, ValidationError{Path: path, Error: message})
+}
+
+func (v *validator) text(path string, value string, maxLength int, required bool) {
+	switch n := utf8.RuneCountInString(value); {
+	case n == 0 && required:
+		v.fail(path, "is missing")
+	case n > maxLength:
+		v.fail(path, fmt.Sprintf("is longer than %d characters", maxLength))
+	}
+}
+
+// account returns the account number of an account.
+func (v *validator) account(path string, account CashAccount) string {
+	switch {
+	case account.IBAN != "":
+		v.fail(path+"/Id/IBAN", "is not supported; identify the account by its number in Othr/Id")
+	case account.Number == "":
+		v.fail(path+"/Id/Othr/Id", "is missing")
+	default:
+		v.text(path+"/Id/Othr/Id", account.Number, max34Text, true)
+	}
+	return account.Number
+}
+
+// count checks a number of transactions field against the actual number.
+func (v *validator) count(path string, value string, actual int, required bool) {
+	if value == "" {
+		if required {
+			v.fail(path, "is missing")
+		}
+		return
+	}
+	if value != fmt.Sprint(actual) {
+		v.fail(path, fmt.Sprintf("is %s, the message has %d transactions", value, actual))
+	}
+}
+
+// sum checks an optional control sum against the amounts it adds up.
+func (v *validator) sum(path string, value string, amounts []string) {
+	if value == "" {
+		return
+	}
+	total, err := sumAmounts(amounts)
+	if err != nil {
+		// the amounts themselves are reported
+		return
+	}
+	if _, err := sumAmounts([]string{value}); err != nil {
+		v.fail(path, errInvalidAmount.Error())
+		return
+	}
+	if normalizeDecimal(value) != total {
+		v.fail(path, fmt.Sprintf("is %s, the amounts add up to %s", value, total))
+	}
+}
+
+// parseDateTime parses an ISODateTime, which may omit the time zone.
+func parseDateTime(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		t, err = time.Parse("2006-01-02T15:04:05.999999999", value)
+	}
+	return t, err
+}
+
+// dateTime formats t as an ISODateTime.
+func dateTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// marshal writes v as an indented XML document.
+func marshal(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,188 @@
+package iso20022
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readPain001(t *testing.T, path string) Pain001 {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	msg, err := ParsePain001(f)
+	require.NoError(t, err)
+	return msg
+}
+
+func TestValidatePain001(t *testing.T) {
+	msg := readPain001(t, "testdata/pain001.xml")
+	require.Equal(t, "MSG-2024-0001", msg.Initiate.GroupHeader.MessageID)
+
+	payments, err := msg.Validate()
+	require.NoError(t, err)
+	require.Equal(t, []Payment{
+		{
+			ID:            "PAYROLL-MARCH",
+			DebtorAccount: "DIGI0001000000000137",
+			Currency:      "USD",
+			BatchBooking:  true,
+			ExecutionDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Transactions: []Transaction{
+				{InstructionID: "INSTR-1", EndToEndID: "E2E-1", Amount: 100025, CreditorAccount: "DIGI0001000000000234", CreditorName: "Jane Doe", Remittance: "Salary March"},
+				{EndToEndID: "E2E-2", Amount: 50000, CreditorAccount: "DIGI0001000000000331", CreditorName: "John Roe"},
+			},
+		},
+		{
+			ID:            "SUPPLIERS",
+			DebtorAccount: "DIGI0001000000000428",
+			Currency:      "EUR",
+			ExecutionDate: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			Transactions: []Transaction{
+				{EndToEndID: "INV-77", Amount: 25000, CreditorAccount: "DIGI0001000000000525", CreditorName: "Widgets GmbH", Remittance: "Invoice 77"},
+			},
+		},
+	}, payments)
+}
+
+func TestValidatePain001Invalid(t *testing.T) {
+	msg := readPain001(t, "testdata/pain001_invalid.xml")
+	_, err := msg.Validate()
+	require.Error(t, err)
+
+	errs, ok := err.(ValidationErrors)
+	require.True(t, ok)
+	paths := make([]string, len(errs))
+	for i, err := range errs {
+		paths[i] = err.Path
+	}
+	require.Equal(t, []string{
+		"PmtInf[1]/PmtMtd",
+		"PmtInf[1]/DbtrAcct/Id/IBAN",
+		"PmtInf[1]/CdtTrfTxInf[1]/Amt/InstdAmt",
+		"PmtInf[1]/CdtTrfTxInf[2]/PmtId/EndToEndId",
+		"PmtInf[1]/CdtTrfTxInf[2]/Amt/InstdAmt",
+		"GrpHdr/NbOfTxs",
+		"GrpHdr/CtrlSum",
+	}, paths)
+}
+
+func TestValidatePain001Rules(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(msg *Pain001)
+		path   string
+	}{
+		{
+			name:   "MissingMessageID",
+			modify: func(msg *Pain001) { msg.Initiate.GroupHeader.MessageID = "" },
+			path:   "GrpHdr/MsgId",
+		},
+		{
+			name: "LongMessageID",
+			modify: func(msg *Pain001) {
+				msg.Initiate.GroupHeader.MessageID = "MSG-0123456789-0123456789-0123456789"
+			},
+			path: "GrpHdr/MsgId",
+		},
+		{
+			name:   "CreationDateTime",
+			modify: func(msg *Pain001) { msg.Initiate.GroupHeader.CreationDateTime = "yesterday" },
+			path:   "GrpHdr/CreDtTm",
+		},
+		{
+			name: "DuplicatePaymentInformationID",
+			modify: func(msg *Pain001) {
+				msg.Initiate.PaymentInformation[1].ID = msg.Initiate.PaymentInformation[0].ID
+			},
+			path: "PmtInf[2]/PmtInfId",
+		},
+		{
+			name:   "PaymentControlSum",
+			modify: func(msg *Pain001) { msg.Initiate.PaymentInformation[0].ControlSum = "1500.26" },
+			path:   "PmtInf[1]/CtrlSum",
+		},
+		{
+			name:   "PaymentNumberOfTransactions",
+			modify: func(msg *Pain001) { msg.Initiate.PaymentInformation[0].NumberOfTransactions = "3" },
+			path:   "PmtInf[1]/NbOfTxs",
+		},
+		{
+			name:   "DebtorAccountCurrency",
+			modify: func(msg *Pain001) { msg.Initiate.PaymentInformation[0].DebtorAccount.Currency = "EUR" },
+			path:   "PmtInf[1]/DbtrAcct/Ccy",
+		},
+		{
+			name:   "BatchBooking",
+			modify: func(msg *Pain001) { msg.Initiate.PaymentInformation[0].BatchBooking = "yes" },
+			path:   "PmtInf[1]/BtchBookg",
+		},
+		{
+			name:   "ExecutionDate",
+			modify: func(msg *Pain001) { msg.Initiate.PaymentInformation[0].RequestedExecution = "2024-13-01" },
+			path:   "PmtInf[1]/ReqdExctnDt",
+		},
+		{
+			name:   "MissingCreditorAccount",
+			modify: func(msg *Pain001) { msg.Initiate.PaymentInformation[1].Transactions[0].CreditorAccount.Number = "" },
+			path:   "PmtInf[2]/CdtTrfTxInf[1]/CdtrAcct/Id/Othr/Id",
+		},
+		{
+			name: "ZeroAmount",
+			modify: func(msg *Pain001) {
+				msg.Initiate.PaymentInformation[1].Transactions[0].InstructedAmount.Value = "0.00"
+				msg.Initiate.GroupHeader.ControlSum = ""
+			},
+			path: "PmtInf[2]/CdtTrfTxInf[1]/Amt/InstdAmt",
+		},
+		{
+			name: "UnsupportedCurrency",
+			modify: func(msg *Pain001) {
+				msg.Initiate.PaymentInformation[1].Transactions[0].InstructedAmount.Currency = "GBP"
+			},
+			path: "PmtInf[2]/CdtTrfTxInf[1]/Amt/InstdAmt",
+		},
+		{
+			name: "LongRemittance",
+			modify: func(msg *Pain001) {
+				msg.Initiate.PaymentInformation[1].Transactions[0].Remittance.Unstructured = string(bytes.Repeat([]byte("x"), 141))
+			},
+			path: "PmtInf[2]/CdtTrfTxInf[1]/RmtInf/Ustrd",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			msg := readPain001(t, "testdata/pain001.xml")
+			tc.modify(&msg)
+			_, err := msg.Validate()
+			require.Error(t, err)
+			errs := err.(ValidationErrors)
+			require.Len(t, errs, 1, errs.Error())
+			require.Equal(t, tc.path, errs[0].Path)
+		})
+	}
+}
+
+func TestPain001RoundTrip(t *testing.T) {
+	msg := readPain001(t, "testdata/pain001.xml")
+
+	var buf bytes.Buffer
+	require.NoError(t, msg.Marshal(&buf))
+	again, err := ParsePain001(&buf)
+	require.NoError(t, err)
+	require.Equal(t, msg, again)
+}
+
+func TestParsePain001Errors(t *testing.T) {
+	_, err := ParsePain001(bytes.NewBufferString("<Document><CstmrCdtTrfInitn>"))
+	require.ErrorIs(t, err, ErrUnreadableMessage)
+
+	_, err = ParsePain001(bytes.NewBufferString(`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"></Document>`))
+	require.ErrorIs(t, err, ErrUnreadableMessage)
+	require.ErrorContains(t, err, "unsupported message")
+}
@@ -0,0 +1,247 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+// Statuses of a message, a payment or a transaction in a pain.002 report.
+const (
+	StatusSettled           = "ACSC"
+	StatusPartiallyAccepted = "PART"
+	StatusPending           = "PDNG"
+	StatusRejected          = "RJCT"
+)
+
+// Reason codes of the rejections, from the ISO external status reason code list.
+const (
+	ReasonAccountNotFound   = "AC01"
+	ReasonAccountBlocked    = "AC06"
+	ReasonWrongCurrency     = "AM03"
+	ReasonInsufficientFunds = "AM04"
+	ReasonDuplicate         = "AM05"
+	ReasonInvalidFileFormat = "FF01"
+	ReasonNarrative         = "NARR"
+)
+
+// maxAdditionalInfo is the length of an AddtlInf element.
+const maxAdditionalInfo = 105
+
+// Pain002 is a customer payment status report.
+type Pain002 struct {
+	XMLName xml.Name
+	Report  CustomerPaymentStatusReport `xml:"CstmrPmtStsRpt"`
+}
+
+type CustomerPaymentStatusReport struct {
+	GroupHeader   StatusGroupHeader       `xml:"GrpHdr"`
+	OriginalGroup OriginalGroupStatus     `xml:"OrgnlGrpInfAndSts"`
+	Payments      []OriginalPaymentStatus `xml:"OrgnlPmtInfAndSts,omitempty"`
+}
+
+type StatusGroupHeader struct {
+	MessageID        string `xml:"MsgId"`
+	CreationDateTime string `xml:"CreDtTm"`
+}
+
+type OriginalGroupStatus struct {
+	MessageID            string         `xml:"OrgnlMsgId"`
+	MessageNameID        string         `xml:"OrgnlMsgNmId"`
+	NumberOfTransactions string         `xml:"OrgnlNbOfTxs,omitempty"`
+	Status               string         `xml:"GrpSts,omitempty"`
+	Reasons              []StatusReason `xml:"StsRsnInf,omitempty"`
+}
+
+type OriginalPaymentStatus struct {
+	PaymentInformationID string              `xml:"OrgnlPmtInfId"`
+	Status               string              `xml:"PmtInfSts,omitempty"`
+	Reasons              []StatusReason      `xml:"StsRsnInf,omitempty"`
+	Transactions         []TransactionStatus `xml:"TxInfAndSts,omitempty"`
+}
+
+type TransactionStatus struct {
+	OriginalInstructionID string         `xml:"OrgnlInstrId,omitempty"`
+	OriginalEndToEndID    string         `xml:"OrgnlEndToEndId"`
+	Status                string         `xml:"TxSts"`
+	Reasons               []StatusReason `xml:"StsRsnInf,omitempty"`
+}
+
+type StatusReason struct {
+	Code           string   `xml:"Rsn>Cd"`
+	AdditionalInfo []string `xml:"AddtlInf,omitempty"`
+}
+
+// newStatusReason returns a reason with info cut to the length of AddtlInf.
+func newStatusReason(code string, info ...string) StatusReason {
+	reason := StatusReason{Code: code}
+	for _, s := range info {
+		if utf8.RuneCountInString(s) > maxAdditionalInfo {
+			s = string([]rune(s)[:maxAdditionalInfo])
+		}
+		reason.AdditionalInfo = append(reason.AdditionalInfo, s)
+	}
+	return reason
+}
+
+// ParsePain002 reads a pain.002 message.
+func ParsePain002(r io.Reader) (Pain002, error) {
+	var msg Pain002
+	if err := xml.NewDecoder(r).Decode(&msg); err != nil {
+		return msg, fmt.Errorf("cannot read pain.002 message: %w", err)
+	}
+	if msg.XMLName.Local != "Document" || msg.XMLName.Space != Pain002Namespace {
+		return msg, fmt.Errorf("unsupported message %q, expected %s", msg.XMLName.Space, Pain002Namespace)
+	}
+	return msg, nil
+}
+
+// Marshal writes the message as an XML document.
+func (msg Pain002) Marshal(w io.Writer) error {
+	msg.XMLName = xml.Name{Local: "Document", Space: Pain002Namespace}
+	return marshal(w, msg)
+}
+
+// pain001Name is the OrgnlMsgNmId of the reports on pain.001 messages.
+const pain001Name = "pain.001.001.03"
+
+// RejectPain001 returns the report rejecting a whole pain.001 message that
+// broke the rules in errs.
+func RejectPain001(messageID string, createdAt time.Time, original Pain001, errs ValidationErrors) Pain002 {
+	reasons := make([]StatusReason, len(errs))
+	for i, err := range errs {
+		reasons[i] = newStatusReason(ReasonInvalidFileFormat, err.Path+" "+err.Error)
+	}
+	header := original.Initiate.GroupHeader
+	return Pain002{Report: CustomerPaymentStatusReport{
+		GroupHeader: StatusGroupHeader{MessageID: messageID, CreationDateTime: dateTime(createdAt)},
+		OriginalGroup: OriginalGroupStatus{
+			MessageID:            header.MessageID,
+			MessageNameID:        pain001Name,
+			NumberOfTransactions: header.NumberOfTransactions,
+			Status:               StatusRejected,
+			Reasons:              reasons,
+		},
+	}}
+}
+
+// StatusReport is what became of the payments of a pain.001 message.
+type StatusReport struct {
+	// MessageID identifies the report itself.
+	MessageID         string
+	CreatedAt         time.Time
+	OriginalMessageID string
+	Payments          []PaymentOutcome
+}
+
+// PaymentOutcome is what became of one payment information block: either
+// the batch it was booked as, or why it was rejected before that.
+type PaymentOutcome struct {
+	Payment Payment
+	Batch   *db.TransferBatchResult
+	// Reason and Info are set when the payment was rejected as a whole.
+	Reason string
+	Info   []string
+}
+
+// NewPain002 returns the report of the outcome of the payments of a message.
+func NewPain002(report StatusReport) Pain002 {
+	msg := Pain002{Report: CustomerPaymentStatusReport{
+		GroupHeader: StatusGroupHeader{MessageID: report.MessageID, CreationDateTime: dateTime(report.CreatedAt)},
+		OriginalGroup: OriginalGroupStatus{
+			MessageID:     report.OriginalMessageID,
+			MessageNameID: pain001Name,
+		},
+	}}
+	var transactions int
+	var statuses []string
+	for _, outcome := range report.Payments {
+		status := paymentStatus(outcome)
+		statuses = append(statuses, status.Status)
+		transactions += len(outcome.Payment.Transactions)
+		msg.Report.Payments = append(msg.Report.Payments, status)
+	}
+	msg.Report.OriginalGroup.NumberOfTransactions = fmt.Sprint(transactions)
+	msg.Report.OriginalGroup.Status = combineStatuses(statuses)
+	return msg
+}
+
+// paymentStatus reports on a payment and each of its transactions.
+func paymentStatus(outcome PaymentOutcome) OriginalPaymentStatus {
+	status := OriginalPaymentStatus{PaymentInformationID: outcome.Payment.ID}
+	if outcome.Batch == nil {
+		status.Status = StatusRejected
+		status.Reasons = []StatusReason{newStatusReason(outcome.Reason, outcome.Info...)}
+		return status
+	}
+
+	items := make(map[string]db.TransferBatchItem, len(outcome.Batch.Items))
+	for _, item := range outcome.Batch.Items {
+		items[item.EndToEndID] = item
+	}
+	var statuses []string
+	for _, tx := range outcome.Payment.Transactions {
+		txStatus := TransactionStatus{
+			OriginalInstructionID: tx.InstructionID,
+			OriginalEndToEndID:    tx.EndToEndID,
+		}
+		item := items[tx.EndToEndID]
+		switch {
+		case item.Status == db.TransferBatchItemCompleted:
+			txStatus.Status = StatusSettled
+		case item.Status == db.TransferBatchItemFailed:
+			txStatus.Status = StatusRejected
+			txStatus.Reasons = []StatusReason{newStatusReason(reasonCode(item.FailureCode), item.Reason)}
+		case outcome.Batch.Batch.Status == db.TransferBatchFailed:
+			// an atomic batch stops at the first failure and books nothing
+			txStatus.Status = StatusRejected
+			txStatus.Reasons = []StatusReason{newStatusReason(ReasonNarrative, "not booked because another transaction of the batch failed")}
+		case outcome.Batch.Batch.Status == db.TransferBatchRejected:
+			txStatus.Status = StatusRejected
+			txStatus.Reasons = []StatusReason{newStatusReason(ReasonNarrative, "not booked because the payment wasn't approved")}
+		default:
+			txStatus.Status = StatusPending
+		}
+		statuses = append(statuses, txStatus.Status)
+		status.Transactions = append(status.Transactions, txStatus)
+	}
+	status.Status = combineStatuses(statuses)
+	return status
+}
+
+// combineStatuses returns the status of a group of payments or transactions.
+func combineStatuses(statuses []string) string {
+	count := make(map[string]int)
+	for _, status := range statuses {
+		count[status]++
+	}
+	switch len(statuses) {
+	case count[StatusSettled]:
+		return StatusSettled
+	case count[StatusRejected]:
+		return StatusRejected
+	case count[StatusPending]:
+		return StatusPending
+	}
+	return StatusPartiallyAccepted
+}
+
+// reasonCode returns the reason code of the failure code a batch item
+// recorded.
+func reasonCode(failureCode string) string {
+	switch failureCode {
+	case db.BatchItemInsufficientFunds:
+		return ReasonInsufficientFunds
+	case db.BatchItemAccountFrozen:
+		return ReasonAccountBlocked
+	case db.BatchItemCurrencyMismatch:
+		return ReasonWrongCurrency
+	case db.BatchItemAccountNotFound:
+		return ReasonAccountNotFound
+	}
+	return ReasonNarrative
+}
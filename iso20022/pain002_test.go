@@ -0,0 +1,175 @@
+package iso20022
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+var reportTime = time.Date(2024, 3, 1, 9, 31, 0, 0, time.UTC)
+
+// requireGolden checks that marshal writes the XML of the golden file.
+func requireGolden(t *testing.T, path string, marshal func(io.Writer) error) {
+	var buf bytes.Buffer
+	require.NoError(t, marshal(&buf))
+	golden, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(golden), buf.String())
+}
+
+// testStatusReport is the outcome of testdata/pain001.xml: the payroll
+// booked, and one of the supplier payments failing.
+func testStatusReport(t *testing.T) StatusReport {
+	payments, err := readPain001(t, "testdata/pain001.xml").Validate()
+	require.NoError(t, err)
+	return StatusReport{
+		MessageID:         "STS-MSG-2024-0001",
+		CreatedAt:         reportTime,
+		OriginalMessageID: "MSG-2024-0001",
+		Payments: []PaymentOutcome{
+			{
+				Payment: payments[0],
+				Batch: &db.TransferBatchResult{
+					Batch: db.TransferBatch{ID: 1, Status: db.TransferBatchCompleted},
+					Items: []db.TransferBatchItem{
+						{ID: 1, EndToEndID: "E2E-1", Status: db.TransferBatchItemCompleted},
+						{ID: 2, EndToEndID: "E2E-2", Status: db.TransferBatchItemCompleted},
+					},
+				},
+			},
+			{
+				Payment: payments[1],
+				Batch: &db.TransferBatchResult{
+					Batch: db.TransferBatch{ID: 2, Status: db.TransferBatchFailed},
+					Items: []db.TransferBatchItem{
+						{ID: 3, EndToEndID: "INV-77", Status: db.TransferBatchItemFailed, Reason: db.ErrInsufficientFunds.Error(), FailureCode: db.BatchItemInsufficientFunds},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestNewPain002(t *testing.T) {
+	msg := NewPain002(testStatusReport(t))
+	require.Equal(t, StatusPartiallyAccepted, msg.Report.OriginalGroup.Status)
+	require.Equal(t, "3", msg.Report.OriginalGroup.NumberOfTransactions)
+	require.Len(t, msg.Report.Payments, 2)
+	require.Equal(t, StatusSettled, msg.Report.Payments[0].Status)
+	require.Equal(t, StatusRejected, msg.Report.Payments[1].Status)
+	require.Equal(t, ReasonInsufficientFunds, msg.Report.Payments[1].Transactions[0].Reasons[0].Code)
+
+	requireGolden(t, "testdata/pain002.xml", msg.Marshal)
+}
+
+func TestPain002RoundTrip(t *testing.T) {
+	msg := NewPain002(testStatusReport(t))
+	var buf bytes.Buffer
+	require.NoError(t, msg.Marshal(&buf))
+	again, err := ParsePain002(&buf)
+	require.NoError(t, err)
+	msg.XMLName = again.XMLName
+	require.Equal(t, msg, again)
+}
+
+func TestPain002Statuses(t *testing.T) {
+	report := testStatusReport(t)
+	payment := report.Payments[0].Payment
+
+	testCases := []struct {
+		name     string
+		outcome  PaymentOutcome
+		status   string
+		txStatus []string
+	}{
+		{
+			name: "AtomicBatchFailed",
+			outcome: PaymentOutcome{Payment: payment, Batch: &db.TransferBatchResult{
+				Batch: db.TransferBatch{Status: db.TransferBatchFailed},
+				Items: []db.TransferBatchItem{
+					{EndToEndID: "E2E-1", Status: db.TransferBatchItemFailed, Reason: "account 7: " + db.ErrAccountFrozen.Error(), FailureCode: db.BatchItemAccountFrozen},
+					{EndToEndID: "E2E-2", Status: db.TransferBatchItemPending},
+				},
+			}},
+			status:   StatusRejected,
+			txStatus: []string{StatusRejected, StatusRejected},
+		},
+		{
+			name: "PartiallyCompleted",
+			outcome: PaymentOutcome{Payment: payment, Batch: &db.TransferBatchResult{
+				Batch: db.TransferBatch{Status: db.TransferBatchPartiallyCompleted},
+				Items: []db.TransferBatchItem{
+					{EndToEndID: "E2E-1", Status: db.TransferBatchItemCompleted},
+					{EndToEndID: "E2E-2", Status: db.TransferBatchItemFailed, Reason: "something else", FailureCode: db.BatchItemOtherFailure},
+				},
+			}},
+			status:   StatusPartiallyAccepted,
+			txStatus: []string{StatusSettled, StatusRejected},
+		},
+		{
+			name: "Pending",
+			outcome: PaymentOutcome{Payment: payment, Batch: &db.TransferBatchResult{
+				Batch: db.TransferBatch{Status: db.TransferBatchPending},
+				Items: []db.TransferBatchItem{
+					{EndToEndID: "E2E-1", Status: db.TransferBatchItemPending},
+					{EndToEndID: "E2E-2", Status: db.TransferBatchItemPending},
+				},
+			}},
+			status:   StatusPending,
+			txStatus: []string{StatusPending, StatusPending},
+		},
+		{
+			name: "HeldBatchRejected",
+			outcome: PaymentOutcome{Payment: payment, Batch: &db.TransferBatchResult{
+				Batch: db.TransferBatch{Status: db.TransferBatchRejected},
+				Items: []db.TransferBatchItem{
+					{EndToEndID: "E2E-1", Status: db.TransferBatchItemPending},
+					{EndToEndID: "E2E-2", Status: db.TransferBatchItemPending},
+				},
+			}},
+			status:   StatusRejected,
+			txStatus: []string{StatusRejected, StatusRejected},
+		},
+		{
+			name:    "Rejected",
+			outcome: PaymentOutcome{Payment: payment, Reason: ReasonAccountNotFound, Info: []string{"debtor account not found"}},
+			status:  StatusRejected,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			msg := NewPain002(StatusReport{MessageID: "STS", CreatedAt: reportTime, OriginalMessageID: "MSG", Payments: []PaymentOutcome{tc.outcome}})
+			require.Equal(t, tc.status, msg.Report.OriginalGroup.Status)
+			status := msg.Report.Payments[0]
+			require.Equal(t, tc.status, status.Status)
+			require.Len(t, status.Transactions, len(tc.txStatus))
+			for j, txStatus := range tc.txStatus {
+				require.Equal(t, txStatus, status.Transactions[j].Status)
+			}
+		})
+	}
+}
+
+func TestRejectPain001(t *testing.T) {
+	original := readPain001(t, "testdata/pain001_invalid.xml")
+	_, err := original.Validate()
+	require.Error(t, err)
+
+	msg := RejectPain001("STS-MSG-2024-0002", reportTime, original, err.(ValidationErrors))
+	require.Equal(t, "MSG-2024-0002", msg.Report.OriginalGroup.MessageID)
+	require.Equal(t, StatusRejected, msg.Report.OriginalGroup.Status)
+	require.Len(t, msg.Report.OriginalGroup.Reasons, 7)
+	for _, reason := range msg.Report.OriginalGroup.Reasons {
+		require.Equal(t, ReasonInvalidFileFormat, reason.Code)
+		require.Len(t, reason.AdditionalInfo, 1)
+		require.LessOrEqual(t, len([]rune(reason.AdditionalInfo[0])), maxAdditionalInfo)
+	}
+	require.Empty(t, msg.Report.Payments)
+}
@@ -0,0 +1,164 @@
+// Package ledger checks the hash chains of the entries of the accounts, and
+// writes signed checkpoints of their heads that can be anchored outside the
+// bank.
+//
+// Every entry of an account carries the SHA-256 of the hash of the previous
+// entry of the account and of its own contents, which the database computes
+// as the entry is written. Editing or deleting an entry breaks the chain from
+// that entry on.
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+// verifyPageSize is how many entries Verify reads at a time.
+const verifyPageSize = 1000
+
+// EntryHash returns the hash of an entry that follows the entry hashed to
+// prev in the chain of its account; prev is empty for the first entry. It
+// covers the same text as entry_chain_text in the database.
+func EntryHash(prev []byte, entry db.Entry) []byte {
+	h := sha256.New()
+	h.Write(prev)
+	h.Write([]byte(chainText(entry)))
+	return h.Sum(nil)
+}
+
+func chainText(entry db.Entry) string {
+	createdAt := ""
+	if entry.CreatedAt.Valid {
+		createdAt = entry.CreatedAt.Time.UTC().Format("2006-01-02T15:04:05.000000Z")
+	}
+	return strings.Join([]string{
+		nullInt(entry.AccountID),
+		nullInt(entry.ChainSeq),
+		strconv.FormatInt(entry.ID, 10),
+		strconv.FormatInt(entry.Amount, 10),
+		createdAt,
+		nullInt(entry.TransferID),
+		nullInt(entry.TransferGroupID),
+		nullInt(entry.FeeTransferID),
+	}, "|")
+}
+
+func nullInt(n sql.NullInt64) string {
+	if !n.Valid {
+		return ""
+	}
+	return strconv.FormatInt(n.Int64, 10)
+}
+
+// BrokenLink is where a chain stops holding.
+type BrokenLink struct {
+	AccountID int64 `json:"account_id"`
+	ChainSeq  int64 `json:"chain_seq"`
+	// EntryID is zero when the entry is missing.
+	EntryID int64  `json:"entry_id,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+func (link BrokenLink) String() string {
+	if link.EntryID == 0 {
+		return fmt.Sprintf("account %d, entry #%d: %s", link.AccountID, link.ChainSeq, link.Reason)
+	}
+	return fmt.Sprintf("account %d, entry #%d (id %d): %s", link.AccountID, link.ChainSeq, link.EntryID, link.Reason)
+}
+
+type VerifyResult struct {
+	Accounts int64 `json:"accounts"`
+	Entries  int64 `json:"entries"`
+	// Broken is the first broken link, in account and chain order, or nil
+	// when every chain holds.
+	Broken *BrokenLink `json:"broken,omitempty"`
+}
+
+// Verify walks the chain of every account, account by account, recomputing
+// the hash of every entry, and stops at the first broken link. The heads of
+// the chains are read first and every chain must still lead through its
+// head, so entries deleted from the end of a chain are caught too; entries
+// written while Verify runs are checked like the others.
+func Verify(ctx context.Context, store db.Store) (VerifyResult, error) {
+	var result VerifyResult
+	heads, err := store.ListChainHeads(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	var accountID, seq int64
+	var prev []byte
+	// endChain checks the chain that ends at seq, and that the accounts
+	// before next whose heads were read have a chain at all.
+	endChain := func(next int64) *BrokenLink {
+		for len(heads) > 0 && heads[0].ID < next {
+			head := heads[0]
+			heads = heads[1:]
+			if head.ID == accountID && seq >= head.LastEntrySeq {
+				continue
+			}
+			last := int64(0)
+			if head.ID == accountID {
+				last = seq
+			}
+			return &BrokenLink{AccountID: head.ID, ChainSeq: last + 1, Reason: "entry is missing"}
+		}
+		return nil
+	}
+
+	for {
+		entries, err := store.ListChainEntries(ctx, db.ListChainEntriesParams{
+			AfterAccountID: accountID,
+			AfterChainSeq:  seq,
+			PageSize:       verifyPageSize,
+		})
+		if err != nil {
+			return result, err
+		}
+		for _, entry := range entries {
+			if entry.AccountID.Int64 != accountID {
+				if result.Broken = endChain(entry.AccountID.Int64); result.Broken != nil {
+					return result, nil
+				}
+				accountID, seq, prev = entry.AccountID.Int64, 0, nil
+				result.Accounts++
+			}
+			link := BrokenLink{AccountID: accountID, ChainSeq: seq + 1}
+			if entry.ChainSeq.Int64 != seq+1 {
+				link.Reason = "entry is missing"
+				result.Broken = &link
+				return result, nil
+			}
+			link.EntryID = entry.ID
+			hash := EntryHash(prev, entry)
+			if !bytes.Equal(hash, entry.Hash) {
+				link.Reason = "hash doesn't match the entry and the one before it"
+				result.Broken = &link
+				return result, nil
+			}
+			if len(heads) > 0 && heads[0].ID == accountID && heads[0].LastEntrySeq == entry.ChainSeq.Int64 &&
+				!bytes.Equal(heads[0].LastEntryHash, hash) {
+				link.Reason = "hash doesn't match the head of the chain"
+				result.Broken = &link
+				return result, nil
+			}
+			seq, prev = entry.ChainSeq.Int64, hash
+			result.Entries++
+		}
+		if len(entries) < verifyPageSize {
+			break
+		}
+	}
+	result.Broken = endChain(accountID + 1)
+	if result.Broken == nil && len(heads) > 0 {
+		result.Broken = &BrokenLink{AccountID: heads[0].ID, ChainSeq: 1, Reason: "entry is missing"}
+	}
+	return result, nil
+}
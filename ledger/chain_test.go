@@ -0,0 +1,206 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+// chainEntries returns n chained entries of an account.
+func chainEntries(accountID int64, firstID int64, n int) []db.Entry {
+	entries := make([]db.Entry, n)
+	var prev []byte
+	for i := range entries {
+		entry := db.Entry{
+			ID:         firstID + int64(i),
+			AccountID:  sql.NullInt64{Int64: accountID, Valid: true},
+			Amount:     int64(100 * (i + 1)),
+			CreatedAt:  sql.NullTime{Time: time.Date(2026, 10, 1, 12, 0, i, 123456000, time.UTC), Valid: true},
+			TransferID: sql.NullInt64{Int64: int64(i + 1), Valid: true},
+			ChainSeq:   sql.NullInt64{Int64: int64(i + 1), Valid: true},
+		}
+		entry.Hash = EntryHash(prev, entry)
+		prev = entry.Hash
+		entries[i] = entry
+	}
+	return entries
+}
+
+// headsOf returns the heads of the chains, in account order.
+func headsOf(chains ...[]db.Entry) []db.ListChainHeadsRow {
+	var heads []db.ListChainHeadsRow
+	for _, chain := range chains {
+		last := chain[len(chain)-1]
+		heads = append(heads, db.ListChainHeadsRow{
+			ID:            last.AccountID.Int64,
+			LastEntrySeq:  last.ChainSeq.Int64,
+			LastEntryHash: last.Hash,
+		})
+	}
+	return heads
+}
+
+// expectChains makes store serve the heads and the entries page by page.
+func expectChains(store *mockdb.MockStore, heads []db.ListChainHeadsRow, entries []db.Entry) {
+	store.EXPECT().ListChainHeads(gomock.Any()).Times(1).Return(heads, nil)
+	store.EXPECT().
+		ListChainEntries(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, arg db.ListChainEntriesParams) ([]db.Entry, error) {
+			page := []db.Entry{}
+			for _, entry := range entries {
+				after := entry.AccountID.Int64 > arg.AfterAccountID ||
+					(entry.AccountID.Int64 == arg.AfterAccountID && entry.ChainSeq.Int64 > arg.AfterChainSeq)
+				if after && len(page) < int(arg.PageSize) {
+					page = append(page, entry)
+				}
+			}
+			return page, nil
+		})
+}
+
+func TestEntryHash(t *testing.T) {
+	entry := chainEntries(7, 40, 1)[0]
+	require.Equal(t, "7|1|40|100|2026-10-01T12:00:00.123456Z|1||", chainText(entry))
+	require.Len(t, entry.Hash, 32)
+
+	// the hash of the previous entry is part of the hash
+	require.NotEqual(t, entry.Hash, EntryHash([]byte{1}, entry))
+	entry.Amount++
+	require.NotEqual(t, hex.EncodeToString(entry.Hash), hex.EncodeToString(EntryHash(nil, entry)))
+}
+
+func TestVerify(t *testing.T) {
+	testCases := []struct {
+		name  string
+		setup func() ([]db.ListChainHeadsRow, []db.Entry)
+		check func(t *testing.T, result VerifyResult)
+	}{
+		{
+			name: "OK",
+			setup: func() ([]db.ListChainHeadsRow, []db.Entry) {
+				a, b := chainEntries(1, 1, 3), chainEntries(2, 10, 2)
+				return headsOf(a, b), append(a, b...)
+			},
+			check: func(t *testing.T, result VerifyResult) {
+				require.Nil(t, result.Broken)
+				require.Equal(t, int64(2), result.Accounts)
+				require.Equal(t, int64(5), result.Entries)
+			},
+		},
+		{
+			name: "Empty",
+			setup: func() ([]db.ListChainHeadsRow, []db.Entry) {
+				return nil, nil
+			},
+			check: func(t *testing.T, result VerifyResult) {
+				require.Nil(t, result.Broken)
+				require.Zero(t, result.Entries)
+			},
+		},
+		{
+			name: "TamperedAmount",
+			setup: func() ([]db.ListChainHeadsRow, []db.Entry) {
+				a, b := chainEntries(1, 1, 3), chainEntries(2, 10, 2)
+				heads := headsOf(a, b)
+				a[1].Amount = 1
+				return heads, append(a, b...)
+			},
+			check: func(t *testing.T, result VerifyResult) {
+				require.Equal(t, &BrokenLink{AccountID: 1, ChainSeq: 2, EntryID: 2, Reason: "hash doesn't match the entry and the one before it"}, result.Broken)
+				require.Equal(t, int64(1), result.Entries)
+			},
+		},
+		{
+			name: "RehashedEntry",
+			setup: func() ([]db.ListChainHeadsRow, []db.Entry) {
+				a := chainEntries(1, 1, 3)
+				heads := headsOf(a)
+				// the entry and the ones after it were hashed again, but the
+				// head on the account still has the old hash
+				a[1].Amount = 1
+				a[1].Hash = EntryHash(a[0].Hash, a[1])
+				a[2].Hash = EntryHash(a[1].Hash, a[2])
+				return heads, a
+			},
+			check: func(t *testing.T, result VerifyResult) {
+				require.Equal(t, &BrokenLink{AccountID: 1, ChainSeq: 3, EntryID: 3, Reason: "hash doesn't match the head of the chain"}, result.Broken)
+			},
+		},
+		{
+			name: "MissingEntry",
+			setup: func() ([]db.ListChainHeadsRow, []db.Entry) {
+				a := chainEntries(1, 1, 3)
+				return headsOf(a), []db.Entry{a[0], a[2]}
+			},
+			check: func(t *testing.T, result VerifyResult) {
+				require.Equal(t, &BrokenLink{AccountID: 1, ChainSeq: 2, Reason: "entry is missing"}, result.Broken)
+			},
+		},
+		{
+			name: "MissingTail",
+			setup: func() ([]db.ListChainHeadsRow, []db.Entry) {
+				a, b := chainEntries(1, 1, 3), chainEntries(2, 10, 2)
+				return headsOf(a, b), append(a[:2], b...)
+			},
+			check: func(t *testing.T, result VerifyResult) {
+				require.Equal(t, &BrokenLink{AccountID: 1, ChainSeq: 3, Reason: "entry is missing"}, result.Broken)
+			},
+		},
+		{
+			name: "MissingChain",
+			setup: func() ([]db.ListChainHeadsRow, []db.Entry) {
+				a, b := chainEntries(1, 1, 3), chainEntries(2, 10, 2)
+				return headsOf(a, b), a
+			},
+			check: func(t *testing.T, result VerifyResult) {
+				require.Equal(t, &BrokenLink{AccountID: 2, ChainSeq: 1, Reason: "entry is missing"}, result.Broken)
+			},
+		},
+		{
+			name: "EntriesAfterHeads",
+			setup: func() ([]db.ListChainHeadsRow, []db.Entry) {
+				// entries written after the heads were read still verify
+				a := chainEntries(1, 1, 3)
+				return headsOf(a[:2]), a
+			},
+			check: func(t *testing.T, result VerifyResult) {
+				require.Nil(t, result.Broken)
+				require.Equal(t, int64(3), result.Entries)
+			},
+		},
+		{
+			name: "ManyPages",
+			setup: func() ([]db.ListChainHeadsRow, []db.Entry) {
+				a, b := chainEntries(1, 1, verifyPageSize+1), chainEntries(2, 5000, verifyPageSize)
+				return headsOf(a, b), append(a, b...)
+			},
+			check: func(t *testing.T, result VerifyResult) {
+				require.Nil(t, result.Broken)
+				require.Equal(t, int64(2*verifyPageSize+1), result.Entries)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			heads, entries := tc.setup()
+			expectChains(store, heads, entries)
+
+			result, err := Verify(context.Background(), store)
+			require.NoError(t, err)
+			tc.check(t, result)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+var (
+	ErrBadCheckpointRoot      = errors.New("checkpoint root doesn't match its heads")
+	ErrBadCheckpointSignature = errors.New("checkpoint signature doesn't verify")
+)
+
+// ChainHead is the latest entry of the chain of an account.
+type ChainHead struct {
+	AccountID int64 `json:"account_id"`
+	ChainSeq  int64 `json:"chain_seq"`
+	// Hash is the hash of the entry in hex.
+	Hash string `json:"hash"`
+}
+
+// Checkpoint records the heads of all the chains at a point in time, signed
+// with the bank's ledger key. Publishing its root somewhere the bank doesn't
+// control pins down the whole ledger up to then.
+type Checkpoint struct {
+	CreatedAt time.Time   `json:"created_at"`
+	Heads     []ChainHead `json:"heads"`
+	// Root is the SHA-256 of the heads, one account_id|chain_seq|hash line
+	// each, in hex.
+	Root string `json:"root"`
+	// PublicKey is the ed25519 key Signature verifies with, in hex.
+	PublicKey string `json:"public_key"`
+	// Signature is the ed25519 signature of the creation time and the root,
+	// in hex.
+	Signature string `json:"signature"`
+}
+
+// NewCheckpoint reads the heads of the chains and signs them with key.
+func NewCheckpoint(ctx context.Context, store db.Store, key ed25519.PrivateKey, now time.Time) (Checkpoint, error) {
+	rows, err := store.ListChainHeads(ctx)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	checkpoint := Checkpoint{
+		CreatedAt: now.UTC(),
+		Heads:     make([]ChainHead, len(rows)),
+	}
+	for i, row := range rows {
+		checkpoint.Heads[i] = ChainHead{AccountID: row.ID, ChainSeq: row.LastEntrySeq, Hash: hex.EncodeToString(row.LastEntryHash)}
+	}
+	checkpoint.Root = checkpoint.root()
+	checkpoint.PublicKey = hex.EncodeToString(key.Public().(ed25519.PublicKey))
+	checkpoint.Signature = hex.EncodeToString(ed25519.Sign(key, checkpoint.signedMessage()))
+	return checkpoint, nil
+}
+
+func (checkpoint Checkpoint) root() string {
+	h := sha256.New()
+	for _, head := range checkpoint.Heads {
+		fmt.Fprintf(h, "%d|%d|%s\n", head.AccountID, head.ChainSeq, head.Hash)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (checkpoint Checkpoint) signedMessage() []byte {
+	return []byte(fmt.Sprintf("digi-bank ledger checkpoint\n%s\n%s\n", checkpoint.CreatedAt.UTC().Format(time.RFC3339Nano), checkpoint.Root))
+}
+
+// VerifySignature checks that the root covers the heads and that key signed
+// the checkpoint. The key must come from somewhere other than the checkpoint.
+func (checkpoint Checkpoint) VerifySignature(key ed25519.PublicKey) error {
+	if checkpoint.root() != checkpoint.Root {
+		return ErrBadCheckpointRoot
+	}
+	signature, err := hex.DecodeString(checkpoint.Signature)
+	if err != nil || !ed25519.Verify(key, checkpoint.signedMessage(), signature) {
+		return ErrBadCheckpointSignature
+	}
+	return nil
+}
+
+// CheckHeads checks that the chains still lead through the heads of the
+// checkpoint, and returns the first head that has gone, or nil. Together with
+// Verify, that shows nothing up to the checkpoint has changed since.
+func (checkpoint Checkpoint) CheckHeads(ctx context.Context, store db.Store) (*BrokenLink, error) {
+	for _, head := range checkpoint.Heads {
+		link := &BrokenLink{AccountID: head.AccountID, ChainSeq: head.ChainSeq}
+		entry, err := store.GetChainEntry(ctx, db.GetChainEntryParams{AccountID: head.AccountID, ChainSeq: head.ChainSeq})
+		if errors.Is(db.TranslateError(err), db.ErrRecordNotFound) {
+			link.Reason = "entry is missing"
+			return link, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		link.EntryID = entry.ID
+		if hex.EncodeToString(entry.Hash) != head.Hash {
+			link.Reason = "hash doesn't match the checkpoint"
+			return link, nil
+		}
+	}
+	return nil, nil
+}
+
+// WriteCheckpoint writes the checkpoint as JSON to a new file in dir and
+// returns its path. The file only appears once it is complete.
+func WriteCheckpoint(dir string, checkpoint Checkpoint) (string, error) {
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("ledger-checkpoint-%s.json", checkpoint.CreatedAt.UTC().Format("20060102T150405Z"))
+	tmpPath := filepath.Join(dir, "."+name+".tmp")
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0o640); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return path, nil
+}
+
+// ReadCheckpoint reads a checkpoint WriteCheckpoint wrote.
+func ReadCheckpoint(path string) (Checkpoint, error) {
+	var checkpoint Checkpoint
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return checkpoint, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&checkpoint); err != nil {
+		return checkpoint, fmt.Errorf("cannot read checkpoint %s: %w", path, err)
+	}
+	return checkpoint, nil
+}
+
+// LoadSigningKey reads an ed25519 private key from a PKCS #8 PEM file, like
+// the one openssl genpkey -algorithm ed25519 writes.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s holds no PEM private key", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse ledger signing key %s: %w", path, err)
+	}
+	ed25519Key, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("ledger signing key %s isn't an ed25519 key", path)
+	}
+	return ed25519Key, nil
+}
@@ -0,0 +1,146 @@
+package ledger
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+)
+
+func randomCheckpoint(t *testing.T, store *mockdb.MockStore, key ed25519.PrivateKey, chains ...[]db.Entry) Checkpoint {
+	store.EXPECT().ListChainHeads(gomock.Any()).Times(1).Return(headsOf(chains...), nil)
+	checkpoint, err := NewCheckpoint(context.Background(), store, key, time.Date(2026, 10, 2, 0, 5, 0, 0, time.UTC))
+	require.NoError(t, err)
+	return checkpoint
+}
+
+func TestCheckpointSignature(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	store := mockdb.NewMockStore(ctrl)
+	checkpoint := randomCheckpoint(t, store, key, chainEntries(1, 1, 3), chainEntries(2, 10, 2))
+	require.Len(t, checkpoint.Heads, 2)
+	require.Equal(t, ChainHead{AccountID: 2, ChainSeq: 2, Hash: checkpoint.Heads[1].Hash}, checkpoint.Heads[1])
+	require.NoError(t, checkpoint.VerifySignature(pub))
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.ErrorIs(t, checkpoint.VerifySignature(otherPub), ErrBadCheckpointSignature)
+
+	tampered := checkpoint
+	tampered.Heads = append([]ChainHead{}, checkpoint.Heads...)
+	tampered.Heads[0].ChainSeq = 2
+	require.ErrorIs(t, tampered.VerifySignature(pub), ErrBadCheckpointRoot)
+
+	tampered = checkpoint
+	tampered.CreatedAt = tampered.CreatedAt.Add(time.Hour)
+	require.ErrorIs(t, tampered.VerifySignature(pub), ErrBadCheckpointSignature)
+}
+
+func TestCheckpointCheckHeads(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	store := mockdb.NewMockStore(ctrl)
+	a, b := chainEntries(1, 1, 3), chainEntries(2, 10, 2)
+	checkpoint := randomCheckpoint(t, store, key, a, b)
+
+	store.EXPECT().
+		GetChainEntry(gomock.Any(), gomock.Eq(db.GetChainEntryParams{AccountID: 1, ChainSeq: 3})).
+		Times(2).
+		Return(a[2], nil)
+	store.EXPECT().
+		GetChainEntry(gomock.Any(), gomock.Eq(db.GetChainEntryParams{AccountID: 2, ChainSeq: 2})).
+		Times(1).
+		Return(b[1], nil)
+	broken, err := checkpoint.CheckHeads(context.Background(), store)
+	require.NoError(t, err)
+	require.Nil(t, broken)
+
+	// the chain of account 2 was rewritten after the checkpoint
+	rewritten := b[1]
+	rewritten.Hash = EntryHash(nil, rewritten)
+	store.EXPECT().
+		GetChainEntry(gomock.Any(), gomock.Eq(db.GetChainEntryParams{AccountID: 2, ChainSeq: 2})).
+		Times(1).
+		Return(rewritten, nil)
+	broken, err = checkpoint.CheckHeads(context.Background(), store)
+	require.NoError(t, err)
+	require.Equal(t, &BrokenLink{AccountID: 2, ChainSeq: 2, EntryID: 11, Reason: "hash doesn't match the checkpoint"}, broken)
+}
+
+func TestCheckpointMissingHead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	store := mockdb.NewMockStore(ctrl)
+	checkpoint := randomCheckpoint(t, store, key, chainEntries(1, 1, 3))
+
+	store.EXPECT().
+		GetChainEntry(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.Entry{}, db.ErrRecordNotFound)
+	broken, err := checkpoint.CheckHeads(context.Background(), store)
+	require.NoError(t, err)
+	require.Equal(t, &BrokenLink{AccountID: 1, ChainSeq: 3, Reason: "entry is missing"}, broken)
+}
+
+func TestWriteCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	store := mockdb.NewMockStore(ctrl)
+	checkpoint := randomCheckpoint(t, store, key, chainEntries(1, 1, 3))
+
+	dir := filepath.Join(t.TempDir(), "checkpoints")
+	path, err := WriteCheckpoint(dir, checkpoint)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "ledger-checkpoint-20261002T000500Z.json"), path)
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	read, err := ReadCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, checkpoint, read)
+	require.NoError(t, read.VerifySignature(pub))
+
+	_, err = ReadCheckpoint(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "ledger.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	loaded, err := LoadSigningKey(path)
+	require.NoError(t, err)
+	require.Equal(t, key, loaded)
+
+	require.NoError(t, os.WriteFile(path, []byte("not a key"), 0o600))
+	_, err = LoadSigningKey(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,210 @@
+// Package sanctions screens names against a sanctions and watch list kept
+// in a local CSV or XML file.
+//
+// Names are compared after normalization with the Jaro-Winkler similarity,
+// so spelling variants, missing diacritics and reordered names still match.
+// The list is held in memory and can be reloaded from its file at any time;
+// screening keeps using the old entries until a reload succeeds.
+package sanctions
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is one name on the list. Aliases of a listed party are separate
+// entries with the same ID.
+type Entry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Match is the entry a name matched and how closely.
+type Match struct {
+	Entry Entry   `json:"entry"`
+	Score float64 `json:"score"`
+}
+
+// List is the sanctions list loaded from a file. It is safe for concurrent use.
+type List struct {
+	path string
+
+	mu       sync.RWMutex
+	entries  []Entry
+	loadedAt time.Time
+}
+
+// NewList returns an empty list that Reload fills from the file at path. An
+// empty path means there is no list and nothing ever matches.
+func NewList(path string) *List {
+	return &List{path: path}
+}
+
+// Reload reads the list's file again and replaces the entries with its
+// contents. On error the entries loaded before are kept.
+func (list *List) Reload() (int, error) {
+	if list.path == "" {
+		return 0, nil
+	}
+	entries, err := LoadFile(list.path)
+	if err != nil {
+		return 0, err
+	}
+
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	list.entries = entries
+	list.loadedAt = time.Now()
+	return len(entries), nil
+}
+
+// Len returns the number of entries on the list.
+func (list *List) Len() int {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	return len(list.entries)
+}
+
+// LoadedAt returns when the list was last loaded, or the zero time.
+func (list *List) LoadedAt() time.Time {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+	return list.loadedAt
+}
+
+// Match returns the entry most similar to name if its score is at least
+// threshold.
+func (list *List) Match(name string, threshold float64) (Match, bool) {
+	list.mu.RLock()
+	defer list.mu.RUnlock()
+
+	var best Match
+	for _, entry := range list.entries {
+		if score := Similarity(name, entry.Name); score > best.Score {
+			best = Match{Entry: entry, Score: score}
+		}
+	}
+	return best, best.Score > 0 && best.Score >= threshold
+}
+
+// LoadFile reads a list from a .csv or .xml file.
+func LoadFile(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		entries, err = ParseCSV(file)
+	case ".xml":
+		entries, err = ParseXML(file)
+	default:
+		return nil, fmt.Errorf("sanctions list %s: unsupported file type", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sanctions list %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// ParseCSV reads a list with a header row naming at least the id and name
+// columns. An aliases column, if present, holds other names of the same
+// party separated by semicolons.
+func ParseCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	idColumn, hasID := columns["id"]
+	nameColumn, hasName := columns["name"]
+	if !hasID || !hasName {
+		return nil, errors.New("header must have id and name columns")
+	}
+	aliasColumn, hasAliases := columns["aliases"]
+
+	var entries []Entry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) <= max(idColumn, nameColumn) {
+			return nil, fmt.Errorf("line %d: missing id or name", line)
+		}
+		var aliases []string
+		if hasAliases && aliasColumn < len(record) && record[aliasColumn] != "" {
+			aliases = strings.Split(record[aliasColumn], ";")
+		}
+		entries, err = appendEntry(entries, record[idColumn], record[nameColumn], aliases)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+type xmlList struct {
+	Entries []struct {
+		ID      string   `xml:"id,attr"`
+		Name    string   `xml:"name"`
+		Aliases []string `xml:"alias"`
+	} `xml:"entry"`
+}
+
+// ParseXML reads a list of entry elements, each with an id attribute, a name
+// and any number of alias elements:
+//
+//	<sanctions>
+//	  <entry id="SDN-1"><name>Ivan Petrov</name><alias>Ivan Petroff</alias></entry>
+//	</sanctions>
+func ParseXML(r io.Reader) ([]Entry, error) {
+	var doc xmlList
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	var err error
+	for i, e := range doc.Entries {
+		entries, err = appendEntry(entries, e.ID, e.Name, e.Aliases)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+	}
+	return entries, nil
+}
+
+func appendEntry(entries []Entry, id string, name string, aliases []string) ([]Entry, error) {
+	id = strings.TrimSpace(id)
+	if id == "" || Normalize(name) == "" {
+		return nil, errors.New("missing id or name")
+	}
+	entries = append(entries, Entry{ID: id, Name: strings.TrimSpace(name)})
+	for _, alias := range aliases {
+		if Normalize(alias) != "" {
+			entries = append(entries, Entry{ID: id, Name: strings.TrimSpace(alias)})
+		}
+	}
+	return entries, nil
+}
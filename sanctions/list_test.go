@@ -0,0 +1,106 @@
+package sanctions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFile(t *testing.T) {
+	want := []Entry{
+		{ID: "SDN-1001", Name: "Ivan Petrov"},
+		{ID: "SDN-1001", Name: "Ivan Petroff"},
+		{ID: "SDN-1001", Name: "I. Petrov"},
+		{ID: "SDN-1002", Name: "Trần Văn Đức"},
+		{ID: "SDN-1003", Name: "Global Shipping Holdings Ltd"},
+	}
+	for _, path := range []string{"testdata/list.csv", "testdata/list.xml"} {
+		t.Run(path, func(t *testing.T) {
+			entries, err := LoadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, want, entries)
+		})
+	}
+
+	_, err := LoadFile("testdata/missing.csv")
+	require.Error(t, err)
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		csv  string
+	}{
+		{"NoHeader", ""},
+		{"NoNameColumn", "id,alias\nX-1,Someone\n"},
+		{"MissingName", "id,name\nX-1,\n"},
+		{"MissingID", "id,name\n,Someone\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(tc.csv))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestListMatch(t *testing.T) {
+	list := NewList("testdata/list.csv")
+	_, ok := list.Match("Ivan Petrov", 0.9)
+	require.False(t, ok)
+
+	n, err := list.Reload()
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+	require.Equal(t, 5, list.Len())
+	require.False(t, list.LoadedAt().IsZero())
+
+	match, ok := list.Match("Tran Van Duc", 0.9)
+	require.True(t, ok)
+	require.Equal(t, "SDN-1002", match.Entry.ID)
+	require.Equal(t, 1.0, match.Score)
+
+	match, ok = list.Match("ivan petroff", 0.9)
+	require.True(t, ok)
+	require.Equal(t, Entry{ID: "SDN-1001", Name: "Ivan Petroff"}, match.Entry)
+
+	_, ok = list.Match("Nguyen Thi Mai", 0.9)
+	require.False(t, ok)
+}
+
+func TestListReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.csv")
+	require.NoError(t, os.WriteFile(path, []byte("id,name\nX-1,Ivan Petrov\n"), 0o600))
+
+	list := NewList(path)
+	_, err := list.Reload()
+	require.NoError(t, err)
+	_, ok := list.Match("Maria Garcia", 0.9)
+	require.False(t, ok)
+
+	require.NoError(t, os.WriteFile(path, []byte("id,name\nX-1,Ivan Petrov\nX-2,Maria Garcia\n"), 0o600))
+	n, err := list.Reload()
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	_, ok = list.Match("Maria Garcia", 0.9)
+	require.True(t, ok)
+
+	// a broken file keeps the entries loaded before
+	require.NoError(t, os.WriteFile(path, []byte("name\nMaria Garcia\n"), 0o600))
+	_, err = list.Reload()
+	require.Error(t, err)
+	require.Equal(t, 2, list.Len())
+}
+
+func TestListWithoutPath(t *testing.T) {
+	list := NewList("")
+	n, err := list.Reload()
+	require.NoError(t, err)
+	require.Zero(t, n)
+	_, ok := list.Match("Ivan Petrov", 0)
+	require.False(t, ok)
+}
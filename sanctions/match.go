@@ -0,0 +1,104 @@
+package sanctions
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldings maps the letters that don't decompose into a base letter and a
+// mark, so Unicode normalization alone can't strip them.
+var foldings = strings.NewReplacer("đ", "d", "ø", "o", "ł", "l", "ß", "ss", "æ", "ae", "œ", "oe")
+
+// Normalize folds name for matching: lower case, no diacritics, and words of
+// letters and digits separated by single spaces.
+func Normalize(name string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, strings.ToLower(name))
+	if err != nil {
+		folded = strings.ToLower(name)
+	}
+	folded = foldings.Replace(folded)
+	words := strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// sortWords returns the words of a normalized name in alphabetical order, so
+// names match whatever order their parts are written in.
+func sortWords(name string) string {
+	words := strings.Fields(name)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// Similarity scores how alike two names are, from 0 to 1. Both are
+// normalized first and compared as written and with their words sorted;
+// the better of the two Jaro-Winkler similarities wins.
+func Similarity(a string, b string) float64 {
+	a, b = Normalize(a), Normalize(b)
+	return max(JaroWinkler(a, b), JaroWinkler(sortWords(a), sortWords(b)))
+}
+
+// JaroWinkler returns the Jaro-Winkler similarity of a and b, from 0 for
+// nothing in common to 1 for equal strings.
+func JaroWinkler(a string, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	jaro := jaro(s1, s2)
+
+	// boost strings that share a prefix of up to four runes
+	prefix := 0
+	for prefix < min(len(s1), len(s2), 4) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+func jaro(s1 []rune, s2 []rune) float64 {
+	if len(s1) == 0 && len(s2) == 0 {
+		return 1
+	}
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	window := max(max(len(s1), len(s2))/2-1, 0)
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		for j := max(0, i-window); j < min(len(s2), i+window+1); j++ {
+			if !matched2[j] && s1[i] == s2[j] {
+				matched1[i], matched2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	return (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions/2))/m) / 3
+}
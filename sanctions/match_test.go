@@ -0,0 +1,44 @@
+package sanctions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		want float64
+	}{
+		{"martha", "marhta", 0.961},
+		{"dwayne", "duane", 0.840},
+		{"dixon", "dicksonx", 0.813},
+		{"same", "same", 1},
+		{"", "", 1},
+		{"abc", "", 0},
+		{"abc", "xyz", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.a+"/"+tc.b, func(t *testing.T) {
+			require.InDelta(t, tc.want, JaroWinkler(tc.a, tc.b), 0.001)
+			require.InDelta(t, tc.want, JaroWinkler(tc.b, tc.a), 0.001)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	require.Equal(t, "tran van duc", Normalize("  Trần  Văn Đức "))
+	require.Equal(t, "o brien john", Normalize("O'Brien, John"))
+	require.Equal(t, "jose muller", Normalize("JOSÉ MÜLLER"))
+	require.Equal(t, "", Normalize("--"))
+}
+
+func TestSimilarity(t *testing.T) {
+	require.Equal(t, 1.0, Similarity("Trần Văn Đức", "tran van duc"))
+	// reordered names are the same name
+	require.Equal(t, 1.0, Similarity("Petrov, Ivan", "Ivan Petrov"))
+	require.Greater(t, Similarity("Ivan Petrow", "Ivan Petrov"), 0.95)
+	require.Less(t, Similarity("Nguyen Thi Mai", "Ivan Petrov"), 0.7)
+}
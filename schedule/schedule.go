@@ -0,0 +1,136 @@
+// Package schedule computes the dates recurring payments fall on.
+//
+// Every rule first produces a nominal date (the 15th, every Monday, the
+// last day of the month), which the calendar then moves onto a business
+// day. The next occurrence is always computed from the previous nominal
+// date, so adjustments for weekends and holidays never make a schedule drift.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Weekly          Frequency = "weekly"
+	Monthly         Frequency = "monthly"
+	LastBusinessDay Frequency = "last_business_day"
+)
+
+// Rule describes when a recurring payment is due.
+type Rule struct {
+	Frequency Frequency
+	// Weekday is the day of the week for weekly rules.
+	Weekday time.Weekday
+	// DayOfMonth is the day for monthly rules; months that are too short
+	// use their last day instead.
+	DayOfMonth int
+}
+
+func (rule Rule) Validate() error {
+	switch rule.Frequency {
+	case Weekly:
+		if rule.Weekday < time.Sunday || rule.Weekday > time.Saturday {
+			return fmt.Errorf("invalid weekday %d", rule.Weekday)
+		}
+	case Monthly:
+		if rule.DayOfMonth < 1 || rule.DayOfMonth > 31 {
+			return fmt.Errorf("invalid day of month %d", rule.DayOfMonth)
+		}
+	case LastBusinessDay:
+	default:
+		return errors.New("unknown frequency " + string(rule.Frequency))
+	}
+	return nil
+}
+
+// FirstOnOrAfter returns the first nominal date of the rule on or after day.
+func (rule Rule) FirstOnOrAfter(day time.Time) time.Time {
+	day = Date(day)
+	switch rule.Frequency {
+	case Weekly:
+		return day.AddDate(0, 0, (int(rule.Weekday)-int(day.Weekday())+7)%7)
+	case Monthly:
+		nominal := dayOfMonth(day.Year(), day.Month(), rule.DayOfMonth)
+		if nominal.Before(day) {
+			nominal = dayOfMonth(day.Year(), day.Month()+1, rule.DayOfMonth)
+		}
+		return nominal
+	default:
+		return lastDayOfMonth(day.Year(), day.Month())
+	}
+}
+
+// After returns the nominal date that follows nominal.
+func (rule Rule) After(nominal time.Time) time.Time {
+	return rule.FirstOnOrAfter(Date(nominal).AddDate(0, 0, 1))
+}
+
+// RunDate moves a nominal date onto a business day: forward for most rules,
+// backward for LastBusinessDay so the payment stays in its month.
+func (rule Rule) RunDate(nominal time.Time, calendar Calendar) time.Time {
+	if rule.Frequency == LastBusinessDay {
+		return calendar.PreviousBusinessDay(nominal)
+	}
+	return calendar.NextBusinessDay(nominal)
+}
+
+// Calendar tells business days apart from weekends and holidays.
+type Calendar struct {
+	holidays map[time.Time]bool
+}
+
+func NewCalendar(holidays ...time.Time) Calendar {
+	calendar := Calendar{holidays: make(map[time.Time]bool, len(holidays))}
+	for _, holiday := range holidays {
+		calendar.holidays[Date(holiday)] = true
+	}
+	return calendar
+}
+
+func (calendar Calendar) IsBusinessDay(day time.Time) bool {
+	day = Date(day)
+	weekday := day.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday && !calendar.holidays[day]
+}
+
+// NextBusinessDay returns day itself if it is a business day, otherwise the
+// first business day after it.
+func (calendar Calendar) NextBusinessDay(day time.Time) time.Time {
+	day = Date(day)
+	for !calendar.IsBusinessDay(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// PreviousBusinessDay returns day itself if it is a business day, otherwise
+// the last business day before it.
+func (calendar Calendar) PreviousBusinessDay(day time.Time) time.Time {
+	day = Date(day)
+	for !calendar.IsBusinessDay(day) {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// Date truncates t to midnight UTC of its calendar day.
+func Date(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func dayOfMonth(year int, month time.Month, day int) time.Time {
+	last := lastDayOfMonth(year, month)
+	if day > last.Day() {
+		return last
+	}
+	return time.Date(last.Year(), last.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+func lastDayOfMonth(year int, month time.Month) time.Time {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+}
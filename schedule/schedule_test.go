@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestFirstOnOrAfter(t *testing.T) {
+	testCases := []struct {
+		name string
+		rule Rule
+		day  time.Time
+		want time.Time
+	}{
+		{"WeeklySameDay", Rule{Frequency: Weekly, Weekday: time.Monday}, date(2024, 1, 1), date(2024, 1, 1)},
+		{"WeeklyLaterInWeek", Rule{Frequency: Weekly, Weekday: time.Friday}, date(2024, 1, 1), date(2024, 1, 5)},
+		{"WeeklyNextWeek", Rule{Frequency: Weekly, Weekday: time.Monday}, date(2024, 1, 2), date(2024, 1, 8)},
+		{"MonthlyThisMonth", Rule{Frequency: Monthly, DayOfMonth: 15}, date(2024, 1, 10), date(2024, 1, 15)},
+		{"MonthlyNextMonth", Rule{Frequency: Monthly, DayOfMonth: 15}, date(2024, 1, 16), date(2024, 2, 15)},
+		{"MonthlyShortMonth", Rule{Frequency: Monthly, DayOfMonth: 31}, date(2024, 2, 1), date(2024, 2, 29)},
+		{"MonthlyAcrossYear", Rule{Frequency: Monthly, DayOfMonth: 5}, date(2024, 12, 6), date(2025, 1, 5)},
+		{"LastBusinessDay", Rule{Frequency: LastBusinessDay}, date(2024, 3, 10), date(2024, 3, 31)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.rule.FirstOnOrAfter(tc.day))
+		})
+	}
+}
+
+func TestAfterDoesNotDrift(t *testing.T) {
+	rule := Rule{Frequency: Monthly, DayOfMonth: 31}
+	nominal := date(2024, 1, 31)
+	var got []time.Time
+	for i := 0; i < 4; i++ {
+		nominal = rule.After(nominal)
+		got = append(got, nominal)
+	}
+	require.Equal(t, []time.Time{date(2024, 2, 29), date(2024, 3, 31), date(2024, 4, 30), date(2024, 5, 31)}, got)
+}
+
+func TestRunDate(t *testing.T) {
+	// Friday 2024-03-29 is a holiday
+	calendar := NewCalendar(date(2024, 3, 29))
+
+	// Saturday 2024-06-15 moves to Monday
+	monthly := Rule{Frequency: Monthly, DayOfMonth: 15}
+	require.Equal(t, date(2024, 6, 17), monthly.RunDate(date(2024, 6, 15), calendar))
+
+	// the holiday moves a Friday payment to the following Monday
+	weekly := Rule{Frequency: Weekly, Weekday: time.Friday}
+	require.Equal(t, date(2024, 4, 1), weekly.RunDate(date(2024, 3, 29), calendar))
+
+	// Sunday 2024-03-31, then the holiday, so Thursday
+	last := Rule{Frequency: LastBusinessDay}
+	require.Equal(t, date(2024, 3, 28), last.RunDate(date(2024, 3, 31), calendar))
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Rule{Frequency: Weekly, Weekday: time.Sunday}.Validate())
+	require.NoError(t, Rule{Frequency: Monthly, DayOfMonth: 31}.Validate())
+	require.NoError(t, Rule{Frequency: LastBusinessDay}.Validate())
+	require.Error(t, Rule{Frequency: Monthly}.Validate())
+	require.Error(t, Rule{Frequency: Weekly, Weekday: 7}.Validate())
+	require.Error(t, Rule{Frequency: "daily"}.Validate())
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Account numbers look like DIGI0001000000012345: a four letter bank code,
+// a four digit branch code, a ten digit serial and two ISO 7064 mod 97-10
+// check digits (the same scheme IBAN uses).
+const (
+	BankCode   = "DIGI"
+	BranchCode = "0001"
+
+	accountSerialDigits = 10
+	accountNumberLength = len(BankCode) + len(BranchCode) + accountSerialDigits + 2
+)
+
+// NewAccountNumber builds the account number for the given serial.
+func NewAccountNumber(serial int64) string {
+	bban := fmt.Sprintf("%s%s%0*d", BankCode, BranchCode, accountSerialDigits, serial)
+	remainder, _ := mod97(bban + "00")
+	return fmt.Sprintf("%s%02d", bban, 98-remainder)
+}
+
+// IsValidAccountNumber checks the layout and the check digits of an account number.
+func IsValidAccountNumber(number string) bool {
+	if len(number) != accountNumberLength {
+		return false
+	}
+	for i, r := range number {
+		isLetter := r >= 'A' && r <= 'Z'
+		isDigit := r >= '0' && r <= '9'
+		if i < len(BankCode) && !isLetter || i >= len(BankCode) && !isDigit {
+			return false
+		}
+	}
+	remainder, ok := mod97(number)
+	return ok && remainder == 1
+}
+
+// NormalizeAccountNumber strips spaces and upper-cases a user supplied account number.
+func NormalizeAccountNumber(number string) string {
+	return strings.ToUpper(strings.ReplaceAll(number, " ", ""))
+}
+
+// mod97 computes the remainder of the number modulo 97, converting letters
+// to 10..35 as ISO 7064 does.
+func mod97(s string) (int, bool) {
+	remainder := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			remainder = (remainder*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			remainder = (remainder*100 + int(r-'A') + 10) % 97
+		default:
+			return 0, false
+		}
+	}
+	return remainder, true
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountNumber(t *testing.T) {
+	number := NewAccountNumber(12345)
+	require.Equal(t, "DIGI0001000001234559", number)
+	require.True(t, IsValidAccountNumber(number))
+
+	for i := 0; i < 100; i++ {
+		require.True(t, IsValidAccountNumber(RandomAccountNumber()))
+	}
+}
+
+func TestAccountNumberRejectsTypos(t *testing.T) {
+	number := NewAccountNumber(RandomInt(1, 9999999999))
+
+	for i := len(BankCode); i < len(number); i++ {
+		digit := number[i]
+		replacement := byte('0' + (digit-'0'+1)%10)
+		typo := number[:i] + string(replacement) + number[i+1:]
+		require.False(t, IsValidAccountNumber(typo), typo)
+	}
+
+	swapped := number[:10] + string(number[11]) + string(number[10]) + number[12:]
+	if swapped != number {
+		require.False(t, IsValidAccountNumber(swapped))
+	}
+
+	require.False(t, IsValidAccountNumber(""))
+	require.False(t, IsValidAccountNumber("1234"))
+	require.False(t, IsValidAccountNumber("digi"+number[4:]))
+	require.True(t, IsValidAccountNumber(NormalizeAccountNumber(" digi"+number[4:])))
+}
@@ -0,0 +1,20 @@
+package utils
+
+// Categories a transfer can be filed under.
+const (
+	CategoryGeneral  = "general"
+	CategoryBills    = "bills"
+	CategoryRent     = "rent"
+	CategorySalary   = "salary"
+	CategoryShopping = "shopping"
+	CategoryTravel   = "travel"
+	CategorySavings  = "savings"
+)
+
+func IsValidCategory(category string) bool {
+	switch category {
+	case CategoryGeneral, CategoryBills, CategoryRent, CategorySalary, CategoryShopping, CategoryTravel, CategorySavings:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Config stores all configuration of the application.
+// The values are read by viper from a config file or environment variables.
+type Config struct {
+	DBSource string `mapstructure:"DB_SOURCE"`
+	// DBMaxConns and DBMinConns bound the connections of the pool, which
+	// closes them after DBMaxConnLifetime, or DBMaxConnIdleTime unused, and
+	// checks them every DBHealthCheckPeriod. Zero keeps the pgxpool default.
+	DBMaxConns          int32         `mapstructure:"DB_MAX_CONNS"`
+	DBMinConns          int32         `mapstructure:"DB_MIN_CONNS"`
+	DBMaxConnLifetime   time.Duration `mapstructure:"DB_MAX_CONN_LIFETIME"`
+	DBMaxConnIdleTime   time.Duration `mapstructure:"DB_MAX_CONN_IDLE_TIME"`
+	DBHealthCheckPeriod time.Duration `mapstructure:"DB_HEALTH_CHECK_PERIOD"`
+	// TxMaxRetries is how many times a transaction that hit a serialization
+	// failure or a deadlock runs again; zero uses the store's default.
+	TxMaxRetries int `mapstructure:"TX_MAX_RETRIES"`
+	// TransferIsolation is the isolation level of transfers, like serializable;
+	// empty uses the database's default.
+	TransferIsolation string        `mapstructure:"TRANSFER_ISOLATION"`
+	ServerAddress     string        `mapstructure:"SERVER_ADDRESS"`
+	IdempotencyKeyTTL time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	FXQuoteTTL        time.Duration `mapstructure:"FX_QUOTE_TTL"`
+	// ReversalWindow is how long after a transfer its sender may reverse it;
+	// staff can reverse transfers at any time.
+	ReversalWindow time.Duration `mapstructure:"REVERSAL_WINDOW"`
+	// ApprovalThresholds lists, as CUR:amount pairs separated by commas, the
+	// amount above which a transfer in that currency needs a second user's
+	// approval. Currencies that aren't listed never need one.
+	ApprovalThresholds string        `mapstructure:"APPROVAL_THRESHOLDS"`
+	PendingTransferTTL time.Duration `mapstructure:"PENDING_TRANSFER_TTL"`
+	// PaymentRequestTTL is the default, and longest, lifetime of a payment request.
+	PaymentRequestTTL time.Duration `mapstructure:"PAYMENT_REQUEST_TTL"`
+	// TransferConfirmationTTL is how long a sender has to confirm a transfer to an alias.
+	TransferConfirmationTTL time.Duration `mapstructure:"TRANSFER_CONFIRMATION_TTL"`
+
+	// SanctionsListPath is the CSV or XML sanctions list new users and
+	// transfer parties are screened against; empty disables screening.
+	SanctionsListPath string `mapstructure:"SANCTIONS_LIST_PATH"`
+	// Names at least SanctionsFlagThreshold similar to a listed name open a
+	// case; from SanctionsBlockThreshold on the operation is refused.
+	SanctionsFlagThreshold  float64 `mapstructure:"SANCTIONS_FLAG_THRESHOLD"`
+	SanctionsBlockThreshold float64 `mapstructure:"SANCTIONS_BLOCK_THRESHOLD"`
+
+	ScheduledTransferInterval    time.Duration `mapstructure:"SCHEDULED_TRANSFER_INTERVAL"`
+	ScheduledTransferBatchSize   int           `mapstructure:"SCHEDULED_TRANSFER_BATCH_SIZE"`
+	ScheduledTransferMaxAttempts int32         `mapstructure:"SCHEDULED_TRANSFER_MAX_ATTEMPTS"`
+	ScheduledTransferRetryDelay  time.Duration `mapstructure:"SCHEDULED_TRANSFER_RETRY_DELAY"`
+
+	// ACH files are written to ACHOutboxDir, sent by ACHOriginRouting, our
+	// routing number, to the ACH operator at ACHDestinationRouting.
+	ACHOutboxDir          string `mapstructure:"ACH_OUTBOX_DIR"`
+	ACHOriginRouting      string `mapstructure:"ACH_ORIGIN_ROUTING"`
+	ACHOriginName         string `mapstructure:"ACH_ORIGIN_NAME"`
+	ACHDestinationRouting string `mapstructure:"ACH_DESTINATION_ROUTING"`
+	ACHDestinationName    string `mapstructure:"ACH_DESTINATION_NAME"`
+	ACHCompanyID          string `mapstructure:"ACH_COMPANY_ID"`
+	ACHCompanyName        string `mapstructure:"ACH_COMPANY_NAME"`
+	// ACHExportTime is the UTC time of day, like 22:00, of the nightly export.
+	ACHExportTime      string `mapstructure:"ACH_EXPORT_TIME"`
+	ACHExportBatchSize int32  `mapstructure:"ACH_EXPORT_BATCH_SIZE"`
+
+	// TransferQueueInterval is how often queued async transfers are settled.
+	TransferQueueInterval  time.Duration `mapstructure:"TRANSFER_QUEUE_INTERVAL"`
+	TransferQueueBatchSize int           `mapstructure:"TRANSFER_QUEUE_BATCH_SIZE"`
+
+	// ReconciliationTime is the UTC time of day, like 03:00, the balances are
+	// checked against the entries. With ReconciliationRepair the run posts
+	// adjustments for the accounts that drifted.
+	ReconciliationTime   string `mapstructure:"RECONCILIATION_TIME"`
+	ReconciliationRepair bool   `mapstructure:"RECONCILIATION_REPAIR"`
+
+	// LedgerSigningKey is the path of the ed25519 PEM key the daily ledger
+	// checkpoints are signed with; without one no checkpoints are written.
+	// They go to LedgerCheckpointDir at LedgerCheckpointTime, UTC.
+	LedgerSigningKey     string `mapstructure:"LEDGER_SIGNING_KEY"`
+	LedgerCheckpointDir  string `mapstructure:"LEDGER_CHECKPOINT_DIR"`
+	LedgerCheckpointTime string `mapstructure:"LEDGER_CHECKPOINT_TIME"`
+}
+
+// LoadConfig reads configuration from app.env in path, overridden by environment variables.
+func LoadConfig(path string) (config Config, err error) {
+	viper.AddConfigPath(path)
+	viper.SetConfigName("app")
+	viper.SetConfigType("env")
+
+	viper.AutomaticEnv()
+
+	err = viper.ReadInConfig()
+	if err != nil {
+		return
+	}
+
+	err = viper.Unmarshal(&config)
+	if err != nil {
+		return
+	}
+	if config.DBMaxConns > 0 && config.DBMinConns > config.DBMaxConns {
+		err = fmt.Errorf("db min conns %d is above the max conns %d", config.DBMinConns, config.DBMaxConns)
+		return
+	}
+	_, err = ParseCurrencyAmounts(config.ApprovalThresholds)
+	if err != nil {
+		return
+	}
+	if config.SanctionsFlagThreshold > config.SanctionsBlockThreshold {
+		err = fmt.Errorf("sanctions flag threshold %v is above the block threshold %v", config.SanctionsFlagThreshold, config.SanctionsBlockThreshold)
+		return
+	}
+	for _, routing := range []string{config.ACHOriginRouting, config.ACHDestinationRouting} {
+		if !IsValidRoutingNumber(routing) {
+			err = fmt.Errorf("invalid ACH routing number %q", routing)
+			return
+		}
+	}
+	_, err = config.ACHExportClock()
+	if err != nil {
+		return
+	}
+	_, err = config.ReconciliationClock()
+	if err != nil {
+		return
+	}
+	if config.LedgerSigningKey != "" {
+		_, err = config.LedgerCheckpointClock()
+	}
+	return
+}
+
+// ACHExportClock returns the time of day of the nightly ACH export as an
+// offset from midnight UTC.
+func (config Config) ACHExportClock() (time.Duration, error) {
+	return parseClock("ACH export", config.ACHExportTime)
+}
+
+// ReconciliationClock returns the time of day of the nightly reconciliation
+// as an offset from midnight UTC.
+func (config Config) ReconciliationClock() (time.Duration, error) {
+	return parseClock("reconciliation", config.ReconciliationTime)
+}
+
+// LedgerCheckpointClock returns the time of day of the daily ledger
+// checkpoint as an offset from midnight UTC.
+func (config Config) LedgerCheckpointClock() (time.Duration, error) {
+	return parseClock("ledger checkpoint", config.LedgerCheckpointTime)
+}
+
+// parseClock parses a time of day like 22:00 into an offset from midnight.
+func parseClock(what string, clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s time %q", what, clock)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ApprovalThreshold returns the approval threshold configured for currency.
+func (config Config) ApprovalThreshold(currency string) (int64, bool) {
+	thresholds, err := ParseCurrencyAmounts(config.ApprovalThresholds)
+	if err != nil {
+		return 0, false
+	}
+	threshold, ok := thresholds[currency]
+	return threshold, ok
+}
+
+// NeedsApproval reports whether a transfer of amount in currency is above the
+// approval threshold of the currency, so another user has to approve it
+// before it runs. Every way of moving money checks it.
+func (config Config) NeedsApproval(currency string, amount int64) bool {
+	threshold, ok := config.ApprovalThreshold(currency)
+	return ok && amount > threshold
+}
+
+// ParseCurrencyAmounts parses a list like "USD:1000000,EUR:1000000" into a
+// map from currency to amount.
+func ParseCurrencyAmounts(s string) (map[string]int64, error) {
+	amounts := make(map[string]int64)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		currency, value, found := strings.Cut(pair, ":")
+		if !found || !IsValidCurrency(currency) {
+			return nil, fmt.Errorf("invalid currency amount %q", pair)
+		}
+		amount, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || amount < 0 {
+			return nil, fmt.Errorf("invalid currency amount %q", pair)
+		}
+		amounts[currency] = amount
+	}
+	return amounts, nil
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCurrencyAmounts(t *testing.T) {
+	amounts, err := ParseCurrencyAmounts("USD:1000000, EUR:500,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{USD: 1000000, EUR: 500}, amounts)
+
+	amounts, err = ParseCurrencyAmounts("")
+	require.NoError(t, err)
+	require.Empty(t, amounts)
+
+	for _, invalid := range []string{"USD", "XYZ:10", "USD:ten", "USD:-1"} {
+		_, err := ParseCurrencyAmounts(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestApprovalThreshold(t *testing.T) {
+	config := Config{ApprovalThresholds: "USD:1000"}
+
+	threshold, ok := config.ApprovalThreshold(USD)
+	require.True(t, ok)
+	require.Equal(t, int64(1000), threshold)
+
+	_, ok = config.ApprovalThreshold(EUR)
+	require.False(t, ok)
+}
+
+func TestNeedsApproval(t *testing.T) {
+	config := Config{ApprovalThresholds: "USD:1000"}
+
+	require.False(t, config.NeedsApproval(USD, 1000))
+	require.True(t, config.NeedsApproval(USD, 1001))
+	require.False(t, config.NeedsApproval(EUR, 1_000_000))
+}
+
+func TestACHExportClock(t *testing.T) {
+	clock, err := Config{ACHExportTime: "22:30"}.ACHExportClock()
+	require.NoError(t, err)
+	require.Equal(t, 22*time.Hour+30*time.Minute, clock)
+
+	for _, invalid := range []string{"", "24:00", "10pm"} {
+		_, err := Config{ACHExportTime: invalid}.ACHExportClock()
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestReconciliationClock(t *testing.T) {
+	clock, err := Config{ReconciliationTime: "03:00"}.ReconciliationClock()
+	require.NoError(t, err)
+	require.Equal(t, 3*time.Hour, clock)
+
+	_, err = Config{ReconciliationTime: "3am"}.ReconciliationClock()
+	require.Error(t, err)
+}
+
+func TestLedgerCheckpointClock(t *testing.T) {
+	clock, err := Config{LedgerCheckpointTime: "00:05"}.LedgerCheckpointClock()
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Minute, clock)
+
+	_, err = Config{}.LedgerCheckpointClock()
+	require.Error(t, err)
+}
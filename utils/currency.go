@@ -6,6 +6,15 @@ const (
 	VND = "VND"
 )
 
+// MinorUnits returns the number of decimals of currency. Amounts are stored
+// as whole numbers of its minor unit, like cents.
+func MinorUnits(currency string) int {
+	if currency == VND {
+		return 0
+	}
+	return 2
+}
+
 func IsValidCurrency(currency string) bool {
 	switch currency {
 	case USD, EUR, VND:
@@ -0,0 +1,15 @@
+package utils
+
+import "strings"
+
+// MaskName hides all but the first letter of each word of a name, so a
+// sender can recognise a recipient without learning their full name:
+// "Nguyen Van An" becomes "N***** V** A*".
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(words, " ")
+}
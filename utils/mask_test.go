@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskName(t *testing.T) {
+	require.Equal(t, "N***** V** A*", MaskName("Nguyen Van An"))
+	require.Equal(t, "J*** D**", MaskName("  Jane   Doe "))
+	require.Equal(t, "Đ** T****", MaskName("Đức Thắng"))
+	require.Equal(t, "X", MaskName("X"))
+	require.Equal(t, "", MaskName(""))
+}
@@ -41,3 +41,7 @@ func RandomCurrency() string {
 func RandomEmail() string {
 	return RandomString(6) + "@example.com"
 }
+
+func RandomAccountNumber() string {
+	return NewAccountNumber(RandomInt(1, 9999999999))
+}
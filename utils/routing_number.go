@@ -0,0 +1,18 @@
+package utils
+
+// IsValidRoutingNumber reports whether s is a nine digit ABA routing number
+// with a valid check digit.
+func IsValidRoutingNumber(s string) bool {
+	if len(s) != 9 {
+		return false
+	}
+	weights := [9]int{3, 7, 1, 3, 7, 1, 3, 7, 1}
+	sum := 0
+	for i, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+		sum += int(r-'0') * weights[i]
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidRoutingNumber(t *testing.T) {
+	for _, valid := range []string{"011000015", "123456780", "021000021"} {
+		require.True(t, IsValidRoutingNumber(valid), valid)
+	}
+	for _, invalid := range []string{"", "12345678", "123456789", "1234567800", "12345678O"} {
+		require.False(t, IsValidRoutingNumber(invalid), invalid)
+	}
+}
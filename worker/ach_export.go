@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"tutorial.sqlc.dev/app/ach"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// ACHExportWorker writes the outbound payments made during the day to ACH
+// files every night.
+type ACHExportWorker struct {
+	exporter *ach.Exporter
+	// at is the time of the export as an offset from midnight UTC.
+	at time.Duration
+}
+
+func NewACHExportWorker(config utils.Config, store db.Store) (*ACHExportWorker, error) {
+	at, err := config.ACHExportClock()
+	if err != nil {
+		return nil, err
+	}
+	return &ACHExportWorker{
+		exporter: ach.NewExporter(config, store),
+		at:       at,
+	}, nil
+}
+
+// Start runs the export at the configured time every day until ctx is done.
+func (worker *ACHExportWorker) Start(ctx context.Context) {
+	for {
+		timer := time.NewTimer(time.Until(nextRun(time.Now(), worker.at)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if _, err := worker.RunOnce(ctx); err != nil {
+				log.Printf("cannot export outbound payments: %v", err)
+			}
+		}
+	}
+}
+
+// RunOnce exports every pending outbound payment, in as many files as the
+// batch size requires, and returns the paths of the files.
+func (worker *ACHExportWorker) RunOnce(ctx context.Context) ([]string, error) {
+	var paths []string
+	for {
+		result, path, err := worker.exporter.Export(ctx)
+		if errors.Is(err, db.ErrNoOutboundPayments) {
+			return paths, nil
+		}
+		if err != nil {
+			return paths, err
+		}
+		log.Printf("exported %d outbound payments to %s", len(result.Payments), path)
+		paths = append(paths, path)
+	}
+}
+
+// nextRun returns the first time after now that is the offset at from a
+// midnight UTC.
+func nextRun(now time.Time, at time.Duration) time.Time {
+	next := now.UTC().Truncate(24 * time.Hour).Add(at)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
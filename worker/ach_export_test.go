@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestACHExportWorkerRunOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := utils.Config{
+		ACHOutboxDir:          t.TempDir(),
+		ACHOriginRouting:      "123456780",
+		ACHDestinationRouting: "011000015",
+		ACHExportTime:         "22:00",
+		ACHExportBatchSize:    1,
+	}
+	modifiers := []string{"A", "B"}
+	store := mockdb.NewMockStore(ctrl)
+	// every export loads the holidays for the effective date
+	store.EXPECT().ListHolidays(gomock.Any(), gomock.Any()).Times(3).Return([]db.Holiday{}, nil)
+	gomock.InOrder(
+		store.EXPECT().
+			ExportOutboundPaymentsTx(gomock.Any(), gomock.Any()).
+			Times(2).
+			DoAndReturn(func(_ context.Context, arg db.ExportOutboundPaymentsTxParams) (db.ExportOutboundPaymentsTxResult, error) {
+				require.Equal(t, int32(1), arg.Limit)
+				file := db.AchFile{FileName: arg.FileName(modifiers[0]), FileIDModifier: modifiers[0]}
+				modifiers = modifiers[1:]
+				payments := []db.OutboundPayment{{ID: 1, RoutingNumber: "011000015", AccountNumber: "1", Amount: 100}}
+				return db.ExportOutboundPaymentsTxResult{File: file, Payments: payments}, arg.Write(file, payments)
+			}),
+		store.EXPECT().
+			ExportOutboundPaymentsTx(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.ExportOutboundPaymentsTxResult{}, db.ErrNoOutboundPayments),
+	)
+
+	worker, err := NewACHExportWorker(config, store)
+	require.NoError(t, err)
+	paths, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	require.Equal(t, config.ACHOutboxDir, filepath.Dir(paths[0]))
+	require.FileExists(t, paths[1])
+}
+
+func TestNextRun(t *testing.T) {
+	at := 22 * time.Hour
+	morning := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC), nextRun(morning, at))
+	night := time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2026, 10, 20, 22, 0, 0, 0, time.UTC), nextRun(night, at))
+}
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"crypto/ed25519"
+	"log"
+	"time"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/ledger"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// LedgerCheckpointWorker signs the heads of the entry hash chains every day
+// and writes them to a file that can be anchored outside the bank.
+type LedgerCheckpointWorker struct {
+	store db.Store
+	key   ed25519.PrivateKey
+	dir   string
+	// at is the time of the run as an offset from midnight UTC.
+	at time.Duration
+}
+
+func NewLedgerCheckpointWorker(config utils.Config, store db.Store) (*LedgerCheckpointWorker, error) {
+	at, err := config.LedgerCheckpointClock()
+	if err != nil {
+		return nil, err
+	}
+	key, err := ledger.LoadSigningKey(config.LedgerSigningKey)
+	if err != nil {
+		return nil, err
+	}
+	return &LedgerCheckpointWorker{
+		store: store,
+		key:   key,
+		dir:   config.LedgerCheckpointDir,
+		at:    at,
+	}, nil
+}
+
+// Start writes a checkpoint at the configured time every day until ctx is
+// done.
+func (worker *LedgerCheckpointWorker) Start(ctx context.Context) {
+	for {
+		timer := time.NewTimer(time.Until(nextRun(time.Now(), worker.at)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if _, err := worker.RunOnce(ctx, time.Now()); err != nil {
+				log.Printf("cannot write ledger checkpoint: %v", err)
+			}
+		}
+	}
+}
+
+// RunOnce signs the current chain heads and returns the path of the file it
+// wrote.
+func (worker *LedgerCheckpointWorker) RunOnce(ctx context.Context, now time.Time) (string, error) {
+	checkpoint, err := ledger.NewCheckpoint(ctx, worker.store, worker.key, now)
+	if err != nil {
+		return "", err
+	}
+	path, err := ledger.WriteCheckpoint(worker.dir, checkpoint)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("wrote ledger checkpoint %s: %d chains, root %s", path, len(checkpoint.Heads), checkpoint.Root)
+	return path, nil
+}
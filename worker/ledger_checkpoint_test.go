@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/ledger"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestLedgerCheckpointWorkerRunOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "ledger.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListChainHeads(gomock.Any()).
+		Times(1).
+		Return([]db.ListChainHeadsRow{{ID: 1, LastEntrySeq: 4, LastEntryHash: []byte{0xab}}}, nil)
+
+	config := utils.Config{
+		LedgerSigningKey:     keyPath,
+		LedgerCheckpointDir:  filepath.Join(dir, "checkpoints"),
+		LedgerCheckpointTime: "00:05",
+	}
+	worker, err := NewLedgerCheckpointWorker(config, store)
+	require.NoError(t, err)
+	path, err := worker.RunOnce(context.Background(), time.Date(2026, 10, 2, 0, 5, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	checkpoint, err := ledger.ReadCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, []ledger.ChainHead{{AccountID: 1, ChainSeq: 4, Hash: "ab"}}, checkpoint.Heads)
+	require.NoError(t, checkpoint.VerifySignature(pub))
+
+	config.LedgerSigningKey = filepath.Join(dir, "missing.pem")
+	_, err = NewLedgerCheckpointWorker(config, store)
+	require.Error(t, err)
+}
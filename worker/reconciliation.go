@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// ReconciliationWorker checks every night that the balances of the accounts
+// still match their entries.
+type ReconciliationWorker struct {
+	store  db.Store
+	repair bool
+	// at is the time of the run as an offset from midnight UTC.
+	at time.Duration
+}
+
+func NewReconciliationWorker(config utils.Config, store db.Store) (*ReconciliationWorker, error) {
+	at, err := config.ReconciliationClock()
+	if err != nil {
+		return nil, err
+	}
+	return &ReconciliationWorker{
+		store:  store,
+		repair: config.ReconciliationRepair,
+		at:     at,
+	}, nil
+}
+
+// Start runs the reconciliation at the configured time every day until ctx
+// is done.
+func (worker *ReconciliationWorker) Start(ctx context.Context) {
+	for {
+		timer := time.NewTimer(time.Until(nextRun(time.Now(), worker.at)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if _, err := worker.RunOnce(ctx); err != nil {
+				log.Printf("cannot reconcile the ledger: %v", err)
+			}
+		}
+	}
+}
+
+// RunOnce reconciles the ledger and logs what drifted.
+func (worker *ReconciliationWorker) RunOnce(ctx context.Context) (db.ReconciliationResult, error) {
+	result, err := worker.store.Reconcile(ctx, db.ReconcileParams{Repair: worker.repair})
+	if err != nil {
+		return result, err
+	}
+	for _, discrepancy := range result.Discrepancies {
+		log.Printf("reconciliation run %d: %s", result.Run.ID, discrepancy)
+	}
+	log.Printf("reconciliation run %d: checked %d accounts, %d drifted", result.Run.ID, result.Run.AccountsChecked, result.Run.Discrepancies)
+	return result, nil
+}
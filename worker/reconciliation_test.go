@@ -0,0 +1,32 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestReconciliationWorkerRunOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		Reconcile(gomock.Any(), gomock.Eq(db.ReconcileParams{Repair: true})).
+		Times(1).
+		Return(db.ReconciliationResult{Run: db.ReconciliationRun{ID: 1, Repair: true}}, nil)
+
+	worker, err := NewReconciliationWorker(utils.Config{ReconciliationTime: "03:00", ReconciliationRepair: true}, store)
+	require.NoError(t, err)
+	result, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Run.ID)
+
+	_, err = NewReconciliationWorker(utils.Config{ReconciliationTime: "3am"}, store)
+	require.Error(t, err)
+}
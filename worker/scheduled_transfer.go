@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// ScheduledTransferWorker periodically executes scheduled transfers and
+// standing orders that are due.
+type ScheduledTransferWorker struct {
+	store    db.Store
+	interval time.Duration
+	params   db.RunScheduledTransfersParams
+}
+
+func NewScheduledTransferWorker(config utils.Config, store db.Store) *ScheduledTransferWorker {
+	return &ScheduledTransferWorker{
+		store:    store,
+		interval: config.ScheduledTransferInterval,
+		params: db.RunScheduledTransfersParams{
+			Limit:              config.ScheduledTransferBatchSize,
+			MaxAttempts:        config.ScheduledTransferMaxAttempts,
+			RetryDelay:         config.ScheduledTransferRetryDelay,
+			NeedsApproval:      config.NeedsApproval,
+			PendingTransferTTL: config.PendingTransferTTL,
+		},
+	}
+}
+
+// Start runs the worker every interval until ctx is done.
+func (worker *ScheduledTransferWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(worker.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := worker.RunOnce(ctx); err != nil {
+				log.Printf("cannot run scheduled transfers: %v", err)
+			}
+		}
+	}
+}
+
+// RunOnce materializes the standing order occurrences due today and then
+// executes the transfers that are due now.
+func (worker *ScheduledTransferWorker) RunOnce(ctx context.Context) error {
+	if _, err := worker.store.MaterializeStandingOrders(ctx, time.Now()); err != nil {
+		return err
+	}
+	runs, err := worker.store.RunDueScheduledTransfers(ctx, worker.params)
+	for _, run := range runs {
+		if run.Outcome != db.ScheduledRunExecuted {
+			log.Printf("scheduled transfer %d %s: %s", run.ScheduledTransferID, run.Outcome, run.Reason)
+		}
+	}
+	return err
+}
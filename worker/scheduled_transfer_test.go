@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestScheduledTransferWorkerRunOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := utils.Config{
+		ScheduledTransferInterval:    time.Minute,
+		ScheduledTransferBatchSize:   10,
+		ScheduledTransferMaxAttempts: 3,
+		ScheduledTransferRetryDelay:  time.Hour,
+		ApprovalThresholds:           "USD:10000",
+		PendingTransferTTL:           time.Hour,
+	}
+	store := mockdb.NewMockStore(ctrl)
+	requireParams := func(_ context.Context, arg db.RunScheduledTransfersParams) {
+		require.Equal(t, 10, arg.Limit)
+		require.Equal(t, int32(3), arg.MaxAttempts)
+		require.Equal(t, time.Hour, arg.RetryDelay)
+		require.Equal(t, time.Hour, arg.PendingTransferTTL)
+		require.True(t, arg.NeedsApproval(utils.USD, 10001))
+		require.False(t, arg.NeedsApproval(utils.USD, 10000))
+	}
+	store.EXPECT().MaterializeStandingOrders(gomock.Any(), gomock.Any()).Times(2)
+	gomock.InOrder(
+		store.EXPECT().
+			RunDueScheduledTransfers(gomock.Any(), gomock.Any()).
+			Times(1).
+			Do(requireParams).
+			Return([]db.ScheduledTransferRun{{Outcome: db.ScheduledRunExecuted}, {Outcome: db.ScheduledRunHeld}}, nil),
+		store.EXPECT().
+			RunDueScheduledTransfers(gomock.Any(), gomock.Any()).
+			Times(1).
+			Do(requireParams).
+			Return(nil, errors.New("connection refused")),
+	)
+
+	worker := NewScheduledTransferWorker(config, store)
+	require.NoError(t, worker.RunOnce(context.Background()))
+	require.Error(t, worker.RunOnce(context.Background()))
+}
+
+func TestScheduledTransferWorkerMaterializeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().MaterializeStandingOrders(gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("connection refused"))
+	store.EXPECT().RunDueScheduledTransfers(gomock.Any(), gomock.Any()).Times(0)
+
+	worker := NewScheduledTransferWorker(utils.Config{}, store)
+	require.Error(t, worker.RunOnce(context.Background()))
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+// TransferQueueWorker periodically settles transfers that were queued with
+// POST /transfer in async mode.
+type TransferQueueWorker struct {
+	store     db.Store
+	interval  time.Duration
+	batchSize int
+}
+
+func NewTransferQueueWorker(config utils.Config, store db.Store) *TransferQueueWorker {
+	return &TransferQueueWorker{
+		store:     store,
+		interval:  config.TransferQueueInterval,
+		batchSize: config.TransferQueueBatchSize,
+	}
+}
+
+// Start runs the worker every interval until ctx is done.
+func (worker *TransferQueueWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(worker.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := worker.RunOnce(ctx); err != nil {
+				log.Printf("cannot process queued transfers: %v", err)
+			}
+		}
+	}
+}
+
+// RunOnce settles the queued transfers, up to the batch size.
+func (worker *TransferQueueWorker) RunOnce(ctx context.Context) error {
+	transfers, err := worker.store.ProcessQueuedTransfers(ctx, worker.batchSize)
+	for _, transfer := range transfers {
+		if transfer.Status == db.TransferFailed {
+			log.Printf("queued transfer %d failed: %s", transfer.ID, transfer.FailureReason)
+		}
+	}
+	return err
+}
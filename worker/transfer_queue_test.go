@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "tutorial.sqlc.dev/app/db/mock"
+	db "tutorial.sqlc.dev/app/db/sqlc"
+	"tutorial.sqlc.dev/app/utils"
+)
+
+func TestTransferQueueWorkerRunOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := utils.Config{
+		TransferQueueInterval:  time.Second,
+		TransferQueueBatchSize: 50,
+	}
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ProcessQueuedTransfers(gomock.Any(), gomock.Eq(50)).
+			Times(1).
+			Return([]db.Transfer{
+				{ID: 1, Status: db.TransferCompleted},
+				{ID: 2, Status: db.TransferFailed, FailureReason: "insufficient funds"},
+			}, nil),
+		store.EXPECT().
+			ProcessQueuedTransfers(gomock.Any(), gomock.Eq(50)).
+			Times(1).
+			Return(nil, errors.New("connection refused")),
+	)
+
+	worker := NewTransferQueueWorker(config, store)
+	require.NoError(t, worker.RunOnce(context.Background()))
+	require.Error(t, worker.RunOnce(context.Background()))
+}